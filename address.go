@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"syscall"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/user32"
+)
+
+type namedPointer struct {
+	name    string
+	pointer appconfig.Pointer
+}
+
+// namedPointers returns every pointer in the program's config,
+// named after the pointer and the section containing it.
+func (o *programUI) namedPointers() []namedPointer {
+	var pointers []namedPointer
+	for _, saveRestore := range o.program.SaveRestores {
+		sectionName := sectionDisplayName(o.program, saveRestore, saveRestore.DisplayName())
+		for _, pointer := range saveRestore.Pointers {
+			pointers = append(pointers, namedPointer{
+				name:    sectionName + ": " + pointer.DisplayName(),
+				pointer: pointer,
+			})
+		}
+	}
+
+	for _, writer := range o.program.Writers {
+		sectionName := sectionDisplayName(o.program, writer, writer.DisplayName())
+		for _, pointer := range writer.Pointers {
+			pointers = append(pointers, namedPointer{
+				name:    sectionName + ": " + pointer.Pointer.DisplayName(),
+				pointer: pointer.Pointer,
+			})
+		}
+	}
+
+	return pointers
+}
+
+// addPointerMenus adds "Copy resolved address" and "View memory"
+// submenus, and an
+// "Open in <tool>" submenu for each configured tool. Each submenu
+// contains an item for every pointer in the program's config.
+func (o *programUI) addPointerMenus() {
+	pointers := o.namedPointers()
+	if len(pointers) == 0 {
+		return
+	}
+
+	o.addPointerMenu(i18n.T(i18n.CopyAddressMenu), pointers, o.copyAddress)
+	o.addPointerMenu(i18n.T(i18n.ViewMemoryMenu), pointers, o.viewMemory)
+
+	for _, tool := range o.app.tools {
+		tool := tool
+		o.addPointerMenu(i18n.Sprintf(i18n.OpenInToolMenu, tool.Name), pointers,
+			func(name string, pointer appconfig.Pointer) {
+				o.openInTool(tool, name, pointer)
+			})
+	}
+}
+
+func (o *programUI) addPointerMenu(title string, pointers []namedPointer, onClick func(string, appconfig.Pointer)) {
+	parent := o.menu.claim(title, "")
+
+	for _, p := range pointers {
+		p := p
+		parent.claim(p.name, "").setOnClick(func() {
+			onClick(p.name, p.pointer)
+		})
+	}
+}
+
+func (o *programUI) copyAddress(name string, pointer appconfig.Pointer) {
+	addr, err := o.routine.ResolveAddr(pointer)
+	if err != nil {
+		log.Printf("failed to resolve address of %s - %s", name, err)
+		o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+		return
+	}
+
+	err = user32.SetClipboardText(fmt.Sprintf("%X", addr))
+	if err != nil {
+		log.Printf("failed to copy address of %s - %s", name, err)
+		o.app.errorLog.addEntry(err.Error())
+		return
+	}
+
+	log.Printf("copied address of %s (0x%x) to clipboard", name, addr)
+}
+
+func (o *programUI) openInTool(tool *appconfig.Tool, name string, pointer appconfig.Pointer) {
+	pid, err := o.routine.PID()
+	if err != nil {
+		o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+		return
+	}
+
+	addr, err := o.routine.ResolveAddr(pointer)
+	if err != nil {
+		log.Printf("failed to resolve address of %s - %s", name, err)
+		o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+		return
+	}
+
+	cmd := exec.Command(tool.Path)
+
+	// Pass the arguments to the tool exactly as they were written,
+	// since Windows programs parse their own command line.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: syscall.EscapeArg(tool.Path) + " " +
+			tool.CommandLineArgs(pid, addr, o.program.General.ExeName),
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		log.Printf("failed to start %s - %s", tool.Name, err)
+		o.app.errorLog.addEntry(tool.Name + ": " + err.Error())
+		return
+	}
+
+	log.Printf("opened %s (0x%x) in %s", name, addr, tool.Name)
+
+	go cmd.Wait()
+}
+
+// sectionDisplayName returns label if it is non-empty.
+// Otherwise, the section's ID is returned.
+func sectionDisplayName(program *appconfig.ProgramConfig, section interface{}, label string) string {
+	if label != "" {
+		return label
+	}
+
+	return program.SectionID(section)
+}
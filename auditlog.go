@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"sync"
+)
+
+// maxIPCAuditLogSize is the size in bytes that the IPC audit log
+// can grow to before it is moved to ipcAuditLogName + ".old",
+// replacing the previous one.
+const maxIPCAuditLogSize = 1 << 20
+
+// auditLogFile is an append-only log file that is rotated once it
+// reaches maxSize, so that at most two files of maxSize are kept.
+type auditLogFile struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// openAuditLogFile opens the log file at path, creating
+// it if it does not exist.
+func openAuditLogFile(path string, maxSize int64) (*auditLogFile, error) {
+	o := &auditLogFile{
+		path:    path,
+		maxSize: maxSize,
+	}
+
+	err := o.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *auditLogFile) open() error {
+	file, err := os.OpenFile(o.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	o.file = file
+	o.size = info.Size()
+
+	return nil
+}
+
+// rotate moves the current file to path + ".old"
+// and opens a new file at path.
+func (o *auditLogFile) rotate() error {
+	_ = o.file.Close()
+	o.file = nil
+
+	err := os.Rename(o.path, o.path+".old")
+	if err != nil {
+		return err
+	}
+
+	return o.open()
+}
+
+func (o *auditLogFile) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// A previous rotation failed to open the new file.
+	if o.file == nil {
+		err := o.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if o.size > 0 && o.size+int64(len(p)) > o.maxSize {
+		err := o.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := o.file.Write(p)
+	o.size += int64(n)
+
+	return n, err
+}
+
+func (o *auditLogFile) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return nil
+	}
+
+	err := o.file.Close()
+	o.file = nil
+
+	return err
+}
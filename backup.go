@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/getlantern/systray"
+)
+
+const (
+	backupsDirName = "backups"
+
+	// maxBackupsPerConfig is the number of backups that are kept
+	// for each config file. Older backups are deleted.
+	maxBackupsPerConfig = 10
+
+	// backupTimeLayout is the layout of the names of backup files.
+	backupTimeLayout = "20060102-150405.000000000"
+
+	// backupParseLayout parses the names of backup files. The
+	// fractional seconds are optional when parsing, so backups
+	// named before they were added are still listed.
+	backupParseLayout = "20060102-150405"
+)
+
+// errConfigRestored is sent on the app's reload channel to
+// reload the program configs after a backup is restored.
+var errConfigRestored = errors.New("config backup restored")
+
+// configBackup is a backup of a config file.
+type configBackup struct {
+	// ConfigName is the name of the config file.
+	ConfigName string
+
+	// Path is the path of the backup file.
+	Path string
+
+	Time time.Time
+}
+
+// isBackedUpFileName returns true if name is
+// the name of a file that is backed up.
+func isBackedUpFileName(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".conf")
+}
+
+// writeConfigFile replaces the config file at filePath with data
+// after backing up its current contents. Code that modifies a
+// config file must use this function so that the user can roll
+// back the change.
+func writeConfigFile(configDir string, filePath string, data []byte) error {
+	_, err := backupConfigFile(configDir, filePath)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash
+	// does not leave a truncated file behind.
+	tmpPath := filePath + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write config file - %w", err)
+	}
+
+	err = os.Rename(tmpPath, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to replace config file - %w", err)
+	}
+
+	return nil
+}
+
+// backupConfigFile saves a copy of the config file at filePath
+// in the backups directory, unless the newest backup is the same.
+// It returns true if a backup was saved.
+func backupConfigFile(configDir string, filePath string) (bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to read config file - %w", err)
+	}
+
+	configName := filepath.Base(filePath)
+
+	backups, err := configBackups(configDir, configName)
+	if err != nil {
+		return false, err
+	}
+
+	if len(backups) > 0 {
+		newest, err := os.ReadFile(backups[0].Path)
+		if err == nil && bytes.Equal(newest, data) {
+			return false, nil
+		}
+	}
+
+	backupDir := filepath.Join(configDir, backupsDirName, configName)
+	err = os.MkdirAll(backupDir, 0o700)
+	if err != nil {
+		return false, fmt.Errorf("failed to create backups directory - %w", err)
+	}
+
+	backupPath, err := writeBackupFile(backupDir, time.Now(), data)
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("backed up %s to %s", configName, backupPath)
+
+	backups, err = configBackups(configDir, configName)
+	if err != nil {
+		return true, err
+	}
+
+	for i := maxBackupsPerConfig; i < len(backups); i++ {
+		err = os.Remove(backups[i].Path)
+		if err != nil {
+			log.Printf("failed to remove old backup %s - %s", backups[i].Path, err)
+		}
+	}
+
+	return true, nil
+}
+
+// writeBackupFile writes data to a new backup file in backupDir
+// that is named after backupTime. The clock may not advance between
+// two backups, so the time is moved forward until the name is unused.
+func writeBackupFile(backupDir string, backupTime time.Time, data []byte) (string, error) {
+	for {
+		backupPath := filepath.Join(backupDir, backupTime.Format(backupTimeLayout)+".conf")
+
+		f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			backupTime = backupTime.Add(time.Nanosecond)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create backup file - %w", err)
+		}
+
+		_, err = f.Write(data)
+		closeErr := f.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(backupPath)
+			return "", fmt.Errorf("failed to write backup file - %w", err)
+		}
+
+		return backupPath, nil
+	}
+}
+
+// configBackups returns the backups of the config file
+// named configName, newest first.
+func configBackups(configDir string, configName string) ([]configBackup, error) {
+	backupDir := filepath.Join(configDir, backupsDirName, configName)
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read backups directory - %w", err)
+	}
+
+	var backups []configBackup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		backupTime, err := time.ParseInLocation(backupParseLayout,
+			strings.TrimSuffix(entry.Name(), ".conf"), time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, configBackup{
+			ConfigName: configName,
+			Path:       filepath.Join(backupDir, entry.Name()),
+			Time:       backupTime,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+
+	return backups, nil
+}
+
+// backupConfigFiles backs up each config file in configDir
+// whose contents changed since its newest backup, so that
+// edits made outside of blaj can also be rolled back.
+//
+// If gitSnapshots is enabled and configDir is a git repository,
+// the config files that changed are also committed.
+func (o *app) backupConfigFiles(configDir string, entries []os.DirEntry) {
+	var configNames []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isBackedUpFileName(entry.Name()) {
+			continue
+		}
+
+		configNames = append(configNames, entry.Name())
+
+		_, err := backupConfigFile(configDir, filepath.Join(configDir, entry.Name()))
+		if err != nil {
+			log.Printf("failed to back up %s - %s", entry.Name(), err)
+		}
+	}
+
+	o.renderBackupsMenu(configDir)
+
+	if o.settings.GitSnapshots && isGitRepo(configDir) {
+		err := commitConfigSnapshot(configDir, configNames)
+		if err != nil {
+			log.Printf("failed to commit config snapshot - %s", err)
+			o.errorLog.addEntry("failed to commit config snapshot - " + err.Error())
+		}
+	}
+}
+
+func (o *app) addRestoreBackupMenu() {
+	o.backupMenus = newMenuPool(systray.AddMenuItem(
+		i18n.T(i18n.RestoreBackupMenu), i18n.T(i18n.RestoreBackupMenuTooltip)))
+}
+
+// renderBackupsMenu rebuilds the restore menu with a submenu
+// for each config file that has backups.
+func (o *app) renderBackupsMenu(configDir string) {
+	entries, err := os.ReadDir(filepath.Join(configDir, backupsDirName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("failed to read backups directory - %s", err)
+	}
+
+	menus := o.backupMenus.begin()
+	defer o.backupMenus.end()
+
+	numConfigs := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		backups, err := configBackups(configDir, entry.Name())
+		if err != nil {
+			log.Printf("failed to list backups of %s - %s", entry.Name(), err)
+			continue
+		}
+
+		if len(backups) == 0 {
+			continue
+		}
+
+		numConfigs++
+		configMenu := menus.claim(entry.Name(), "")
+
+		for _, backup := range backups {
+			backup := backup
+
+			item := configMenu.claim(backup.Time.Format("2006-01-02 15:04:05"),
+				i18n.T(i18n.RestoreBackupItemTooltip))
+			item.setOnClick(func() {
+				err := o.restoreBackup(configDir, backup)
+				if err != nil {
+					log.Printf("failed to restore backup %s - %s", backup.Path, err)
+					o.errorLog.addEntry(err.Error())
+				}
+			})
+		}
+	}
+
+	if numConfigs == 0 {
+		menus.claim(i18n.T(i18n.NoBackupsMenu), "").item.Disable()
+	}
+}
+
+// restoreBackup replaces a config file with one of its backups and
+// reloads the configs. The current contents of the config file are
+// backed up first, so a restore can itself be rolled back.
+func (o *app) restoreBackup(configDir string, backup configBackup) error {
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file - %w", err)
+	}
+
+	err = writeConfigFile(configDir, filepath.Join(configDir, backup.ConfigName), data)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s - %w", backup.ConfigName, err)
+	}
+
+	log.Printf("restored %s from %s", backup.ConfigName, backup.Path)
+
+	select {
+	case o.reload <- errConfigRestored:
+	default:
+	}
+
+	return nil
+}
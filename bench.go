@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+// benchDuration is the minimum time that each
+// benchmark of the bench command runs for.
+const benchDuration = time.Second
+
+// benchmark is a benchmark run by the bench command.
+type benchmark struct {
+	name string
+
+	// op is the operation that is measured.
+	op func() error
+
+	// bytes is the number of bytes that op processes,
+	// or 0 if its throughput is not measured.
+	bytes int64
+}
+
+// benchResult is the JSON output of the bench command.
+type benchResult struct {
+	Name        string  `json:"name"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     int64   `json:"ns_per_op"`
+	MBPerSec    float64 `json:"mb_per_sec,omitempty"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s bench [options]\n\n"+
+			"measures the performance of pointer resolution and config parsing,\n"+
+			"so that changes to them can be compared before and after\n\n", appName)
+		flags.PrintDefaults()
+	}
+
+	depth := flags.Int("depth", 8, "The number of offsets in the benchmarked pointer chains")
+	sections := flags.Int("sections", 500, "The number of sections in the generated config")
+	configPath := flags.String("config", "", "Also benchmark parsing this config file")
+	asJSON := flags.Bool("json", false, "Print the output as JSON")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if *depth < 1 {
+		return errors.New("-depth must be at least 1")
+	}
+
+	if *sections < 1 {
+		return errors.New("-sections must be at least 1")
+	}
+
+	benchmarks := []benchmark{
+		{
+			name: fmt.Sprintf("lookupAddr/depth=%d", *depth),
+			op:   progctl.LookupAddrBench(*depth, false),
+		},
+		{
+			name: fmt.Sprintf("lookupAddr/depth=%d/32bit", *depth),
+			op:   progctl.LookupAddrBench(*depth, true),
+		},
+		parseBenchmark(fmt.Sprintf("ParseSchema/sections=%d", *sections),
+			appconfig.BenchConfig(*sections)),
+	}
+
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read config file - %w", err)
+		}
+
+		benchmarks = append(benchmarks, parseBenchmark(
+			"ParseSchema/"+filepath.Base(*configPath), data))
+	}
+
+	var results []benchResult
+	for _, bench := range benchmarks {
+		if !*asJSON {
+			fmt.Fprintf(os.Stderr, "running %s...\n", bench.name)
+		}
+
+		result, err := bench.run()
+		if err != nil {
+			return fmt.Errorf("benchmark %s failed - %w", bench.name, err)
+		}
+
+		results = append(results, result)
+	}
+
+	if *asJSON {
+		return printJSON(results)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "BENCHMARK\tITERATIONS\tNS/OP\tMB/S\tB/OP\tALLOCS/OP")
+	for _, result := range results {
+		mbPerSec := "-"
+		if result.MBPerSec > 0 {
+			mbPerSec = fmt.Sprintf("%.2f", result.MBPerSec)
+		}
+
+		fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%d\t%d\n", result.Name, result.Iterations,
+			result.NsPerOp, mbPerSec, result.BytesPerOp, result.AllocsPerOp)
+	}
+
+	return table.Flush()
+}
+
+// parseBenchmark returns a benchmark of parsing the program config
+// in data.
+func parseBenchmark(name string, data []byte) benchmark {
+	return benchmark{
+		name: name,
+		op: func() error {
+			_, err := appconfig.ParseProgramConfig(bytes.NewReader(data))
+			return err
+		},
+		bytes: int64(len(data)),
+	}
+}
+
+// run calls the benchmark's op repeatedly, doubling the number of
+// calls until they take at least benchDuration, and returns the
+// average cost of a call. The testing package's benchmarks are not
+// used since they would link the testing package into blaj.
+func (o benchmark) run() (benchResult, error) {
+	// An op that fails reports no results, so
+	// it is checked before it is measured.
+	err := o.op()
+	if err != nil {
+		return benchResult{}, err
+	}
+
+	for n := 1; ; n *= 2 {
+		runtime.GC()
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+
+		for i := 0; i < n; i++ {
+			err = o.op()
+			if err != nil {
+				return benchResult{}, err
+			}
+		}
+
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed < benchDuration {
+			continue
+		}
+
+		result := benchResult{
+			Name:        o.name,
+			Iterations:  n,
+			NsPerOp:     elapsed.Nanoseconds() / int64(n),
+			BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(n),
+			AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(n),
+		}
+
+		if o.bytes > 0 {
+			result.MBPerSec = float64(o.bytes) * float64(n) / 1e6 / elapsed.Seconds()
+		}
+
+		return result, nil
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/bundle"
+	"github.com/SeungKang/blaj/internal/credui"
+)
+
+// isConfigFileName returns true if name is the name
+// of a program config file or a config bundle. Local
+// config files are loaded with the config they change.
+func isConfigFileName(name string) bool {
+	if appconfig.IsLocalConfigFileName(name) {
+		return false
+	}
+
+	return strings.HasSuffix(name, ".conf") || strings.HasSuffix(name, bundle.FileExt)
+}
+
+// configLoader loads program configs and config bundles. The
+// passphrases of bundles are remembered so that the user is only
+// prompted once per bundle.
+type configLoader struct {
+	settings *appconfig.Blaj
+
+	mu          sync.Mutex
+	passphrases map[string]string
+}
+
+// load loads the program config or config bundle at filePath,
+// followed by its local config file if it has one.
+func (o *configLoader) load(filePath string) (*appconfig.ProgramConfig, error) {
+	program, err := loadWithIncludes(func() (*appconfig.ProgramConfig, error) {
+		return o.loadShared(filePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hasLocal, err := program.ApplyLocalConfigFromPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasLocal {
+		log.Printf("applied %s to %s", filepath.Base(appconfig.LocalConfigPath(filePath)),
+			filepath.Base(filePath))
+	}
+
+	return program, nil
+}
+
+func (o *configLoader) loadShared(filePath string) (*appconfig.ProgramConfig, error) {
+	if !strings.HasSuffix(filePath, bundle.FileExt) {
+		return appconfig.ProgramConfigFromPath(filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config bundle - %w", err)
+	}
+
+	opened, err := o.open(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opened.Signer == nil:
+		if o.settings.RequireTrustedSigner {
+			return nil, errors.New("config bundle is not signed (see requireTrustedSigner)")
+		}
+	case o.settings.IsTrustedSigner(opened.Signer):
+		log.Printf("%s is signed by trusted signer %s",
+			filepath.Base(filePath), bundle.EncodePublicKey(opened.Signer))
+	default:
+		if o.settings.RequireTrustedSigner {
+			return nil, fmt.Errorf("config bundle is signed by untrusted signer %s",
+				bundle.EncodePublicKey(opened.Signer))
+		}
+
+		log.Printf("warning: %s is signed by untrusted signer %s",
+			filepath.Base(filePath), bundle.EncodePublicKey(opened.Signer))
+	}
+
+	// The decrypted config is validated like any other config.
+	program, err := appconfig.ParseProgramConfig(bytes.NewReader(opened.Config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config bundle - %w", err)
+	}
+
+	return program, nil
+}
+
+// open decrypts a bundle, prompting for its passphrase
+// if it has not been entered before.
+func (o *configLoader) open(filePath string, data []byte) (*bundle.Opened, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	passphrase, hasIt := o.passphrases[filePath]
+	if hasIt {
+		opened, err := bundle.Open(data, passphrase)
+		if err == nil {
+			return opened, nil
+		}
+
+		// The bundle may have been replaced with
+		// one that uses a different passphrase.
+		delete(o.passphrases, filePath)
+	}
+
+	passphrase, err := credui.PromptPassword(appName,
+		"Enter the passphrase for "+filepath.Base(filePath),
+		filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	opened, err := bundle.Open(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.passphrases == nil {
+		o.passphrases = make(map[string]string)
+	}
+
+	o.passphrases[filePath] = passphrase
+
+	return opened, nil
+}
+
+// newCLIConfigLoader returns a configLoader that uses
+// the application settings file, for use by commands.
+func newCLIConfigLoader() (*configLoader, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load app config - %w", err)
+	}
+
+	return &configLoader{settings: settings.Blaj}, nil
+}
+
+// programConfigFromPath loads the program config or
+// config bundle at filePath for a command.
+func programConfigFromPath(filePath string) (*appconfig.ProgramConfig, error) {
+	loader, err := newCLIConfigLoader()
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := loader.load(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, warning := range program.Warnings {
+		log.Printf("warning: %s - %s", filepath.Base(filePath), warning)
+	}
+
+	return program, nil
+}
+
+func runBundle(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s bundle [options] <config-file>\n\n"+
+			"encrypts a config file with a passphrase so that its offsets cannot be\n"+
+			"easily read. the bundle is saved next to the config file with a %s\n"+
+			"extension and can be loaded by copying it to the %s config directory\n\n",
+			appName, bundle.FileExt, appName)
+		flags.PrintDefaults()
+	}
+
+	keyPath := flags.String("sign", "", "Sign the bundle with the private key in `key-file` (see keygen)")
+	outPath := flags.String("o", "", "The `path` to save the bundle to")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("please specify a config file")
+	}
+
+	configPath := flags.Arg(0)
+
+	// Make sure the config is valid before sealing it,
+	// since its author is usually the only one who
+	// can fix it.
+	_, err = loadWithIncludes(func() (*appconfig.ProgramConfig, error) {
+		return appconfig.ProgramConfigFromPath(configPath)
+	})
+	if err != nil {
+		return err
+	}
+
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var signKey ed25519.PrivateKey
+	if *keyPath != "" {
+		keyStr, err := os.ReadFile(*keyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read private key file - %w", err)
+		}
+
+		signKey, err = bundle.ParsePrivateKey(string(keyStr))
+		if err != nil {
+			return err
+		}
+	}
+
+	passphrase, err := credui.PromptPassword(appName,
+		"Enter a passphrase for the bundle of "+filepath.Base(configPath),
+		filepath.Base(configPath))
+	if err != nil {
+		return err
+	}
+
+	sealed, err := bundle.Seal(config, passphrase, signKey)
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		*outPath = strings.TrimSuffix(configPath, filepath.Ext(configPath)) + bundle.FileExt
+	}
+
+	err = os.WriteFile(*outPath, sealed, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write bundle - %w", err)
+	}
+
+	log.Printf("saved bundle to %s", *outPath)
+
+	return nil
+}
+
+func runKeygen(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s keygen <key-file>\n\n"+
+			"generates a private key for signing config bundles and saves it to\n"+
+			"key-file. the public key is printed to stdout and can be added to the\n"+
+			"trustedSigner setting of people who use your configs\n",
+			appName)
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("please specify a key file")
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key - %w", err)
+	}
+
+	f, err := os.OpenFile(flags.Arg(0), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file - %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(bundle.EncodePrivateKey(private) + "\n")
+	if err != nil {
+		return fmt.Errorf("failed to write key file - %w", err)
+	}
+
+	fmt.Println(bundle.EncodePublicKey(public))
+
+	return nil
+}
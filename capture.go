@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/input"
+	"github.com/SeungKang/blaj/internal/ipc"
+	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/mitchellh/go-ps"
+)
+
+// suggestedVirtualKeys are the keys that are suggested when every
+// captured key is already bound: F5 to F12 and the numpad digits.
+var suggestedVirtualKeys = []byte{
+	0x74, 0x75, 0x76, 0x77, 0x78, 0x79, 0x7a, 0x7b,
+	0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+}
+
+// maxSuggestions is the maximum number of
+// keybinds that a capture suggests.
+const maxSuggestions = 5
+
+func (o *app) setKeyboard(keyboard *input.Dispatcher) {
+	o.programsMu.Lock()
+	defer o.programsMu.Unlock()
+
+	o.keyboard = keyboard
+}
+
+// captureKeys records the next count keys that are pressed, along
+// with the program that was in the foreground and the sections that
+// the keys are already bound to. Keys are not suppressed, so they
+// still reach the focused program.
+func (o *app) captureKeys(ctx context.Context, count int) (ipc.Capture, error) {
+	o.programsMu.Lock()
+	keyboard := o.keyboard
+	programs := o.programs
+	o.programsMu.Unlock()
+
+	if keyboard == nil {
+		return ipc.Capture{}, errors.New("keyboard input is not running")
+	}
+
+	events := make(chan input.KeyEvent, 64)
+	unsubscribe, err := keyboard.Subscribe(func(event input.KeyEvent) bool {
+		select {
+		case events <- event:
+		default:
+		}
+
+		return false
+	})
+	if err != nil {
+		return ipc.Capture{}, fmt.Errorf("failed to subscribe to keyboard events - %w", err)
+	}
+	defer unsubscribe()
+
+	var capture ipc.Capture
+
+	// Holding a key repeats its key down event,
+	// so only the first one is recorded.
+	held := make(map[byte]struct{})
+
+capture:
+	for len(capture.Keys) < count {
+		var event input.KeyEvent
+		select {
+		case <-ctx.Done():
+			if len(capture.Keys) == 0 {
+				return ipc.Capture{}, errors.New("no keys were pressed")
+			}
+
+			break capture
+		case event = <-events:
+		}
+
+		if !event.Down {
+			delete(held, event.VirtualKey)
+			continue
+		}
+
+		if _, isHeld := held[event.VirtualKey]; isHeld {
+			continue
+		}
+
+		held[event.VirtualKey] = struct{}{}
+
+		capture.Keys = append(capture.Keys, capturedKey(event, programs))
+	}
+
+	capture.Suggestions = suggestKeybinds(capture.Keys, programs)
+
+	return capture, nil
+}
+
+func capturedKey(event input.KeyEvent, programs []*programUI) ipc.CapturedKey {
+	keys := progctl.EventKeys(event)
+
+	captured := ipc.CapturedKey{
+		VirtualKey: appconfig.Key{VirtualKey: event.VirtualKey}.String(),
+	}
+
+	if event.Char != 0 {
+		captured.Char = appconfig.Key{Char: event.Char}.String()
+	}
+
+	if event.ScanCode != 0 {
+		captured.ScanCode = appconfig.Key{ScanCode: event.ScanCode}.String()
+	}
+
+	if event.ForegroundPID != 0 {
+		process, err := ps.FindProcess(event.ForegroundPID)
+		if err == nil && process != nil {
+			captured.ForegroundExe = process.Executable()
+		}
+	}
+
+	for _, program := range programs {
+		if strings.EqualFold(program.program.General.ExeName, captured.ForegroundExe) {
+			captured.GameFocused = true
+		}
+
+		captured.Conflicts = append(captured.Conflicts, keyConflicts(program.program, keys)...)
+	}
+
+	return captured
+}
+
+// keyConflicts describes the sections of program
+// that are bound to any of keys.
+func keyConflicts(program *appconfig.ProgramConfig, keys []appconfig.Key) []string {
+	var conflicts []string
+	for _, key := range keys {
+		for _, section := range program.Keybinds[key] {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s (%s)",
+				program.General.ExeName, program.SectionID(section), key))
+		}
+	}
+
+	return conflicts
+}
+
+// suggestKeybinds returns keybinds that are not used by any of the
+// programs. The captured keys without conflicts are suggested first,
+// followed by unused function and numpad keys.
+func suggestKeybinds(captured []ipc.CapturedKey, programs []*programUI) []string {
+	var suggestions []string
+	suggested := make(map[string]struct{})
+
+	suggest := func(keybind string) {
+		if _, hasIt := suggested[keybind]; hasIt || len(suggestions) >= maxSuggestions {
+			return
+		}
+
+		suggested[keybind] = struct{}{}
+		suggestions = append(suggestions, keybind)
+	}
+
+	for _, key := range captured {
+		if len(key.Conflicts) > 0 {
+			continue
+		}
+
+		// Characters are the easiest keybinds to read, but
+		// keys without one are bound by virtual-key code.
+		if key.Char != "" {
+			suggest(key.Char)
+		} else {
+			suggest(key.VirtualKey)
+		}
+	}
+
+	for _, virtualKey := range suggestedVirtualKeys {
+		key := appconfig.Key{VirtualKey: virtualKey}
+
+		isBound := false
+		for _, program := range programs {
+			if len(program.program.Keybinds[key]) > 0 {
+				isBound = true
+				break
+			}
+		}
+
+		if !isBound {
+			suggest(key.String())
+		}
+	}
+
+	return suggestions
+}
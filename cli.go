@@ -0,0 +1,804 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/input"
+	"github.com/SeungKang/blaj/internal/ipc"
+	"github.com/SeungKang/blaj/internal/kernel32"
+	"github.com/SeungKang/blaj/internal/memdump"
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+const usage = `usage: ` + appName + ` [-safe] [command]
+
+When no command is specified, ` + appName + ` starts in the systray.
+
+options:
+  -safe   start in safe mode, which disables all writers (this can also
+          be enabled by holding shift while ` + appName + ` starts)
+
+commands:
+  run      perform a single action against a running program and exit
+  replay   verify a recorded trace file against a config
+  test     resolve a config's pointers against a memory dump
+  bundle   encrypt and optionally sign a config for sharing
+  keygen   generate a key for signing config bundles
+  signfeed sign an offset manifest for publishing as an offset feed
+  docs     print a reference of every config file parameter
+  list     list the programs and sections loaded by the running instance
+  status   display the status of the running instance's programs
+  devices  list the names of the connected keyboards for inputDevice
+  capture  record the next keys pressed and suggest unused keybinds
+  bench    measure the performance of pointer resolution and parsing
+  help     display this information
+`
+
+// runCommand runs the command line command specified by args.
+// safeMode is true if the -safe option was specified.
+func runCommand(args []string, safeMode bool) error {
+	// Errors are not fatal because the parent
+	// process may not have a console.
+	_ = kernel32.AttachParentConsole()
+	log.SetOutput(os.Stderr)
+
+	switch args[0] {
+	case "run":
+		return runOneShot(args[1:], safeMode)
+	case "replay":
+		return runReplay(args[1:])
+	case "test":
+		return runTest(args[1:])
+	case "bundle":
+		return runBundle(args[1:])
+	case "keygen":
+		return runKeygen(args[1:])
+	case "signfeed":
+		return runSignFeed(args[1:])
+	case "docs":
+		return runDocs(args[1:])
+	case "list":
+		return runList(args[1:])
+	case "status":
+		return runStatus(args[1:])
+	case "devices":
+		return runDevices(args[1:])
+	case "capture":
+		return runCapture(args[1:])
+	case "bench":
+		return runBench(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command: %q", args[0])
+	}
+}
+
+func runOneShot(args []string, safeMode bool) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s run -exe <exe-name> (-save|-restore|-write) <section> [options]\n\n"+
+			"sections are identified by their name, label, or type and index (e.g. saverestore#1)\n\n",
+			appName)
+		flags.PrintDefaults()
+	}
+
+	exeName := flags.String("exe", "", "The exe name of the target program")
+	configPath := flags.String("config", "", "The config file to use (defaults to the config in the\n"+
+		appName+" config directory with a matching exeName)")
+	saveName := flags.String("save", "", "Save the pointers of a SaveRestore `section` to the state file")
+	restoreName := flags.String("restore", "", "Restore the pointers of a SaveRestore `section` from the state file")
+	writeName := flags.String("write", "", "Write the data of a Writer `section`")
+	statePath := flags.String("state", "", "The state file to save to or restore from (defaults to\n"+
+		"stdout when saving and stdin when restoring)")
+	slot := flags.Int("slot", 0, "Save to or restore from save slot `number` of the section (see slotKeys)\n"+
+		"rather than the state of its saveState and restoreState keybinds")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	var program *appconfig.ProgramConfig
+	switch {
+	case *configPath != "":
+		program, err = programConfigFromPath(*configPath)
+		if err != nil {
+			return err
+		}
+	case *exeName != "":
+		program, err = findProgramConfig(*exeName)
+		if err != nil {
+			return err
+		}
+	default:
+		flags.Usage()
+		return errors.New("please specify -exe or -config")
+	}
+
+	var numActions int
+	for _, name := range []string{*saveName, *restoreName, *writeName} {
+		if name != "" {
+			numActions++
+		}
+	}
+
+	if numActions != 1 {
+		flags.Usage()
+		return errors.New("please specify exactly one of -save, -restore, or -write")
+	}
+
+	if *slot != 0 && *writeName != "" {
+		flags.Usage()
+		return errors.New("-slot can only be used with -save or -restore")
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load app config - %w", err)
+	}
+
+	oneShot, err := progctl.NewOneShot(program, guardFromSettings(settings.Blaj))
+	if err != nil {
+		return err
+	}
+	defer oneShot.Close()
+
+	oneShot.SafeMode = safeMode
+
+	switch {
+	case *saveName != "":
+		saveRestore, err := saveRestoreByName(program, *saveName)
+		if err != nil {
+			return err
+		}
+
+		err = checkSlot(saveRestore, *saveName, *slot)
+		if err != nil {
+			return err
+		}
+
+		saved, err := oneShot.SaveSlot(saveRestore, *slot)
+		if err != nil {
+			return err
+		}
+
+		err = writeStateFile(*statePath, saved)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("saved %s", *saveName)
+	case *restoreName != "":
+		saveRestore, err := saveRestoreByName(program, *restoreName)
+		if err != nil {
+			return err
+		}
+
+		err = checkSlot(saveRestore, *restoreName, *slot)
+		if err != nil {
+			return err
+		}
+
+		saved, err := readStateFile(*statePath)
+		if err != nil {
+			return err
+		}
+
+		err = oneShot.RestoreSlot(saveRestore, *slot, saved)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("restored %s", *restoreName)
+	case *writeName != "":
+		section, err := program.SectionByName(*writeName)
+		if err != nil {
+			return err
+		}
+
+		writer, isWriter := section.(*appconfig.Writer)
+		if !isWriter {
+			return fmt.Errorf("%q is not a writer section", *writeName)
+		}
+
+		err = oneShot.Write(writer)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("wrote %s", *writeName)
+	}
+
+	return nil
+}
+
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s replay [options] <trace-file>\n\n"+
+			"replays the actions in a trace file against a mock process and compares\n"+
+			"the resulting memory operations to the recorded ones\n\n",
+			appName)
+		flags.PrintDefaults()
+	}
+
+	configPath := flags.String("config", "", "The config file to use (defaults to the config in the\n"+
+		appName+" config directory with the trace's exeName)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("please specify a trace file")
+	}
+
+	trace, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read trace file - %w", err)
+	}
+
+	var program *appconfig.ProgramConfig
+	if *configPath != "" {
+		program, err = programConfigFromPath(*configPath)
+	} else {
+		var exeName string
+		exeName, err = progctl.TraceExeName(bytes.NewReader(trace))
+		if err != nil {
+			return err
+		}
+
+		program, err = findProgramConfig(exeName)
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := progctl.ReplayTrace(program, bytes.NewReader(trace))
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range result.Mismatches {
+		fmt.Println(mismatch)
+	}
+
+	if len(result.Mismatches) > 0 {
+		return fmt.Errorf("found %d mismatches in %d actions",
+			len(result.Mismatches), result.NumActions)
+	}
+
+	fmt.Printf("replayed %d actions without mismatches\n", result.NumActions)
+
+	return nil
+}
+
+// maxTestValueSize is the number of bytes of each
+// pointer's value displayed by the test command.
+const maxTestValueSize = 32
+
+func runTest(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s test [options] <config-file> <dump-file>\n\n"+
+			"resolves each pointer in a config file against a memory dump and displays\n"+
+			"the resolved addresses and values. the dump file may be a minidump or, if\n"+
+			"-base is specified, a raw copy of a range of the program's memory\n\n",
+			appName)
+		flags.PrintDefaults()
+	}
+
+	baseStr := flags.String("base", "", "The `address` where a raw dump file starts in the program's memory")
+	exeBaseStr := flags.String("exe-base", "", "The base `address` of the exe in a raw dump (defaults to -base)")
+	is32Bit := flags.Bool("32bit", false, "The dumped program is 32-bit (detected automatically for minidumps\n"+
+		"of 32-bit systems)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("please specify a config file and a dump file")
+	}
+
+	program, err := programConfigFromPath(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	dumpFile, err := os.Open(flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("failed to open dump file - %w", err)
+	}
+	defer dumpFile.Close()
+
+	snapshot := progctl.Snapshot{
+		Modules: make(map[string]uintptr),
+		Is32Bit: *is32Bit,
+	}
+
+	if *baseStr != "" {
+		base, err := strconv.ParseUint(*baseStr, 0, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse -base - %w", err)
+		}
+
+		exeBase := base
+		if *exeBaseStr != "" {
+			exeBase, err = strconv.ParseUint(*exeBaseStr, 0, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse -exe-base - %w", err)
+			}
+		}
+
+		info, err := dumpFile.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat dump file - %w", err)
+		}
+
+		snapshot.Mem = memdump.NewRaw(dumpFile, info.Size(), uintptr(base))
+		snapshot.Modules[program.General.ExeName] = uintptr(exeBase)
+	} else {
+		dump, err := memdump.OpenMinidump(dumpFile)
+		if err != nil {
+			return fmt.Errorf("failed to open minidump - %w", err)
+		}
+
+		snapshot.Mem = dump
+		snapshot.Is32Bit = snapshot.Is32Bit || dump.Is32Bit
+		for _, module := range dump.Modules {
+			snapshot.Modules[module.Name] = module.BaseAddr
+		}
+	}
+
+	results, err := progctl.CheckPointers(program, snapshot)
+	if err != nil {
+		return err
+	}
+
+	numFailed := 0
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SECTION\tPOINTER\tADDRESS\tVALUE")
+	for _, result := range results {
+		if result.Err != nil {
+			numFailed++
+			fmt.Fprintf(table, "%s\t%s\t\terror: %s\n",
+				result.Section, result.Pointer.DisplayName(), result.Err)
+			continue
+		}
+
+		var value string
+		switch {
+		case result.Pointer.Display != "":
+			value = result.Pointer.FormatValue(result.Data)
+		case len(result.Data) > maxTestValueSize:
+			value = hex.EncodeToString(result.Data[:maxTestValueSize]) + "..."
+		default:
+			value = hex.EncodeToString(result.Data)
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t0x%x\t%s\n",
+			result.Section, result.Pointer.DisplayName(), result.Addr, value)
+	}
+
+	err = table.Flush()
+	if err != nil {
+		return err
+	}
+
+	if numFailed > 0 {
+		return fmt.Errorf("%d of %d pointers could not be resolved", numFailed, len(results))
+	}
+
+	return nil
+}
+
+func runList(args []string) error {
+	status, asJSON, err := queryStatus("list", args)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(status.Programs)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "EXE NAME\tSECTION\tLABEL\tKEYBINDS")
+	for _, program := range status.Programs {
+		for _, section := range program.Sections {
+			var keybinds []string
+			for name, keybind := range section.Keybinds {
+				keybinds = append(keybinds, name+"="+keybind)
+			}
+			sort.Strings(keybinds)
+
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\n",
+				program.ExeName, section.Type, section.Label, strings.Join(keybinds, " "))
+		}
+	}
+
+	return table.Flush()
+}
+
+func runDevices(args []string) error {
+	flags := flag.NewFlagSet("devices", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s devices\n\n"+
+			"lists the device names of the connected keyboards, which can be\n"+
+			"used as the value of a config's inputDevice parameter\n", appName)
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	keyboards, err := input.Keyboards()
+	if err != nil {
+		return err
+	}
+
+	for _, keyboard := range keyboards {
+		fmt.Println(keyboard)
+	}
+
+	return nil
+}
+
+func runCapture(args []string) error {
+	flags := flag.NewFlagSet("capture", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s capture [options]\n\n"+
+			"records the next keys pressed while the running instance is in the\n"+
+			"systray and suggests keybinds that no loaded config uses\n\n", appName)
+		flags.PrintDefaults()
+	}
+
+	count := flags.Int("n", 1, fmt.Sprintf("The number of keys to record (at most %d)", ipc.MaxCaptureKeys))
+	asJSON := flags.Bool("json", false, "Print the output as JSON")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if *count < 1 || *count > ipc.MaxCaptureKeys {
+		return fmt.Errorf("-n must be between 1 and %d", ipc.MaxCaptureKeys)
+	}
+
+	configDir, err := configDirPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "press %d key(s) within %s...\n", *count, ipc.CaptureTimeout)
+
+	capture, err := ipc.CaptureKeys(filepath.Join(configDir, ipc.AddrFileName),
+		filepath.Join(configDir, ipc.TokenFileName), *count)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(capture)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "CHAR\tVIRTUAL KEY\tSCAN CODE\tFOREGROUND\tCONFLICTS")
+	for _, key := range capture.Keys {
+		foreground := key.ForegroundExe
+		if key.GameFocused {
+			foreground += " (game)"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", key.Char, key.VirtualKey, key.ScanCode,
+			foreground, strings.Join(key.Conflicts, ", "))
+	}
+
+	err = table.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nsuggested keybinds: %s\n", strings.Join(capture.Suggestions, " "))
+
+	return nil
+}
+
+func runStatus(args []string) error {
+	status, asJSON, err := queryStatus("status", args)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(status)
+	}
+
+	fmt.Printf("%s %s\n\n", appName, status.Version)
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "EXE NAME\tSTATE\tSESSION\tTOTAL\tCPU\tMEMORY\tLAST ERROR")
+	for _, program := range status.Programs {
+		sessionStr := "-"
+		total := time.Duration(program.TotalAttached) * time.Second
+
+		// The total does not include the current session.
+		if program.AttachedAt != nil {
+			session := time.Since(*program.AttachedAt)
+			sessionStr = formatUptime(session)
+			total += session
+		}
+
+		cpuStr, memoryStr := "-", "-"
+		if program.Process != nil {
+			cpuStr = fmt.Sprintf("%.1f%%", program.Process.CPUPercent)
+			memoryStr = fmt.Sprintf("%.1f MiB", float64(program.Process.WorkingSet)/(1<<20))
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", program.ExeName, program.StateDetail,
+			sessionStr, formatUptime(total), cpuStr, memoryStr, program.LastError)
+	}
+
+	err = table.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, program := range status.Programs {
+		if len(program.PointerErrors) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(program.PointerErrors))
+		for name := range program.PointerErrors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("\n%s pointer errors:\n", program.ExeName)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, program.PointerErrors[name])
+		}
+	}
+
+	if repo := status.ConfigRepo; repo != nil {
+		head := repo.Head
+		if head == "" {
+			head = "no commits"
+		}
+
+		switch {
+		case repo.Error != "":
+			fmt.Printf("\nconfig repo: %s\n", repo.Error)
+		case repo.Dirty:
+			fmt.Printf("\nconfig repo: dirty (%s)\n", head)
+			for _, change := range repo.Changes {
+				fmt.Println("  " + change)
+			}
+		default:
+			fmt.Printf("\nconfig repo: clean (%s)\n", head)
+		}
+	}
+
+	if len(status.Errors) > 0 {
+		fmt.Println("\nrecent errors:")
+		for _, message := range status.Errors {
+			fmt.Println("  " + message)
+		}
+	}
+
+	return nil
+}
+
+// queryStatus parses the common flags of the list and status
+// commands and requests the status of the running instance.
+func queryStatus(command string, args []string) (ipc.Status, bool, error) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print the output as JSON")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+
+		return ipc.Status{}, false, err
+	}
+
+	configDir, err := configDirPath()
+	if err != nil {
+		return ipc.Status{}, false, err
+	}
+
+	status, err := ipc.GetStatus(filepath.Join(configDir, ipc.AddrFileName),
+		filepath.Join(configDir, ipc.TokenFileName))
+	if err != nil {
+		return ipc.Status{}, false, err
+	}
+
+	return status, *asJSON, nil
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// findProgramConfig returns the program config in the config
+// directory whose exeName matches exeName.
+func findProgramConfig(exeName string) (*appconfig.ProgramConfig, error) {
+	configDir, err := configDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	pathInfos, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory - %w", err)
+	}
+
+	loader, err := newCLIConfigLoader()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pathInfo := range pathInfos {
+		if pathInfo.IsDir() || !isConfigFileName(pathInfo.Name()) {
+			continue
+		}
+
+		program, err := loader.load(filepath.Join(configDir, pathInfo.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s - %w", pathInfo.Name(), err)
+		}
+
+		if program.General.ExeName == strings.ToLower(exeName) {
+			return program, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no config with exeName %q found in %s", exeName, configDir)
+}
+
+func saveRestoreByName(program *appconfig.ProgramConfig, name string) (*appconfig.SaveRestore, error) {
+	section, err := program.SectionByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	saveRestore, isSaveRestore := section.(*appconfig.SaveRestore)
+	if !isSaveRestore {
+		return nil, fmt.Errorf("%q is not a saverestore section", name)
+	}
+
+	return saveRestore, nil
+}
+
+// checkSlot returns an error if slot is not one of the save slots
+// of the section named name. Slot 0 is the state of the section's
+// saveState and restoreState keybinds, which every section has.
+func checkSlot(saveRestore *appconfig.SaveRestore, name string, slot int) error {
+	if slot == 0 {
+		return nil
+	}
+
+	for _, saveSlot := range saveRestore.Slots {
+		if saveSlot.Number == slot {
+			return nil
+		}
+	}
+
+	if len(saveRestore.Slots) == 0 {
+		return fmt.Errorf("%q does not have save slots (see slotKeys)", name)
+	}
+
+	return fmt.Errorf("%q does not have save slot %d (its slots are %s)",
+		name, slot, saveRestore.SlotRange())
+}
+
+// writeStateFile writes saved states as a JSON object that maps
+// pointer names to hex-encoded data.
+func writeStateFile(filePath string, saved map[string][]byte) error {
+	encoded := make(map[string]string, len(saved))
+	for name, data := range saved {
+		encoded[name] = hex.EncodeToString(data)
+	}
+
+	out := os.Stdout
+	if filePath != "" {
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open state file - %w", err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to write state file - %w", err)
+	}
+
+	return nil
+}
+
+func readStateFile(filePath string) (map[string][]byte, error) {
+	var in io.Reader = os.Stdin
+	if filePath != "" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state file - %w", err)
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	var encoded map[string]string
+	err := json.NewDecoder(in).Decode(&encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state file - %w", err)
+	}
+
+	saved := make(map[string][]byte, len(encoded))
+	for name, str := range encoded {
+		data, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode state for %q - %w", name, err)
+		}
+
+		saved[name] = data
+	}
+
+	return saved, nil
+}
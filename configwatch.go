@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+// configWatchInterval is how often the config files
+// of the running programs are checked for removal.
+const configWatchInterval = 2 * time.Second
+
+// watchConfigRemoval checks that the config file of each of the
+// running programs still exists every configWatchInterval until ctx
+// is done. When a config file is deleted, the program's saved states
+// are written to the states directory if the saveStatesOnRemove
+// setting is enabled, and the program's routine is stopped and its
+// menus are released without reloading the other programs.
+func (o *app) watchConfigRemoval(ctx context.Context, configDir string) {
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		o.programsMu.Lock()
+		programs := o.programs
+		o.programsMu.Unlock()
+
+		for _, ui := range programs {
+			_, err := os.Stat(filepath.Join(configDir, ui.fileName))
+			if !errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			log.Printf("%s was removed", ui.fileName)
+
+			if o.settings.SaveStatesOnRemove {
+				err = saveRemovedStates(configDir, ui.fileName, ui.routine)
+				if err != nil {
+					log.Printf("failed to save states of %s - %s", ui.fileName, err)
+					o.errorLog.addEntry(ui.fileName + ": failed to save states - " + err.Error())
+				}
+			}
+
+			o.stopProgram(ui)
+			o.releaseEnabledItem(ui.fileName)
+		}
+	}
+}
+
+// saveRemovedStates writes the states saved by routine to a state file
+// in the states directory that is named after the config file, so they
+// can be restored using blaj run -restore. Nothing is written if the
+// program is not running or has no saved states.
+func saveRemovedStates(configDir string, fileName string, routine *progctl.Routine) error {
+	saved, err := routine.SavedStates()
+	if errors.Is(err, progctl.ErrNotAttached) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(saved) == 0 {
+		return nil
+	}
+
+	statesDir := filepath.Join(configDir, statesDirName)
+
+	err = os.MkdirAll(statesDir, 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create states directory - %w", err)
+	}
+
+	statePath := filepath.Join(statesDir,
+		strings.TrimSuffix(fileName, filepath.Ext(fileName))+".json")
+
+	err = writeStateFile(statePath, saved)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("saved %d states of %s to %s", len(saved), fileName, statePath)
+
+	return nil
+}
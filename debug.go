@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// serveDebug serves the net/http/pprof handlers on the debugAddress
+// setting until ctx is done. The goroutines of each program are
+// labeled with the program's exe name, which helps diagnose leaks
+// in the attach and detach lifecycle.
+func (o *app) serveDebug(ctx context.Context) {
+	if o.settings.DebugAddress == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", o.settings.DebugAddress)
+	if err != nil {
+		log.Printf("failed to start debug server - %s", err)
+		return
+	}
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Printf("debug server listening on http://%s/debug/pprof/", listener.Addr())
+
+	err = server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("debug server exited - %s", err)
+	}
+}
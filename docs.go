@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+func runDocs(args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s docs [options]\n\n"+
+			"prints a reference of every config file section and parameter\n\n",
+			appName)
+		flags.PrintDefaults()
+	}
+
+	format := flags.String("format", "markdown", "The output `format` (markdown or html)")
+	outPath := flags.String("o", "", "The `path` to save the reference to instead of printing it")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	var writeFn func(io.Writer, []appconfig.SectionDoc) error
+	switch *format {
+	case "markdown", "md":
+		writeFn = writeMarkdownDocs
+	case "html":
+		writeFn = writeHTMLDocs
+	default:
+		return fmt.Errorf("unknown format: %q", *format)
+	}
+
+	docs, err := appconfig.Docs()
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		return writeFn(os.Stdout, docs)
+	}
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = writeFn(f, docs)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeMarkdownDocs(w io.Writer, docs []appconfig.SectionDoc) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "# %s Config Reference\n", appName)
+
+	file := ""
+	for _, section := range docs {
+		if section.File != file {
+			file = section.File
+			fmt.Fprintf(buf, "\n## %s\n", file)
+		}
+
+		fmt.Fprintf(buf, "\n### `[%s]`\n\n%s\n\n", section.Name, section.Help)
+		fmt.Fprintf(buf, "- Required: %s\n", yesNo(section.Required))
+		fmt.Fprintf(buf, "- Limit: %s\n\n", limitString(section.Limit))
+
+		fmt.Fprintln(buf, "| Parameter | Type | Required | Default | Limit | Description |")
+		fmt.Fprintln(buf, "| --- | --- | --- | --- | --- | --- |")
+
+		for _, param := range section.Params {
+			fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s | %s |\n",
+				param.Name,
+				markdownCell(param.Type),
+				yesNo(param.Required),
+				markdownCell(param.Default),
+				limitString(param.Limit),
+				markdownCell(param.Help))
+		}
+	}
+
+	return buf.Flush()
+}
+
+func markdownCell(str string) string {
+	return strings.ReplaceAll(str, "|", "\\|")
+}
+
+var htmlDocsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"yesNo": yesNo,
+	"limit": limitString,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.AppName}} Config Reference</title>
+</head>
+<body>
+<h1>{{.AppName}} Config Reference</h1>
+{{- $file := ""}}
+{{- range .Sections}}
+{{- if ne .File $file}}{{$file = .File}}
+<h2>{{.File}}</h2>
+{{- end}}
+<h3><code>[{{.Name}}]</code></h3>
+<p>{{.Help}}</p>
+<p>Required: {{yesNo .Required}}<br>Limit: {{limit .Limit}}</p>
+<table>
+<tr><th>Parameter</th><th>Type</th><th>Required</th><th>Default</th><th>Limit</th><th>Description</th></tr>
+{{- range .Params}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{yesNo .Required}}</td><td>{{.Default}}</td><td>{{limit .Limit}}</td><td>{{.Help}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+func writeHTMLDocs(w io.Writer, docs []appconfig.SectionDoc) error {
+	return htmlDocsTemplate.Execute(w, struct {
+		AppName  string
+		Sections []appconfig.SectionDoc
+	}{
+		AppName:  appName,
+		Sections: docs,
+	})
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+
+	return "No"
+}
+
+func limitString(limit int) string {
+	if limit == 0 {
+		return "None"
+	}
+
+	return strconv.Itoa(limit)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/dbghelp"
+	"github.com/SeungKang/blaj/internal/i18n"
+)
+
+const dumpsDirName = "dumps"
+
+func (o *programUI) addDumpMenu() {
+	item := o.menu.claim(i18n.T(i18n.SaveDumpMenu), i18n.T(i18n.SaveDumpMenuTooltip))
+
+	item.setOnClick(func() {
+		filePath, err := o.saveDump()
+		if err != nil {
+			log.Printf("failed to save memory dump of %s - %s", o.program.General.ExeName, err)
+			o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+			return
+		}
+
+		log.Printf("saved memory dump of %s to %s", o.program.General.ExeName, filePath)
+
+		showInExplorer(filePath)
+	})
+}
+
+// saveDump writes a minidump of the attached program to
+// a new file in the dumps directory and returns its path.
+func (o *programUI) saveDump() (string, error) {
+	dumpType, err := dbghelp.ParseDumpType(o.app.settings.DumpType)
+	if err != nil {
+		return "", err
+	}
+
+	configDir, err := configDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	dumpsDir := filepath.Join(configDir, dumpsDirName)
+	err = os.MkdirAll(dumpsDir, 0o700)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dumps directory - %w", err)
+	}
+
+	filePath := filepath.Join(dumpsDir, fmt.Sprintf("%s-%s.dmp",
+		o.program.General.ExeName, time.Now().Format("20060102-150405")))
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dump file - %w", err)
+	}
+	defer f.Close()
+
+	err = o.routine.WriteMiniDump(f, dumpType)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, f.Close()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/getlantern/systray"
+)
+
+// errProgramToggled is sent on the app's reload channel to reload
+// the program configs after a program is enabled while the configs
+// are not loaded.
+var errProgramToggled = errors.New("program enabled or disabled")
+
+func (o *app) addEnabledProgramsMenu() {
+	o.enabledMenus = newMenuPool(systray.AddMenuItem(
+		i18n.T(i18n.EnabledProgramsMenu), i18n.T(i18n.EnabledProgramsTooltip)))
+}
+
+// renderEnabledProgramsMenu rebuilds the enabled programs menu with
+// an item for each config file, which is checked if the config is not
+// disabled. Configs that failed to load are included so that they can
+// be disabled without editing them.
+func (o *app) renderEnabledProgramsMenu(configDir string, files []*appconfig.LoadedFile) {
+	menus := o.enabledMenus.begin()
+	defer o.enabledMenus.end()
+
+	items := make(map[string]*pooledMenu, len(files))
+
+	for _, file := range files {
+		file := file
+
+		item := menus.claim(file.Name, i18n.T(i18n.EnabledProgramTooltip))
+		if file.Disabled {
+			item.item.Uncheck()
+		} else {
+			item.item.Check()
+		}
+
+		item.setOnClick(func() {
+			enabled := !item.item.Checked()
+
+			err := o.setProgramEnabled(configDir, file.Name, enabled)
+			if err != nil {
+				log.Printf("failed to change whether %s is disabled - %s", file.Name, err)
+				o.errorLog.addEntry(err.Error())
+			}
+
+			// The program may fail to start after
+			// its config file has been enabled.
+			if appconfig.DisabledFromPath(filepath.Join(configDir, file.Name)) {
+				item.item.Uncheck()
+			} else {
+				item.item.Check()
+			}
+		})
+
+		items[file.Name] = item
+	}
+
+	o.programsMu.Lock()
+	o.enabledItems = items
+	o.programsMu.Unlock()
+}
+
+// releaseEnabledItem removes the item of a config
+// file that was deleted from the enabled programs menu.
+func (o *app) releaseEnabledItem(fileName string) {
+	o.programsMu.Lock()
+	item, hasIt := o.enabledItems[fileName]
+	delete(o.enabledItems, fileName)
+	o.programsMu.Unlock()
+
+	if hasIt {
+		o.enabledMenus.release(item)
+	}
+}
+
+// setProgramEnabled sets the disabled parameter of a config file's
+// local config file. The program's routine is then stopped or started
+// without reloading the other programs. The configs are only reloaded
+// if they are not loaded, such as after every config failed to load.
+func (o *app) setProgramEnabled(configDir string, fileName string, enabled bool) error {
+	configPath := filepath.Join(configDir, fileName)
+
+	data, err := appconfig.LocalConfigWithDisabled(configPath, !enabled)
+	if err != nil {
+		return fmt.Errorf("failed to change %s - %w", fileName, err)
+	}
+
+	localPath := appconfig.LocalConfigPath(configPath)
+
+	err = writeConfigFile(configDir, localPath, data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s - %w", filepath.Base(localPath), err)
+	}
+
+	if !enabled {
+		log.Printf("disabled %s", fileName)
+
+		ui := o.programByFile(fileName)
+		if ui != nil {
+			o.stopProgram(ui)
+		}
+
+		return nil
+	}
+
+	log.Printf("enabled %s", fileName)
+
+	started, err := o.startProgramFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	if !started {
+		select {
+		case o.reload <- errProgramToggled:
+		default:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/SeungKang/blaj/internal/icon"
+	"github.com/SeungKang/blaj/internal/shell32"
+)
+
+// menuIconSizes are the image sizes generated for the icons of
+// programs in the systray menu.
+var menuIconSizes = []int{16, 20, 24, 32}
+
+// runningIcon returns the menu icon of an attached program, which is
+// the icon of the program's exe file. The generic running icon is
+// returned if the exe's icon cannot be extracted.
+func (o *programUI) runningIcon() []byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.exeIcon != nil {
+		return o.exeIcon
+	}
+
+	ico, err := o.extractExeIcon()
+	if err != nil {
+		log.Printf("failed to get icon of %s - %s", o.program.General.ExeName, err)
+		return statusRunningIcon
+	}
+
+	o.exeIcon = ico
+
+	return ico
+}
+
+func (o *programUI) extractExeIcon() ([]byte, error) {
+	exePath, err := o.routine.ExePath()
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := shell32.ExeIcon(exePath)
+	if err != nil {
+		return nil, err
+	}
+
+	scaled := make([]image.Image, len(menuIconSizes))
+	for i, size := range menuIconSizes {
+		scaled[i] = icon.Resize(img, size)
+	}
+
+	ico, err := icon.Encode(scaled...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode icon - %w", err)
+	}
+
+	return ico, nil
+}
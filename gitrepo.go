@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/ipc"
+)
+
+// configFilesPathspec matches the config files at the top of the
+// config directory, which excludes the backups and other files
+// that blaj writes there.
+const configFilesPathspec = ":(glob)*.conf"
+
+// gitTimeout is how long git can run before it is killed, so that
+// a git command that waits for something (e.g. a credential prompt
+// or a lock held by another git process) does not block blaj.
+const gitTimeout = 10 * time.Second
+
+// isGitRepo returns true if dir is the top
+// directory of a git repository.
+func isGitRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// runGit runs git in dir and returns its output. git is
+// killed if it runs for longer than gitTimeout. Commits are
+// not signed, since signing may prompt for a passphrase.
+func runGit(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir, "-c", "commit.gpgsign=false"},
+		args...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("git %s timed out after %s", args[0], gitTimeout)
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message != "" {
+			return "", fmt.Errorf("git %s failed - %w - %s", args[0], err, message)
+		}
+
+		return "", fmt.Errorf("git %s failed - %w", args[0], err)
+	}
+
+	return string(output), nil
+}
+
+// commitConfigSnapshot commits the config files in configDir that
+// have uncommitted changes. Only the named files are committed,
+// so other changes that the user has staged are left alone.
+func commitConfigSnapshot(configDir string, configNames []string) error {
+	if len(configNames) == 0 {
+		return nil
+	}
+
+	status, err := runGit(configDir, append([]string{"status", "--porcelain", "--"}, configNames...)...)
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) > 3 {
+			changed = append(changed, strings.Trim(line[3:], `"`))
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	_, err = runGit(configDir, append([]string{"add", "--"}, changed...)...)
+	if err != nil {
+		return err
+	}
+
+	// Snapshots are committed in the background, so the user's
+	// hooks, which may prompt or run slowly, are skipped.
+	message := "Snapshot " + strings.Join(changed, ", ")
+	_, err = runGit(configDir, append([]string{"commit", "--no-verify", "-m", message, "--"},
+		changed...)...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("committed snapshot of %s", strings.Join(changed, ", "))
+
+	return nil
+}
+
+// configRepoStatus returns the status of the config files in the
+// git repository in configDir, or nil if configDir is not a git
+// repository.
+func configRepoStatus(configDir string) *ipc.ConfigRepoStatus {
+	if !isGitRepo(configDir) {
+		return nil
+	}
+
+	repoStatus := &ipc.ConfigRepoStatus{}
+
+	status, err := runGit(configDir, "status", "--porcelain", "--", configFilesPathspec)
+	if err != nil {
+		repoStatus.Error = err.Error()
+		return repoStatus
+	}
+
+	for _, line := range strings.Split(status, "\n") {
+		if line != "" {
+			repoStatus.Changes = append(repoStatus.Changes, line)
+		}
+	}
+
+	repoStatus.Dirty = len(repoStatus.Changes) > 0
+
+	// A repository without commits has no HEAD.
+	head, err := runGit(configDir, "log", "-1", "--format=%h %s")
+	if err == nil {
+		repoStatus.Head = strings.TrimSpace(head)
+	}
+
+	return repoStatus
+}
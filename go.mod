@@ -6,7 +6,7 @@ require (
 	github.com/Andoryuuta/kiwi v0.0.0-20200827010936-214591e6213d
 	github.com/getlantern/systray v1.2.2
 	github.com/mitchellh/go-ps v1.0.0
-	github.com/stephen-fox/user32util v0.3.1
+	golang.org/x/sys v0.1.0
 )
 
 require (
@@ -18,6 +18,5 @@ require (
 	github.com/getlantern/ops v0.0.0-20190325191751-d70cb0d6f85f // indirect
 	github.com/go-stack/stack v1.8.0 // indirect
 	github.com/oxtoacart/bpool v0.0.0-20190530202638-03653db5a59c // indirect
-	golang.org/x/sys v0.1.0 // indirect
 	golang.org/x/text v0.3.2 // indirect
 )
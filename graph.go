@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+// sparkBars are the bars of a sparkline, from lowest to highest.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a line of bars that are scaled between
+// the minimum and maximum values. Values that could not be read
+// (NaN) and infinite values are drawn as spaces.
+func sparkline(values []float64) string {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		if !isFinite(value) {
+			continue
+		}
+
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+	}
+
+	// The range of very large values can overflow.
+	span := max - min
+
+	line := &strings.Builder{}
+	for _, value := range values {
+		switch {
+		case !isFinite(value):
+			line.WriteRune(' ')
+		case max == min || !isFinite(span):
+			line.WriteRune(sparkBars[0])
+		default:
+			i := int((value - min) / span * float64(len(sparkBars)-1))
+			if i < 0 {
+				i = 0
+			} else if i > len(sparkBars)-1 {
+				i = len(sparkBars) - 1
+			}
+
+			line.WriteRune(sparkBars[i])
+		}
+	}
+
+	return line.String()
+}
+
+// isFinite returns true if value is neither NaN nor infinite.
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// historyTitle returns the menu title of a graphed pointer, which
+// shows a sparkline of its recent values and its current value
+// (e.g. "speed ▁▂▄▆█ 12.5").
+func historyTitle(history progctl.ValueHistory) string {
+	current := "?"
+	if len(history.Values) > 0 && !math.IsNaN(history.Values[len(history.Values)-1]) {
+		current = strconv.FormatFloat(history.Values[len(history.Values)-1], 'g', 6, 64)
+	}
+
+	return fmt.Sprintf("%s %s %s", history.Pointer.DisplayName(), sparkline(history.Values), current)
+}
+
+func (o *programUI) ValueHistoryChanged(exename string, histories []progctl.ValueHistory) {
+	for _, history := range histories {
+		menu, hasIt := o.historyMenus[history.ID()]
+		if hasIt {
+			menu.SetTitle(historyTitle(history))
+			menu.Show()
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+// PointerHealthChanged shows the number of pointers that failed the
+// last health check in the program's menu, and lists them in the
+// menu item's tooltip. The program's icon is red while any pointer
+// is unhealthy.
+func (o *programUI) PointerHealthChanged(exename string, unhealthy []progctl.UnhealthyPointer) {
+	if o.healthMenu == nil {
+		return
+	}
+
+	if len(unhealthy) == 0 {
+		o.healthMenu.Hide()
+		o.runningMenu.SetIcon(o.runningIcon())
+		return
+	}
+
+	lines := make([]string, len(unhealthy))
+	for i, pointer := range unhealthy {
+		lines[i] = fmt.Sprintf("%s: %s - %s",
+			pointer.Section, pointer.Pointer.DisplayName(), pointer.Err)
+	}
+
+	o.healthMenu.SetTitle(i18n.Sprintf(i18n.UnhealthyPointersMenu, len(unhealthy)))
+	o.healthMenu.SetTooltip(strings.Join(lines, "\n"))
+	o.healthMenu.Show()
+	o.runningMenu.SetIcon(statusErrorIcon)
+}
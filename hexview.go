@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/hexview"
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+const (
+	hexViewDirName = "hexview"
+
+	// hexViewSize is the number of bytes displayed
+	// by the "View memory" menu.
+	hexViewSize = 256
+)
+
+// viewMemory opens a hex dump of the memory at pointer in Notepad.
+// If the hexViewWriteBack setting is enabled, bytes that the user
+// edits are written back to the program when Notepad is closed.
+func (o *programUI) viewMemory(name string, pointer appconfig.Pointer) {
+	err := o.viewMemoryWithError(name, pointer)
+	if err != nil {
+		log.Printf("failed to view memory of %s - %s", name, err)
+		o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+	}
+}
+
+func (o *programUI) viewMemoryWithError(name string, pointer appconfig.Pointer) error {
+	addr, err := o.routine.ResolveAddr(pointer)
+	if err != nil {
+		return fmt.Errorf("failed to resolve address - %w", err)
+	}
+
+	// Edited bytes are only written back to the
+	// process that the hex dump was read from.
+	processID, err := o.routine.ProcessID()
+	if err != nil {
+		return err
+	}
+
+	start := addr &^ (hexview.BytesPerLine - 1)
+
+	// If the end of the range is unreadable (e.g., it is in a guard
+	// page), the readable part is displayed.
+	original, err := o.routine.ReadMemory(start, hexViewSize)
+	var partialErr *progctl.PartialReadError
+	if err != nil && (!errors.As(err, &partialErr) || len(original) == 0) {
+		return fmt.Errorf("failed to read memory at 0x%x - %w", start, err)
+	}
+
+	configDir, err := configDirPath()
+	if err != nil {
+		return err
+	}
+
+	hexViewDir := filepath.Join(configDir, hexViewDirName)
+	err = os.MkdirAll(hexViewDir, 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create hexview directory - %w", err)
+	}
+
+	filePath := filepath.Join(hexViewDir, fmt.Sprintf("%s-%X.txt", o.program.General.ExeName, start))
+
+	header := []string{
+		fmt.Sprintf("%s (0x%X) in %s", name, addr, o.program.General.ExeName),
+	}
+
+	writeBack := o.app.settings.HexViewWriteBack && !o.app.safeMode
+	if writeBack {
+		header = append(header, "edit the hex bytes and save this file to write them to the program")
+	} else {
+		header = append(header, "this file is read-only (see the hexViewWriteBack setting)")
+	}
+
+	if partialErr != nil {
+		header = append(header, fmt.Sprintf("0x%X-0x%X (%d bytes) could not be read",
+			partialErr.Addr, partialErr.Addr+uintptr(partialErr.Size), partialErr.Size))
+	}
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create hexview file - %w", err)
+	}
+
+	err = hexview.Write(f, start, original, header)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write hexview file - %w", err)
+	}
+
+	cmd := exec.Command("notepad.exe", filePath)
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("failed to start notepad - %w", err)
+	}
+
+	log.Printf("opened memory of %s (0x%x) in notepad", name, addr)
+
+	go func() {
+		defer os.Remove(filePath)
+
+		err := cmd.Wait()
+		if err != nil || !writeBack {
+			return
+		}
+
+		err = o.writeEditedMemory(filePath, processID, start, original)
+		if err != nil {
+			log.Printf("failed to write edited memory of %s - %s", name, err)
+			o.app.errorLog.addEntry(o.program.General.ExeName + ": " + err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// writeEditedMemory writes the bytes in the hex dump at filePath
+// that differ from original, which is the memory at start when the
+// dump was created. Nothing is written if the program's process is
+// not processID, which is the process that the dump was read from.
+func (o *programUI) writeEditedMemory(filePath string, processID progctl.ProcessID, start uintptr, original []byte) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	editedStart, edited, err := hexview.Parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse edited hex dump - %w", err)
+	}
+
+	if editedStart != start || len(edited) != len(original) {
+		return errors.New("the address or number of bytes in the hex dump was changed")
+	}
+
+	if bytes.Equal(edited, original) {
+		return nil
+	}
+
+	for i := 0; i < len(edited); {
+		if edited[i] == original[i] {
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(edited) && edited[end] != original[end] {
+			end++
+		}
+
+		err = o.routine.WriteMemoryOf(processID, start+uintptr(i), edited[i:end])
+		if errors.Is(err, progctl.ErrProcessChanged) {
+			return fmt.Errorf("not writing edited bytes to %s because it was restarted after its memory was viewed",
+				o.program.General.ExeName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %d bytes at 0x%x - %w", end-i, start+uintptr(i), err)
+		}
+
+		log.Printf("wrote %d edited bytes at 0x%x in %s", end-i, start+uintptr(i), o.program.General.ExeName)
+
+		i = end
+	}
+
+	return nil
+}
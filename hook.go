@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/user32"
+)
+
+// hookEvent describes an event to the programs of [Hook] sections.
+type hookEvent struct {
+	name    string
+	exeName string
+	pid     int
+	section string
+	label   string
+	err     error
+}
+
+// env returns the environment variables that describe the event.
+func (o hookEvent) env() []string {
+	env := []string{
+		"BLAJ_EVENT=" + o.name,
+		"BLAJ_EXE=" + o.exeName,
+	}
+
+	if o.pid != 0 {
+		env = append(env, "BLAJ_PID="+strconv.Itoa(o.pid))
+	}
+
+	if o.section != "" {
+		env = append(env, "BLAJ_SECTION="+o.section)
+	}
+
+	if o.label != "" {
+		env = append(env, "BLAJ_LABEL="+o.label)
+	}
+
+	if o.err != nil {
+		env = append(env, "BLAJ_ERROR="+o.err.Error())
+	}
+
+	return env
+}
+
+// maxQueuedHookEvents is the number of events that can wait for
+// their hooks to be started before new events are dropped.
+const maxQueuedHookEvents = 64
+
+// runHooks queues the event for runHookQueue, which starts the program
+// of each hook that handles it. It does not block, since it is called
+// while keybinds and routine events are being handled. The event is
+// dropped and logged if the queue is full.
+func (o *app) runHooks(event hookEvent) {
+	if len(o.hooks) == 0 {
+		return
+	}
+
+	select {
+	case o.hookEvents <- event:
+	default:
+		log.Printf("dropped %s hook event of %s - too many queued events",
+			event.name, event.exeName)
+	}
+}
+
+// runHookQueue starts the hooks of the events queued by runHooks
+// until ctx is done.
+func (o *app) runHookQueue(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-o.hookEvents:
+			o.startHooks(event)
+		}
+	}
+}
+
+// startHooks starts the program of each hook that handles the event.
+// Hooks are not waited on, and failures are only logged so that
+// a broken hook cannot produce an endless stream of error events.
+//
+// Hooks with QuietWhenFullscreen set are skipped while a program that
+// blaj controls is fullscreen in the foreground. The event is logged.
+func (o *app) startHooks(event hookEvent) {
+	fullscreen := ""
+	checkedFullscreen := false
+
+	for _, hook := range o.hooks {
+		if !hook.Handles(event.name, event.exeName) {
+			continue
+		}
+
+		if hook.QuietWhenFullscreen {
+			if !checkedFullscreen {
+				fullscreen = o.fullscreenProgram()
+				checkedFullscreen = true
+			}
+
+			if fullscreen != "" {
+				log.Printf("skipping %s hook %s while %s is fullscreen", event.name, hook.Path, fullscreen)
+				continue
+			}
+		}
+
+		err := startHook(hook, event)
+		if err != nil {
+			log.Printf("failed to run %s hook %s - %s", event.name, hook.Path, err)
+		}
+	}
+}
+
+// fullscreenProgram returns the exe name of the program that blaj
+// controls whose window is fullscreen in the foreground, or an empty
+// string if there is no such program.
+func (o *app) fullscreenProgram() string {
+	pid := user32.FullscreenForegroundPID()
+	if pid == 0 {
+		return ""
+	}
+
+	o.programsMu.Lock()
+	programs := o.programs
+	o.programsMu.Unlock()
+
+	for _, program := range programs {
+		programPID, err := program.routine.PID()
+		if err == nil && programPID == pid {
+			return program.program.General.ExeName
+		}
+	}
+
+	return ""
+}
+
+func startHook(hook *appconfig.Hook, event hookEvent) error {
+	cmd := exec.Command(hook.Path)
+	cmd.Env = append(os.Environ(), event.env()...)
+
+	// Pass the arguments exactly as they were written, since
+	// Windows programs parse their own command line.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine:    syscall.EscapeArg(hook.Path) + " " + hook.Args,
+		HideWindow: true,
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	go cmd.Wait()
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/remoteconf"
+)
+
+// includesApplyInterval is how often updated includes are
+// checked for being applicable while programs are attached.
+const includesApplyInterval = time.Minute
+
+// errIncludesUpdated is sent on the program error channel to
+// reload the program configs after an included file is updated.
+var errIncludesUpdated = errors.New("included config file updated")
+
+// loadWithIncludes calls load until it succeeds or fails for a
+// reason other than an included file not having been downloaded
+// yet. Config files are parsed using the cached copies of their
+// includes, so the missing includes are downloaded between calls.
+func loadWithIncludes(load func() (*appconfig.ProgramConfig, error)) (*appconfig.ProgramConfig, error) {
+	for {
+		program, err := load()
+		if err == nil {
+			return program, nil
+		}
+
+		downloaded, fetchErr := remoteconf.FetchMissing(err)
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+
+		if !downloaded {
+			return nil, err
+		}
+	}
+}
+
+// watchIncludes checks the files included by the programs for updates
+// in the background, since configs are parsed using the cached copies
+// of their includes. Once a file is updated and no programs are
+// attached, errIncludesUpdated is sent on reload so that the configs
+// are loaded again with the updated files.
+func (o *app) watchIncludes(ctx context.Context, programs []*appconfig.ProgramConfig, reload chan<- error) {
+	seen := make(map[string]struct{})
+	var updated []string
+
+	for _, program := range programs {
+		for _, rawURL := range program.Includes {
+			if _, hasIt := seen[rawURL]; hasIt {
+				continue
+			}
+			seen[rawURL] = struct{}{}
+
+			result, err := remoteconf.Fetch(rawURL)
+			switch {
+			case err != nil:
+				log.Printf("failed to check %s for updates - %v", rawURL, err)
+			case result.Stale != nil:
+				log.Printf("failed to check %s for updates - %v", rawURL, result.Stale)
+				o.errorLog.addEntry("using cached copy of " + rawURL + " - " + result.Stale.Error())
+			case result.Changed:
+				log.Printf("%s was updated", rawURL)
+				updated = append(updated, rawURL)
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+
+	if len(updated) == 0 {
+		return
+	}
+
+	o.errorLog.addEntry(strings.Join(updated, ", ") +
+		" updated (they will be applied once no programs are attached)")
+
+	ticker := time.NewTicker(includesApplyInterval)
+	defer ticker.Stop()
+
+	// Reloading restarts every program routine,
+	// which would discard attached programs' state.
+	for o.attachedCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case reload <- errIncludesUpdated:
+	}
+}
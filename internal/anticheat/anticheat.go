@@ -0,0 +1,123 @@
+// Package anticheat prevents blaj from attaching to games that are
+// protected by anti-cheat software. Modifying the memory of these
+// games may result in the user being banned.
+package anticheat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownExes maps the exe names of games known to use anti-cheat
+// software to the name of the anti-cheat software.
+var knownExes = map[string]string{
+	"valorant.exe":                      "Riot Vanguard",
+	"valorant-win64-shipping.exe":       "Riot Vanguard",
+	"league of legends.exe":             "Riot Vanguard",
+	"fortniteclient-win64-shipping.exe": "Easy Anti-Cheat and BattlEye",
+	"r5apex.exe":                        "Easy Anti-Cheat",
+	"r5apex_dx12.exe":                   "Easy Anti-Cheat",
+	"tslgame.exe":                       "BattlEye",
+	"rainbowsix.exe":                    "BattlEye",
+	"rainbowsix_be.exe":                 "BattlEye",
+	"destiny2.exe":                      "BattlEye",
+	"escapefromtarkov.exe":              "BattlEye",
+	"cs2.exe":                           "Valve Anti-Cheat",
+	"csgo.exe":                          "Valve Anti-Cheat",
+	"dota2.exe":                         "Valve Anti-Cheat",
+	"overwatch.exe":                     "Defense Matrix",
+	"cod.exe":                           "Ricochet",
+	"gta5.exe":                          "BattlEye",
+}
+
+// knownModules maps the names of modules loaded by anti-cheat
+// software to the name of the anti-cheat software.
+var knownModules = map[string]string{
+	"easyanticheat.dll":     "Easy Anti-Cheat",
+	"easyanticheat_x64.dll": "Easy Anti-Cheat",
+	"easyanticheat_x86.dll": "Easy Anti-Cheat",
+	"easyanticheat_eos.dll": "Easy Anti-Cheat",
+	"beclient.dll":          "BattlEye",
+	"beclient_x64.dll":      "BattlEye",
+}
+
+// Guard decides whether blaj may attach to a program.
+type Guard struct {
+	// Allow contains lowercase exe names that may be attached to
+	// even if they are known to use anti-cheat software (for example,
+	// a game that is only played offline).
+	Allow []string
+
+	// Deny contains additional lowercase exe names that must
+	// not be attached to.
+	Deny []string
+}
+
+// Error is returned when a program is protected by anti-cheat software.
+type Error struct {
+	ExeName   string
+	AntiCheat string
+}
+
+func (o *Error) Error() string {
+	return fmt.Sprintf("refusing to attach to %s because it is protected by %s - "+
+		"modifying its memory may get you banned (add \"allowExe = %s\" to the "+
+		"[Blaj] section of blaj.ini to override this)",
+		o.ExeName, o.AntiCheat, o.ExeName)
+}
+
+func (o *Guard) allowed(exeName string) bool {
+	if o == nil {
+		return false
+	}
+
+	for _, allowed := range o.Allow {
+		if allowed == exeName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckExe returns an *Error if exeName belongs to a game
+// that is known to use anti-cheat software.
+func (o *Guard) CheckExe(exeName string) error {
+	exeName = strings.ToLower(exeName)
+	if o.allowed(exeName) {
+		return nil
+	}
+
+	if o != nil {
+		for _, denied := range o.Deny {
+			if denied == exeName {
+				return &Error{ExeName: exeName, AntiCheat: "anti-cheat software (user deny list)"}
+			}
+		}
+	}
+
+	antiCheat, isKnown := knownExes[exeName]
+	if isKnown {
+		return &Error{ExeName: exeName, AntiCheat: antiCheat}
+	}
+
+	return nil
+}
+
+// CheckModules returns an *Error if any of the module names loaded
+// by exeName belong to anti-cheat software.
+func (o *Guard) CheckModules(exeName string, moduleNames []string) error {
+	exeName = strings.ToLower(exeName)
+	if o.allowed(exeName) {
+		return nil
+	}
+
+	for _, moduleName := range moduleNames {
+		antiCheat, isKnown := knownModules[strings.ToLower(moduleName)]
+		if isKnown {
+			return &Error{ExeName: exeName, AntiCheat: antiCheat}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package appconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// aliasPrefix is the prefix of a reference to an address alias
+// declared in the [Addresses] section (e.g. "@playerBase").
+const aliasPrefix = "@"
+
+// Addresses is the [Addresses] section. Each parameter declares a
+// named pointer chain that can be referenced by the pointers of the
+// sections that follow it.
+//
+// If the [General] section configures an offset feed, the feed's
+// value for a parameter is used instead of the parameter's value.
+type Addresses struct {
+	config *ProgramConfig
+}
+
+func (o *Addresses) RequiredParams() []string {
+	return nil
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Addresses) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "<name>", Type: pointerType, Example: "player",
+			Help: "A pointer chain that later pointers can refer to by writing @<name>."},
+	}
+}
+
+func (o *Addresses) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	return func(param *ini.Param) error {
+		if o.config.feed != nil {
+			feedValue, hasIt := o.config.feed.Addresses[name]
+			if hasIt {
+				feedParam := *param
+				feedParam.Value = feedValue
+
+				pointer, err := pointerFromParam(&feedParam, o.config)
+				if err != nil {
+					return fmt.Errorf("failed to parse address from offset feed version %q: %q - %w",
+						o.config.feed.Version, param.Name, err)
+				}
+
+				o.config.setAddress(name, pointer)
+				return nil
+			}
+		}
+
+		pointer, err := pointerFromParam(param, o.config)
+		if err != nil {
+			return fmt.Errorf("failed to parse address: %q - %w", param.Name, err)
+		}
+
+		o.config.setAddress(name, pointer)
+		return nil
+	}, ini.SchemaRule{Limit: 1}
+}
+
+func (o *Addresses) Validate() error {
+	return nil
+}
+
+func (o *ProgramConfig) setAddress(name string, pointer Pointer) {
+	if o.addresses == nil {
+		o.addresses = make(map[string]Pointer)
+	}
+
+	o.addresses[name] = pointer
+}
+
+// resolveAlias returns the pointer chain of the alias referenced
+// by str (e.g. "@playerBase").
+func resolveAlias(str string, addresses map[string]Pointer) (Pointer, error) {
+	name := strings.ToLower(strings.TrimPrefix(str, aliasPrefix))
+
+	pointer, hasIt := addresses[name]
+	if !hasIt {
+		return Pointer{}, fmt.Errorf("unknown address %q (addresses must be declared in an [Addresses] section before they are used)", str)
+	}
+
+	return pointer, nil
+}
@@ -0,0 +1,33 @@
+package appconfig
+
+import (
+	"fmt"
+)
+
+// resolveAfterRestores sets the AfterRestore field of each SaveRestore
+// section that has an afterRestore param. It is called once every
+// section has been parsed, since a SaveRestore section may refer to
+// a writer that is declared after it.
+func (o *ProgramConfig) resolveAfterRestores() error {
+	for _, saveRestore := range o.SaveRestores {
+		if saveRestore.afterRestore == "" {
+			continue
+		}
+
+		section, err := o.SectionByName(saveRestore.afterRestore)
+		if err != nil {
+			return fmt.Errorf("failed to find afterRestore section of %s - %w",
+				o.SectionID(saveRestore), err)
+		}
+
+		writer, ok := section.(*Writer)
+		if !ok {
+			return fmt.Errorf("afterRestore section of %s must be a writer section, not %s",
+				o.SectionID(saveRestore), o.SectionID(section))
+		}
+
+		saveRestore.AfterRestore = writer
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package appconfig
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -8,25 +10,60 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/SeungKang/blaj/internal/bundle"
 	"github.com/SeungKang/blaj/internal/ini"
+	"github.com/SeungKang/blaj/internal/offsetfeed"
+	"github.com/SeungKang/blaj/internal/remoteconf"
 )
 
+// maxLineSize is the maximum length of a config file line. It is
+// large enough to accommodate writers with large amounts of data.
+const maxLineSize = 16 * 1024 * 1024
+
 const (
 	readPointerParamSuffix  = "pointer_"
 	writePointerParamSuffix = "pointer"
 	dataParamSuffix         = "data"
+	labelParamSuffix        = "label"
+	fieldsParamSuffix       = "fields"
+
+	// defaultMaxPointerSize is the default maximum size of a pointer.
+	// It prevents a typo like "xPointer_400000000" from reading
+	// hundreds of megabytes from the target process.
+	defaultMaxPointerSize = 1 << 20
+)
+
+const (
+	// defaultReattachGracePeriod is how long saved states are kept
+	// after the program exits, in case it restarts itself.
+	defaultReattachGracePeriod = 10 * time.Second
+
+	// defaultMaxStateMemory is the default maximum total
+	// size of a program's saved states.
+	defaultMaxStateMemory = 256 << 20
+
+	// defaultHeartbeatInterval is how often the program's
+	// memory is read to check that it is still accessible.
+	defaultHeartbeatInterval = 5 * time.Second
+
+	// defaultOffsetFeedInterval is how often offset
+	// feeds are checked for updates.
+	defaultOffsetFeedInterval = time.Hour
+
+	// defaultFreezeInterval is how often a frozen
+	// writer's data is written.
+	defaultFreezeInterval = 100 * time.Millisecond
 )
 
 func ProgramConfigFromPath(filePath string) (*ProgramConfig, error) {
-	configFile, err := os.Open(filePath)
+	configFile, err := openConfigFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open config file - %w", err)
 	}
-	defer configFile.Close()
 
 	config, err := parseProgramConfig(configFile)
-	configFile.Close()
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config - %w", err)
 	}
@@ -36,11 +73,32 @@ func ProgramConfigFromPath(filePath string) (*ProgramConfig, error) {
 
 type Config struct {
 	Programs []*ProgramConfig
+
+	// Files is the result of loading each of the files
+	// in the config directory (see LoadAll).
+	Files []*LoadedFile
+}
+
+// ParseProgramConfig parses a program config from r. Like config
+// files, r may be encoded as UTF-8 (with or without a BOM) or UTF-16.
+func ParseProgramConfig(r io.Reader) (*ProgramConfig, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config - %w", err)
+	}
+
+	decoded, err := toUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProgramConfig(bytes.NewReader(decoded))
 }
 
 func parseProgramConfig(r io.Reader) (*ProgramConfig, error) {
 	programConfig := &ProgramConfig{
-		Keybinds: make(map[byte][]interface{}),
+		Keybinds: make(map[Key][]interface{}),
+		Strict:   true,
 	}
 	err := ini.ParseSchema(r, programConfig)
 	if err != nil {
@@ -54,33 +112,360 @@ type ProgramConfig struct {
 	General      *General
 	SaveRestores []*SaveRestore
 	Writers      []*Writer
-	Keybinds     map[byte][]interface{}
+	Counters     []*Counter
+	Seeds        []*Seed
+	Nudges       []*Nudge
+	Keybinds     map[Key][]interface{}
+
+	// Strict is false if unknown sections and parameters are
+	// ignored rather than treated as errors. It is set by the
+	// "strict" global parameter, which must come before the
+	// first section.
+	Strict bool
+
+	// Warnings describes the unknown sections and parameters
+	// that were ignored because Strict is false.
+	Warnings []string
+
+	// Includes are the URLs of the config files that
+	// were included, in the order they were included.
+	Includes []string
+
+	addresses map[string]Pointer
+	feed      *offsetfeed.Manifest
+
+	// local is true while a local config file is being
+	// applied (see ApplyLocalConfigFromPath).
+	local bool
+
+	// sectionOrder maps each SaveRestore, Writer, Counter, and
+	// Nudge section to the order that it was declared in.
+	sectionOrder map[interface{}]int
+}
+
+// SectionByName returns the SaveRestore, Writer, Counter, or Nudge
+// section identified by name. The name may either be a section's name
+// (e.g. "boss2" for [SaveRestore "boss2"]), its label (both
+// case-insensitive), or the section type followed by its 1-based
+// index in the config file (e.g. "saverestore#2").
+func (o *ProgramConfig) SectionByName(name string) (interface{}, error) {
+	named := o.namedSection(name)
+	if named != nil {
+		return named, nil
+	}
+
+	sectionType, indexStr, hasIndex := strings.Cut(strings.ToLower(name), "#")
+	if hasIndex {
+		index, err := strconv.Atoi(indexStr)
+		if err != nil || index < 1 {
+			return nil, fmt.Errorf("invalid section index: %q", indexStr)
+		}
+
+		switch sectionType {
+		case "saverestore":
+			if index > len(o.SaveRestores) {
+				return nil, fmt.Errorf("only %d saverestore sections are defined", len(o.SaveRestores))
+			}
+
+			return o.SaveRestores[index-1], nil
+		case "writer":
+			if index > len(o.Writers) {
+				return nil, fmt.Errorf("only %d writer sections are defined", len(o.Writers))
+			}
+
+			return o.Writers[index-1], nil
+		case "counter":
+			if index > len(o.Counters) {
+				return nil, fmt.Errorf("only %d counter sections are defined", len(o.Counters))
+			}
+
+			return o.Counters[index-1], nil
+		case "nudge":
+			if index > len(o.Nudges) {
+				return nil, fmt.Errorf("only %d nudge sections are defined", len(o.Nudges))
+			}
+
+			return o.Nudges[index-1], nil
+		}
+	}
+
+	var found []interface{}
+	for _, saveRestore := range o.SaveRestores {
+		if strings.EqualFold(saveRestore.Label, name) {
+			found = append(found, saveRestore)
+		}
+	}
+
+	for _, writer := range o.Writers {
+		if strings.EqualFold(writer.Label, name) {
+			found = append(found, writer)
+		}
+	}
+
+	for _, counter := range o.Counters {
+		if strings.EqualFold(counter.Label, name) {
+			found = append(found, counter)
+		}
+	}
+
+	for _, nudge := range o.Nudges {
+		if strings.EqualFold(nudge.Label, name) {
+			found = append(found, nudge)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no section named %q", name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%d sections are named %q", len(found), name)
+	}
+}
+
+// SectionID returns a string that identifies section. It is the
+// section's name if it has one, since names do not change when
+// sections are added or moved. Otherwise, it is the section's type
+// and 1-based index (e.g. "writer#1"). The result can be passed to
+// SectionByName. An empty string is returned if section does not
+// belong to the config.
+func (o *ProgramConfig) SectionID(section interface{}) string {
+	if name := sectionName(section); name != "" && o.namedSection(name) == section {
+		return name
+	}
+
+	switch v := section.(type) {
+	case *SaveRestore:
+		for i, saveRestore := range o.SaveRestores {
+			if saveRestore == v {
+				return "saverestore#" + strconv.Itoa(i+1)
+			}
+		}
+	case *Writer:
+		for i, writer := range o.Writers {
+			if writer == v {
+				return "writer#" + strconv.Itoa(i+1)
+			}
+		}
+	case *Counter:
+		for i, counter := range o.Counters {
+			if counter == v {
+				return "counter#" + strconv.Itoa(i+1)
+			}
+		}
+	case *Nudge:
+		for i, nudge := range o.Nudges {
+			if nudge == v {
+				return "nudge#" + strconv.Itoa(i+1)
+			}
+		}
+	}
+
+	return ""
+}
+
+// NeedsWriteAccess returns true if the config has sections that
+// write to the program's memory. Configs that only count keypresses
+// or display values only need to read it.
+func (o *ProgramConfig) NeedsWriteAccess() bool {
+	return len(o.SaveRestores) > 0 || len(o.Writers) > 0 || len(o.Seeds) > 0 || len(o.Nudges) > 0
+}
+
+// namedSection returns the SaveRestore, Writer, Counter, or
+// Nudge section whose name is name (case-insensitive), or nil
+// if there is no such section.
+func (o *ProgramConfig) namedSection(name string) interface{} {
+	for _, saveRestore := range o.SaveRestores {
+		if saveRestore.Name != "" && strings.EqualFold(saveRestore.Name, name) {
+			return saveRestore
+		}
+	}
+
+	for _, writer := range o.Writers {
+		if writer.Name != "" && strings.EqualFold(writer.Name, name) {
+			return writer
+		}
+	}
+
+	for _, counter := range o.Counters {
+		if counter.Name != "" && strings.EqualFold(counter.Name, name) {
+			return counter
+		}
+	}
+
+	for _, nudge := range o.Nudges {
+		if nudge.Name != "" && strings.EqualFold(nudge.Name, name) {
+			return nudge
+		}
+	}
+
+	return nil
+}
+
+// checkSectionName returns an error if a section
+// named name has already been declared.
+func (o *ProgramConfig) checkSectionName(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if o.namedSection(name) != nil {
+		return fmt.Errorf("a section named %q is already declared in a previous section", name)
+	}
+
+	return nil
+}
+
+// sectionName returns the name of a SaveRestore,
+// Writer, Counter, or Nudge section.
+func sectionName(section interface{}) string {
+	switch v := section.(type) {
+	case *SaveRestore:
+		return v.Name
+	case *Writer:
+		return v.Name
+	case *Counter:
+		return v.Name
+	case *Nudge:
+		return v.Name
+	default:
+		return ""
+	}
+}
+
+// maxPointerSize returns the maximum number of
+// bytes that a pointer may read or write.
+func (o *ProgramConfig) maxPointerSize() int {
+	if o.General == nil {
+		return defaultMaxPointerSize
+	}
+
+	return o.General.MaxPointerSize
+}
+
+// decimalOffsets returns true if pointer offsets
+// are decimal by default.
+func (o *ProgramConfig) decimalOffsets() bool {
+	return o.General != nil && o.General.DecimalOffsets
 }
 
 func (o *ProgramConfig) Rules() ini.ParserRules {
-	return ini.ParserRules{
-		LowercaseNames: true,
+	rules := ini.ParserRules{
+		AllowGlobalParams: true,
+		LowercaseNames:    true,
 		RequiredSections: []string{
 			"general",
 		},
+		IncludeParam:      "include",
+		MaxLineSize:       maxLineSize,
+		AllowSectionNames: true,
+	}
+
+	// The [General] section of a local config file
+	// is optional since the shared file has one.
+	if o.local {
+		rules.RequiredSections = nil
+	}
+
+	return rules
+}
+
+// DescribeSections implements ini.SchemaDescriber.
+func (o *ProgramConfig) DescribeSections() []ini.SectionDescription {
+	return []ini.SectionDescription{
+		{Name: "General", Help: "Identifies the program that the config file applies to."},
+		{Name: "Addresses", Help: "Names addresses so that pointers can refer to them."},
+		{Name: "SaveRestore", Help: "Saves and restores memory when its keybinds are pressed."},
+		{Name: "Writer", Help: "Writes data to memory when its keybind is pressed."},
+		{Name: "Counter", Help: "Counts how many times its keybind is pressed."},
+		{Name: "Seed", Help: "Saves, restores, and rerolls a random number generator's seed."},
+		{Name: "Nudge", Help: "Adds or subtracts a step from a value when its keybinds are pressed."},
+		{Name: "Override", Help: "Changes the keybinds of a section in the shared config file. Local config files only."},
 	}
 }
 
 func (o *ProgramConfig) OnGlobalParam(paramName string) (func(*ini.Param) error, ini.SchemaRule) {
-	return nil, ini.SchemaRule{}
+	switch paramName {
+	case "strict":
+		return func(param *ini.Param) error {
+			strict, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for strict param - %w", err)
+			}
+
+			o.Strict = strict
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+// OnInclude implements ini.Includer. Included config files are read
+// from the copies cached by remoteconf, so that parsing never waits
+// for the network. A *remoteconf.NotCachedError is returned if the
+// file has not been downloaded yet (see remoteconf.FetchMissing).
+func (o *ProgramConfig) OnInclude(rawURL string) (io.Reader, error) {
+	o.Includes = append(o.Includes, rawURL)
+
+	result, err := remoteconf.Cached(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := toUTF8(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(decoded), nil
+}
+
+// OnUnknown implements ini.UnknownHandler. Unknown sections and
+// parameters are errors unless Strict is false, in which case they
+// are recorded in Warnings. This allows a config written for a newer
+// version of blaj to be loaded by an older version.
+func (o *ProgramConfig) OnUnknown(err error) error {
+	if o.Strict {
+		return err
+	}
+
+	o.Warnings = append(o.Warnings, err.Error())
+	return nil
 }
 
 func (o *ProgramConfig) OnSection(name string, actualName string) (func() (ini.SectionSchema, error), ini.SchemaRule) {
+	instanceName := ini.SectionInstanceName(actualName)
+
 	switch name {
 	case "general":
 		return func() (ini.SectionSchema, error) {
-			o.General = &General{}
+			if instanceName != "" {
+				return nil, errors.New("[General] sections cannot be named")
+			}
+
+			// A local config file changes the
+			// shared file's [General] section.
+			if o.local && o.General != nil {
+				return o.General, nil
+			}
+
+			o.General = &General{
+				MaxPointerSize:      defaultMaxPointerSize,
+				ReattachGracePeriod: defaultReattachGracePeriod,
+				HeartbeatInterval:   defaultHeartbeatInterval,
+				MaxStateMemory:      defaultMaxStateMemory,
+				OffsetFeedInterval:  defaultOffsetFeedInterval,
+				config:              o,
+			}
 
 			return o.General, nil
 		}, ini.SchemaRule{Limit: 1}
 	case "saverestore":
 		return func() (ini.SectionSchema, error) {
 			saveRestore := &SaveRestore{
+				Name:   instanceName,
 				config: o,
 			}
 
@@ -89,11 +474,59 @@ func (o *ProgramConfig) OnSection(name string, actualName string) (func() (ini.S
 	case "writer":
 		return func() (ini.SectionSchema, error) {
 			writer := &Writer{
-				config: o,
+				Name:           instanceName,
+				FreezeInterval: defaultFreezeInterval,
+				config:         o,
 			}
 
 			return writer, nil
 		}, ini.SchemaRule{}
+	case "nudge":
+		return func() (ini.SectionSchema, error) {
+			nudge := &Nudge{
+				Name:   instanceName,
+				config: o,
+			}
+
+			return nudge, nil
+		}, ini.SchemaRule{}
+	case "addresses":
+		return func() (ini.SectionSchema, error) {
+			if instanceName != "" {
+				return nil, errors.New("[Addresses] sections cannot be named")
+			}
+
+			return &Addresses{config: o}, nil
+		}, ini.SchemaRule{Limit: 1}
+	case "counter":
+		return func() (ini.SectionSchema, error) {
+			counter := &Counter{
+				Name:   instanceName,
+				config: o,
+			}
+
+			return counter, nil
+		}, ini.SchemaRule{}
+	case "seed":
+		return func() (ini.SectionSchema, error) {
+			seed := &Seed{
+				SaveRestore: &SaveRestore{
+					Name:   instanceName,
+					config: o,
+				},
+				config: o,
+			}
+
+			return seed, nil
+		}, ini.SchemaRule{}
+	case "override":
+		return func() (ini.SectionSchema, error) {
+			if instanceName != "" {
+				return nil, errors.New("[Override] sections cannot be named")
+			}
+
+			return &Override{config: o}, nil
+		}, ini.SchemaRule{}
 	default:
 		return nil, ini.SchemaRule{}
 	}
@@ -105,25 +538,178 @@ type sectionIndex struct {
 }
 
 func (o *ProgramConfig) Validate() error {
-	return nil
+	err := o.resolveAfterRestores()
+	if err != nil {
+		return err
+	}
+
+	return o.resolveChains()
 }
 
 type General struct {
 	ExeName  string
 	Disabled bool
+
+	// DecimalOffsets makes pointer offsets without a "0x" prefix
+	// (or an "h" suffix) decimal instead of hexadecimal.
+	DecimalOffsets bool
+
+	// MaxPointerSize is the maximum number of bytes that
+	// a pointer may read or write.
+	MaxPointerSize int
+
+	// MaxStateMemory is the maximum total number of bytes of
+	// saved states. The least recently saved states are discarded
+	// when it is exceeded. Zero means there is no limit.
+	MaxStateMemory int
+
+	// WaitForWindow delays attaching to the program until it has
+	// a visible window, since programs may not have loaded their
+	// modules while they are starting up.
+	WaitForWindow bool
+
+	// AttachGracePeriod is how long after attaching to the program
+	// that failed actions are retried instead of being reported.
+	AttachGracePeriod time.Duration
+
+	// ReattachGracePeriod is how long saved states are kept after
+	// the program exits. If a new process with the same exe name
+	// starts within this period (e.g. a game that restarts itself
+	// through a launcher), it is attached to with the saved states
+	// intact. Zero disables this behavior.
+	ReattachGracePeriod time.Duration
+
+	// HeartbeatInterval is how often a byte of the program's
+	// memory is read to detect process handles that are no
+	// longer valid. Zero disables heartbeats.
+	HeartbeatInterval time.Duration
+
+	// OffsetFeed is the URL of a signed offset manifest whose
+	// addresses replace the values of the [Addresses] section.
+	OffsetFeed string
+
+	// OffsetFeedSigner is the public key that OffsetFeed
+	// must be signed with.
+	OffsetFeedSigner ed25519.PublicKey
+
+	// OffsetFeedInterval is how often OffsetFeed is checked for
+	// updates while blaj is running. Zero means that it is only
+	// checked when blaj starts.
+	OffsetFeedInterval time.Duration
+
+	// OffsetFeedVersion and OffsetFeedSerial identify the manifest
+	// that was applied when the config was loaded. They are empty
+	// if the feed has not been downloaded yet.
+	OffsetFeedVersion string
+	OffsetFeedSerial  uint64
+
+	// PauseWhenMinimized stops frozen writers from writing
+	// while all of the program's windows are minimized.
+	PauseWhenMinimized bool
+
+	// PausedPointer, if non-nil, points to a byte that is
+	// non-zero while the game is paused. Frozen writers do
+	// not write while the game is paused.
+	PausedPointer *Pointer
+
+	// ValueHistory is how long the values of the pointers returned
+	// by ProgramConfig.GraphedPointers are recorded for graphing.
+	// Zero disables recording.
+	ValueHistory time.Duration
+
+	// HealthCheckInterval is how often every pointer is resolved
+	// and read in the background to detect pointers that no longer
+	// work (e.g. after a game update). Zero disables health checks.
+	HealthCheckInterval time.Duration
+
+	// TraceChains logs each address and value that is read
+	// while the pointer chains of actions are followed.
+	TraceChains bool
+
+	// IdleTimeout is how long after the last keybind the process
+	// handle is closed, provided that no writers are frozen and no
+	// actions are scheduled. The handle is reopened by the next
+	// keybind. Zero keeps the handle open.
+	IdleTimeout time.Duration
+
+	// InputDevice, if non-empty, limits keybinds to keyboards whose
+	// device names contain it (case-insensitive), e.g. the vendor
+	// and product ID of a macro pad. Device names are only known
+	// when keyboard input is received using Raw Input.
+	InputDevice string
+
+	config *ProgramConfig
 }
 
 func (o *General) RequiredParams() []string {
+	if o.config.local {
+		return nil
+	}
+
 	return []string{
 		"exename",
 	}
 }
 
+// DescribeParams implements ini.ParamDescriber.
+func (o *General) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "exeName", Type: stringType, Required: true,
+			Help: "The name of the program's executable file (e.g. MirrorsEdge.exe)."},
+		{Name: "disabled", Type: boolType, Default: "false",
+			Help: "Skip this config file."},
+		{Name: "decimalOffsets", Type: boolType, Default: "false",
+			Help: "Treat offsets without a 0x prefix or h suffix as decimal."},
+		{Name: "maxPointerSize", Type: "number", Default: "1048576",
+			Help: "The maximum number of bytes that a pointer can read or write."},
+		{Name: "maxStateMemory", Type: "number", Default: "268435456",
+			Help: "The maximum number of bytes of saved states to keep in memory. 0 means no limit."},
+		{Name: "waitForWindow", Type: boolType, Default: "false",
+			Help: "Wait until the program has a visible window before attaching to it."},
+		{Name: "attachGracePeriod", Type: durationType, Default: "0s",
+			Help: "How long after attaching that failed actions are retried rather than reported."},
+		{Name: "reattachGracePeriod", Type: durationType, Default: "10s",
+			Help: "How long saved states are kept after the program exits in case it restarts."},
+		{Name: "heartbeatInterval", Type: durationType, Default: "5s",
+			Help: "How often the program is checked for being responsive. 0s disables the check."},
+		{Name: "pauseWhenMinimized", Type: boolType, Default: "false",
+			Help: "Stop frozen writers from writing while the program is minimized."},
+		{Name: "pausedPointer", Type: pointerType,
+			Help: "A byte that is non-zero while the game is paused. Frozen writers do not write while it is set."},
+		{Name: "valueHistory", Type: durationType, Default: "0s",
+			Help: "How long to graph the values of pointers with a numeric display format. 0s disables graphs."},
+		{Name: "healthCheckInterval", Type: durationType, Default: "0s",
+			Help: "How often every pointer is checked in the background. 0s disables the checks."},
+		{Name: "traceChains", Type: boolType, Default: "false",
+			Help: "Log each address and value read while following pointer chains."},
+		{Name: "idleTimeout", Type: durationType, Default: "0s",
+			Help: "How long after the last keybind the program's process handle is closed. 0s keeps it open."},
+		{Name: "inputDevice", Type: stringType,
+			Help: "Only handle keybinds from keyboards whose device names contain this text (e.g. VID_1234&PID_5678)."},
+		{Name: "offsetFeed", Type: "https url",
+			Help: "A signed offset manifest whose addresses replace the values of the [Addresses] section."},
+		{Name: "offsetFeedSigner", Type: "base64 public key",
+			Help: "The public key that the offset feed must be signed with. Required if offsetFeed is set."},
+		{Name: "offsetFeedInterval", Type: durationType, Default: "1h",
+			Help: "How often the offset feed is checked for updates. 0s disables the checks."},
+	}
+}
+
 func (o *General) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
 	switch name {
 	case "exename":
 		return func(param *ini.Param) error {
-			o.ExeName = strings.ToLower(param.Value)
+			exeName := strings.ToLower(param.Value)
+			if exeName == "" {
+				return errors.New("exeName is empty")
+			}
+
+			if o.config.local && exeName != o.ExeName {
+				return fmt.Errorf("exeName cannot be changed by a local config file (the shared config's exeName is %q)",
+					o.ExeName)
+			}
+
+			o.ExeName = exeName
 			return nil
 		}, ini.SchemaRule{Limit: 1}
 	case "disabled":
@@ -136,16 +722,260 @@ func (o *General) OnParam(name string) (func(param *ini.Param) error, ini.Schema
 			o.Disabled = disabled
 			return nil
 		}, ini.SchemaRule{Limit: 1}
+	case "maxpointersize":
+		return func(param *ini.Param) error {
+			maxPointerSize, err := strconv.ParseUint(param.Value, 10, 31)
+			if err != nil {
+				return fmt.Errorf("failed to parse maxPointerSize param - %w", err)
+			}
+
+			if maxPointerSize == 0 {
+				return errors.New("maxPointerSize must be greater than zero")
+			}
+
+			o.MaxPointerSize = int(maxPointerSize)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "waitforwindow":
+		return func(param *ini.Param) error {
+			waitForWindow, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for waitForWindow param - %w", err)
+			}
+
+			o.WaitForWindow = waitForWindow
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "attachgraceperiod":
+		return func(param *ini.Param) error {
+			gracePeriod, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse attachGracePeriod param - %w", err)
+			}
+
+			if gracePeriod < 0 {
+				return errors.New("attachGracePeriod cannot be negative")
+			}
+
+			o.AttachGracePeriod = gracePeriod
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "heartbeatinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse heartbeatInterval param - %w", err)
+			}
+
+			if interval < 0 {
+				return errors.New("heartbeatInterval cannot be negative")
+			}
+
+			o.HeartbeatInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "pausewhenminimized":
+		return func(param *ini.Param) error {
+			pause, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for pauseWhenMinimized param - %w", err)
+			}
+
+			o.PauseWhenMinimized = pause
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "pausedpointer":
+		return func(param *ini.Param) error {
+			pointer, err := pointerFromParam(param, o.config)
+			if err != nil {
+				return fmt.Errorf("failed to parse pausedPointer param - %w", err)
+			}
+
+			o.PausedPointer = &pointer
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "valuehistory":
+		return func(param *ini.Param) error {
+			history, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse valueHistory param - %w", err)
+			}
+
+			if history < 0 {
+				return errors.New("valueHistory cannot be negative")
+			}
+
+			o.ValueHistory = history
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "healthcheckinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse healthCheckInterval param - %w", err)
+			}
+
+			if interval < 0 {
+				return errors.New("healthCheckInterval cannot be negative")
+			}
+
+			o.HealthCheckInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "tracechains":
+		return func(param *ini.Param) error {
+			traceChains, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for traceChains param - %w", err)
+			}
+
+			o.TraceChains = traceChains
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "idletimeout":
+		return func(param *ini.Param) error {
+			timeout, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse idleTimeout param - %w", err)
+			}
+
+			if timeout < 0 {
+				return errors.New("idleTimeout cannot be negative")
+			}
+
+			o.IdleTimeout = timeout
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "inputdevice":
+		return func(param *ini.Param) error {
+			o.InputDevice = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "offsetfeed":
+		return func(param *ini.Param) error {
+			if o.config.local {
+				return errors.New("offsetFeed cannot be set by a local config file")
+			}
+
+			if !strings.HasPrefix(param.Value, "https://") {
+				return fmt.Errorf("offsetFeed must be an https url (got %q)", param.Value)
+			}
+
+			o.OffsetFeed = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "offsetfeedsigner":
+		return func(param *ini.Param) error {
+			if o.config.local {
+				return errors.New("offsetFeedSigner cannot be set by a local config file")
+			}
+
+			signer, err := bundle.ParsePublicKey(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse offsetFeedSigner param - %w", err)
+			}
+
+			o.OffsetFeedSigner = signer
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "offsetfeedinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse offsetFeedInterval param - %w", err)
+			}
+
+			if interval < 0 {
+				return errors.New("offsetFeedInterval cannot be negative")
+			}
+
+			o.OffsetFeedInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "reattachgraceperiod":
+		return func(param *ini.Param) error {
+			gracePeriod, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse reattachGracePeriod param - %w", err)
+			}
+
+			if gracePeriod < 0 {
+				return errors.New("reattachGracePeriod cannot be negative")
+			}
+
+			o.ReattachGracePeriod = gracePeriod
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "maxstatememory":
+		return func(param *ini.Param) error {
+			maxStateMemory, err := strconv.ParseUint(param.Value, 10, 31)
+			if err != nil {
+				return fmt.Errorf("failed to parse maxStateMemory param - %w", err)
+			}
+
+			o.MaxStateMemory = int(maxStateMemory)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "decimaloffsets":
+		return func(param *ini.Param) error {
+			decimalOffsets, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for decimalOffsets param - %w", err)
+			}
+
+			o.DecimalOffsets = decimalOffsets
+			return nil
+		}, ini.SchemaRule{Limit: 1}
 	default:
 		return nil, ini.SchemaRule{}
 	}
 }
 
 func (o *General) Validate() error {
+	// The offset feed was already fetched
+	// when the shared config was parsed.
+	if o.config.local {
+		return nil
+	}
+
+	if o.OffsetFeed == "" {
+		if o.OffsetFeedSigner != nil {
+			return errors.New("offsetFeedSigner requires offsetFeed")
+		}
+
+		return nil
+	}
+
+	if o.OffsetFeedSigner == nil {
+		return errors.New("offsetFeed requires offsetFeedSigner")
+	}
+
+	if len(o.config.addresses) > 0 {
+		return errors.New("the [Addresses] section must come after the [General] section when offsetFeed is set")
+	}
+
+	// The feed is downloaded in the background (see offsetfeed.Fetch)
+	// so that loading the config never waits for the network. Not
+	// having a feed yet is not fatal, since the config's own addresses
+	// can still be used.
+	manifest, err := offsetfeed.Cached(o.OffsetFeed, o.OffsetFeedSigner, o.ExeName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		o.config.Warnings = append(o.config.Warnings,
+			fmt.Sprintf("failed to load offset feed - %s", err))
+		return nil
+	}
+
+	o.OffsetFeedVersion = manifest.Version
+	o.OffsetFeedSerial = manifest.Serial
+	o.config.feed = manifest
+
 	return nil
 }
 
-func readPointerFromParam(param *ini.Param) (Pointer, error) {
+func readPointerFromParam(param *ini.Param, config *ProgramConfig) (Pointer, error) {
 	_, sizeStr, hasIt := strings.Cut(strings.ToLower(param.Name), readPointerParamSuffix)
 	if !hasIt {
 		return Pointer{}, fmt.Errorf("pointer missing number of bytes to save")
@@ -157,7 +987,11 @@ func readPointerFromParam(param *ini.Param) (Pointer, error) {
 			sizeStr, err)
 	}
 
-	pointer, err := pointerFromParam(param)
+	if size == 0 {
+		return Pointer{}, fmt.Errorf("size must be greater than zero")
+	}
+
+	pointer, err := pointerFromParam(param, config)
 	if err != nil {
 		return Pointer{}, fmt.Errorf("failed to create pointer from param - %w", err)
 	}
@@ -166,87 +1000,321 @@ func readPointerFromParam(param *ini.Param) (Pointer, error) {
 	return pointer, nil
 }
 
-func pointerFromParam(param *ini.Param) (Pointer, error) {
+// pointerFromParam parses a pointer chain. The chain may start with
+// a module name, or with a reference to an address alias declared in
+// the config's [Addresses] section, in which case the alias's chain
+// is followed by the remaining offsets. The chain may continue in
+// other modules (see ModuleStep).
+func pointerFromParam(param *ini.Param, config *ProgramConfig) (Pointer, error) {
+	parts := strings.Split(param.Value, chainModuleSeparator)
+
+	pointer, err := chainFromStr(parts[0], config)
+	if err != nil {
+		return Pointer{}, err
+	}
+
+	for _, part := range parts[1:] {
+		step, err := moduleStepFromStr(part, config)
+		if err != nil {
+			return Pointer{}, err
+		}
+
+		pointer.Steps = append(pointer.Steps, step)
+	}
+
+	pointer.Name = param.Name
+	return pointer, nil
+}
+
+// chainFromStr parses the part of a pointer chain
+// that comes before any module steps.
+func chainFromStr(str string, config *ProgramConfig) (Pointer, error) {
 	// TODO: support module names with spaces
-	strs := strings.Fields(param.Value)
+	strs := strings.Fields(str)
 	if len(strs) == 0 {
 		return Pointer{}, fmt.Errorf("pointer is empty")
 	}
 
 	var startIndex int
 	var optModuleName string
-	if strings.Contains(strs[0], ".") {
+	var values []uintptr
+	var steps []ModuleStep
+	switch {
+	case strings.HasPrefix(strs[0], aliasPrefix):
+		alias, err := resolveAlias(strs[0], config.addresses)
+		if err != nil {
+			return Pointer{}, err
+		}
+
+		startIndex = 1
+		optModuleName = alias.OptModule
+		values = append(values, alias.Addrs...)
+		steps = copySteps(alias.Steps)
+	case strings.Contains(strs[0], "."):
 		startIndex = 1
 		optModuleName = strs[0]
 	}
 
-	var values []uintptr
-	for _, str := range strs[startIndex:] {
-		str = strings.TrimPrefix(str, "0x")
-		value, err := strconv.ParseUint(str, 16, 64)
-		if err != nil {
-			return Pointer{}, fmt.Errorf("failed to convert string to uint: %q - %w",
-				str, err)
-		}
+	offsets, err := offsetsFromStrs(strs[startIndex:], config.decimalOffsets())
+	if err != nil {
+		return Pointer{}, err
+	}
 
-		values = append(values, uintptr(value))
+	// The remaining offsets of a chain that starts with an
+	// alias continue from the end of the alias's chain.
+	if len(steps) > 0 {
+		last := &steps[len(steps)-1]
+		last.Addrs = append(last.Addrs, offsets...)
+	} else {
+		values = append(values, offsets...)
+	}
+
+	if len(values) == 0 {
+		return Pointer{}, fmt.Errorf("pointer has no address")
 	}
 
 	return Pointer{
-		Name:      param.Name,
 		Addrs:     values,
 		OptModule: strings.ToLower(optModuleName),
+		Steps:     steps,
 	}, nil
 }
 
-func keybindFromStr(keybindStr string) (byte, error) {
-	if len(keybindStr) != 1 {
-		return 0, fmt.Errorf("keybind must be 1 character")
-	}
-
-	return keybindStr[0], nil
-}
-
 type SaveRestore struct {
+	// Name is the section's name (e.g. "boss2" for
+	// [SaveRestore "boss2"]). It is empty if the
+	// section is not named.
+	Name string
+
 	// TODO: make Pointers into a map
 	Pointers     []Pointer
-	SaveState    byte
-	RestoreState byte
-	config       *ProgramConfig
+	SaveState    Key
+	RestoreState Key
+
+	// CompareState is the keybind that logs whether the current
+	// values of the pointers equal their saved values. It is the
+	// zero Key if the section does not have a compareState param.
+	CompareState Key
+
+	// Slots are the numbered save states created by the slotKeys
+	// param. It is empty if the section does not have the param.
+	Slots []SaveSlot
+
+	// AfterRestore is the Writer that is written (or turned on, if
+	// it is a freeze writer) AfterRestoreDelay after the section is
+	// restored. It is nil if the section does not have an
+	// afterRestore param.
+	AfterRestore      *Writer
+	AfterRestoreDelay time.Duration
+
+	// Seed is the [Seed] section that the section was
+	// declared by, or nil if it is a [SaveRestore] section.
+	Seed *Seed
+
+	Label string
+	Scope KeybindScope
+
+	// Priority orders the section's action relative to the
+	// other sections bound to the same key (see RunsBefore).
+	Priority int
+
+	// ConsumeKey prevents the section's keybinds from reaching
+	// the program while it is in the foreground.
+	ConsumeKey bool
+
+	// Chain is the sections that are triggered after
+	// the memory is saved, restored, or compared.
+	Chain
+
+	labels   map[string]string
+	filters  map[string]*ValueFilter
+	fields   map[string][]uintptr
+	displays map[string]DisplayFormat
+	masks    map[string]string
+	config   *ProgramConfig
+
+	// afterRestore is the value of the afterRestore param. It
+	// is resolved by ProgramConfig.Validate, since the writer
+	// may be declared after this section.
+	afterRestore string
+}
+
+// DisplayName returns the section's label if one was specified,
+// or an empty string otherwise.
+func (o *SaveRestore) DisplayName() string {
+	return o.Label
 }
 
 func (o *SaveRestore) RequiredParams() []string {
+	// The slots' keybinds replace saveState and restoreState.
+	if len(o.Slots) > 0 {
+		return nil
+	}
+
 	return []string{
 		"savestate",
 		"restorestate",
 	}
 }
 
-func (o *SaveRestore) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
-	switch {
-	case "savestate" == name:
+// DescribeParams implements ini.ParamDescriber.
+func (o *SaveRestore) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "<nickname>Pointer_#", Type: pointerType, Required: true, Example: "xPointer_4",
+			Help: "The location of the memory to save and restore. # is the number of bytes."},
+		{Name: "<nickname>StringPointer", Type: stringPointerType, Example: "nameStringPointer",
+			Help: "The location of a null-terminated string to save and restore."},
+		{Name: "saveState", Type: keybindType, Required: true,
+			Help: "The keybind that saves the memory. Optional if slotKeys is set."},
+		{Name: "restoreState", Type: keybindType, Required: true,
+			Help: "The keybind that restores the memory. Optional if slotKeys is set."},
+		{Name: "compareState", Type: keybindType,
+			Help: "The keybind that checks whether the memory equals the saved memory."},
+		{Name: "slotKeys", Type: "range of digits (e.g. 1-9)",
+			Help: "Creates a save slot for each digit. Shift+digit saves the slot and the digit restores it. saveState and restoreState are optional if this is set."},
+		{Name: "afterRestore", Type: stringType,
+			Help: "The name or label of a Writer section that is written after the memory is restored."},
+		{Name: "afterRestoreDelay", Type: "number (milliseconds)", Default: "0",
+			Help: "How long after the memory is restored that the afterRestore writer is written."},
+		{Name: "scope", Type: scopeType, Default: "global",
+			Help: "Where the keybinds are active."},
+		{Name: "priority", Type: priorityType, Default: "0",
+			Help: "Sections bound to the same key run in order of highest priority first."},
+		{Name: "consumeKey", Type: boolType, Default: "false",
+			Help: "Prevent the keybinds from reaching the game while it is in the foreground."},
+		{Name: "onSuccess", Type: stringType,
+			Help: "The name or label of a section that is triggered after a keybind of this section succeeds."},
+		{Name: "onFailure", Type: stringType,
+			Help: "The name or label of a section that is triggered after a keybind of this section fails."},
+		{Name: "label", Type: stringType,
+			Help: "A human-readable name for the section."},
+		{Name: "<nickname>Label", Type: stringType, Example: "xLabel",
+			Help: "A human-readable name for the pointer with the same nickname."},
+		{Name: "<nickname>Fields", Type: pointerType, Example: "xFields",
+			Help: "Offsets of the fields to save from the structure the pointer leads to."},
+		{Name: "<nickname>Type", Type: stringType, Example: "xType",
+			Help: "The type of the values at the pointer (e.g. float32)."},
+		{Name: "<nickname>ClampMin", Type: "number", Example: "xClampMin",
+			Help: "The minimum value that is restored."},
+		{Name: "<nickname>ClampMax", Type: "number", Example: "xClampMax",
+			Help: "The maximum value that is restored."},
+		{Name: "<nickname>Round", Type: boolType, Default: "false", Example: "xRound",
+			Help: "Round values to the nearest integer when they are restored."},
+		{Name: "<nickname>Display", Type: "hex, string, or a type", Default: "hex", Example: "xDisplay",
+			Help: "The format used to show the pointer's value in the log."},
+		{Name: "<nickname>Mask", Type: "number (e.g. 0b00001100)", Example: "xMask",
+			Help: "Only save and restore the bits that are set in the mask."},
+	}
+}
+
+func (o *SaveRestore) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch {
+	case "savestate" == name:
+		return func(param *ini.Param) error {
+			saveStateKeybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.SaveState = saveStateKeybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "restorestate" == name:
+		return func(param *ini.Param) error {
+			restoreStateKeybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.RestoreState = restoreStateKeybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "comparestate" == name:
+		return func(param *ini.Param) error {
+			compareStateKeybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.CompareState = compareStateKeybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case slotKeysParam == name:
+		return func(param *ini.Param) error {
+			slots, err := saveSlotsFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse slotKeys param - %w", err)
+			}
+
+			o.Slots = slots
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "afterrestore" == name:
+		return func(param *ini.Param) error {
+			o.afterRestore = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "afterrestoredelay" == name:
+		return func(param *ini.Param) error {
+			delayMs, err := strconv.ParseUint(param.Value, 10, 31)
+			if err != nil {
+				return fmt.Errorf("failed to parse afterRestoreDelay param - %w", err)
+			}
+
+			o.AfterRestoreDelay = time.Duration(delayMs) * time.Millisecond
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case scopeParam == name:
+		return func(param *ini.Param) error {
+			scope, err := keybindScopeFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Scope = scope
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case consumeKeyParam == name:
+		return func(param *ini.Param) error {
+			consumeKey, err := consumeKeyFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.ConsumeKey = consumeKey
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case priorityParam == name:
 		return func(param *ini.Param) error {
-			saveStateKeybind, err := keybindFromStr(param.Value)
+			priority, err := priorityFromStr(param.Value)
 			if err != nil {
-				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+				return err
 			}
 
-			o.SaveState = saveStateKeybind
+			o.Priority = priority
 			return nil
 		}, ini.SchemaRule{Limit: 1}
-	case "restorestate" == name:
+	case onSuccessParam == name, onFailureParam == name:
+		return o.Chain.onParam(name)
+	case labelParamSuffix == name:
 		return func(param *ini.Param) error {
-			restoreStateKeybind, err := keybindFromStr(param.Value)
+			o.Label = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, stringPointerParamSuffix) && name != stringPointerParamSuffix:
+		return func(param *ini.Param) error {
+			pointer, err := stringPointerFromParam(param, o.config)
 			if err != nil {
-				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+				return fmt.Errorf("failed to parse string pointer: %q - %w",
+					param.Name, err)
 			}
 
-			o.RestoreState = restoreStateKeybind
+			o.Pointers = append(o.Pointers, pointer)
 			return nil
 		}, ini.SchemaRule{Limit: 1}
 	case strings.Contains(name, readPointerParamSuffix):
 		return func(param *ini.Param) error {
-			pointer, err := readPointerFromParam(param)
+			pointer, err := readPointerFromParam(param, o.config)
 			if err != nil {
 				return fmt.Errorf("failed to parse pointer: %q - %w",
 					param.Name, err)
@@ -255,6 +1323,64 @@ func (o *SaveRestore) OnParam(name string) (func(param *ini.Param) error, ini.Sc
 			o.Pointers = append(o.Pointers, pointer)
 			return nil
 		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, fieldsParamSuffix) && name != fieldsParamSuffix:
+		return func(param *ini.Param) error {
+			fields, err := offsetsFromStrs(strings.Fields(param.Value), o.config.decimalOffsets())
+			if err != nil {
+				return fmt.Errorf("failed to parse fields - %w", err)
+			}
+
+			if len(fields) == 0 {
+				return errors.New("fields cannot be empty")
+			}
+
+			if o.fields == nil {
+				o.fields = make(map[string][]uintptr)
+			}
+
+			o.fields[strings.TrimSuffix(name, fieldsParamSuffix)] = fields
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, labelParamSuffix):
+		return func(param *ini.Param) error {
+			if o.labels == nil {
+				o.labels = make(map[string]string)
+			}
+
+			o.labels[strings.TrimSuffix(name, labelParamSuffix)] = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, displayParamSuffix) && name != displayParamSuffix:
+		return func(param *ini.Param) error {
+			format, err := displayFormatFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			if o.displays == nil {
+				o.displays = make(map[string]DisplayFormat)
+			}
+
+			o.displays[strings.TrimSuffix(name, displayParamSuffix)] = format
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, maskParamSuffix) && name != maskParamSuffix:
+		return func(param *ini.Param) error {
+			if o.masks == nil {
+				o.masks = make(map[string]string)
+			}
+
+			o.masks[strings.TrimSuffix(name, maskParamSuffix)] = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case isFilterParam(name):
+		return func(param *ini.Param) error {
+			if o.filters == nil {
+				o.filters = make(map[string]*ValueFilter)
+			}
+
+			return filterFromParam(o.filters, param, name)
+		}, ini.SchemaRule{Limit: 1}
 	default:
 		return nil, ini.SchemaRule{}
 	}
@@ -265,10 +1391,137 @@ func (o *SaveRestore) Validate() error {
 		return errors.New("no pointers were specified")
 	}
 
-	if o.SaveState == o.RestoreState {
+	for nickname, label := range o.labels {
+		found := false
+		for i := range o.Pointers {
+			if o.Pointers[i].nickname() == nickname {
+				o.Pointers[i].Label = label
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("label %q does not match any pointer", nickname+labelParamSuffix)
+		}
+	}
+
+	for nickname, fields := range o.fields {
+		found := false
+		for i := range o.Pointers {
+			if o.Pointers[i].nickname() == nickname {
+				o.Pointers[i].Fields = fields
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("fields %q do not match any pointer", nickname+fieldsParamSuffix)
+		}
+	}
+
+	for _, pointer := range o.Pointers {
+		if pointer.String != nil && len(pointer.Fields) > 0 {
+			return fmt.Errorf("%q is a string pointer, which cannot have fields", pointer.Name)
+		}
+	}
+
+	for nickname, filter := range o.filters {
+		found := false
+		for i := range o.Pointers {
+			if o.Pointers[i].nickname() == nickname {
+				err := filter.validate(o.Pointers[i].NBytes)
+				if err != nil {
+					return fmt.Errorf("invalid value options for %q - %w", o.Pointers[i].Name, err)
+				}
+
+				o.Pointers[i].Filter = filter
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("value options for %q do not match any pointer", nickname)
+		}
+	}
+
+	for nickname, format := range o.displays {
+		found := false
+		for i := range o.Pointers {
+			if o.Pointers[i].nickname() == nickname {
+				err := format.validate(o.Pointers[i].NBytes)
+				if err != nil {
+					return fmt.Errorf("invalid display format for %q - %w", o.Pointers[i].Name, err)
+				}
+
+				o.Pointers[i].Display = format
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("display format for %q does not match any pointer", nickname)
+		}
+	}
+
+	for nickname, maskStr := range o.masks {
+		found := false
+		for i := range o.Pointers {
+			if o.Pointers[i].nickname() == nickname {
+				mask, err := maskFromStr(maskStr, o.Pointers[i].NBytes)
+				if err != nil {
+					return fmt.Errorf("invalid mask for %q - %w", o.Pointers[i].Name, err)
+				}
+
+				o.Pointers[i].Mask = mask
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("mask for %q does not match any pointer", nickname)
+		}
+	}
+
+	for _, pointer := range o.Pointers {
+		if pointer.Size() > o.config.maxPointerSize() {
+			return fmt.Errorf("%q reads %d bytes, which is more than the maximum of %d bytes (see maxPointerSize)",
+				pointer.Name, pointer.Size(), o.config.maxPointerSize())
+		}
+	}
+
+	if o.SaveState == (Key{}) && o.RestoreState == (Key{}) && len(o.Slots) == 0 {
+		return errors.New("saveState and restoreState are required unless slotKeys is specified")
+	}
+
+	if o.SaveState == o.RestoreState && o.SaveState != (Key{}) {
 		return errors.New("cannot have duplicate keybind for saveState and restoreState")
 	}
 
+	if o.HasCompareState() && (o.CompareState == o.SaveState || o.CompareState == o.RestoreState) {
+		return errors.New("compareState cannot be the same keybind as saveState or restoreState")
+	}
+
+	for _, keybind := range []Key{o.SaveState, o.RestoreState, o.CompareState} {
+		_, _, isSlotKey := o.Slot(keybind)
+		if isSlotKey {
+			return fmt.Errorf("keybind %s is already used by slotKeys", keybind)
+		}
+	}
+
+	if o.AfterRestoreDelay > 0 && o.afterRestore == "" {
+		return errors.New("afterRestoreDelay requires the afterRestore param")
+	}
+
+	err := o.config.checkSectionName(o.Name)
+	if err != nil {
+		return err
+	}
+
 	for _, pointer := range o.Pointers {
 		for _, saveRestore := range o.config.SaveRestores {
 			for _, otherPointer := range saveRestore.Pointers {
@@ -280,30 +1533,145 @@ func (o *SaveRestore) Validate() error {
 	}
 
 	o.config.SaveRestores = append(o.config.SaveRestores, o)
+	o.config.declareSection(o)
+
+	// saveState and restoreState are optional if the
+	// section has slots, so they may be the zero Key.
+	if o.SaveState != (Key{}) {
+		bySaveKeybinds := o.config.Keybinds[o.SaveState]
+		bySaveKeybinds = append(bySaveKeybinds, o)
+		o.config.Keybinds[o.SaveState] = bySaveKeybinds
+	}
 
-	bySaveKeybinds := o.config.Keybinds[o.SaveState]
-	bySaveKeybinds = append(bySaveKeybinds, o)
-	o.config.Keybinds[o.SaveState] = bySaveKeybinds
+	if o.RestoreState != (Key{}) {
+		byRestoreKeybinds := o.config.Keybinds[o.RestoreState]
+		byRestoreKeybinds = append(byRestoreKeybinds, o)
+		o.config.Keybinds[o.RestoreState] = byRestoreKeybinds
+	}
+
+	for _, keybind := range o.slotKeybinds() {
+		o.config.Keybinds[keybind] = append(o.config.Keybinds[keybind], o)
+	}
 
-	byRestoreKeybinds := o.config.Keybinds[o.RestoreState]
-	byRestoreKeybinds = append(byRestoreKeybinds, o)
-	o.config.Keybinds[o.RestoreState] = byRestoreKeybinds
+	if o.HasCompareState() {
+		byCompareKeybinds := o.config.Keybinds[o.CompareState]
+		byCompareKeybinds = append(byCompareKeybinds, o)
+		o.config.Keybinds[o.CompareState] = byCompareKeybinds
+	}
 
 	return nil
 }
 
+// HasCompareState returns true if the section has a compareState keybind.
+func (o *SaveRestore) HasCompareState() bool {
+	return o.CompareState != Key{}
+}
+
 type Writer struct {
+	// Name is the section's name (e.g. "noclip" for
+	// [Writer "noclip"]). It is empty if the section
+	// is not named.
+	Name string
+
 	Pointers map[string]WritePointer
-	Keybind  byte
+	Keybind  Key
+	Label    string
+	Scope    KeybindScope
+
+	// Priority orders the section's action relative to the
+	// other sections bound to the same key (see RunsBefore).
+	Priority int
+
+	// ConsumeKey prevents the section's keybinds from reaching
+	// the program while it is in the foreground.
+	ConsumeKey bool
+
+	// Chain is the sections that are triggered
+	// after the writer is written or toggled.
+	Chain
+
+	// Freeze makes the keybind toggle the writer on and off
+	// rather than writing once. While on, the data is written
+	// every FreezeInterval.
+	Freeze         bool
+	FreezeInterval time.Duration
+
+	// Group is the lowercase name of the writer's group, if any.
+	// Writers in the same group are mutually exclusive (see
+	// ProgramConfig.ExclusiveWriters).
+	Group string
+
+	filters  map[string]*ValueFilter
+	displays map[string]DisplayFormat
+	masks    map[string]string
+	texts    map[string]string
 	config   *ProgramConfig
 }
 
+// DisplayName returns the section's label if one was specified,
+// or an empty string otherwise.
+func (o *Writer) DisplayName() string {
+	return o.Label
+}
+
 func (o *Writer) RequiredParams() []string {
 	return []string{
 		"keybind",
 	}
 }
 
+// DescribeParams implements ini.ParamDescriber.
+func (o *Writer) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "<nickname>Pointer", Type: pointerType, Required: true, Example: "xPointer",
+			Help: "The location of the memory to write."},
+		{Name: "<nickname>Pointer_#", Type: pointerType, Example: "xPointer_4",
+			Help: "The location of the memory to write, instead of <nickname>Pointer. The data must be # bytes."},
+		{Name: "<nickname>StringPointer", Type: stringPointerType, Example: "nameStringPointer",
+			Help: "The location of a null-terminated string to write."},
+		{Name: "<nickname>Data", Type: "hexadecimal bytes", Required: true, Example: "xData",
+			Help: "The data to write to the pointer with the same nickname."},
+		{Name: "<nickname>DataList", Type: "comma separated hexadecimal bytes", Example: "levelDataList",
+			Help: "Values that are written in turn by each press of the keybind, instead of data."},
+		{Name: "<nickname>Text", Type: stringType, Example: "nameText",
+			Help: "The text to write to the string pointer with the same nickname, instead of data."},
+		{Name: "keybind", Type: keybindType, Required: true,
+			Help: "The keybind that writes the data."},
+		{Name: "scope", Type: scopeType, Default: "global",
+			Help: "Where the keybind is active."},
+		{Name: "priority", Type: priorityType, Default: "0",
+			Help: "Sections bound to the same key run in order of highest priority first."},
+		{Name: "consumeKey", Type: boolType, Default: "false",
+			Help: "Prevent the keybind from reaching the game while it is in the foreground."},
+		{Name: "onSuccess", Type: stringType,
+			Help: "The name or label of a section that is triggered after the data is written."},
+		{Name: "onFailure", Type: stringType,
+			Help: "The name or label of a section that is triggered if writing the data fails."},
+		{Name: "freeze", Type: boolType, Default: "false",
+			Help: "Make the keybind toggle rewriting the data every freezeInterval."},
+		{Name: "freezeInterval", Type: durationType, Default: "100ms",
+			Help: "How often the data of a frozen writer is written."},
+		{Name: "group", Type: stringType,
+			Help: "Writing turns off the frozen writers in the same group."},
+		{Name: "label", Type: stringType,
+			Help: "A human-readable name for the section."},
+		{Name: "<nickname>Label", Type: stringType, Example: "xLabel",
+			Help: "A human-readable name for the pointer with the same nickname."},
+		{Name: "<nickname>Type", Type: stringType, Example: "xType",
+			Help: "The type of the values in the data (e.g. float32)."},
+		{Name: "<nickname>ClampMin", Type: "number", Example: "xClampMin",
+			Help: "The minimum value that is written."},
+		{Name: "<nickname>ClampMax", Type: "number", Example: "xClampMax",
+			Help: "The maximum value that is written."},
+		{Name: "<nickname>Round", Type: boolType, Default: "false", Example: "xRound",
+			Help: "Round values to the nearest integer when they are written."},
+		{Name: "<nickname>Display", Type: "hex, string, or a type", Default: "hex", Example: "xDisplay",
+			Help: "The format used to show the written data in the log."},
+		{Name: "<nickname>Mask", Type: "number (e.g. 0b00001100)", Example: "xMask",
+			Help: "Only write the bits that are set in the mask."},
+	}
+}
+
 func (o *Writer) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
 	switch {
 	case "keybind" == name:
@@ -316,16 +1684,131 @@ func (o *Writer) OnParam(name string) (func(param *ini.Param) error, ini.SchemaR
 			o.Keybind = keybind
 			return nil
 		}, ini.SchemaRule{Limit: 1}
-	case strings.HasSuffix(name, writePointerParamSuffix):
+	case scopeParam == name:
+		return func(param *ini.Param) error {
+			scope, err := keybindScopeFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Scope = scope
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case consumeKeyParam == name:
+		return func(param *ini.Param) error {
+			consumeKey, err := consumeKeyFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.ConsumeKey = consumeKey
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case priorityParam == name:
+		return func(param *ini.Param) error {
+			priority, err := priorityFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Priority = priority
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case onSuccessParam == name, onFailureParam == name:
+		return o.Chain.onParam(name)
+	case "freeze" == name:
+		return func(param *ini.Param) error {
+			freeze, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for freeze param - %w", err)
+			}
+
+			o.Freeze = freeze
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "freezeinterval" == name:
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse freezeInterval param - %w", err)
+			}
+
+			if interval <= 0 {
+				return errors.New("freezeInterval must be greater than zero")
+			}
+
+			o.FreezeInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "group" == name:
+		return func(param *ini.Param) error {
+			o.Group = strings.ToLower(param.Value)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case labelParamSuffix == name:
+		return func(param *ini.Param) error {
+			o.Label = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, writePointerParamSuffix) || strings.Contains(name, readPointerParamSuffix):
 		return func(param *ini.Param) error {
 
 			return o.addWriterPointer(param, name)
 		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, dataListParamSuffix) && name != dataListParamSuffix:
+		return func(param *ini.Param) error {
+			return o.addDataList(param, name)
+		}, ini.SchemaRule{Limit: 1}
 	case strings.HasSuffix(name, dataParamSuffix):
 		return func(param *ini.Param) error {
 
 			return o.addData(param, name)
 		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, textParamSuffix) && name != textParamSuffix:
+		return func(param *ini.Param) error {
+			if o.texts == nil {
+				o.texts = make(map[string]string)
+			}
+
+			o.texts[strings.TrimSuffix(name, textParamSuffix)] = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, labelParamSuffix):
+		return func(param *ini.Param) error {
+
+			return o.addLabel(param, name)
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, displayParamSuffix) && name != displayParamSuffix:
+		return func(param *ini.Param) error {
+			format, err := displayFormatFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			if o.displays == nil {
+				o.displays = make(map[string]DisplayFormat)
+			}
+
+			o.displays[strings.TrimSuffix(name, displayParamSuffix)] = format
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, maskParamSuffix) && name != maskParamSuffix:
+		return func(param *ini.Param) error {
+			if o.masks == nil {
+				o.masks = make(map[string]string)
+			}
+
+			o.masks[strings.TrimSuffix(name, maskParamSuffix)] = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case isFilterParam(name):
+		return func(param *ini.Param) error {
+			if o.filters == nil {
+				o.filters = make(map[string]*ValueFilter)
+			}
+
+			return filterFromParam(o.filters, param, name)
+		}, ini.SchemaRule{Limit: 1}
 	default:
 		return nil, ini.SchemaRule{}
 	}
@@ -336,12 +1819,85 @@ func (o *Writer) Validate() error {
 		return fmt.Errorf("no pointers provided")
 	}
 
+	if o.Freeze && o.HasDataList() {
+		return errors.New("freeze writers cannot have a data list")
+	}
+
+	for nickname, text := range o.texts {
+		writePointer, hasIt := o.Pointers[nickname]
+		if !hasIt || writePointer.Pointer.String == nil {
+			return fmt.Errorf("text for %q does not match any string pointer", nickname)
+		}
+
+		if len(writePointer.Data) > 0 {
+			return fmt.Errorf("%q cannot have both data and text", nickname)
+		}
+
+		data, err := writePointer.Pointer.String.Encode(text)
+		if err != nil {
+			return fmt.Errorf("failed to encode text for %q - %w", nickname, err)
+		}
+
+		writePointer.Data = data
+		o.Pointers[nickname] = writePointer
+	}
+
+	for nickname, filter := range o.filters {
+		writePointer, hasIt := o.Pointers[nickname]
+		if !hasIt {
+			return fmt.Errorf("value options for %q do not match any pointer", nickname)
+		}
+
+		err := filter.validate(len(writePointer.Data))
+		if err != nil {
+			return fmt.Errorf("invalid value options for %q - %w", nickname, err)
+		}
+
+		writePointer.Pointer.Filter = filter
+		o.Pointers[nickname] = writePointer
+	}
+
+	for nickname, format := range o.displays {
+		writePointer, hasIt := o.Pointers[nickname]
+		if !hasIt {
+			return fmt.Errorf("display format for %q does not match any pointer", nickname)
+		}
+
+		err := format.validate(len(writePointer.Data))
+		if err != nil {
+			return fmt.Errorf("invalid display format for %q - %w", nickname, err)
+		}
+
+		writePointer.Pointer.Display = format
+		o.Pointers[nickname] = writePointer
+	}
+
+	for nickname, maskStr := range o.masks {
+		writePointer, hasIt := o.Pointers[nickname]
+		if !hasIt {
+			return fmt.Errorf("mask for %q does not match any pointer", nickname)
+		}
+
+		mask, err := maskFromStr(maskStr, len(writePointer.Data))
+		if err != nil {
+			return fmt.Errorf("invalid mask for %q - %w", nickname, err)
+		}
+
+		writePointer.Pointer.Mask = mask
+		o.Pointers[nickname] = writePointer
+	}
+
 	for name, writePointer := range o.Pointers {
 		err := writePointer.validate()
 		if err != nil {
 			return fmt.Errorf("failed to validate: %q - %w", name, err)
 		}
 
+		if len(writePointer.Data) > o.config.maxPointerSize() {
+			return fmt.Errorf("%q writes %d bytes, which is more than the maximum of %d bytes (see maxPointerSize)",
+				name, len(writePointer.Data), o.config.maxPointerSize())
+		}
+
 		for _, writer := range o.config.Writers {
 			_, hasIt := writer.Pointers[name]
 			if hasIt {
@@ -350,7 +1906,13 @@ func (o *Writer) Validate() error {
 		}
 	}
 
+	err := o.config.checkSectionName(o.Name)
+	if err != nil {
+		return err
+	}
+
 	o.config.Writers = append(o.config.Writers, o)
+	o.config.declareSection(o)
 
 	byWriteKeybinds := o.config.Keybinds[o.Keybind]
 	byWriteKeybinds = append(byWriteKeybinds, o)
@@ -360,13 +1922,27 @@ func (o *Writer) Validate() error {
 }
 
 func (o *Writer) addWriterPointer(param *ini.Param, paramNameLC string) error {
-	pointer, err := pointerFromParam(param)
+	var pointer Pointer
+	var err error
+	name, _, hasSize := strings.Cut(paramNameLC, readPointerParamSuffix)
+	if strings.HasSuffix(paramNameLC, stringPointerParamSuffix) {
+		// The data is usually specified by the <nickname>Text
+		// param, which is encoded by Validate.
+		name = strings.TrimSuffix(paramNameLC, stringPointerParamSuffix)
+		pointer, err = stringPointerFromParam(param, o.config)
+	} else if hasSize {
+		// The size is optional for writers. If specified,
+		// the data must be exactly that many bytes.
+		pointer, err = readPointerFromParam(param, o.config)
+	} else {
+		name = strings.TrimSuffix(paramNameLC, writePointerParamSuffix)
+		pointer, err = pointerFromParam(param, o.config)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to parse pointer: %q - %w",
 			param.Name, err)
 	}
 
-	name := strings.TrimSuffix(paramNameLC, writePointerParamSuffix)
 	wp, _ := o.Pointers[name]
 	if o.Pointers == nil {
 		o.Pointers = make(map[string]WritePointer)
@@ -378,6 +1954,7 @@ func (o *Writer) addWriterPointer(param *ini.Param, paramNameLC string) error {
 	}
 
 	wp.Pointer = pointer
+	wp.Pointer.Label = wp.label
 	o.Pointers[name] = wp
 	return nil
 }
@@ -409,9 +1986,29 @@ func (o *Writer) addData(param *ini.Param, paramNameLC string) error {
 	return nil
 }
 
+func (o *Writer) addLabel(param *ini.Param, paramNameLC string) error {
+	name := strings.TrimSuffix(paramNameLC, labelParamSuffix)
+	wp, _ := o.Pointers[name]
+	if o.Pointers == nil {
+		o.Pointers = make(map[string]WritePointer)
+	}
+
+	wp.label = param.Value
+	wp.Pointer.Label = param.Value
+	o.Pointers[name] = wp
+	return nil
+}
+
 type WritePointer struct {
 	Pointer Pointer
 	Data    []byte
+
+	// DataList, if non-empty, contains the values that are
+	// written in turn by each press of the writer's keybind
+	// (see DataAt). Data is the first value.
+	DataList [][]byte
+
+	label string
 }
 
 func (o *WritePointer) validate() error {
@@ -423,12 +2020,82 @@ func (o *WritePointer) validate() error {
 		return fmt.Errorf("write data not provided")
 	}
 
+	if o.Pointer.NBytes > 0 && len(o.Data) != o.Pointer.NBytes {
+		return fmt.Errorf("data is %d bytes, but the pointer's size is %d bytes",
+			len(o.Data), o.Pointer.NBytes)
+	}
+
 	return nil
 }
 
 type Pointer struct {
 	Name      string
+	Label     string
 	Addrs     []uintptr
 	NBytes    int
 	OptModule string
+
+	// Fields, if non-empty, makes the pointer a composite of several
+	// NBytes-sized values, each located at the given offset from the
+	// pointer's address (e.g. the x, y, and z coordinates of a
+	// position). The values are saved and restored together.
+	Fields []uintptr
+
+	// Filter, if non-nil, is applied to the pointer's
+	// data before it is restored or written.
+	Filter *ValueFilter
+
+	// Display is the format used to display the pointer's value.
+	Display DisplayFormat
+
+	// Steps continue the chain in other modules. They are
+	// followed after Addrs.
+	Steps []ModuleStep
+
+	// String, if non-nil, makes the pointer point to a null-terminated
+	// string. NBytes is the size of the string's buffer. Restores and
+	// writes set every character after the string's end to null.
+	String *StringOptions
+
+	// Mask, if non-nil, limits reads and writes to the bits that
+	// are set in it (see Masked). It is NBytes long and is in the
+	// program's (little-endian) byte order.
+	Mask []byte
+}
+
+// FormatValue returns data, the pointer's value,
+// formatted using the pointer's Display format.
+func (o Pointer) FormatValue(data []byte) string {
+	return o.Display.Format(data)
+}
+
+// Size returns the total number of bytes saved by the pointer.
+func (o Pointer) Size() int {
+	if len(o.Fields) > 0 {
+		return o.NBytes * len(o.Fields)
+	}
+
+	return o.NBytes
+}
+
+// DisplayName returns the pointer's label if one was specified.
+// Otherwise, the pointer's parameter name is returned.
+func (o Pointer) DisplayName() string {
+	if o.Label != "" {
+		return o.Label
+	}
+
+	return o.Name
+}
+
+// nickname returns the lowercase custom name prefix of the pointer's
+// parameter name (e.g. "xcoord" for "xCoordPointer_4").
+func (o Pointer) nickname() string {
+	name := strings.ToLower(o.Name)
+	if o.String != nil {
+		return strings.TrimSuffix(name, stringPointerParamSuffix)
+	}
+
+	nickname, _, _ := strings.Cut(name, readPointerParamSuffix)
+	return nickname
 }
@@ -0,0 +1,53 @@
+package appconfig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+func FuzzParseProgramConfig(f *testing.F) {
+	f.Add(BenchConfig(2))
+	f.Add([]byte("[General]\nexeName = game.exe\n\n[SaveRestore]\nxPointer_4 = game.exe 0x1C 0x70\n" +
+		"saveState = 1\nrestoreState = 2\n"))
+	f.Add([]byte("[General]\nexeName = game.exe\n\n[Writer]\nxPointer = 0x1C\nxData = 00 01\nkeybind = 3\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		program, err := ParseProgramConfig(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		if program.General == nil || program.General.ExeName == "" {
+			t.Fatalf("parsed a config without an exe name from %q", data)
+		}
+	})
+}
+
+func FuzzPointerFromParam(f *testing.F) {
+	f.Add("xPointer_4", "game.exe 0x1C 0x70")
+	f.Add("xPointer_8", "0x1C 0x70 -> other.dll 0x10")
+	f.Add("xPointer", "0x1C 0x70")
+	f.Add("xPointer_0", "0x1C")
+	f.Add("xPointer_4", "@player 0x10")
+
+	f.Fuzz(func(t *testing.T, name string, value string) {
+		config := &ProgramConfig{
+			Keybinds: make(map[Key][]interface{}),
+			General:  &General{},
+			addresses: map[string]Pointer{
+				"player": {Name: "player", OptModule: "game.exe", Addrs: []uintptr{0x1C, 0x70}},
+			},
+		}
+
+		param := &ini.Param{Name: name, Value: value}
+
+		pointer, err := readPointerFromParam(param, config)
+		if err == nil && pointer.NBytes <= 0 {
+			t.Fatalf("parsed pointer with %d bytes from %s = %s", pointer.NBytes, name, value)
+		}
+
+		_, _ = pointerFromParam(param, config)
+	})
+}
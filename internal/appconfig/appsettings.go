@@ -0,0 +1,542 @@
+package appconfig
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/bundle"
+	"github.com/SeungKang/blaj/internal/ini"
+	"github.com/SeungKang/blaj/internal/input/backend"
+)
+
+// AppConfigFileName is the name of the file containing application-wide
+// settings. It is stored in the same directory as the program configs.
+const AppConfigFileName = "blaj.ini"
+
+// AppConfigFromPath parses the application settings file at filePath.
+//
+// If the file does not exist, an AppConfig containing the default
+// settings is returned.
+func AppConfigFromPath(filePath string) (*AppConfig, error) {
+	configFile, err := openConfigFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return DefaultAppConfig(), nil
+		}
+
+		return nil, fmt.Errorf("failed to open app config file - %w", err)
+	}
+
+	config, err := parseAppConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app config - %w", err)
+	}
+
+	return config, nil
+}
+
+// DefaultAppConfig returns an AppConfig containing the default settings.
+func DefaultAppConfig() *AppConfig {
+	return &AppConfig{
+		Blaj: defaultBlaj(),
+	}
+}
+
+func parseAppConfig(r io.Reader) (*AppConfig, error) {
+	appConfig := DefaultAppConfig()
+
+	err := ini.ParseSchema(r, appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return appConfig, nil
+}
+
+// AppConfig contains settings that apply to the entire application
+// rather than to a single program.
+type AppConfig struct {
+	Blaj  *Blaj
+	Tools []*Tool
+	Hooks []*Hook
+}
+
+func (o *AppConfig) Rules() ini.ParserRules {
+	return ini.ParserRules{
+		LowercaseNames: true,
+	}
+}
+
+// DescribeSections implements ini.SchemaDescriber.
+func (o *AppConfig) DescribeSections() []ini.SectionDescription {
+	return []ini.SectionDescription{
+		{Name: "Blaj", Help: "Settings that apply to the entire application."},
+		{Name: "Tool", Help: "Adds a program to the tray menu that is started with a pointer's address."},
+		{Name: "Hook", Help: "Runs a program when an event occurs."},
+	}
+}
+
+func (o *AppConfig) OnGlobalParam(paramName string) (func(*ini.Param) error, ini.SchemaRule) {
+	return nil, ini.SchemaRule{}
+}
+
+func (o *AppConfig) OnSection(name string, actualName string) (func() (ini.SectionSchema, error), ini.SchemaRule) {
+	switch name {
+	case "blaj":
+		return func() (ini.SectionSchema, error) {
+			return o.Blaj, nil
+		}, ini.SchemaRule{Limit: 1}
+	case "tool":
+		return func() (ini.SectionSchema, error) {
+			return &Tool{config: o}, nil
+		}, ini.SchemaRule{}
+	case "hook":
+		return func() (ini.SectionSchema, error) {
+			return &Hook{config: o}, nil
+		}, ini.SchemaRule{}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *AppConfig) Validate() error {
+	return nil
+}
+
+func defaultBlaj() *Blaj {
+	return &Blaj{
+		Language:     "en",
+		DumpType:     "heap",
+		InputBackend: backend.Hook,
+
+		ProcessScanInterval: defaultProcessScanInterval,
+		WindowPollInterval:  defaultWindowPollInterval,
+		SyncInterval:        defaultSyncInterval,
+	}
+}
+
+const (
+	// defaultProcessScanInterval is how often programs
+	// that are not running are checked for.
+	defaultProcessScanInterval = 5 * time.Second
+
+	// defaultWindowPollInterval is how often a program is checked
+	// for a window when its waitForWindow param is true.
+	defaultWindowPollInterval = time.Second
+
+	// defaultSyncInterval is how often files are
+	// synced when the syncURL param is set.
+	defaultSyncInterval = 5 * time.Minute
+)
+
+// Blaj is the [Blaj] section of the application settings file.
+type Blaj struct {
+	Language     string
+	RecordTraces bool
+	AllowExes    []string
+	DenyExes     []string
+
+	// DumpType is the amount of memory included in minidumps
+	// saved from the tray. It is one of "small", "heap", or "full".
+	DumpType string
+
+	// IPCAddress is the address that the IPC server listens on.
+	// An empty string uses a random loopback port.
+	IPCAddress string
+
+	// IPCAllowRemote allows the IPC server to listen on a
+	// non-loopback address and accept remote clients.
+	IPCAllowRemote bool
+
+	// InputBackend is the name of the input.Backend used
+	// to receive keyboard input (see package backend).
+	InputBackend string
+
+	// DebugAddress, if non-empty, is the loopback address
+	// that the pprof debug server listens on.
+	DebugAddress string
+
+	// HexViewWriteBack writes the bytes edited in the
+	// "View memory" hex dump back to the program.
+	HexViewWriteBack bool
+
+	// TrustedSigners are the public keys of config authors
+	// whose signed config bundles are trusted.
+	TrustedSigners []ed25519.PublicKey
+
+	// RequireTrustedSigner refuses to load config bundles
+	// that were not signed by one of TrustedSigners.
+	RequireTrustedSigner bool
+
+	// ProcessScanInterval is how often the running
+	// processes are checked for programs to attach to.
+	ProcessScanInterval time.Duration
+
+	// WindowPollInterval is how often a program is checked for
+	// a window when its General.WaitForWindow field is true.
+	WindowPollInterval time.Duration
+
+	// MinFreezeInterval is the minimum interval between the
+	// writes of frozen writers. Writers whose FreezeInterval
+	// is shorter are written every MinFreezeInterval instead.
+	MinFreezeInterval time.Duration
+
+	// PrioritizeInput raises the priority of the thread that
+	// receives keyboard input and lowers the priority of the
+	// threads that poll programs, so that keybinds remain
+	// responsive while a game uses every CPU core.
+	PrioritizeInput bool
+
+	// LowProfile avoids behavior that anti-virus heuristics
+	// commonly flag: the keyboard hook is only installed while a
+	// program is attached, and programs are opened with read access
+	// until a value is first written.
+	LowProfile bool
+
+	// GitSnapshots commits the config files that changed
+	// when the config directory is a git repository.
+	GitSnapshots bool
+
+	// SaveStatesOnRemove writes a program's saved states to the
+	// states directory when its config file is deleted.
+	SaveStatesOnRemove bool
+
+	// SyncURL, if non-empty, is the https URL of the WebDAV
+	// directory that configs and saved states are synced with.
+	SyncURL string
+
+	// SyncUsername is the user name used to log in to SyncURL.
+	SyncUsername string
+
+	// SyncInterval is how often files are synced with SyncURL.
+	// Zero only syncs when blaj starts and when requested.
+	SyncInterval time.Duration
+}
+
+// IsTrustedSigner returns true if key is one of TrustedSigners.
+func (o *Blaj) IsTrustedSigner(key ed25519.PublicKey) bool {
+	for _, trusted := range o.TrustedSigners {
+		if trusted.Equal(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *Blaj) RequiredParams() []string {
+	return nil
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Blaj) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "language", Type: stringType, Default: "en",
+			Help: "The language of the user interface (en, ja, or ko)."},
+		{Name: "recordTraces", Type: boolType, Default: "false",
+			Help: "Record the memory accessed by each action to a trace file."},
+		{Name: "dumpType", Type: "small, heap, or full", Default: "heap",
+			Help: "The amount of memory saved by memory dumps."},
+		{Name: "allowExe", Type: stringType,
+			Help: "Only attach to programs with this executable name. Can be repeated."},
+		{Name: "denyExe", Type: stringType,
+			Help: "Never attach to programs with this executable name. Can be repeated."},
+		{Name: "ipcAddress", Type: "string (host:port)",
+			Help: "The address that commands such as blaj status connect to."},
+		{Name: "ipcAllowRemote", Type: boolType, Default: "false",
+			Help: "Allow ipcAddress to be a non-loopback address."},
+		{Name: "inputBackend", Type: "hook or rawinput", Default: "hook",
+			Help: "How keyboard input is received."},
+		{Name: "debugAddress", Type: "string (host:port)",
+			Help: "The loopback address to serve profiling data on."},
+		{Name: "hexViewWriteBack", Type: boolType, Default: "false",
+			Help: "Write bytes changed in the memory viewer back to the program."},
+		{Name: "trustedSigner", Type: "string (base64 public key)",
+			Help: "The public key of a trusted config bundle signer. Can be repeated."},
+		{Name: "requireTrustedSigner", Type: boolType, Default: "false",
+			Help: "Only load config bundles signed by a trusted signer."},
+		{Name: "processScanInterval", Type: durationType, Default: "5s",
+			Help: "How often running processes are checked for programs to attach to."},
+		{Name: "windowPollInterval", Type: durationType, Default: "1s",
+			Help: "How often a program is checked for a window when waitForWindow is true."},
+		{Name: "minFreezeInterval", Type: durationType, Default: "0s",
+			Help: "The minimum time between the writes of frozen writers. 0s means no minimum."},
+		{Name: "prioritizeInput", Type: boolType, Default: "false",
+			Help: "Raise the priority of keyboard input and lower the priority of polling."},
+		{Name: "lowProfile", Type: boolType, Default: "false",
+			Help: "Only hook the keyboard while a program is attached and request write access when it is first needed."},
+		{Name: "gitSnapshots", Type: boolType, Default: "false",
+			Help: "Commit changed config files when the config directory is a git repository."},
+		{Name: "saveStatesOnRemove", Type: boolType, Default: "false",
+			Help: "Write a program's saved states to the states directory when its config file is deleted."},
+		{Name: "syncURL", Type: "https url",
+			Help: "The WebDAV directory that configs and saved states are synced with."},
+		{Name: "syncUsername", Type: stringType,
+			Help: "The user name used to log in to syncURL."},
+		{Name: "syncInterval", Type: durationType, Default: "5m",
+			Help: "How often files are synced with syncURL. 0s only syncs at startup and when requested."},
+	}
+}
+
+func (o *Blaj) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case "language":
+		return func(param *ini.Param) error {
+			o.Language = strings.ToLower(param.Value)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "recordtraces":
+		return func(param *ini.Param) error {
+			recordTraces, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for recordTraces param - %w", err)
+			}
+
+			o.RecordTraces = recordTraces
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "dumptype":
+		return func(param *ini.Param) error {
+			dumpType := strings.ToLower(param.Value)
+			switch dumpType {
+			case "small", "heap", "full":
+				o.DumpType = dumpType
+				return nil
+			default:
+				return fmt.Errorf("unknown dumpType: %q (must be small, heap, or full)", param.Value)
+			}
+		}, ini.SchemaRule{Limit: 1}
+	case "ipcaddress":
+		return func(param *ini.Param) error {
+			_, _, err := net.SplitHostPort(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse ipcAddress - %w", err)
+			}
+
+			o.IPCAddress = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "ipcallowremote":
+		return func(param *ini.Param) error {
+			allowRemote, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for ipcAllowRemote param - %w", err)
+			}
+
+			o.IPCAllowRemote = allowRemote
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "inputbackend":
+		return func(param *ini.Param) error {
+			if !backend.IsBackend(param.Value) {
+				return fmt.Errorf("unknown inputBackend: %q (must be %s or %s)",
+					param.Value, backend.Hook, backend.RawInput)
+			}
+
+			o.InputBackend = strings.ToLower(param.Value)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "debugaddress":
+		return func(param *ini.Param) error {
+			_, _, err := net.SplitHostPort(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse debugAddress - %w", err)
+			}
+
+			o.DebugAddress = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "hexviewwriteback":
+		return func(param *ini.Param) error {
+			writeBack, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for hexViewWriteBack param - %w", err)
+			}
+
+			o.HexViewWriteBack = writeBack
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "trustedsigner":
+		return func(param *ini.Param) error {
+			key, err := bundle.ParsePublicKey(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse trustedSigner - %w", err)
+			}
+
+			o.TrustedSigners = append(o.TrustedSigners, key)
+			return nil
+		}, ini.SchemaRule{}
+	case "requiretrustedsigner":
+		return func(param *ini.Param) error {
+			require, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for requireTrustedSigner param - %w", err)
+			}
+
+			o.RequireTrustedSigner = require
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "processscaninterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse processScanInterval param - %w", err)
+			}
+
+			if interval <= 0 {
+				return errors.New("processScanInterval must be greater than zero")
+			}
+
+			o.ProcessScanInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "windowpollinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse windowPollInterval param - %w", err)
+			}
+
+			if interval <= 0 {
+				return errors.New("windowPollInterval must be greater than zero")
+			}
+
+			o.WindowPollInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "minfreezeinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse minFreezeInterval param - %w", err)
+			}
+
+			if interval < 0 {
+				return errors.New("minFreezeInterval cannot be negative")
+			}
+
+			o.MinFreezeInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "prioritizeinput":
+		return func(param *ini.Param) error {
+			prioritize, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for prioritizeInput param - %w", err)
+			}
+
+			o.PrioritizeInput = prioritize
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "lowprofile":
+		return func(param *ini.Param) error {
+			lowProfile, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for lowProfile param - %w", err)
+			}
+
+			o.LowProfile = lowProfile
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "gitsnapshots":
+		return func(param *ini.Param) error {
+			gitSnapshots, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for gitSnapshots param - %w", err)
+			}
+
+			o.GitSnapshots = gitSnapshots
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "savestatesonremove":
+		return func(param *ini.Param) error {
+			saveStates, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for saveStatesOnRemove param - %w", err)
+			}
+
+			o.SaveStatesOnRemove = saveStates
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "syncurl":
+		return func(param *ini.Param) error {
+			if !strings.HasPrefix(param.Value, "https://") {
+				return fmt.Errorf("syncURL must be an https url (got %q)", param.Value)
+			}
+
+			o.SyncURL = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "syncusername":
+		return func(param *ini.Param) error {
+			o.SyncUsername = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "syncinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse syncInterval param - %w", err)
+			}
+
+			if interval < 0 {
+				return errors.New("syncInterval cannot be negative")
+			}
+
+			o.SyncInterval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "allowexe":
+		return func(param *ini.Param) error {
+			o.AllowExes = append(o.AllowExes, strings.ToLower(param.Value))
+			return nil
+		}, ini.SchemaRule{}
+	case "denyexe":
+		return func(param *ini.Param) error {
+			o.DenyExes = append(o.DenyExes, strings.ToLower(param.Value))
+			return nil
+		}, ini.SchemaRule{}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *Blaj) Validate() error {
+	if o.IPCAddress != "" && !o.IPCAllowRemote && !isLoopbackAddr(o.IPCAddress) {
+		return fmt.Errorf("ipcAddress %q is not a loopback address (set ipcAllowRemote to true to allow remote clients)",
+			o.IPCAddress)
+	}
+
+	if o.DebugAddress != "" && !isLoopbackAddr(o.DebugAddress) {
+		return fmt.Errorf("debugAddress %q is not a loopback address", o.DebugAddress)
+	}
+
+	if o.RequireTrustedSigner && len(o.TrustedSigners) == 0 {
+		return errors.New("requireTrustedSigner is true, but no trustedSigner was specified")
+	}
+
+	return nil
+}
+
+// isLoopbackAddr returns true if addr's host is a loopback
+// IP address or "localhost".
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
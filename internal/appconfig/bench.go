@@ -0,0 +1,40 @@
+package appconfig
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// benchKeys are the keybinds used by the sections of the
+// configs generated by BenchConfig.
+const benchKeys = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// BenchConfig generates a program config with numSections
+// SaveRestore and Writer sections, which is parsed by
+// BenchmarkParseProgramConfig and the bench command to measure
+// ini.ParseSchema on large configs. Each section's pointers have
+// a chain of five offsets.
+func BenchConfig(numSections int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("[General]\nexeName = bench.exe\n")
+
+	for i := 0; i < numSections; i++ {
+		save := benchKeys[(2*i)%len(benchKeys)]
+		restore := benchKeys[(2*i+1)%len(benchKeys)]
+
+		fmt.Fprintf(&buf, "\n[SaveRestore]\nlabel = section %d\n", i)
+		for j := 0; j < 3; j++ {
+			fmt.Fprintf(&buf, "coord%dPointer_4 = 0x%x 0xcc 0x1cc 0x2f8 0x%x\n",
+				j, 0x1000000+i*0x100, 0xe8+j*4)
+		}
+
+		fmt.Fprintf(&buf, "saveState = %c\nrestoreState = %c\n", save, restore)
+
+		fmt.Fprintf(&buf, "\n[Writer]\nlabel = writer %d\n", i)
+		fmt.Fprintf(&buf, "countPointer = 0x%x 0x194 0x128 0x3c 0x11c\n", 0x2000000+i*0x100)
+		fmt.Fprintf(&buf, "countData = 0x00000000\nkeybind = %c\n", save)
+	}
+
+	return buf.Bytes()
+}
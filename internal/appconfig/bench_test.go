@@ -0,0 +1,20 @@
+package appconfig
+
+import (
+	"bytes"
+	"testing"
+)
+
+func BenchmarkParseProgramConfig(b *testing.B) {
+	data := BenchConfig(500)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		_, err := ParseProgramConfig(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
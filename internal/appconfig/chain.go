@@ -0,0 +1,171 @@
+package appconfig
+
+import (
+	"fmt"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+const (
+	onSuccessParam = "onsuccess"
+	onFailureParam = "onfailure"
+)
+
+// Chain is the sections that are triggered after the action
+// of a SaveRestore, Writer, or Counter section, which allows
+// simple pipelines such as writing a writer after a restore.
+type Chain struct {
+	// OnSuccess is the SaveRestore, Writer, or Counter section
+	// that is triggered after the section's action succeeds.
+	// It is nil if the section does not have an onSuccess param.
+	OnSuccess interface{}
+
+	// OnFailure is the SaveRestore, Writer, or Counter section
+	// that is triggered after the section's action fails.
+	// It is nil if the section does not have an onFailure param.
+	OnFailure interface{}
+
+	// onSuccess and onFailure are the values of the onSuccess and
+	// onFailure params. They are resolved by ProgramConfig.Validate,
+	// since the sections may be declared after this section.
+	onSuccess string
+	onFailure string
+}
+
+func (o *Chain) onParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case onSuccessParam:
+		return func(param *ini.Param) error {
+			o.onSuccess = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case onFailureParam:
+		return func(param *ini.Param) error {
+			o.onFailure = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+// SectionChain returns the Chain of a SaveRestore, Writer,
+// Counter, or Seed section, or nil if section has none.
+func SectionChain(section interface{}) *Chain {
+	switch v := section.(type) {
+	case *SaveRestore:
+		return &v.Chain
+	case *Writer:
+		return &v.Chain
+	case *Counter:
+		return &v.Chain
+	case *Seed:
+		return &v.SaveRestore.Chain
+	default:
+		return nil
+	}
+}
+
+// resolveChains sets the OnSuccess and OnFailure fields of each
+// section's Chain. It is called once every section has been parsed,
+// since a section may refer to a section that is declared after it.
+func (o *ProgramConfig) resolveChains() error {
+	var sections []interface{}
+	for _, saveRestore := range o.SaveRestores {
+		sections = append(sections, saveRestore)
+	}
+
+	for _, writer := range o.Writers {
+		sections = append(sections, writer)
+	}
+
+	for _, counter := range o.Counters {
+		sections = append(sections, counter)
+	}
+
+	for _, section := range sections {
+		chain := SectionChain(section)
+
+		var err error
+		chain.OnSuccess, err = o.resolveChained(section, "onSuccess", chain.onSuccess)
+		if err != nil {
+			return err
+		}
+
+		chain.OnFailure, err = o.resolveChained(section, "onFailure", chain.onFailure)
+		if err != nil {
+			return err
+		}
+	}
+
+	checked := make(map[interface{}]bool)
+	for _, section := range sections {
+		err := o.checkChainCycle(section, nil, checked)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveChained returns the section that the param named
+// paramName of section refers to, or nil if name is empty.
+func (o *ProgramConfig) resolveChained(section interface{}, paramName string, name string) (interface{}, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	chained, err := o.SectionByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s section of %s - %w",
+			paramName, o.SectionID(section), err)
+	}
+
+	if _, isNudge := chained.(*Nudge); isNudge {
+		return nil, fmt.Errorf("%s section of %s cannot be a nudge section",
+			paramName, o.SectionID(section))
+	}
+
+	if chained == section {
+		return nil, fmt.Errorf("%s section of %s cannot be the section itself",
+			paramName, o.SectionID(section))
+	}
+
+	return chained, nil
+}
+
+// checkChainCycle returns an error if following the chains of
+// section leads back to a section in path, which would trigger
+// the sections forever. Sections in checked are known to not
+// lead to a loop, and section is added to it once checked.
+func (o *ProgramConfig) checkChainCycle(section interface{}, path []interface{}, checked map[interface{}]bool) error {
+	if checked[section] {
+		return nil
+	}
+
+	for _, visited := range path {
+		if visited == section {
+			return fmt.Errorf("onSuccess and onFailure sections of %s form a loop",
+				o.SectionID(section))
+		}
+	}
+
+	path = append(path, section)
+
+	chain := SectionChain(section)
+	for _, chained := range []interface{}{chain.OnSuccess, chain.OnFailure} {
+		if chained == nil {
+			continue
+		}
+
+		err := o.checkChainCycle(chained, path, checked)
+		if err != nil {
+			return err
+		}
+	}
+
+	checked[section] = true
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package appconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const consumeKeyParam = "consumekey"
+
+func consumeKeyFromStr(str string) (bool, error) {
+	consumeKey, err := strconv.ParseBool(str)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse boolean for consumeKey param - %w", err)
+	}
+
+	return consumeKey, nil
+}
+
+// SectionConsumesKey returns true if the keybinds of a SaveRestore,
+// Writer, Counter, Seed, or Nudge section are prevented from reaching
+// the program when they are pressed.
+func SectionConsumesKey(section interface{}) bool {
+	switch v := section.(type) {
+	case *SaveRestore:
+		return v.ConsumeKey
+	case *Writer:
+		return v.ConsumeKey
+	case *Counter:
+		return v.ConsumeKey
+	case *Seed:
+		return v.SaveRestore.ConsumeKey
+	case *Nudge:
+		return v.ConsumeKey
+	default:
+		return false
+	}
+}
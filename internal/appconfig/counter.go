@@ -0,0 +1,265 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// defaultCounterTriggerInterval is how often the
+// memory of a counter's trigger is read.
+const defaultCounterTriggerInterval = 100 * time.Millisecond
+
+// Counter counts the number of times its keybind is pressed or its
+// trigger fires (for example, the number of practice attempts).
+// Totals are persisted across sessions.
+type Counter struct {
+	// Name is the section's name (e.g. "resets" for
+	// [Counter "resets"]). It is empty if the section
+	// is not named.
+	Name string
+
+	Label string
+
+	// Keybind is the zero Key if the counter
+	// is only incremented by its Trigger.
+	Keybind Key
+	Scope   KeybindScope
+
+	// Trigger, if non-nil, increments the counter
+	// when the memory that it points to changes.
+	Trigger *CounterTrigger
+
+	// Priority orders the section's action relative to the
+	// other sections bound to the same key (see RunsBefore).
+	Priority int
+
+	// ConsumeKey prevents the section's keybinds from reaching
+	// the program while it is in the foreground.
+	ConsumeKey bool
+
+	// Chain is the sections that are triggered
+	// after the counter is incremented.
+	Chain
+
+	config *ProgramConfig
+}
+
+// CounterTrigger increments a Counter when the memory at Pointer
+// changes to Value (e.g. when a death flag is set), or each time
+// that it changes if Value is nil.
+type CounterTrigger struct {
+	Pointer Pointer
+	Value   []byte
+
+	// Interval is how often the memory is read.
+	Interval time.Duration
+}
+
+// Fires returns true if the memory at the trigger's pointer
+// changing from prev to current increments the counter.
+func (o *CounterTrigger) Fires(prev []byte, current []byte) bool {
+	if bytes.Equal(prev, current) {
+		return false
+	}
+
+	return o.Value == nil || bytes.Equal(current, o.Value)
+}
+
+// DisplayName returns the counter's label.
+func (o *Counter) DisplayName() string {
+	return o.Label
+}
+
+func (o *Counter) RequiredParams() []string {
+	return []string{
+		"label",
+	}
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Counter) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "label", Type: stringType, Required: true,
+			Help: "The name of the counter, which must be unique."},
+		{Name: "keybind", Type: keybindType,
+			Help: "The keybind that increments the counter. Required unless <nickname>Pointer_# is set."},
+		{Name: "<nickname>Pointer_#", Type: pointerType, Example: "deadPointer_1",
+			Help: "The location of a value that increments the counter when it changes to triggerValue. # is the number of bytes."},
+		{Name: "triggerValue", Type: "hexadecimal",
+			Help: "The value that increments the counter. Any change increments it if this is not set."},
+		{Name: "triggerInterval", Type: durationType, Default: defaultCounterTriggerInterval.String(),
+			Help: "How often the value at <nickname>Pointer_# is read."},
+		{Name: "scope", Type: scopeType, Default: "global",
+			Help: "Where the keybind is active."},
+		{Name: "priority", Type: priorityType, Default: "0",
+			Help: "Sections bound to the same key run in order of highest priority first."},
+		{Name: "consumeKey", Type: boolType, Default: "false",
+			Help: "Prevent the keybind from reaching the game while it is in the foreground."},
+		{Name: "onSuccess", Type: stringType,
+			Help: "The name or label of a section that is triggered after the counter is incremented."},
+	}
+}
+
+func (o *Counter) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case "label":
+		return func(param *ini.Param) error {
+			o.Label = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "keybind":
+		return func(param *ini.Param) error {
+			keybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.Keybind = keybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case scopeParam:
+		return func(param *ini.Param) error {
+			scope, err := keybindScopeFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Scope = scope
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case consumeKeyParam:
+		return func(param *ini.Param) error {
+			consumeKey, err := consumeKeyFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.ConsumeKey = consumeKey
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case priorityParam:
+		return func(param *ini.Param) error {
+			priority, err := priorityFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Priority = priority
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "triggervalue":
+		return func(param *ini.Param) error {
+			value := strings.TrimPrefix(param.Value, "0x")
+			if len(value)%2 == 1 {
+				value = "0" + value
+			}
+
+			data, err := hex.DecodeString(value)
+			if err != nil {
+				return fmt.Errorf("failed to decode trigger value - %w", err)
+			}
+
+			o.trigger().Value = data
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "triggerinterval":
+		return func(param *ini.Param) error {
+			interval, err := time.ParseDuration(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse trigger interval - %w", err)
+			}
+
+			if interval <= 0 {
+				return errors.New("trigger interval must be greater than zero")
+			}
+
+			o.trigger().Interval = interval
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case onSuccessParam:
+		return o.Chain.onParam(name)
+	default:
+		if !strings.Contains(name, readPointerParamSuffix) {
+			return nil, ini.SchemaRule{}
+		}
+
+		return func(param *ini.Param) error {
+			if o.Trigger != nil && o.Trigger.Pointer.Name != "" {
+				return fmt.Errorf("counter already has a pointer defined (%q)", o.Trigger.Pointer.Name)
+			}
+
+			pointer, err := readPointerFromParam(param, o.config)
+			if err != nil {
+				return fmt.Errorf("failed to parse pointer: %q - %w", param.Name, err)
+			}
+
+			o.trigger().Pointer = pointer
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	}
+}
+
+// trigger returns the counter's Trigger,
+// creating it if it does not exist.
+func (o *Counter) trigger() *CounterTrigger {
+	if o.Trigger == nil {
+		o.Trigger = &CounterTrigger{
+			Interval: defaultCounterTriggerInterval,
+		}
+	}
+
+	return o.Trigger
+}
+
+func (o *Counter) Validate() error {
+	if o.Label == "" {
+		return errors.New("label cannot be empty")
+	}
+
+	if o.Trigger != nil {
+		if o.Trigger.Pointer.Name == "" {
+			return errors.New("triggerValue and triggerInterval require a pointer")
+		}
+
+		pointer := o.Trigger.Pointer
+		if pointer.Size() > o.config.maxPointerSize() {
+			return fmt.Errorf("%q reads %d bytes, which is more than the maximum of %d bytes (see maxPointerSize)",
+				pointer.Name, pointer.Size(), o.config.maxPointerSize())
+		}
+
+		if o.Trigger.Value != nil && len(o.Trigger.Value) != pointer.Size() {
+			return fmt.Errorf("trigger value is %d bytes, but the pointer is %d bytes",
+				len(o.Trigger.Value), pointer.Size())
+		}
+	}
+
+	if o.Keybind == (Key{}) && o.Trigger == nil {
+		return errors.New("a keybind or a pointer must be specified")
+	}
+
+	for _, counter := range o.config.Counters {
+		if counter.Label == o.Label {
+			return fmt.Errorf("a counter with label %q is already declared in a previous section", o.Label)
+		}
+	}
+
+	err := o.config.checkSectionName(o.Name)
+	if err != nil {
+		return err
+	}
+
+	o.config.Counters = append(o.config.Counters, o)
+	o.config.declareSection(o)
+
+	if o.Keybind != (Key{}) {
+		o.config.Keybinds[o.Keybind] = append(o.config.Keybinds[o.Keybind], o)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package appconfig
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+const dataListParamSuffix = "datalist"
+
+// HasDataList returns true if any of the writer's
+// pointers have a list of values to cycle through.
+func (o *Writer) HasDataList() bool {
+	for _, pointer := range o.Pointers {
+		if len(pointer.DataList) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DataAt returns the data that is written by the index'th press of
+// the writer's keybind. It is the pointer's Data if it does not have
+// a DataList. Otherwise, the values in the DataList are cycled
+// through, starting over after the last value.
+func (o WritePointer) DataAt(index int) []byte {
+	if len(o.DataList) == 0 {
+		return o.Data
+	}
+
+	return o.DataList[index%len(o.DataList)]
+}
+
+// addDataList parses a <nickname>DataList param, which is a comma
+// separated list of values that are written in turn. The first value
+// is used as the pointer's Data so that the list is validated like
+// any other data.
+func (o *Writer) addDataList(param *ini.Param, paramNameLC string) error {
+	var dataList [][]byte
+	for _, valueStr := range strings.Split(param.Value, ",") {
+		value := strings.TrimPrefix(strings.TrimSpace(valueStr), "0x")
+		if len(value)%2 == 1 {
+			value = "0" + value
+		}
+
+		data, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("failed to decode data list value %q - %w", valueStr, err)
+		}
+
+		if len(data) == 0 {
+			return errors.New("data list values cannot be empty")
+		}
+
+		if len(dataList) > 0 && len(data) != len(dataList[0]) {
+			return fmt.Errorf("data list value %q is %d bytes, but the first value is %d bytes",
+				strings.TrimSpace(valueStr), len(data), len(dataList[0]))
+		}
+
+		dataList = append(dataList, data)
+	}
+
+	if len(dataList) < 2 {
+		return errors.New("data list must contain at least two values (use <nickname>Data for one value)")
+	}
+
+	name := strings.TrimSuffix(paramNameLC, dataListParamSuffix)
+	wp, _ := o.Pointers[name]
+	if o.Pointers == nil {
+		o.Pointers = make(map[string]WritePointer)
+	}
+
+	if len(wp.Data) > 0 {
+		return errors.New("write pointer already has data defined")
+	}
+
+	wp.Data = dataList[0]
+	wp.DataList = dataList
+	o.Pointers[name] = wp
+	return nil
+}
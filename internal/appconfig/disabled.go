@@ -0,0 +1,144 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/bundle"
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// DisabledFromPath returns true if the program config at filePath
+// is disabled (see General.Disabled), without fully parsing it. Only
+// the disabled parameters of the config file and its local config
+// file are read, so includes and offset feeds are not fetched and
+// sections are not validated. This allows a directory containing
+// many disabled configs to be loaded quickly.
+//
+// Config bundles are encrypted, so only their local config files
+// are read. False is returned if a file cannot be read or parsed,
+// in which case the problem is reported when the config is loaded.
+func DisabledFromPath(filePath string) bool {
+	disabled := false
+
+	paths := []string{filePath, LocalConfigPath(filePath)}
+	if strings.HasSuffix(filePath, bundle.FileExt) {
+		paths = paths[1:]
+	}
+
+	for _, path := range paths {
+		value, hasIt := disabledParamFromPath(path)
+		if hasIt {
+			disabled = value
+		}
+	}
+
+	return disabled
+}
+
+// LocalConfigWithDisabled returns the contents of the local config
+// file of the program config at filePath with the disabled parameter
+// of its [General] section set to disabled. The local config file is
+// changed rather than the shared config file so that the shared file
+// can still be replaced with a newer version, and since config bundles
+// cannot be changed. The contents are empty apart from the [General]
+// section if the local config file does not exist.
+func LocalConfigWithDisabled(filePath string, disabled bool) ([]byte, error) {
+	data, err := os.ReadFile(LocalConfigPath(filePath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read local config file - %w", err)
+	}
+
+	decoded, err := toUTF8(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w - please save the file as UTF-8", err)
+	}
+
+	return ini.SetParam(decoded, "General", "disabled", strconv.FormatBool(disabled)), nil
+}
+
+// disabledParamFromPath returns the value of the disabled parameter
+// of the config file at filePath. False is returned for hasIt if the
+// parameter is not set or cannot be parsed.
+func disabledParamFromPath(filePath string) (disabled bool, hasIt bool) {
+	configFile, err := openConfigFile(filePath)
+	if err != nil {
+		return false, false
+	}
+
+	scanner := &disabledScanner{}
+
+	err = ini.ParseSchema(configFile, scanner)
+	if err != nil || scanner.disabled == nil {
+		return false, false
+	}
+
+	return *scanner.disabled, true
+}
+
+// disabledScanner is an ini.Schema that only parses the
+// disabled parameter of the [General] section.
+type disabledScanner struct {
+	disabled *bool
+}
+
+func (o *disabledScanner) Rules() ini.ParserRules {
+	return ini.ParserRules{
+		AllowGlobalParams:        true,
+		AllowUnknownGlobalParams: true,
+		AllowUnknownSections:     true,
+		AllowUnknownParams:       true,
+		LowercaseNames:           true,
+		AllowSectionNames:        true,
+		MaxLineSize:              maxLineSize,
+	}
+}
+
+func (o *disabledScanner) OnGlobalParam(string) (func(*ini.Param) error, ini.SchemaRule) {
+	return nil, ini.SchemaRule{}
+}
+
+func (o *disabledScanner) OnSection(name string, _ string) (func() (ini.SectionSchema, error), ini.SchemaRule) {
+	if name != "general" {
+		return nil, ini.SchemaRule{}
+	}
+
+	return func() (ini.SectionSchema, error) {
+		return &disabledGeneral{scanner: o}, nil
+	}, ini.SchemaRule{}
+}
+
+func (o *disabledScanner) Validate() error {
+	return nil
+}
+
+type disabledGeneral struct {
+	scanner *disabledScanner
+}
+
+func (o *disabledGeneral) RequiredParams() []string {
+	return nil
+}
+
+func (o *disabledGeneral) OnParam(name string) (func(*ini.Param) error, ini.SchemaRule) {
+	if name != "disabled" {
+		return nil, ini.SchemaRule{}
+	}
+
+	return func(param *ini.Param) error {
+		disabled, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return err
+		}
+
+		o.scanner.disabled = &disabled
+		return nil
+	}, ini.SchemaRule{}
+}
+
+func (o *disabledGeneral) Validate() error {
+	return nil
+}
@@ -0,0 +1,201 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const displayParamSuffix = "display"
+
+// DisplayFormat is the format used to display a pointer's
+// value in logs and the user interface. It is either a
+// ValueType, HexDisplay, StringDisplay, or UTF16StringDisplay.
+type DisplayFormat string
+
+const (
+	// HexDisplay displays values as hex-encoded bytes.
+	// It is the default format.
+	HexDisplay DisplayFormat = "hex"
+
+	// StringDisplay displays values as text that ends
+	// at the first null byte.
+	StringDisplay DisplayFormat = "string"
+
+	// UTF16StringDisplay displays values as UTF-16 text
+	// that ends at the first null character.
+	UTF16StringDisplay DisplayFormat = "utf16"
+)
+
+func displayFormatFromStr(str string) (DisplayFormat, error) {
+	format := DisplayFormat(strings.ToLower(str))
+	switch {
+	case format == HexDisplay, format == StringDisplay, format == UTF16StringDisplay:
+		return format, nil
+	case ValueType(format).Size() > 0:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown display format: %q", str)
+	}
+}
+
+func (o DisplayFormat) validate(nBytes int) error {
+	size := ValueType(o).Size()
+	if size > 0 && nBytes%size != 0 {
+		return fmt.Errorf("%d bytes is not a multiple of the size of %s (%d bytes)",
+			nBytes, o, size)
+	}
+
+	return nil
+}
+
+// Format returns a human-readable representation of data.
+// Pointers containing several values (e.g. composite pointers)
+// are displayed as a comma-separated list. Data that does not
+// fit the format is displayed as hex.
+func (o DisplayFormat) Format(data []byte) string {
+	switch o {
+	case "", HexDisplay:
+		return "0x" + hex.EncodeToString(data)
+	case StringDisplay:
+		if i := bytes.IndexByte(data, 0); i > -1 {
+			data = data[:i]
+		}
+
+		if !utf8.Valid(data) {
+			return "0x" + hex.EncodeToString(data)
+		}
+
+		return strconv.Quote(string(data))
+	case UTF16StringDisplay:
+		if len(data)%2 != 0 {
+			return "0x" + hex.EncodeToString(data)
+		}
+
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i < len(data); i += 2 {
+			unit := binary.LittleEndian.Uint16(data[i:])
+			if unit == 0 {
+				break
+			}
+
+			units = append(units, unit)
+		}
+
+		return strconv.Quote(string(utf16.Decode(units)))
+	}
+
+	valueType := ValueType(o)
+	size := valueType.Size()
+	if size == 0 || len(data) == 0 || len(data)%size != 0 {
+		return "0x" + hex.EncodeToString(data)
+	}
+
+	values := make([]string, 0, len(data)/size)
+	for i := 0; i < len(data); i += size {
+		values = append(values, formatValue(valueType, data[i:i+size]))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// Number returns the first value in data as a float64. False is
+// returned if the format is not a ValueType or data is too short.
+func (o DisplayFormat) Number(data []byte) (float64, bool) {
+	valueType := ValueType(o)
+	size := valueType.Size()
+	if size == 0 || len(data) < size {
+		return 0, false
+	}
+
+	return (&ValueFilter{Type: valueType}).get(data[:size]), true
+}
+
+// GraphedPointer is a pointer whose values are graphed
+// (see ProgramConfig.GraphedPointers).
+type GraphedPointer struct {
+	// Section identifies the pointer's section (see SectionID).
+	Section string
+
+	Pointer Pointer
+}
+
+// ID returns a string that identifies the pointer among the graphed
+// pointers. Writers' pointers can have the same names as the pointers
+// of other sections, so the pointer's name alone is not unique.
+func (o GraphedPointer) ID() string {
+	return o.Section + "/" + o.Pointer.Name
+}
+
+// GraphedPointers returns the pointers whose values are graphed when
+// General.ValueHistory is non-zero, which are the pointers that have
+// a numeric display format. Pointers are returned in the order that
+// they were declared, with Writer pointers in name order.
+func (o *ProgramConfig) GraphedPointers() []GraphedPointer {
+	var pointers []GraphedPointer
+	for _, saveRestore := range o.SaveRestores {
+		for _, pointer := range saveRestore.Pointers {
+			if ValueType(pointer.Display).Size() > 0 {
+				pointers = append(pointers, GraphedPointer{
+					Section: o.SectionID(saveRestore),
+					Pointer: pointer,
+				})
+			}
+		}
+	}
+
+	for _, writer := range o.Writers {
+		names := make([]string, 0, len(writer.Pointers))
+		for name := range writer.Pointers {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			pointer := writer.Pointers[name].Pointer
+			if ValueType(pointer.Display).Size() > 0 {
+				pointers = append(pointers, GraphedPointer{
+					Section: o.SectionID(writer),
+					Pointer: pointer,
+				})
+			}
+		}
+	}
+
+	return pointers
+}
+
+// formatValue formats a single little-endian value. Integers are
+// not converted to float64 so that 64-bit values are exact.
+func formatValue(valueType ValueType, b []byte) string {
+	switch valueType {
+	case Int8Type:
+		return strconv.FormatInt(int64(int8(b[0])), 10)
+	case Uint8Type:
+		return strconv.FormatUint(uint64(b[0]), 10)
+	case Int16Type:
+		return strconv.FormatInt(int64(int16(binary.LittleEndian.Uint16(b))), 10)
+	case Uint16Type:
+		return strconv.FormatUint(uint64(binary.LittleEndian.Uint16(b)), 10)
+	case Int32Type:
+		return strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(b))), 10)
+	case Uint32Type:
+		return strconv.FormatUint(uint64(binary.LittleEndian.Uint32(b)), 10)
+	case Int64Type:
+		return strconv.FormatInt(int64(binary.LittleEndian.Uint64(b)), 10)
+	case Uint64Type:
+		return strconv.FormatUint(binary.LittleEndian.Uint64(b), 10)
+	case Float32Type:
+		return strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 'g', -1, 32)
+	default:
+		return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 'g', -1, 64)
+	}
+}
@@ -0,0 +1,155 @@
+package appconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// ProgramConfigFile and AppSettingsFile identify the
+// file that a documented section belongs to.
+const (
+	ProgramConfigFile = "Program Config Files"
+	AppSettingsFile   = "App Settings File"
+)
+
+// Types used by parameter descriptions.
+const (
+	boolType          = "boolean (true or false)"
+	durationType      = "duration (e.g. 30s or 1m)"
+	keybindType       = "keybind (a character, vk:<code>, or sc:<code>)"
+	pointerType       = "hexadecimal space delimited"
+	stringPointerType = "hexadecimal space delimited, followed by max=<characters> and optionally encoding=<ascii or utf16>"
+	scopeType         = "global, game, or blaj"
+	stringType        = "string"
+)
+
+// SectionDoc documents a config file section.
+type SectionDoc struct {
+	ini.SectionDescription
+
+	// File is either ProgramConfigFile or AppSettingsFile.
+	File string
+
+	// Limit is the maximum number of instances of the
+	// section. Zero means that there is no limit.
+	Limit int
+
+	// Required is true if the section is required.
+	Required bool
+
+	Params []ParamDoc
+}
+
+// ParamDoc documents a section's parameter.
+type ParamDoc struct {
+	ini.ParamDescription
+
+	// Limit is the maximum number of instances of the
+	// parameter. Zero means that there is no limit.
+	Limit int
+}
+
+// Docs returns the documentation of every section of program
+// config files and the app settings file.
+//
+// Sections and parameters are described by the schemas that parse
+// them (see ini.SchemaDescriber and ini.ParamDescriber), and their
+// limits and requirements are read from the schemas' rules. An error
+// is returned if a described parameter is not accepted by its schema,
+// or if a required parameter is not described, which keeps the
+// documentation from drifting from the code. The README is compared
+// to the documentation by TestDocsMatchReadme.
+func Docs() ([]SectionDoc, error) {
+	programDocs, err := schemaDocs(&ProgramConfig{Keybinds: make(map[Key][]interface{})},
+		ProgramConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsDocs, err := schemaDocs(DefaultAppConfig(), AppSettingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(programDocs, settingsDocs...), nil
+}
+
+func schemaDocs(schema interface {
+	ini.Schema
+	ini.SchemaDescriber
+}, file string) ([]SectionDoc, error) {
+	rules := schema.Rules()
+
+	var docs []SectionDoc
+	for _, section := range schema.DescribeSections() {
+		newSection, sectionRule := schema.OnSection(strings.ToLower(section.Name), section.Name)
+		if newSection == nil {
+			return nil, fmt.Errorf("described section %q is not accepted by the %s schema",
+				section.Name, file)
+		}
+
+		sectionSchema, err := newSection()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %q section - %w", section.Name, err)
+		}
+
+		describer, isDescriber := sectionSchema.(ini.ParamDescriber)
+		if !isDescriber {
+			return nil, fmt.Errorf("%q section does not describe its params", section.Name)
+		}
+
+		doc := SectionDoc{
+			SectionDescription: section,
+			File:               file,
+			Limit:              sectionRule.Limit,
+			Required:           contains(rules.RequiredSections, strings.ToLower(section.Name)),
+		}
+
+		required := sectionSchema.RequiredParams()
+		described := make(map[string]bool)
+
+		for _, param := range describer.DescribeParams() {
+			name := strings.ToLower(param.ExampleName())
+			described[name] = true
+
+			onParam, paramRule := sectionSchema.OnParam(name)
+			if onParam == nil {
+				return nil, fmt.Errorf("described param %q is not accepted by the %q section",
+					param.Name, section.Name)
+			}
+
+			if contains(required, name) && !param.Required {
+				return nil, fmt.Errorf("required param %q of the %q section is not described as required",
+					param.Name, section.Name)
+			}
+
+			doc.Params = append(doc.Params, ParamDoc{
+				ParamDescription: param,
+				Limit:            paramRule.Limit,
+			})
+		}
+
+		for _, name := range required {
+			if !described[name] {
+				return nil, fmt.Errorf("required param %q of the %q section is not described",
+					name, section.Name)
+			}
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func contains(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
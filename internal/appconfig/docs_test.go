@@ -0,0 +1,111 @@
+package appconfig
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// readmePath is the path of the README, which documents the
+// parameters of every section, relative to this package.
+const readmePath = "../../README.md"
+
+var (
+	readmeSectionRe = regexp.MustCompile("^## `\\[([A-Za-z]+)\\]`")
+	readmeParamRe   = regexp.MustCompile("`([^`]+)`")
+)
+
+// readmeParams returns the names of the parameters that are documented
+// by the README's "### `name`" headings, keyed by the name of the
+// "## `[Section]`" heading that they are under.
+func readmeParams(t *testing.T) map[string][]string {
+	f, err := os.Open(readmePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	params := make(map[string][]string)
+	section := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section = ""
+			match := readmeSectionRe.FindStringSubmatch(line)
+			if match != nil {
+				section = match[1]
+				params[section] = nil
+			}
+		case strings.HasPrefix(line, "### ") && section != "":
+			for _, match := range readmeParamRe.FindAllStringSubmatch(line, -1) {
+				params[section] = append(params[section], match[1])
+			}
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return params
+}
+
+// TestDocsMatchReadme checks that the parameters described by the
+// schemas and the parameters documented by the README are the same,
+// so that neither can drift from the other.
+func TestDocsMatchReadme(t *testing.T) {
+	docs, err := Docs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readme := readmeParams(t)
+
+	for _, section := range docs {
+		documented, hasIt := readme[section.Name]
+		if !hasIt {
+			t.Errorf("[%s] is not documented in the README", section.Name)
+			continue
+		}
+
+		described := make(map[string]bool)
+		for _, param := range section.Params {
+			described[strings.ToLower(param.Name)] = true
+
+			if !containsFold(documented, param.Name) {
+				t.Errorf("[%s] %s is described by its schema, but not documented in the README",
+					section.Name, param.Name)
+			}
+		}
+
+		for _, name := range documented {
+			if !described[strings.ToLower(name)] {
+				t.Errorf("[%s] %s is documented in the README, but not described by its schema",
+					section.Name, name)
+			}
+		}
+
+		delete(readme, section.Name)
+	}
+
+	for name := range readme {
+		t.Errorf("[%s] is documented in the README, but not described by a schema", name)
+	}
+}
+
+func containsFold(strs []string, str string) bool {
+	for _, s := range strs {
+		if strings.EqualFold(s, str) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+	utf32LEBOM = []byte{0xff, 0xfe, 0x00, 0x00}
+	utf32BEBOM = []byte{0x00, 0x00, 0xfe, 0xff}
+)
+
+// openConfigFile reads the file at filePath and returns its contents
+// transcoded to UTF-8.
+//
+// Text editors such as Notepad may save files as UTF-16 or as UTF-8
+// with a byte order mark, neither of which the INI parser understands.
+func openConfigFile(filePath string) (io.Reader, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := toUTF8(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w - please save the file as UTF-8", err)
+	}
+
+	return bytes.NewReader(decoded), nil
+}
+
+func toUTF8(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, utf32LEBOM), bytes.HasPrefix(data, utf32BEBOM):
+		return nil, errors.New("file is encoded as UTF-32, which is not supported")
+	case bytes.HasPrefix(data, utf8BOM):
+		data = data[len(utf8BOM):]
+	case bytes.HasPrefix(data, utf16LEBOM):
+		return utf16ToUTF8(data[len(utf16LEBOM):], binary.LittleEndian)
+	case bytes.HasPrefix(data, utf16BEBOM):
+		return utf16ToUTF8(data[len(utf16BEBOM):], binary.BigEndian)
+	case looksLikeUTF16(data):
+		// UTF-16 without a byte order mark. ASCII characters
+		// have a zero byte at either the odd or even indexes.
+		if data[1] == 0 {
+			return utf16ToUTF8(data, binary.LittleEndian)
+		}
+
+		return utf16ToUTF8(data, binary.BigEndian)
+	}
+
+	if bytes.IndexByte(data, 0) > -1 {
+		return nil, errors.New("file contains null bytes and its encoding could not be determined")
+	}
+
+	if !utf8.Valid(data) {
+		return nil, errors.New("file is not valid UTF-8")
+	}
+
+	return data, nil
+}
+
+// looksLikeUTF16 returns true if the first bytes of data look like
+// UTF-16 encoded ASCII text.
+func looksLikeUTF16(data []byte) bool {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return false
+	}
+
+	return (data[0] != 0 && data[1] == 0 && data[2] != 0 && data[3] == 0) ||
+		(data[0] == 0 && data[1] != 0 && data[2] == 0 && data[3] != 0)
+}
+
+func utf16ToUTF8(data []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, errors.New("file is UTF-16 encoded, but has an odd number of bytes")
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[i*2:])
+	}
+
+	return []byte(string(utf16.Decode(units))), nil
+}
@@ -0,0 +1,248 @@
+package appconfig
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+const (
+	typeParamSuffix     = "type"
+	clampMinParamSuffix = "clampmin"
+	clampMaxParamSuffix = "clampmax"
+	roundParamSuffix    = "round"
+)
+
+// filterParamSuffixes are the suffixes of the per-pointer
+// parameters that configure a ValueFilter.
+var filterParamSuffixes = []string{
+	typeParamSuffix,
+	clampMinParamSuffix,
+	clampMaxParamSuffix,
+	roundParamSuffix,
+}
+
+// ValueType is the type of the values stored at a pointer.
+type ValueType string
+
+const (
+	Int8Type    ValueType = "int8"
+	Int16Type   ValueType = "int16"
+	Int32Type   ValueType = "int32"
+	Int64Type   ValueType = "int64"
+	Uint8Type   ValueType = "uint8"
+	Uint16Type  ValueType = "uint16"
+	Uint32Type  ValueType = "uint32"
+	Uint64Type  ValueType = "uint64"
+	Float32Type ValueType = "float32"
+	Float64Type ValueType = "float64"
+)
+
+// Size returns the size of a single value in bytes,
+// or 0 if the type is unknown.
+func (o ValueType) Size() int {
+	switch o {
+	case Int8Type, Uint8Type:
+		return 1
+	case Int16Type, Uint16Type:
+		return 2
+	case Int32Type, Uint32Type, Float32Type:
+		return 4
+	case Int64Type, Uint64Type, Float64Type:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// IsFloat returns true if the type is a floating point type.
+func (o ValueType) IsFloat() bool {
+	return o == Float32Type || o == Float64Type
+}
+
+// ValueFilter post-processes the little-endian values stored at
+// a pointer before they are restored or written (for example,
+// rounding a saved position so the player does not end up slightly
+// inside geometry). The data is treated as an array of Type.
+type ValueFilter struct {
+	Type     ValueType
+	ClampMin *float64
+	ClampMax *float64
+	Round    bool
+}
+
+// Apply returns a copy of data with the filter applied to each
+// value. data is returned as-is if the filter is nil.
+func (o *ValueFilter) Apply(data []byte) ([]byte, error) {
+	if o == nil {
+		return data, nil
+	}
+
+	size := o.Type.Size()
+	if size == 0 || len(data)%size != 0 {
+		return nil, fmt.Errorf("%d bytes is not a multiple of the size of %s", len(data), o.Type)
+	}
+
+	filtered := make([]byte, len(data))
+	copy(filtered, data)
+
+	for i := 0; i < len(data); i += size {
+		value := o.get(data[i : i+size])
+
+		// Only re-encode values that changed, since converting
+		// large 64-bit integers to float64 loses precision.
+		newValue := o.filter(value)
+		if newValue != value && !math.IsNaN(value) {
+			o.put(filtered[i:i+size], newValue)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (o *ValueFilter) filter(value float64) float64 {
+	if o.Round {
+		value = math.Round(value)
+	}
+
+	if o.ClampMin != nil && value < *o.ClampMin {
+		value = *o.ClampMin
+	}
+
+	if o.ClampMax != nil && value > *o.ClampMax {
+		value = *o.ClampMax
+	}
+
+	return value
+}
+
+func (o *ValueFilter) get(b []byte) float64 {
+	switch o.Type {
+	case Int8Type:
+		return float64(int8(b[0]))
+	case Uint8Type:
+		return float64(b[0])
+	case Int16Type:
+		return float64(int16(binary.LittleEndian.Uint16(b)))
+	case Uint16Type:
+		return float64(binary.LittleEndian.Uint16(b))
+	case Int32Type:
+		return float64(int32(binary.LittleEndian.Uint32(b)))
+	case Uint32Type:
+		return float64(binary.LittleEndian.Uint32(b))
+	case Int64Type:
+		return float64(int64(binary.LittleEndian.Uint64(b)))
+	case Uint64Type:
+		return float64(binary.LittleEndian.Uint64(b))
+	case Float32Type:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	default:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+}
+
+func (o *ValueFilter) put(b []byte, value float64) {
+	switch o.Type {
+	case Int8Type, Uint8Type:
+		b[0] = byte(int64(value))
+	case Int16Type, Uint16Type:
+		binary.LittleEndian.PutUint16(b, uint16(int64(value)))
+	case Int32Type, Uint32Type:
+		binary.LittleEndian.PutUint32(b, uint32(int64(value)))
+	case Int64Type:
+		binary.LittleEndian.PutUint64(b, uint64(int64(value)))
+	case Uint64Type:
+		binary.LittleEndian.PutUint64(b, uint64(value))
+	case Float32Type:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(value)))
+	default:
+		binary.LittleEndian.PutUint64(b, math.Float64bits(value))
+	}
+}
+
+func (o *ValueFilter) validate(nBytes int) error {
+	if o.Type == "" {
+		return errors.New("a type must be specified to clamp or round values")
+	}
+
+	size := o.Type.Size()
+	if nBytes%size != 0 {
+		return fmt.Errorf("%d bytes is not a multiple of the size of %s (%d bytes)",
+			nBytes, o.Type, size)
+	}
+
+	if o.Round && !o.Type.IsFloat() {
+		return fmt.Errorf("cannot round values of integer type %s", o.Type)
+	}
+
+	if o.ClampMin != nil && o.ClampMax != nil && *o.ClampMin > *o.ClampMax {
+		return fmt.Errorf("clampMin (%v) is greater than clampMax (%v)",
+			*o.ClampMin, *o.ClampMax)
+	}
+
+	return nil
+}
+
+func isFilterParam(paramNameLC string) bool {
+	_, _, isFilter := filterParamNickname(paramNameLC)
+	return isFilter
+}
+
+// filterParamNickname returns the nickname prefix of a per-pointer
+// ValueFilter parameter and the parameter's suffix.
+func filterParamNickname(paramNameLC string) (string, string, bool) {
+	for _, suffix := range filterParamSuffixes {
+		if strings.HasSuffix(paramNameLC, suffix) && len(paramNameLC) > len(suffix) {
+			return strings.TrimSuffix(paramNameLC, suffix), suffix, true
+		}
+	}
+
+	return "", "", false
+}
+
+// filterFromParam updates the ValueFilter in filters for the pointer
+// with the nickname prefix of param's name.
+func filterFromParam(filters map[string]*ValueFilter, param *ini.Param, paramNameLC string) error {
+	nickname, suffix, _ := filterParamNickname(paramNameLC)
+
+	filter, hasIt := filters[nickname]
+	if !hasIt {
+		filter = &ValueFilter{}
+		filters[nickname] = filter
+	}
+
+	switch suffix {
+	case typeParamSuffix:
+		valueType := ValueType(strings.ToLower(param.Value))
+		if valueType.Size() == 0 {
+			return fmt.Errorf("unknown value type: %q", param.Value)
+		}
+
+		filter.Type = valueType
+	case clampMinParamSuffix, clampMaxParamSuffix:
+		value, err := strconv.ParseFloat(param.Value, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse number: %q - %w", param.Value, err)
+		}
+
+		if suffix == clampMinParamSuffix {
+			filter.ClampMin = &value
+		} else {
+			filter.ClampMax = &value
+		}
+	case roundParamSuffix:
+		round, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return fmt.Errorf("failed to parse boolean: %q - %w", param.Value, err)
+		}
+
+		filter.Round = round
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package appconfig
+
+// ExclusiveWriters returns the writers that are mutually exclusive
+// with writer, which are the other writers in its group. Turning a
+// writer on, or writing it if it is not a freeze writer, turns off
+// the frozen writers that are exclusive with it (e.g. an "easy mode"
+// patch set turns off a "hard mode" patch set).
+func (o *ProgramConfig) ExclusiveWriters(writer *Writer) []*Writer {
+	if writer.Group == "" {
+		return nil
+	}
+
+	var exclusive []*Writer
+	for _, other := range o.Writers {
+		if other != writer && other.Group == writer.Group {
+			exclusive = append(exclusive, other)
+		}
+	}
+
+	return exclusive
+}
@@ -0,0 +1,166 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// Hook events.
+const (
+	HookEventAttached = "attached"
+	HookEventDetached = "detached"
+	HookEventSave     = "save"
+	HookEventRestore  = "restore"
+	HookEventWrite    = "write"
+	HookEventCount    = "count"
+	HookEventCompare  = "compare"
+	HookEventReroll   = "reroll"
+	HookEventError    = "error"
+)
+
+var hookEvents = []string{
+	HookEventAttached,
+	HookEventDetached,
+	HookEventSave,
+	HookEventRestore,
+	HookEventWrite,
+	HookEventCount,
+	HookEventCompare,
+	HookEventReroll,
+	HookEventError,
+}
+
+// Hook is a [Hook] section of the application settings file.
+// It describes an external program that is run when one of
+// the hook's events occurs. The event is described to the
+// program using environment variables.
+type Hook struct {
+	// Events is the set of events that run the hook.
+	Events map[string]struct{}
+	Path   string
+	Args   string
+
+	// ExeName optionally limits the hook to a single
+	// program. It is lowercase.
+	ExeName string
+
+	// QuietWhenFullscreen skips the hook while a program that
+	// blaj controls is fullscreen in the foreground, so that
+	// hooks that show notifications or play sounds do not
+	// interrupt a run.
+	QuietWhenFullscreen bool
+
+	config *AppConfig
+}
+
+// Handles returns true if the hook should be run when event
+// occurs in the program named exeName.
+func (o *Hook) Handles(event string, exeName string) bool {
+	if o.ExeName != "" && o.ExeName != strings.ToLower(exeName) {
+		return false
+	}
+
+	_, hasIt := o.Events[event]
+	return hasIt
+}
+
+func (o *Hook) RequiredParams() []string {
+	return []string{
+		"event",
+		"path",
+	}
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Hook) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "event", Type: "comma-separated list of events", Required: true,
+			Help: "The events that run the program: " + strings.Join(hookEvents, ", ") + "."},
+		{Name: "path", Type: stringType, Required: true,
+			Help: "The path of the program to run."},
+		{Name: "args", Type: stringType,
+			Help: "The program's arguments."},
+		{Name: "exeName", Type: stringType,
+			Help: "Only run the program for events of the program with this executable name."},
+		{Name: "quietWhenFullscreen", Type: boolType, Default: "false",
+			Help: "Do not run the program while a game is fullscreen in the foreground."},
+	}
+}
+
+func (o *Hook) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case "event":
+		return func(param *ini.Param) error {
+			for _, event := range strings.Split(param.Value, ",") {
+				event = strings.ToLower(strings.TrimSpace(event))
+				if !isHookEvent(event) {
+					return fmt.Errorf("unknown event: %q (must be one of: %s)",
+						event, strings.Join(hookEvents, ", "))
+				}
+
+				if o.Events == nil {
+					o.Events = make(map[string]struct{})
+				}
+
+				o.Events[event] = struct{}{}
+			}
+
+			return nil
+		}, ini.SchemaRule{}
+	case "path":
+		return func(param *ini.Param) error {
+			o.Path = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "args":
+		return func(param *ini.Param) error {
+			o.Args = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "exename":
+		return func(param *ini.Param) error {
+			o.ExeName = strings.ToLower(param.Value)
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "quietwhenfullscreen":
+		return func(param *ini.Param) error {
+			quiet, err := strconv.ParseBool(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse boolean for quietWhenFullscreen param - %w", err)
+			}
+
+			o.QuietWhenFullscreen = quiet
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *Hook) Validate() error {
+	if len(o.Events) == 0 {
+		return errors.New("event cannot be empty")
+	}
+
+	if o.Path == "" {
+		return errors.New("path cannot be empty")
+	}
+
+	o.config.Hooks = append(o.config.Hooks, o)
+
+	return nil
+}
+
+func isHookEvent(event string) bool {
+	for _, hookEvent := range hookEvents {
+		if event == hookEvent {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package appconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key is a keyboard key that a keybind is assigned to. Exactly
+// one of Char, VirtualKey, and ScanCode is non-zero.
+type Key struct {
+	// Char is the character that the key produces. It is
+	// resolved using the keyboard layout of the focused window
+	// when the key is pressed, so "z" matches the key labeled
+	// Z on both QWERTY and AZERTY keyboards. Letters are
+	// stored in uppercase.
+	Char rune
+
+	// VirtualKey is the key's virtual-key code.
+	VirtualKey byte
+
+	// ScanCode is the key's scan code. Extended keys
+	// have a 0xE0 prefix (e.g. 0xE048 for the up arrow).
+	ScanCode uint16
+
+	// Shift is true if the key must be pressed while
+	// shift is held (e.g. "shift+1").
+	Shift bool
+}
+
+// shiftPrefix is the prefix of keybinds that require shift.
+const shiftPrefix = "shift+"
+
+// String returns the key in the format used by config files.
+func (o Key) String() string {
+	var str string
+	switch {
+	case o.Char != 0:
+		str = string(o.Char)
+	case o.ScanCode != 0:
+		str = fmt.Sprintf("sc:0x%02X", o.ScanCode)
+	default:
+		str = fmt.Sprintf("vk:0x%02X", o.VirtualKey)
+	}
+
+	if o.Shift {
+		return shiftPrefix + str
+	}
+
+	return str
+}
+
+// Shifted returns the key with Shift set.
+func (o Key) Shifted() Key {
+	o.Shift = true
+	return o
+}
+
+// keybindFromStr parses a keybind. A keybind is either a single
+// character, "vk:" followed by a virtual-key code, or "sc:"
+// followed by a scan code. Scan codes identify the physical key
+// regardless of the keyboard layout. Any of these can be prefixed
+// with "shift+" to require that shift is held.
+func keybindFromStr(keybindStr string) (Key, error) {
+	if len(keybindStr) > len(shiftPrefix) && strings.EqualFold(keybindStr[:len(shiftPrefix)], shiftPrefix) {
+		key, err := keybindFromStr(keybindStr[len(shiftPrefix):])
+		if err != nil {
+			return Key{}, err
+		}
+
+		if key.Shift {
+			return Key{}, fmt.Errorf("keybind cannot have more than one %q prefix", shiftPrefix)
+		}
+
+		return key.Shifted(), nil
+	}
+
+	prefix, codeStr, hasPrefix := strings.Cut(keybindStr, ":")
+	if !hasPrefix || keybindStr == ":" {
+		char, size := utf8.DecodeRuneInString(keybindStr)
+		if size == 0 || size != len(keybindStr) || char == utf8.RuneError || unicode.IsSpace(char) {
+			return Key{}, fmt.Errorf("keybind must be 1 character, vk:<code>, or sc:<code>")
+		}
+
+		return Key{Char: unicode.ToUpper(char)}, nil
+	}
+
+	switch strings.ToLower(prefix) {
+	case "vk":
+		code, err := strconv.ParseUint(codeStr, 0, 8)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse virtual-key code - %w", err)
+		}
+
+		if code == 0 {
+			return Key{}, fmt.Errorf("virtual-key code cannot be zero")
+		}
+
+		return Key{VirtualKey: byte(code)}, nil
+	case "sc":
+		code, err := strconv.ParseUint(codeStr, 0, 16)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse scan code - %w", err)
+		}
+
+		if code == 0 {
+			return Key{}, fmt.Errorf("scan code cannot be zero")
+		}
+
+		return Key{ScanCode: uint16(code)}, nil
+	default:
+		return Key{}, fmt.Errorf("unknown keybind prefix: %q", prefix)
+	}
+}
@@ -0,0 +1,202 @@
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadOptions configures LoadAll.
+type LoadOptions struct {
+	// Match returns true if the file named name is a program
+	// config. It defaults to matching .conf files that are
+	// not local config files.
+	Match func(name string) bool
+
+	// Load loads the program config at filePath.
+	// It defaults to ProgramConfigFromPath.
+	Load func(filePath string) (*ProgramConfig, error)
+
+	// Check optionally returns an error if a loaded program
+	// config should be skipped (e.g. because its exeName is
+	// not allowed).
+	Check func(program *ProgramConfig) error
+}
+
+// LoadedFile is the result of loading one of the
+// files in a config directory (see LoadAll).
+type LoadedFile struct {
+	// Name is the file's name.
+	Name string
+
+	// Program is the file's program config. It is nil
+	// if the file is disabled or failed to load.
+	Program *ProgramConfig
+
+	// Disabled is true if the program config is disabled.
+	Disabled bool
+
+	// Err is the error that occurred while loading the file.
+	Err error
+
+	// Skipped is the error returned by LoadOptions.Check
+	// if the program config was skipped.
+	Skipped error
+
+	// Conflicts describes how the program config conflicts with
+	// the program configs of the files before it, such as both
+	// declaring the same exeName.
+	Conflicts []string
+}
+
+// LoadAll loads every program config in the directory at dirPath
+// into a Config. Disabled configs are skipped before they are parsed.
+// A file that fails to load does not prevent the other files from
+// being loaded, so the result of each file is returned in
+// Config.Files. An error is only returned if the directory cannot
+// be read.
+//
+// The loaded program configs are checked against each other, and
+// any conflicts are recorded in the Conflicts of the later file.
+func LoadAll(dirPath string, options LoadOptions) (*Config, error) {
+	if options.Match == nil {
+		options.Match = func(name string) bool {
+			return strings.HasSuffix(name, ".conf") && !IsLocalConfigFileName(name)
+		}
+	}
+
+	if options.Load == nil {
+		options.Load = ProgramConfigFromPath
+	}
+
+	pathInfos, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+
+	for _, pathInfo := range pathInfos {
+		if pathInfo.IsDir() || !options.Match(pathInfo.Name()) {
+			continue
+		}
+
+		file := &LoadedFile{Name: pathInfo.Name()}
+		config.Files = append(config.Files, file)
+
+		filePath := filepath.Join(dirPath, pathInfo.Name())
+
+		if DisabledFromPath(filePath) {
+			file.Disabled = true
+			continue
+		}
+
+		program, err := options.Load(filePath)
+		if err != nil {
+			file.Err = err
+			continue
+		}
+
+		if program.General.Disabled {
+			file.Disabled = true
+			continue
+		}
+
+		if options.Check != nil {
+			file.Skipped = options.Check(program)
+			if file.Skipped != nil {
+				continue
+			}
+		}
+
+		file.Program = program
+		file.Conflicts = config.conflicts(program)
+
+		config.Programs = append(config.Programs, program)
+	}
+
+	return config, nil
+}
+
+// Err returns the error of the first file that failed
+// to load, or nil if every file was loaded.
+func (o *Config) Err() error {
+	for _, file := range o.Files {
+		if file.Err != nil {
+			return fmt.Errorf("failed to load %s - %w", file.Name, file.Err)
+		}
+	}
+
+	return nil
+}
+
+// conflicts describes how program conflicts with the
+// program configs that have already been loaded.
+func (o *Config) conflicts(program *ProgramConfig) []string {
+	return Conflicts(program, o.Files)
+}
+
+// Conflicts describes how program conflicts with the
+// program configs of files, such as both declaring the
+// same exeName. Files without a program config are ignored.
+func Conflicts(program *ProgramConfig, files []*LoadedFile) []string {
+	var conflicts []string
+
+	for _, file := range files {
+		other := file.Program
+		if other == nil {
+			continue
+		}
+
+		if other.General.ExeName == program.General.ExeName {
+			conflicts = append(conflicts, fmt.Sprintf("exeName %q is also declared by %s",
+				program.General.ExeName, file.Name))
+		}
+
+		keys := sharedGlobalKeybinds(program, other)
+		if len(keys) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("global keybinds %s are also bound by %s",
+				strings.Join(keys, ", "), file.Name))
+		}
+	}
+
+	return conflicts
+}
+
+// sharedGlobalKeybinds returns the keys that both a and b bind in the
+// global scope. Pressing one of these keys while both programs are
+// running triggers the sections of both.
+func sharedGlobalKeybinds(a *ProgramConfig, b *ProgramConfig) []string {
+	var keys []string
+
+	for key, sections := range a.Keybinds {
+		if key == (Key{}) {
+			continue
+		}
+
+		if hasGlobalSection(sections) && hasGlobalSection(b.Keybinds[key]) {
+			keys = append(keys, key.String())
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// hasGlobalSection returns true if any of sections has
+// keybinds that are active in the global scope.
+func hasGlobalSection(sections []interface{}) bool {
+	for _, section := range sections {
+		// The scope is empty if the section
+		// does not have a scope param.
+		scope := SectionScope(section)
+		if scope == "" || scope == GlobalScope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package appconfig
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// maskParamSuffix is the suffix of the per-pointer
+// parameter that sets a pointer's Mask.
+const maskParamSuffix = "mask"
+
+// maskFromStr parses a mask for a value that is size bytes long.
+// The mask is a number, which may be written in binary (0b1100),
+// hexadecimal (0xC), octal (0o14), or decimal (12). It is returned
+// in little-endian byte order, which is how the program stores it.
+func maskFromStr(str string, size int) ([]byte, error) {
+	if size < 1 || size > 8 {
+		return nil, fmt.Errorf("masks can only be used with values that are 1 to 8 bytes, not %d bytes", size)
+	}
+
+	mask, err := strconv.ParseUint(str, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mask: %q - %w", str, err)
+	}
+
+	if mask == 0 {
+		return nil, errors.New("mask must have at least one bit set")
+	}
+
+	if size < 8 && mask>>(8*size) != 0 {
+		return nil, fmt.Errorf("mask %q is larger than the value's size of %d bytes", str, size)
+	}
+
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, mask)
+
+	return data[:size], nil
+}
+
+// Masked returns data with the bits that are not set in the pointer's
+// Mask replaced with the bits of current, which is the value currently
+// in memory. Writing the result changes only the masked bits. If the
+// pointer has no mask, data is returned as-is.
+//
+// The mask is applied to each of the pointer's fields.
+func (o Pointer) Masked(current []byte, data []byte) []byte {
+	if o.Mask == nil {
+		return data
+	}
+
+	masked := make([]byte, len(data))
+	for i := range data {
+		mask := o.Mask[i%len(o.Mask)]
+		masked[i] = current[i]&^mask | data[i]&mask
+	}
+
+	return masked
+}
@@ -0,0 +1,84 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// chainModuleSeparator separates the parts of a pointer chain that
+// start at different modules (e.g. "game.exe 0x1000 -> physics.dll
+// 0x200 0x18").
+const chainModuleSeparator = "->"
+
+// ModuleStep is a part of a pointer chain that continues in another
+// module. Some engines store the locations of another DLL's globals
+// as offsets from the start of that DLL rather than as pointers.
+//
+// The value at the address that the previous part of the chain leads
+// to is read, and it is added to the module's base address. The
+// step's Addrs are then followed from there like the offsets of a
+// chain that starts with a module name.
+type ModuleStep struct {
+	// Module is the lowercase file name of the module.
+	Module string
+	Addrs  []uintptr
+}
+
+// moduleStepFromStr parses a part of a pointer
+// chain that follows a chainModuleSeparator.
+func moduleStepFromStr(str string, config *ProgramConfig) (ModuleStep, error) {
+	strs := strings.Fields(str)
+	if len(strs) == 0 || !strings.Contains(strs[0], ".") {
+		return ModuleStep{}, fmt.Errorf("a module name must follow %q (e.g. %q)",
+			chainModuleSeparator, "-> physics.dll 0x200")
+	}
+
+	offsets, err := offsetsFromStrs(strs[1:], config.decimalOffsets())
+	if err != nil {
+		return ModuleStep{}, err
+	}
+
+	if len(offsets) == 0 {
+		return ModuleStep{}, errors.New("a module name must be followed by at least one offset")
+	}
+
+	return ModuleStep{
+		Module: strings.ToLower(strs[0]),
+		Addrs:  offsets,
+	}, nil
+}
+
+// copySteps returns a deep copy of steps, so that
+// offsets can be appended without modifying steps.
+func copySteps(steps []ModuleStep) []ModuleStep {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	copied := make([]ModuleStep, len(steps))
+	for i, step := range steps {
+		copied[i] = ModuleStep{
+			Module: step.Module,
+			Addrs:  append([]uintptr(nil), step.Addrs...),
+		}
+	}
+
+	return copied
+}
+
+// Modules returns the names of the modules that the pointer's chain
+// starts at or continues in. The exe is only included if the chain
+// starts with its name.
+func (o Pointer) Modules() []string {
+	var modules []string
+	if o.OptModule != "" {
+		modules = append(modules, o.OptModule)
+	}
+
+	for _, step := range o.Steps {
+		modules = append(modules, step.Module)
+	}
+
+	return modules
+}
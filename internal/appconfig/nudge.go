@@ -0,0 +1,319 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// Nudge adds or subtracts a step from the value at a pointer when
+// its keybinds are pressed (for example, to fine-tune a camera or
+// position). The value is read, changed, and written back, so the
+// rest of the program's changes to it are kept.
+type Nudge struct {
+	// Name is the section's name (e.g. "camx" for
+	// [Nudge "camx"]). It is empty if the section
+	// is not named.
+	Name string
+
+	// Pointer is the location of the value. Its size
+	// is the size of the step's type.
+	Pointer Pointer
+
+	// Type is the type of the value, and Step is the
+	// amount that is added or subtracted.
+	Type ValueType
+	Step float64
+
+	// Increase and Decrease are the keybinds that add and
+	// subtract the step. Either may be the zero Key.
+	Increase Key
+	Decrease Key
+
+	// Min and Max, if non-nil, limit the nudged value.
+	Min *float64
+	Max *float64
+
+	Label string
+	Scope KeybindScope
+
+	// Priority orders the section's action relative to the
+	// other sections bound to the same key (see RunsBefore).
+	Priority int
+
+	// ConsumeKey prevents the section's keybinds from reaching
+	// the program while it is in the foreground.
+	ConsumeKey bool
+
+	config *ProgramConfig
+}
+
+// DisplayName returns the section's label if one was specified,
+// or an empty string otherwise.
+func (o *Nudge) DisplayName() string {
+	return o.Label
+}
+
+func (o *Nudge) RequiredParams() []string {
+	return []string{
+		"step",
+	}
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Nudge) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "<nickname>Pointer", Type: pointerType, Required: true, Example: "camXPointer",
+			Help: "The location of the value to nudge."},
+		{Name: "<nickname>Pointer_#", Type: pointerType, Example: "camXPointer_4",
+			Help: "The location of the value to nudge, instead of <nickname>Pointer. # is the number of bytes."},
+		{Name: "step", Type: "type:number (e.g. float32:0.5)", Required: true,
+			Help: "The type of the value and the amount that is added or subtracted."},
+		{Name: "increase", Type: keybindType,
+			Help: "The keybind that adds the step to the value."},
+		{Name: "decrease", Type: keybindType,
+			Help: "The keybind that subtracts the step from the value."},
+		{Name: "min", Type: "number",
+			Help: "The minimum value that is written."},
+		{Name: "max", Type: "number",
+			Help: "The maximum value that is written."},
+		{Name: "scope", Type: scopeType, Default: "global",
+			Help: "Where the keybinds are active."},
+		{Name: "priority", Type: priorityType, Default: "0",
+			Help: "Sections bound to the same key run in order of highest priority first."},
+		{Name: "consumeKey", Type: boolType, Default: "false",
+			Help: "Prevent the keybinds from reaching the game while it is in the foreground."},
+		{Name: "label", Type: stringType,
+			Help: "A human-readable name for the section."},
+	}
+}
+
+func (o *Nudge) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	keyParam := func(dst *Key) func(param *ini.Param) error {
+		return func(param *ini.Param) error {
+			keybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			*dst = keybind
+			return nil
+		}
+	}
+
+	limitParam := func(dst **float64) func(param *ini.Param) error {
+		return func(param *ini.Param) error {
+			value, err := strconv.ParseFloat(param.Value, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse number: %q - %w", param.Value, err)
+			}
+
+			*dst = &value
+			return nil
+		}
+	}
+
+	switch {
+	case "step" == name:
+		return func(param *ini.Param) error {
+			typeStr, stepStr, hasType := strings.Cut(param.Value, ":")
+			if !hasType {
+				return fmt.Errorf("step must be a type followed by a number (e.g. float32:0.5), not %q",
+					param.Value)
+			}
+
+			valueType := ValueType(strings.ToLower(strings.TrimSpace(typeStr)))
+			if valueType.Size() == 0 {
+				return fmt.Errorf("unknown value type: %q", typeStr)
+			}
+
+			step, err := strconv.ParseFloat(strings.TrimSpace(stepStr), 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse step param - %w", err)
+			}
+
+			o.Type = valueType
+			o.Step = step
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "increase" == name:
+		return keyParam(&o.Increase), ini.SchemaRule{Limit: 1}
+	case "decrease" == name:
+		return keyParam(&o.Decrease), ini.SchemaRule{Limit: 1}
+	case "min" == name:
+		return limitParam(&o.Min), ini.SchemaRule{Limit: 1}
+	case "max" == name:
+		return limitParam(&o.Max), ini.SchemaRule{Limit: 1}
+	case scopeParam == name:
+		return func(param *ini.Param) error {
+			scope, err := keybindScopeFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Scope = scope
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case consumeKeyParam == name:
+		return func(param *ini.Param) error {
+			consumeKey, err := consumeKeyFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.ConsumeKey = consumeKey
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case priorityParam == name:
+		return func(param *ini.Param) error {
+			priority, err := priorityFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.Priority = priority
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case labelParamSuffix == name:
+		return func(param *ini.Param) error {
+			o.Label = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case strings.HasSuffix(name, writePointerParamSuffix) || strings.Contains(name, readPointerParamSuffix):
+		return func(param *ini.Param) error {
+			if o.Pointer.Name != "" {
+				return fmt.Errorf("nudge already has a pointer defined (%q)", o.Pointer.Name)
+			}
+
+			var pointer Pointer
+			var err error
+			if strings.Contains(name, readPointerParamSuffix) {
+				pointer, err = readPointerFromParam(param, o.config)
+			} else {
+				pointer, err = pointerFromParam(param, o.config)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse pointer: %q - %w", param.Name, err)
+			}
+
+			o.Pointer = pointer
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *Nudge) Validate() error {
+	if o.Pointer.Name == "" {
+		return errors.New("no pointer was specified")
+	}
+
+	size := o.Type.Size()
+	if o.Pointer.NBytes > 0 && o.Pointer.NBytes != size {
+		return fmt.Errorf("pointer's size is %d bytes, but %s values are %d bytes",
+			o.Pointer.NBytes, o.Type, size)
+	}
+
+	o.Pointer.NBytes = size
+	o.Pointer.Display = DisplayFormat(o.Type)
+
+	if o.Step <= 0 {
+		return errors.New("step must be greater than zero")
+	}
+
+	if !o.Type.IsFloat() && o.Step != math.Trunc(o.Step) {
+		return fmt.Errorf("step must be a whole number for integer type %s", o.Type)
+	}
+
+	if o.Min != nil && o.Max != nil && *o.Min > *o.Max {
+		return fmt.Errorf("min (%v) is greater than max (%v)", *o.Min, *o.Max)
+	}
+
+	if o.Increase == (Key{}) && o.Decrease == (Key{}) {
+		return errors.New("at least one of increase and decrease must be specified")
+	}
+
+	if o.Increase == o.Decrease {
+		return errors.New("cannot have duplicate keybind for increase and decrease")
+	}
+
+	err := o.config.checkSectionName(o.Name)
+	if err != nil {
+		return err
+	}
+
+	o.config.Nudges = append(o.config.Nudges, o)
+	o.config.declareSection(o)
+
+	for _, keybind := range []Key{o.Increase, o.Decrease} {
+		if keybind != (Key{}) {
+			o.config.Keybinds[keybind] = append(o.config.Keybinds[keybind], o)
+		}
+	}
+
+	return nil
+}
+
+// Nudged returns current, the pointer's value, with the step added
+// to it (or subtracted from it, if increase is false). The result is
+// limited to Min, Max, and the range of the value's type, so that
+// integers do not wrap around.
+func (o *Nudge) Nudged(current []byte, increase bool) []byte {
+	filter := &ValueFilter{
+		Type:     o.Type,
+		ClampMin: o.Min,
+		ClampMax: o.Max,
+	}
+
+	value := filter.get(current)
+	if math.IsNaN(value) {
+		return current
+	}
+
+	if increase {
+		value += o.Step
+	} else {
+		value -= o.Step
+	}
+
+	lowest, highest := o.Type.valueRange()
+	value = math.Max(lowest, math.Min(highest, filter.filter(value)))
+
+	nudged := make([]byte, len(current))
+	filter.put(nudged, value)
+
+	return nudged
+}
+
+// valueRange returns the smallest and largest values of the type.
+// The largest 64-bit integers are rounded down to a float64 that
+// converts back to the type without overflowing.
+func (o ValueType) valueRange() (float64, float64) {
+	switch o {
+	case Int8Type:
+		return math.MinInt8, math.MaxInt8
+	case Int16Type:
+		return math.MinInt16, math.MaxInt16
+	case Int32Type:
+		return math.MinInt32, math.MaxInt32
+	case Int64Type:
+		return math.MinInt64, math.Nextafter(math.MaxInt64, 0)
+	case Uint8Type:
+		return 0, math.MaxUint8
+	case Uint16Type:
+		return 0, math.MaxUint16
+	case Uint32Type:
+		return 0, math.MaxUint32
+	case Uint64Type:
+		return 0, math.Nextafter(math.MaxUint64, 0)
+	case Float32Type:
+		return -math.MaxFloat32, math.MaxFloat32
+	default:
+		return -math.MaxFloat64, math.MaxFloat64
+	}
+}
@@ -0,0 +1,93 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// offsetsFromStrs parses each string in strs as an offset expression.
+// Refer to offsetFromStr for the syntax.
+func offsetsFromStrs(strs []string, decimal bool) ([]uintptr, error) {
+	var values []uintptr
+	for _, str := range strs {
+		value, err := offsetFromStr(str, decimal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse offset: %q - %w", str, err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// offsetFromStr parses one or more numbers joined by "+" or "-"
+// (e.g. "0xA0", "-0x10", or "0x1F40B8+16") and returns their sum.
+// Negative results wrap around, which makes them behave as negative
+// offsets when added to an address.
+//
+// Numbers prefixed with "0x" or suffixed with "h" are hexadecimal.
+// Other numbers are decimal if decimal is true, and hexadecimal
+// otherwise.
+func offsetFromStr(str string, decimal bool) (uintptr, error) {
+	if str == "" {
+		return 0, errors.New("offset is empty")
+	}
+
+	var sum int64
+	for str != "" {
+		negative := false
+		switch str[0] {
+		case '-':
+			negative = true
+			str = str[1:]
+		case '+':
+			str = str[1:]
+		}
+
+		end := strings.IndexAny(str, "+-")
+		if end == -1 {
+			end = len(str)
+		}
+
+		value, err := numberFromStr(str[:end], decimal)
+		if err != nil {
+			return 0, err
+		}
+
+		if negative {
+			sum -= value
+		} else {
+			sum += value
+		}
+
+		str = str[end:]
+	}
+
+	return uintptr(sum), nil
+}
+
+func numberFromStr(str string, decimal bool) (int64, error) {
+	base := 16
+	switch {
+	case strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X"):
+		str = str[2:]
+	case strings.HasSuffix(str, "h") || strings.HasSuffix(str, "H"):
+		str = str[:len(str)-1]
+	case decimal:
+		base = 10
+	}
+
+	if str == "" {
+		return 0, errors.New("missing number")
+	}
+
+	value, err := strconv.ParseUint(str, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert string to uint: %q - %w", str, err)
+	}
+
+	return int64(value), nil
+}
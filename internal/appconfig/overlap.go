@@ -0,0 +1,84 @@
+package appconfig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WriterOverlaps returns a description of each pair of writer
+// pointers that write to overlapping bytes. Only pointers whose
+// chains differ in their final offset alone can be compared before
+// the chains are resolved. Writers in the same group are not
+// compared, since they are mutually exclusive.
+func (o *ProgramConfig) WriterOverlaps() []string {
+	type target struct {
+		writer  *Writer
+		pointer WritePointer
+	}
+
+	var targets []target
+	for _, writer := range o.Writers {
+		names := make([]string, 0, len(writer.Pointers))
+		for name := range writer.Pointers {
+			names = append(names, name)
+		}
+
+		// Sort for consistent results, since
+		// Pointers is a map.
+		sort.Strings(names)
+
+		for _, name := range names {
+			targets = append(targets, target{writer: writer, pointer: writer.Pointers[name]})
+		}
+	}
+
+	var overlaps []string
+	for i, a := range targets {
+		for _, b := range targets[i+1:] {
+			if a.writer.Group != "" && a.writer.Group == b.writer.Group {
+				continue
+			}
+
+			if !sameChainPrefix(a.pointer.Pointer, b.pointer.Pointer) {
+				continue
+			}
+
+			aStart := a.pointer.Pointer.Addrs[len(a.pointer.Pointer.Addrs)-1]
+			bStart := b.pointer.Pointer.Addrs[len(b.pointer.Pointer.Addrs)-1]
+			if RangesOverlap(aStart, len(a.pointer.Data), bStart, len(b.pointer.Data)) {
+				overlaps = append(overlaps, fmt.Sprintf("%s (%s) and %s (%s) write to overlapping bytes",
+					a.pointer.Pointer.DisplayName(), o.SectionID(a.writer),
+					b.pointer.Pointer.DisplayName(), o.SectionID(b.writer)))
+			}
+		}
+	}
+
+	return overlaps
+}
+
+// RangesOverlap returns true if the aLen bytes at aStart
+// overlap the bLen bytes at bStart.
+func RangesOverlap(aStart uintptr, aLen int, bStart uintptr, bLen int) bool {
+	return aStart < bStart+uintptr(bLen) && bStart < aStart+uintptr(aLen)
+}
+
+// sameChainPrefix returns true if a and b have the same module
+// and the same offsets, excluding the final offset. Chains that
+// continue in other modules are not compared.
+func sameChainPrefix(a Pointer, b Pointer) bool {
+	if len(a.Steps) > 0 || len(b.Steps) > 0 {
+		return false
+	}
+
+	if a.OptModule != b.OptModule || len(a.Addrs) != len(b.Addrs) {
+		return false
+	}
+
+	for i := 0; i < len(a.Addrs)-1; i++ {
+		if a.Addrs[i] != b.Addrs[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,304 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// LocalConfigSuffix is the file name suffix of local config files.
+// A local config file contains a user's changes to a shared config
+// file with the same base name (e.g. game.local.conf for game.conf),
+// so that the shared file can be updated without losing them.
+const LocalConfigSuffix = ".local.conf"
+
+// IsLocalConfigFileName returns true if name is
+// the name of a local config file.
+func IsLocalConfigFileName(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), LocalConfigSuffix)
+}
+
+// LocalConfigPath returns the path of the local config
+// file for the config file or config bundle at filePath.
+func LocalConfigPath(filePath string) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + LocalConfigSuffix
+}
+
+// ApplyLocalConfigFromPath parses the local config file of the config
+// at filePath on top of the config, if the local file exists. It
+// returns false if there is no local config file.
+//
+// A local config file may add sections, change [General] parameters,
+// and change the keybinds, scopes, and priorities of existing sections
+// using [Override] sections. The shared config file is not modified.
+func (o *ProgramConfig) ApplyLocalConfigFromPath(filePath string) (bool, error) {
+	localPath := LocalConfigPath(filePath)
+
+	localFile, err := openConfigFile(localPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to open local config file - %w", err)
+	}
+
+	err = o.applyLocalConfig(localFile)
+	if err != nil {
+		return true, fmt.Errorf("failed to parse %s - %w", filepath.Base(localPath), err)
+	}
+
+	return true, nil
+}
+
+func (o *ProgramConfig) applyLocalConfig(r io.Reader) error {
+	o.local = true
+	defer func() {
+		o.local = false
+	}()
+
+	return ini.ParseSchema(r, o)
+}
+
+// Override changes the keybinds, scope, or priority of a section
+// that is declared in the shared config file. It may only be used
+// in local config files.
+type Override struct {
+	// Section identifies the section to change. It is
+	// passed to ProgramConfig.SectionByName.
+	Section string
+
+	saveState    *Key
+	restoreState *Key
+	compareState *Key
+	keybind      *Key
+	scope        *KeybindScope
+	priority     *int
+	config       *ProgramConfig
+}
+
+func (o *Override) RequiredParams() []string {
+	return []string{
+		"section",
+	}
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Override) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "section", Type: stringType, Required: true,
+			Help: "The name or label of the section to change, or its type followed by its number (e.g. writer#2)."},
+		{Name: "saveState", Type: keybindType,
+			Help: "The new saveState keybind of a SaveRestore section."},
+		{Name: "restoreState", Type: keybindType,
+			Help: "The new restoreState keybind of a SaveRestore section."},
+		{Name: "compareState", Type: keybindType,
+			Help: "The new compareState keybind of a SaveRestore section."},
+		{Name: "keybind", Type: keybindType,
+			Help: "The new keybind of a Writer or Counter section."},
+		{Name: "scope", Type: scopeType,
+			Help: "The new scope of the section's keybinds."},
+		{Name: "priority", Type: priorityType,
+			Help: "The new priority of the section."},
+	}
+}
+
+func (o *Override) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	keyParam := func(dst **Key) func(param *ini.Param) error {
+		return func(param *ini.Param) error {
+			keybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			*dst = &keybind
+			return nil
+		}
+	}
+
+	switch name {
+	case "section":
+		return func(param *ini.Param) error {
+			o.Section = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "savestate":
+		return keyParam(&o.saveState), ini.SchemaRule{Limit: 1}
+	case "restorestate":
+		return keyParam(&o.restoreState), ini.SchemaRule{Limit: 1}
+	case "comparestate":
+		return keyParam(&o.compareState), ini.SchemaRule{Limit: 1}
+	case "keybind":
+		return keyParam(&o.keybind), ini.SchemaRule{Limit: 1}
+	case scopeParam:
+		return func(param *ini.Param) error {
+			scope, err := keybindScopeFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.scope = &scope
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case priorityParam:
+		return func(param *ini.Param) error {
+			priority, err := priorityFromStr(param.Value)
+			if err != nil {
+				return err
+			}
+
+			o.priority = &priority
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *Override) Validate() error {
+	if !o.config.local {
+		return errors.New("[Override] sections can only be used in local config files")
+	}
+
+	section, err := o.config.SectionByName(o.Section)
+	if err != nil {
+		return err
+	}
+
+	switch v := section.(type) {
+	case *SaveRestore:
+		if o.keybind != nil {
+			return errors.New("keybind cannot be overridden for saverestore sections (use saveState and restoreState)")
+		}
+
+		saveState := v.SaveState
+		if o.saveState != nil {
+			saveState = *o.saveState
+		}
+
+		restoreState := v.RestoreState
+		if o.restoreState != nil {
+			restoreState = *o.restoreState
+		}
+
+		compareState := v.CompareState
+		if o.compareState != nil {
+			compareState = *o.compareState
+		}
+
+		if saveState == restoreState && saveState != (Key{}) {
+			return errors.New("cannot have duplicate keybind for saveState and restoreState")
+		}
+
+		if compareState != (Key{}) && (compareState == saveState || compareState == restoreState) {
+			return errors.New("compareState cannot be the same keybind as saveState or restoreState")
+		}
+
+		for _, keybind := range []Key{saveState, restoreState, compareState} {
+			_, _, isSlotKey := v.Slot(keybind)
+			if isSlotKey {
+				return fmt.Errorf("keybind %s is already used by slotKeys", keybind)
+			}
+		}
+
+		o.config.moveKeybind(v, v.SaveState, saveState)
+		o.config.moveKeybind(v, v.RestoreState, restoreState)
+		v.SaveState = saveState
+		v.RestoreState = restoreState
+
+		if o.compareState != nil {
+			if v.HasCompareState() {
+				o.config.moveKeybind(v, v.CompareState, compareState)
+			} else {
+				o.config.Keybinds[compareState] = append(o.config.Keybinds[compareState], v)
+			}
+
+			v.CompareState = compareState
+		}
+
+		if o.scope != nil {
+			v.Scope = *o.scope
+		}
+
+		if o.priority != nil {
+			v.Priority = *o.priority
+		}
+	case *Writer:
+		if o.saveState != nil || o.restoreState != nil || o.compareState != nil {
+			return errors.New("saveState, restoreState, and compareState can only be overridden for saverestore sections")
+		}
+
+		if o.keybind != nil {
+			o.config.moveKeybind(v, v.Keybind, *o.keybind)
+			v.Keybind = *o.keybind
+		}
+
+		if o.scope != nil {
+			v.Scope = *o.scope
+		}
+
+		if o.priority != nil {
+			v.Priority = *o.priority
+		}
+	case *Counter:
+		if o.saveState != nil || o.restoreState != nil || o.compareState != nil {
+			return errors.New("saveState, restoreState, and compareState can only be overridden for saverestore sections")
+		}
+
+		if o.keybind != nil {
+			o.config.moveKeybind(v, v.Keybind, *o.keybind)
+			v.Keybind = *o.keybind
+		}
+
+		if o.scope != nil {
+			v.Scope = *o.scope
+		}
+
+		if o.priority != nil {
+			v.Priority = *o.priority
+		}
+	case *Nudge:
+		if o.saveState != nil || o.restoreState != nil || o.compareState != nil || o.keybind != nil {
+			return errors.New("only the scope and priority of nudge sections can be overridden")
+		}
+
+		if o.scope != nil {
+			v.Scope = *o.scope
+		}
+
+		if o.priority != nil {
+			v.Priority = *o.priority
+		}
+	}
+
+	return nil
+}
+
+// moveKeybind moves section from the sections bound to from
+// to the sections bound to to.
+func (o *ProgramConfig) moveKeybind(section interface{}, from Key, to Key) {
+	if from == to {
+		return
+	}
+
+	sections := o.Keybinds[from]
+	for i, bound := range sections {
+		if bound == section {
+			sections = append(sections[:i:i], sections[i+1:]...)
+			break
+		}
+	}
+
+	if len(sections) == 0 {
+		delete(o.Keybinds, from)
+	} else {
+		o.Keybinds[from] = sections
+	}
+
+	o.Keybinds[to] = append(o.Keybinds[to], section)
+}
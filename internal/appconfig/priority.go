@@ -0,0 +1,74 @@
+package appconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const priorityParam = "priority"
+
+// priorityType is the documented type of the priority param.
+const priorityType = "number (may be negative)"
+
+func priorityFromStr(str string) (int, error) {
+	priority, err := strconv.ParseInt(str, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse priority param - %w", err)
+	}
+
+	return int(priority), nil
+}
+
+// SectionPriority returns the priority of a SaveRestore,
+// Writer, Counter, Seed, or Nudge section.
+func SectionPriority(section interface{}) int {
+	switch v := section.(type) {
+	case *SaveRestore:
+		return v.Priority
+	case *Writer:
+		return v.Priority
+	case *Counter:
+		return v.Priority
+	case *Seed:
+		return v.SaveRestore.Priority
+	case *Nudge:
+		return v.Priority
+	default:
+		return 0
+	}
+}
+
+// declareSection records that section was declared after the
+// sections that were declared before it (see RunsBefore).
+func (o *ProgramConfig) declareSection(section interface{}) {
+	if o.sectionOrder == nil {
+		o.sectionOrder = make(map[interface{}]int)
+	}
+
+	o.sectionOrder[section] = len(o.sectionOrder)
+}
+
+// RunsBefore returns true if the action of section a is performed
+// before the action of section b when a key press triggers both.
+// Sections with higher priorities run first, and sections with the
+// same priority run in the order that they are declared in (with
+// sections from a local config file after the shared config file).
+func (o *ProgramConfig) RunsBefore(a interface{}, b interface{}) bool {
+	aPriority, bPriority := SectionPriority(a), SectionPriority(b)
+	if aPriority != bPriority {
+		return aPriority > bPriority
+	}
+
+	return o.sectionOrder[declaredSection(a)] < o.sectionOrder[declaredSection(b)]
+}
+
+// declaredSection returns the section that was passed to
+// declareSection for section. A Seed is declared by its
+// SaveRestore section.
+func declaredSection(section interface{}) interface{} {
+	if seed, isSeed := section.(*Seed); isSeed {
+		return seed.SaveRestore
+	}
+
+	return section
+}
@@ -0,0 +1,128 @@
+package appconfig
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// redactedValue replaces the values of redacted parameters.
+const redactedValue = "<redacted>"
+
+// RedactOffsets returns a copy of a program config file with the
+// values of its pointer parameters and [Addresses] section replaced,
+// so that the config can be shared without revealing its offsets.
+// Comments are removed since they often contain offsets as well.
+//
+// The config does not need to be valid. Lines that cannot be
+// parsed are copied as-is.
+func RedactOffsets(config []byte) []byte {
+	var out bytes.Buffer
+	var pointers []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(config))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			// Named sections' headers also contain their
+			// names (e.g. [SaveRestore "boss2"]).
+			section, _, _ := strings.Cut(strings.TrimSpace(trimmed[1:len(trimmed)-1]), " ")
+			pointers = pointerParams(strings.ToLower(section))
+		default:
+			name, _, hasValue := strings.Cut(line, "=")
+			if hasValue && isOffsetParam(pointers, strings.ToLower(strings.TrimSpace(name))) {
+				line = name + "= " + redactedValue
+			}
+		}
+
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes()
+}
+
+// pointerParams returns the described names of the section's
+// parameters whose values are pointers, according to the section's
+// DescribeParams (e.g. "<nickname>Pointer_#").
+func pointerParams(section string) []string {
+	config := &ProgramConfig{Keybinds: make(map[Key][]interface{})}
+
+	newSection, _ := config.OnSection(section, section)
+	if newSection == nil {
+		return nil
+	}
+
+	sectionSchema, err := newSection()
+	if err != nil {
+		return nil
+	}
+
+	describer, isDescriber := sectionSchema.(ini.ParamDescriber)
+	if !isDescriber {
+		return nil
+	}
+
+	var names []string
+	for _, param := range describer.DescribeParams() {
+		if param.Type == pointerType || param.Type == stringPointerType {
+			names = append(names, param.Name)
+		}
+	}
+
+	return names
+}
+
+// isOffsetParam returns true if the parameter is
+// an instance of one of the described pointer params.
+func isOffsetParam(pointers []string, name string) bool {
+	for _, described := range pointers {
+		if paramNameMatches(described, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paramNameMatches returns true if name is an instance of the
+// described parameter name. Placeholders (e.g. <nickname>) match
+// any text, and # matches a number.
+func paramNameMatches(described string, name string) bool {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	described = strings.ToLower(described)
+	for described != "" {
+		switch {
+		case described[0] == '<':
+			_, after, _ := strings.Cut(described, ">")
+			pattern.WriteString(".+")
+			described = after
+		case described[0] == '#':
+			pattern.WriteString("[0-9]+")
+			described = described[1:]
+		default:
+			end := strings.IndexAny(described, "<#")
+			if end < 0 {
+				end = len(described)
+			}
+
+			pattern.WriteString(regexp.QuoteMeta(described[:end]))
+			described = described[end:]
+		}
+	}
+
+	pattern.WriteString("$")
+
+	matched, _ := regexp.MatchString(pattern.String(), name)
+	return matched
+}
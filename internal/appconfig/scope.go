@@ -0,0 +1,69 @@
+package appconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const scopeParam = "scope"
+
+// KeybindScope determines where a section's keybinds are active.
+type KeybindScope string
+
+const (
+	// GlobalScope keybinds are active regardless of
+	// which window is focused. It is the default scope.
+	GlobalScope KeybindScope = "global"
+
+	// GameScope keybinds are only active when a window
+	// of the target program is focused.
+	GameScope KeybindScope = "game"
+
+	// BlajScope keybinds are only active when one of
+	// blaj's own windows is focused.
+	BlajScope KeybindScope = "blaj"
+)
+
+func keybindScopeFromStr(str string) (KeybindScope, error) {
+	scope := KeybindScope(strings.ToLower(str))
+	switch scope {
+	case GlobalScope, GameScope, BlajScope:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown keybind scope: %q", str)
+	}
+}
+
+// IsActive returns true if keybinds in this scope are active
+// when the process identified by foregroundPID is focused.
+// programPID is the PID of the target program and blajPID
+// is the PID of blaj.
+func (o KeybindScope) IsActive(foregroundPID int, programPID int, blajPID int) bool {
+	switch o {
+	case GameScope:
+		return foregroundPID == programPID
+	case BlajScope:
+		return foregroundPID == blajPID
+	default:
+		return true
+	}
+}
+
+// SectionScope returns the KeybindScope of a SaveRestore,
+// Writer, Counter, Seed, or Nudge section.
+func SectionScope(section interface{}) KeybindScope {
+	switch v := section.(type) {
+	case *SaveRestore:
+		return v.Scope
+	case *Writer:
+		return v.Scope
+	case *Counter:
+		return v.Scope
+	case *Seed:
+		return v.SaveRestore.Scope
+	case *Nudge:
+		return v.Scope
+	default:
+		return GlobalScope
+	}
+}
@@ -0,0 +1,195 @@
+package appconfig
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// Seed saves and restores a random number generator's seed, and can
+// also write a fixed seed or step through seeds using its keybinds.
+// This replaces the SaveRestore and Writer sections that would
+// otherwise be needed to practice with a specific seed.
+type Seed struct {
+	// SaveRestore saves and restores the seed. It is added to the
+	// config's SaveRestores, so the seed can be saved and restored
+	// (including from the command line) like any other SaveRestore
+	// section.
+	SaveRestore *SaveRestore
+
+	// Value is the seed written by WriteValue. It is nil if
+	// the section does not have a value param.
+	Value []byte
+
+	// WriteValue is the keybind that writes Value. It is the
+	// zero Key if the section does not have a writeValue param.
+	WriteValue Key
+
+	// Reroll is the keybind that writes the next seed, which is
+	// one more than the seed written by the previous reroll. It
+	// is the zero Key if the section does not have a reroll param.
+	Reroll Key
+
+	config *ProgramConfig
+}
+
+// Pointer returns the seed's pointer.
+func (o *Seed) Pointer() Pointer {
+	return o.SaveRestore.Pointers[0]
+}
+
+// DisplayName returns the section's label if one was specified,
+// or an empty string otherwise.
+func (o *Seed) DisplayName() string {
+	return o.SaveRestore.DisplayName()
+}
+
+func (o *Seed) RequiredParams() []string {
+	return o.SaveRestore.RequiredParams()
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Seed) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "<nickname>Pointer_#", Type: pointerType, Required: true, Example: "seedPointer_4",
+			Help: "The location of the seed. # is the number of bytes (at most 8 if reroll is set)."},
+		{Name: "saveState", Type: keybindType, Required: true,
+			Help: "The keybind that saves the seed."},
+		{Name: "restoreState", Type: keybindType, Required: true,
+			Help: "The keybind that restores the seed."},
+		{Name: "value", Type: "hexadecimal",
+			Help: "A fixed seed that is written by the writeValue keybind."},
+		{Name: "writeValue", Type: keybindType,
+			Help: "The keybind that writes the value param. Requires value."},
+		{Name: "reroll", Type: keybindType,
+			Help: "The keybind that writes the next seed (one more than the previous reroll)."},
+		{Name: "scope", Type: scopeType, Default: "global",
+			Help: "Where the keybinds are active."},
+		{Name: "priority", Type: priorityType, Default: "0",
+			Help: "Sections bound to the same key run in order of highest priority first."},
+		{Name: "consumeKey", Type: boolType, Default: "false",
+			Help: "Prevent the keybinds from reaching the game while it is in the foreground."},
+		{Name: "onSuccess", Type: stringType,
+			Help: "The name or label of a section that is triggered after a keybind of this section succeeds."},
+		{Name: "onFailure", Type: stringType,
+			Help: "The name or label of a section that is triggered after a keybind of this section fails."},
+		{Name: "label", Type: stringType,
+			Help: "A human-readable name for the section."},
+		{Name: "<nickname>Display", Type: "hex, string, or a type", Default: "hex", Example: "seedDisplay",
+			Help: "The format used to show the seed in the log."},
+	}
+}
+
+func (o *Seed) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case "value":
+		return func(param *ini.Param) error {
+			value := strings.TrimPrefix(param.Value, "0x")
+			if len(value)%2 == 1 {
+				value = "0" + value
+			}
+
+			data, err := hex.DecodeString(value)
+			if err != nil {
+				return fmt.Errorf("failed to decode value - %w", err)
+			}
+
+			o.Value = data
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "writevalue":
+		return func(param *ini.Param) error {
+			keybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.WriteValue = keybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "reroll":
+		return func(param *ini.Param) error {
+			keybind, err := keybindFromStr(param.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse keybind: %q - %w", param.Value, err)
+			}
+
+			o.Reroll = keybind
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case slotKeysParam:
+		// A seed section saves a single seed.
+		return nil, ini.SchemaRule{}
+	default:
+		// The remaining params are the same
+		// as those of a SaveRestore section.
+		return o.SaveRestore.OnParam(name)
+	}
+}
+
+func (o *Seed) Validate() error {
+	if len(o.SaveRestore.Pointers) != 1 {
+		return errors.New("a seed section must have exactly one pointer")
+	}
+
+	pointer := o.SaveRestore.Pointers[0]
+
+	if o.Value != nil && len(o.Value) != pointer.Size() {
+		return fmt.Errorf("value is %d bytes, but the pointer's size is %d bytes",
+			len(o.Value), pointer.Size())
+	}
+
+	if o.HasWriteValue() && o.Value == nil {
+		return errors.New("writeValue requires the value param")
+	}
+
+	if o.HasReroll() && (len(pointer.Fields) > 0 || pointer.NBytes > 8) {
+		return errors.New("reroll requires a pointer that is at most 8 bytes and has no fields")
+	}
+
+	keybinds := []Key{o.SaveRestore.SaveState, o.SaveRestore.RestoreState}
+	for _, keybind := range []Key{o.WriteValue, o.Reroll} {
+		if keybind == (Key{}) {
+			continue
+		}
+
+		for _, other := range keybinds {
+			if keybind == other {
+				return errors.New("saveState, restoreState, writeValue, and reroll must be different keybinds")
+			}
+		}
+
+		keybinds = append(keybinds, keybind)
+	}
+
+	err := o.SaveRestore.Validate()
+	if err != nil {
+		return err
+	}
+
+	o.SaveRestore.Seed = o
+	o.config.Seeds = append(o.config.Seeds, o)
+
+	if o.HasWriteValue() {
+		o.config.Keybinds[o.WriteValue] = append(o.config.Keybinds[o.WriteValue], o)
+	}
+
+	if o.HasReroll() {
+		o.config.Keybinds[o.Reroll] = append(o.config.Keybinds[o.Reroll], o)
+	}
+
+	return nil
+}
+
+// HasWriteValue returns true if the section has a writeValue keybind.
+func (o *Seed) HasWriteValue() bool {
+	return o.WriteValue != Key{}
+}
+
+// HasReroll returns true if the section has a reroll keybind.
+func (o *Seed) HasReroll() bool {
+	return o.Reroll != Key{}
+}
@@ -0,0 +1,132 @@
+package appconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const slotKeysParam = "slotkeys"
+
+// numberRowScanCode is the scan code of the 1 key in the number row.
+// The scan codes of 2 to 9 follow it.
+const numberRowScanCode = 0x02
+
+// SaveSlot is one of the numbered save states of a SaveRestore
+// section with a slotKeys param. Each slot saves its own copy of
+// the section's memory, like the save state slots of an emulator.
+type SaveSlot struct {
+	// Number is the slot's number, which is also
+	// the number row key that its keybinds are bound to.
+	Number int
+
+	// Save is the slot's number row key with shift held,
+	// and Restore is the slot's number row key. They are
+	// bound by scan code, so that they match the number row
+	// on layouts where it types symbols (e.g. AZERTY).
+	Save    Key
+	Restore Key
+}
+
+// saveSlotsFromStr parses a slotKeys param, which is a range of
+// digits (e.g. "1-9"). A slot is created for each digit.
+func saveSlotsFromStr(str string) ([]SaveSlot, error) {
+	firstStr, lastStr, isRange := strings.Cut(str, "-")
+	if !isRange {
+		lastStr = firstStr
+	}
+
+	first, err := slotNumberFromStr(firstStr)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := slotNumberFromStr(lastStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if first > last {
+		return nil, fmt.Errorf("slot %d is greater than slot %d", first, last)
+	}
+
+	slots := make([]SaveSlot, 0, last-first+1)
+	for number := first; number <= last; number++ {
+		restore := Key{ScanCode: uint16(numberRowScanCode + number - 1)}
+
+		slots = append(slots, SaveSlot{
+			Number:  number,
+			Save:    restore.Shifted(),
+			Restore: restore,
+		})
+	}
+
+	return slots, nil
+}
+
+func slotNumberFromStr(str string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil || number < 1 || number > 9 {
+		return 0, fmt.Errorf("slot keys must be a range of digits from 1 to 9 (e.g. 1-9), not %q", str)
+	}
+
+	return number, nil
+}
+
+// Slot returns the slot that key saves or restores. save is true if
+// key is the slot's save keybind. ok is false if key does not belong
+// to any of the section's slots.
+//
+// A key also belongs to a slot if it is the slot's digit written as
+// a character or a virtual-key code, since those keys are triggered
+// by the same number row key as the slot.
+func (o *SaveRestore) Slot(key Key) (slot SaveSlot, save bool, ok bool) {
+	number := numberRowDigit(key)
+	if number == 0 {
+		return SaveSlot{}, false, false
+	}
+
+	for _, slot := range o.Slots {
+		if slot.Number == number {
+			return slot, key.Shift, true
+		}
+	}
+
+	return SaveSlot{}, false, false
+}
+
+// numberRowDigit returns the digit from 1 to 9 of the number row key
+// that key is triggered by, or 0 if key is not one of those keys.
+func numberRowDigit(key Key) int {
+	switch {
+	case key.Char >= '1' && key.Char <= '9':
+		return int(key.Char - '0')
+	case key.VirtualKey >= '1' && key.VirtualKey <= '9':
+		return int(key.VirtualKey - '0')
+	case key.ScanCode >= numberRowScanCode && key.ScanCode < numberRowScanCode+9:
+		return int(key.ScanCode-numberRowScanCode) + 1
+	default:
+		return 0
+	}
+}
+
+// SlotRange returns the range of the section's slots in the format
+// of the slotKeys param (e.g. "1-9"), or an empty string if the
+// section does not have slots.
+func (o *SaveRestore) SlotRange() string {
+	if len(o.Slots) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d-%d", o.Slots[0].Number, o.Slots[len(o.Slots)-1].Number)
+}
+
+// slotKeybinds returns the keybinds of the section's slots.
+func (o *SaveRestore) slotKeybinds() []Key {
+	keys := make([]Key, 0, len(o.Slots)*2)
+	for _, slot := range o.Slots {
+		keys = append(keys, slot.Save, slot.Restore)
+	}
+
+	return keys
+}
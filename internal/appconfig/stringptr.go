@@ -0,0 +1,201 @@
+package appconfig
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+const (
+	// stringPointerParamSuffix is the suffix of pointer
+	// parameters that point to null-terminated strings.
+	stringPointerParamSuffix = "stringpointer"
+
+	// textParamSuffix is the suffix of the Writer parameter
+	// that specifies the text written to a string pointer.
+	textParamSuffix = "text"
+)
+
+// StringEncoding is the encoding of a string pointer's text.
+type StringEncoding string
+
+const (
+	// ASCIIEncoding stores one byte per character.
+	ASCIIEncoding StringEncoding = "ascii"
+
+	// UTF16Encoding stores text as little-endian UTF-16,
+	// which is how Windows programs usually store text.
+	UTF16Encoding StringEncoding = "utf16"
+)
+
+// charSize returns the number of bytes in one character.
+func (o StringEncoding) charSize() int {
+	if o == UTF16Encoding {
+		return 2
+	}
+
+	return 1
+}
+
+// StringOptions describes the null-terminated string that a pointer
+// points to. The string is stored in a buffer that holds MaxLength
+// characters, including the null terminator.
+type StringOptions struct {
+	Encoding  StringEncoding
+	MaxLength int
+}
+
+// Size returns the size of the string's buffer in bytes.
+func (o *StringOptions) Size() int {
+	return o.MaxLength * o.Encoding.charSize()
+}
+
+// Encode returns text encoded as a null-terminated string that fills
+// the string's buffer. Text that does not fit is truncated, and the
+// rest of the buffer is filled with null characters.
+func (o *StringOptions) Encode(text string) ([]byte, error) {
+	data := make([]byte, o.Size())
+	maxChars := o.MaxLength - 1
+
+	switch o.Encoding {
+	case UTF16Encoding:
+		units := utf16.Encode([]rune(text))
+		if len(units) > maxChars {
+			units = units[:maxChars]
+
+			// Do not leave half of a surrogate pair.
+			if utf16.IsSurrogate(rune(units[len(units)-1])) {
+				units = units[:len(units)-1]
+			}
+		}
+
+		for i, unit := range units {
+			binary.LittleEndian.PutUint16(data[2*i:], unit)
+		}
+	default:
+		for i, char := range text {
+			if i >= maxChars {
+				break
+			}
+
+			if char > 0x7f {
+				return nil, fmt.Errorf("%q is not an ascii character", char)
+			}
+
+			data[i] = byte(char)
+		}
+	}
+
+	return data, nil
+}
+
+// Terminate returns a copy of data, the contents of the string's
+// buffer, with every character after the first null character
+// set to null. The last character is always set to null, so the
+// result is a valid string even if data was not.
+func (o *StringOptions) Terminate(data []byte) []byte {
+	size := o.Encoding.charSize()
+
+	terminated := make([]byte, len(data))
+	for i := 0; i+size <= len(data)-size; i += size {
+		char := data[i : i+size]
+		if isNullChar(char) {
+			break
+		}
+
+		copy(terminated[i:], char)
+	}
+
+	return terminated
+}
+
+func isNullChar(char []byte) bool {
+	for _, b := range char {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TerminateString returns data with the pointer's string terminated
+// (see StringOptions.Terminate). Data is returned as-is if the
+// pointer is not a string pointer.
+func (o Pointer) TerminateString(data []byte) []byte {
+	if o.String == nil {
+		return data
+	}
+
+	return o.String.Terminate(data)
+}
+
+// stringPointerFromParam parses a string pointer parameter. The value
+// is a pointer chain followed by options (e.g. "0x1C 0x10 encoding=utf16
+// max=32"). The max option is required and is the number of characters
+// in the string's buffer, including the null terminator.
+func stringPointerFromParam(param *ini.Param, config *ProgramConfig) (Pointer, error) {
+	options := &StringOptions{
+		Encoding: ASCIIEncoding,
+	}
+
+	var chain []string
+	for _, str := range strings.Fields(param.Value) {
+		name, value, isOption := strings.Cut(str, "=")
+		if !isOption {
+			chain = append(chain, str)
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "encoding":
+			encoding := StringEncoding(strings.ToLower(value))
+			switch encoding {
+			case ASCIIEncoding, UTF16Encoding:
+				options.Encoding = encoding
+			default:
+				return Pointer{}, fmt.Errorf("unknown string encoding: %q", value)
+			}
+		case "max":
+			maxLength, err := strconv.ParseUint(value, 10, 31)
+			if err != nil {
+				return Pointer{}, fmt.Errorf("failed to parse max option - %w", err)
+			}
+
+			if maxLength < 2 {
+				return Pointer{}, errors.New("max must be at least 2 (one character and the null terminator)")
+			}
+
+			options.MaxLength = int(maxLength)
+		default:
+			return Pointer{}, fmt.Errorf("unknown string pointer option: %q", name)
+		}
+	}
+
+	if options.MaxLength == 0 {
+		return Pointer{}, errors.New("string pointers require the max option (e.g. max=32)")
+	}
+
+	pointer, err := pointerFromParam(&ini.Param{
+		Name:  param.Name,
+		Value: strings.Join(chain, " "),
+	}, config)
+	if err != nil {
+		return Pointer{}, err
+	}
+
+	pointer.NBytes = options.Size()
+	pointer.String = options
+
+	pointer.Display = StringDisplay
+	if options.Encoding == UTF16Encoding {
+		pointer.Display = UTF16StringDisplay
+	}
+
+	return pointer, nil
+}
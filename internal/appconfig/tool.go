@@ -0,0 +1,100 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/ini"
+)
+
+// Tool is a [Tool] section of the application settings file.
+// It describes an external program, such as a debugger, that
+// can be launched with the attached program's PID and the
+// resolved address of a pointer.
+type Tool struct {
+	Name string
+	Path string
+
+	// Args is the tool's command line arguments. Refer to
+	// CommandLineArgs for the supported placeholders.
+	Args string
+
+	config *AppConfig
+}
+
+func (o *Tool) RequiredParams() []string {
+	return []string{
+		"name",
+		"path",
+	}
+}
+
+// DescribeParams implements ini.ParamDescriber.
+func (o *Tool) DescribeParams() []ini.ParamDescription {
+	return []ini.ParamDescription{
+		{Name: "name", Type: stringType, Required: true,
+			Help: "The name shown in the tray menu."},
+		{Name: "path", Type: stringType, Required: true,
+			Help: "The path of the program to start."},
+		{Name: "args", Type: stringType,
+			Help: "The program's arguments."},
+	}
+}
+
+func (o *Tool) OnParam(name string) (func(param *ini.Param) error, ini.SchemaRule) {
+	switch name {
+	case "name":
+		return func(param *ini.Param) error {
+			o.Name = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "path":
+		return func(param *ini.Param) error {
+			o.Path = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	case "args":
+		return func(param *ini.Param) error {
+			o.Args = param.Value
+			return nil
+		}, ini.SchemaRule{Limit: 1}
+	default:
+		return nil, ini.SchemaRule{}
+	}
+}
+
+func (o *Tool) Validate() error {
+	if o.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+
+	if o.Path == "" {
+		return errors.New("path cannot be empty")
+	}
+
+	for _, tool := range o.config.Tools {
+		if strings.EqualFold(tool.Name, o.Name) {
+			return fmt.Errorf("a tool named %q is already declared in a previous section", o.Name)
+		}
+	}
+
+	o.config.Tools = append(o.config.Tools, o)
+
+	return nil
+}
+
+// CommandLineArgs returns the tool's arguments with the following
+// placeholders replaced:
+//
+//   - {pid} - the attached program's process ID in decimal
+//   - {address} - the pointer's resolved address in hexadecimal
+//   - {exe} - the attached program's exe name
+func (o *Tool) CommandLineArgs(pid int, addr uintptr, exeName string) string {
+	return strings.NewReplacer(
+		"{pid}", strconv.Itoa(pid),
+		"{address}", fmt.Sprintf("%X", addr),
+		"{exe}", exeName,
+	).Replace(o.Args)
+}
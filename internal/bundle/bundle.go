@@ -0,0 +1,232 @@
+// Package bundle implements encrypted config bundles, which allow
+// config authors to share configs without their offsets being
+// trivially readable. Bundles can optionally be signed so that
+// users can verify who created them.
+//
+// A bundle consists of a header followed by the config encrypted with
+// AES-256-GCM. The key is derived from a passphrase using PBKDF2 with
+// HMAC-SHA256. If the bundle is signed, the header contains the
+// signer's Ed25519 public key and a signature of the plaintext config.
+// The header is authenticated as additional data.
+package bundle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FileExt is the file extension of bundle files.
+const FileExt = ".blajc"
+
+const (
+	magic = "BLAJCFG1"
+
+	flagSigned = 1 << 0
+
+	saltSize = 16
+	keySize  = 32
+
+	// defaultIterations is the number of PBKDF2 iterations used for
+	// new bundles. maxIterations prevents a corrupt bundle from
+	// causing a huge amount of work.
+	defaultIterations = 600000
+	maxIterations     = 10000000
+)
+
+// ErrWrongPassphrase is returned by Open when the passphrase is
+// incorrect or the bundle was modified.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupt bundle")
+
+// IsBundle returns true if data appears to be a bundle.
+func IsBundle(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// Seal encrypts config with passphrase. If signKey is non-nil,
+// the bundle is signed with it.
+func Seal(config []byte, passphrase string, signKey ed25519.PrivateKey) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt - %w", err)
+	}
+
+	aead, err := newAEAD(passphrase, salt, defaultIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce - %w", err)
+	}
+
+	header := bytes.NewBufferString(magic)
+
+	var flags byte
+	if signKey != nil {
+		flags |= flagSigned
+	}
+
+	header.WriteByte(flags)
+	header.Write(salt)
+	_ = binary.Write(header, binary.BigEndian, uint32(defaultIterations))
+	header.Write(nonce)
+
+	if signKey != nil {
+		header.Write(signKey.Public().(ed25519.PublicKey))
+		header.Write(ed25519.Sign(signKey, config))
+	}
+
+	return aead.Seal(header.Bytes(), nonce, config, header.Bytes()), nil
+}
+
+// Opened is a decrypted bundle.
+type Opened struct {
+	Config []byte
+
+	// Signer is the public key that signed the bundle,
+	// or nil if it was not signed.
+	Signer ed25519.PublicKey
+}
+
+// Open decrypts a bundle created by Seal and verifies
+// its signature if it has one.
+func Open(data []byte, passphrase string) (*Opened, error) {
+	if !IsBundle(data) {
+		return nil, errors.New("file is not a config bundle")
+	}
+
+	r := bytes.NewReader(data[len(magic):])
+
+	flags, err := r.ReadByte()
+	if err != nil {
+		return nil, errors.New("bundle header is truncated")
+	}
+
+	salt := make([]byte, saltSize)
+	var iterations uint32
+
+	_, err = io.ReadFull(r, salt)
+	if err == nil {
+		err = binary.Read(r, binary.BigEndian, &iterations)
+	}
+	if err != nil {
+		return nil, errors.New("bundle header is truncated")
+	}
+
+	if iterations == 0 || iterations > maxIterations {
+		return nil, fmt.Errorf("invalid number of key derivation iterations: %d", iterations)
+	}
+
+	aead, err := newAEAD(passphrase, salt, int(iterations))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	var signer ed25519.PublicKey
+	var signature []byte
+
+	_, err = io.ReadFull(r, nonce)
+	if err == nil && flags&flagSigned != 0 {
+		signer = make([]byte, ed25519.PublicKeySize)
+		signature = make([]byte, ed25519.SignatureSize)
+
+		_, err = io.ReadFull(r, signer)
+		if err == nil {
+			_, err = io.ReadFull(r, signature)
+		}
+	}
+	if err != nil {
+		return nil, errors.New("bundle header is truncated")
+	}
+
+	headerSize := len(data) - r.Len()
+
+	config, err := aead.Open(nil, nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if signer != nil && !ed25519.Verify(signer, config, signature) {
+		return nil, errors.New("bundle signature is invalid")
+	}
+
+	return &Opened{
+		Config: config,
+		Signer: signer,
+	}, nil
+}
+
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2([]byte(passphrase), salt, iterations, keySize, sha256.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher - %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm - %w", err)
+	}
+
+	return aead, nil
+}
+
+// EncodePublicKey returns the base64 encoding of key, which is the
+// format used for trusted keys in the application settings.
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey parses a public key encoded by EncodePublicKey.
+func ParsePublicKey(str string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(str))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key - %w", err)
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d",
+			ed25519.PublicKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// EncodePrivateKey returns the base64 encoding of key's seed.
+func EncodePrivateKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Seed())
+}
+
+// ParsePrivateKey parses a private key encoded by EncodePrivateKey.
+func ParsePrivateKey(str string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(str))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key - %w", err)
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("private key must be %d bytes, got %d",
+			ed25519.SeedSize, len(seed))
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -0,0 +1,45 @@
+package bundle
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+)
+
+// pbkdf2 derives a key of keyLen bytes from password and salt
+// as described in RFC 8018, section 5.2.
+func pbkdf2(password []byte, salt []byte, iterations int, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	blockIndex := make([]byte, 4)
+
+	for block := 1; block <= numBlocks; block++ {
+		binary.BigEndian.PutUint32(blockIndex, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(blockIndex)
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, hashLen)
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
@@ -0,0 +1,55 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		name       string
+		h          func() hash.Hash
+		password   string
+		salt       string
+		iterations int
+		want       string
+	}{
+		// RFC 6070, section 2.
+		{"rfc6070-1", sha1.New, "password", "salt", 1,
+			"0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{"rfc6070-2", sha1.New, "password", "salt", 2,
+			"ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{"rfc6070-3", sha1.New, "password", "salt", 4096,
+			"4b007901b765489abead49d926f721d065a429c1"},
+		{"rfc6070-5", sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096,
+			"3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{"rfc6070-6", sha1.New, "pass\x00word", "sa\x00lt", 4096,
+			"56fa6aa75548099dcc37d7f03425e0c3"},
+
+		// The same inputs using HMAC-SHA256, which bundles use.
+		{"sha256-1", sha256.New, "password", "salt", 1,
+			"120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"sha256-2", sha256.New, "password", "salt", 2,
+			"ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"sha256-3", sha256.New, "password", "salt", 4096,
+			"c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := hex.DecodeString(test.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := pbkdf2([]byte(test.password), []byte(test.salt), test.iterations, len(want), test.h)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("got %x, want %x", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+// Package cloudsync syncs files with remote storage so
+// that configs and saved states can be shared between PCs.
+package cloudsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MaxSize is the maximum size of a synced file.
+const MaxSize = 16 << 20
+
+// remoteCopySuffix is the suffix of the local copies of remote
+// files that conflict with local files.
+const remoteCopySuffix = ".remote"
+
+// ErrUnauthorized is returned by a Provider when the
+// remote storage rejects the credentials.
+var ErrUnauthorized = errors.New("remote storage rejected the credentials")
+
+// Provider is remote storage that files are synced with. Names are
+// slash-separated paths relative to the root of the storage, such as
+// "configs/game.conf".
+type Provider interface {
+	// List returns the files in the remote directory dir, mapping
+	// their names to a version (such as an ETag) that changes each
+	// time the file is modified. A directory that does not exist
+	// has no files.
+	List(ctx context.Context, dir string) (map[string]string, error)
+
+	// Get returns the contents of a remote file.
+	Get(ctx context.Context, name string) ([]byte, error)
+
+	// Put creates or replaces a remote file,
+	// creating its directory if needed.
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// Dir is a local directory whose files are synced
+// with a directory in the remote storage.
+type Dir struct {
+	// Local is the path of the local directory.
+	Local string
+
+	// Remote is the name of the remote directory.
+	Remote string
+
+	// Match returns true if the file named name is synced.
+	// Every file is synced if nil.
+	Match func(name string) bool
+
+	// Write replaces the local file at filePath with a file that
+	// was pulled. If nil, the file is written to a temporary file
+	// which is then renamed.
+	Write func(filePath string, data []byte) error
+}
+
+// Result describes the files that a sync changed.
+type Result struct {
+	// Pushed are the names of the files that were uploaded.
+	Pushed []string
+
+	// Pulled are the names of the files that were downloaded.
+	Pulled []string
+
+	// Conflicts are the names of the files that changed both
+	// locally and remotely. The local files are kept, and
+	// their remote copies are saved next to them with a
+	// ".remote" suffix. A conflict is resolved by deleting
+	// the remote copy, after which the local file is pushed.
+	Conflicts []string
+}
+
+// Syncer syncs local directories with a Provider. Files are never
+// deleted: a file that only exists on one side is copied to the
+// other.
+type Syncer struct {
+	Provider Provider
+	Dirs     []Dir
+
+	// StatePath is the path of the file that records the version
+	// of each file as of the last sync, which is how changes on
+	// either side are detected.
+	StatePath string
+}
+
+// syncedFile is the state of a file as of the last sync.
+type syncedFile struct {
+	// Hash is the SHA-256 hash of the file's contents.
+	Hash string `json:"hash"`
+
+	// Version is the file's remote version.
+	Version string `json:"version"`
+
+	// Conflict is the remote version that conflicted with
+	// the local file, if the conflict is not resolved.
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// Sync pushes the local files that changed since the last sync and
+// pulls the remote files that changed since the last sync.
+func (o *Syncer) Sync(ctx context.Context) (*Result, error) {
+	state, err := o.readState()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, dir := range o.Dirs {
+		err = o.syncDir(ctx, dir, state, result)
+		if err != nil {
+			err = fmt.Errorf("failed to sync %s - %w", dir.Remote, err)
+			break
+		}
+	}
+
+	// The files that were synced before an error
+	// are recorded so they are not synced again.
+	stateErr := o.writeState(state)
+	if err != nil {
+		return result, err
+	}
+
+	if stateErr != nil {
+		return result, stateErr
+	}
+
+	return result, nil
+}
+
+func (o *Syncer) syncDir(ctx context.Context, dir Dir, state map[string]syncedFile, result *Result) error {
+	local, err := localFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	remote, err := o.Provider.List(ctx, dir.Remote)
+	if err != nil {
+		return fmt.Errorf("failed to list remote files - %w", err)
+	}
+
+	names := make([]string, 0, len(local)+len(remote))
+	for name := range local {
+		names = append(names, name)
+	}
+
+	for name := range remote {
+		if !isLocalName(name) {
+			continue
+		}
+
+		if _, hasIt := local[name]; !hasIt && isSynced(dir, name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var pushed []string
+	push := func(name string, data []byte) error {
+		remoteName := path.Join(dir.Remote, name)
+
+		err := o.Provider.Put(ctx, remoteName, data)
+		if err != nil {
+			return fmt.Errorf("failed to push %s - %w", name, err)
+		}
+
+		state[remoteName] = syncedFile{Hash: hashOf(data)}
+		pushed = append(pushed, name)
+		result.Pushed = append(result.Pushed, remoteName)
+
+		return nil
+	}
+
+	for _, name := range names {
+		remoteName := path.Join(dir.Remote, name)
+
+		localPath, err := localPathOf(dir, name)
+		if err != nil {
+			return err
+		}
+
+		data, isLocal := local[name]
+		version, isRemote := remote[name]
+		synced, wasSynced := state[remoteName]
+		hash := hashOf(data)
+
+		localChanged := !wasSynced || synced.Hash != hash
+		remoteChanged := !wasSynced || synced.Version != version
+
+		switch {
+		case !isRemote || (isLocal && localChanged && !remoteChanged):
+			err = push(name, data)
+			if err != nil {
+				return err
+			}
+		case !isLocal || (remoteChanged && !localChanged):
+			remoteData, err := o.Provider.Get(ctx, remoteName)
+			if err != nil {
+				return fmt.Errorf("failed to pull %s - %w", name, err)
+			}
+
+			err = writeFile(dir, localPath, remoteData)
+			if err != nil {
+				return fmt.Errorf("failed to write %s - %w", name, err)
+			}
+
+			state[remoteName] = syncedFile{Hash: hashOf(remoteData), Version: version}
+			result.Pulled = append(result.Pulled, remoteName)
+		case localChanged && remoteChanged:
+			// Deleting the remote copy of a conflicting
+			// file resolves the conflict in favor of
+			// the local file.
+			_, statErr := os.Stat(localPath + remoteCopySuffix)
+			if synced.Conflict == version && errors.Is(statErr, os.ErrNotExist) {
+				err = push(name, data)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			remoteData, err := o.Provider.Get(ctx, remoteName)
+			if err != nil {
+				return fmt.Errorf("failed to pull %s - %w", name, err)
+			}
+
+			// Files that were changed the same way on both
+			// sides (or never synced from this PC) are not
+			// conflicts.
+			if hashOf(remoteData) == hash {
+				state[remoteName] = syncedFile{Hash: hash, Version: version}
+				continue
+			}
+
+			err = writeFile(Dir{}, localPath+remoteCopySuffix, remoteData)
+			if err != nil {
+				return fmt.Errorf("failed to write remote copy of %s - %w", name, err)
+			}
+
+			synced.Conflict = version
+			state[remoteName] = synced
+			result.Conflicts = append(result.Conflicts, remoteName)
+		}
+	}
+
+	if len(pushed) == 0 {
+		return nil
+	}
+
+	// The versions of the pushed files are not known
+	// until the remote directory is listed again.
+	remote, err = o.Provider.List(ctx, dir.Remote)
+	if err != nil {
+		return fmt.Errorf("failed to list remote files - %w", err)
+	}
+
+	for _, name := range pushed {
+		remoteName := path.Join(dir.Remote, name)
+		synced := state[remoteName]
+		synced.Version = remote[name]
+		state[remoteName] = synced
+	}
+
+	return nil
+}
+
+// localFiles returns the contents of the files in dir
+// that are synced, mapped by their names.
+func localFiles(dir Dir) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir.Local)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read local directory - %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !isSynced(dir, entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s - %w", entry.Name(), err)
+		}
+
+		if info.Size() > MaxSize {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir.Local, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s - %w", entry.Name(), err)
+		}
+
+		files[entry.Name()] = data
+	}
+
+	return files, nil
+}
+
+// windowsDeviceNames are the names of devices on Windows, which
+// refer to the device rather than a file in any directory (even
+// when they have a file extension, such as "nul.txt").
+var windowsDeviceNames = []string{
+	"con", "prn", "aux", "nul",
+	"com1", "com2", "com3", "com4", "com5", "com6", "com7", "com8", "com9",
+	"lpt1", "lpt2", "lpt3", "lpt4", "lpt5", "lpt6", "lpt7", "lpt8", "lpt9",
+}
+
+// isLocalName returns true if name, the name of a remote file, can
+// be joined to a local directory to name a file in that directory.
+// Remote names are chosen by the server, so names that contain path
+// or drive separators or "..", and the names of Windows devices, are
+// rejected.
+func isLocalName(name string) bool {
+	if name == "" || name == "." || strings.Contains(name, "..") ||
+		strings.ContainsAny(name, `/\:`) || strings.ContainsRune(name, 0) {
+		return false
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(name), ".")
+	base = strings.TrimRight(base, " ")
+	for _, device := range windowsDeviceNames {
+		if base == device {
+			return false
+		}
+	}
+
+	return true
+}
+
+// localPathOf returns the path of the file named name in dir's
+// local directory. An error is returned if name is not a local
+// name or the path is not inside the directory.
+func localPathOf(dir Dir, name string) (string, error) {
+	if !isLocalName(name) {
+		return "", fmt.Errorf("invalid file name: %q", name)
+	}
+
+	localPath := filepath.Join(dir.Local, name)
+
+	rel, err := filepath.Rel(dir.Local, localPath)
+	if err != nil || rel != name {
+		return "", fmt.Errorf("file name %q is outside of %s", name, dir.Local)
+	}
+
+	return localPath, nil
+}
+
+// isSynced returns true if the file named name in dir is synced.
+func isSynced(dir Dir, name string) bool {
+	// The remote copies of conflicting files and
+	// partially written files are never synced.
+	if strings.HasSuffix(name, remoteCopySuffix) || strings.HasSuffix(name, ".tmp") {
+		return false
+	}
+
+	return dir.Match == nil || dir.Match(name)
+}
+
+func writeFile(dir Dir, filePath string, data []byte) error {
+	if dir.Write != nil {
+		return dir.Write(filePath, data)
+	}
+
+	err := os.MkdirAll(filepath.Dir(filePath), 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash
+	// does not leave a truncated file behind.
+	tmpPath := filePath + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (o *Syncer) readState() (map[string]syncedFile, error) {
+	data, err := os.ReadFile(o.StatePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(map[string]syncedFile), nil
+		}
+
+		return nil, fmt.Errorf("failed to read sync state - %w", err)
+	}
+
+	state := make(map[string]syncedFile)
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sync state - %w", err)
+	}
+
+	return state, nil
+}
+
+func (o *Syncer) writeState(state map[string]syncedFile) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state - %w", err)
+	}
+
+	err = writeFile(Dir{}, o.StatePath, data)
+	if err != nil {
+		return fmt.Errorf("failed to write sync state - %w", err)
+	}
+
+	return nil
+}
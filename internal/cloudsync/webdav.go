@@ -0,0 +1,271 @@
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// propfindBody requests the properties that List uses.
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:getetag/>
+    <d:getlastmodified/>
+    <d:resourcetype/>
+  </d:prop>
+</d:propfind>`
+
+// WebDAV is a Provider that stores files on a WebDAV server, such
+// as Nextcloud or rclone's "serve webdav" command (which can also
+// serve S3 buckets).
+type WebDAV struct {
+	// BaseURL is the URL of the directory that files are stored in.
+	BaseURL *url.URL
+
+	Username string
+	Password string
+
+	// Client is the HTTP client used to make requests. A client
+	// that refuses redirects to non-HTTPS URLs is used if nil.
+	Client *http.Client
+}
+
+// defaultClient is the HTTP client used by a WebDAV without a Client.
+var defaultClient = &http.Client{
+	CheckRedirect: checkRedirect,
+}
+
+// checkRedirect refuses redirects to URLs that are not HTTPS, so
+// that the credentials are never sent in the clear. Otherwise, it
+// behaves like the default policy of at most 10 redirects.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing to follow redirect to non-https url: %s", req.URL.Redacted())
+	}
+
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	return nil
+}
+
+// NewWebDAV returns a WebDAV that stores files in the
+// directory at rawURL. Only HTTPS URLs are supported.
+func NewWebDAV(rawURL string, username string, password string) (*WebDAV, error) {
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url - %w", err)
+	}
+
+	if baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("only https urls are supported (got %q)", baseURL.Scheme)
+	}
+
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+
+	return &WebDAV{
+		BaseURL:  baseURL,
+		Username: username,
+		Password: password,
+	}, nil
+}
+
+// multistatus is the response to a PROPFIND request.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ETag         string `xml:"getetag"`
+				LastModified string `xml:"getlastmodified"`
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func (o *WebDAV) List(ctx context.Context, dir string) (map[string]string, error) {
+	resp, err := o.do(ctx, "PROPFIND", dir+"/", strings.NewReader(propfindBody), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusMultiStatus:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, statusErr(resp)
+	}
+
+	var status multistatus
+	err = xml.NewDecoder(io.LimitReader(resp.Body, MaxSize)).Decode(&status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode propfind response - %w", err)
+	}
+
+	files := make(map[string]string)
+	for _, response := range status.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			continue
+		}
+
+		for _, propstat := range response.Propstat {
+			prop := propstat.Prop
+			if !strings.Contains(propstat.Status, " 200 ") || prop.ResourceType.Collection != nil {
+				continue
+			}
+
+			// Servers without ETags are assumed to
+			// update the modification time instead.
+			version := prop.ETag
+			if version == "" {
+				version = prop.LastModified
+			}
+
+			// The name is used to name a local file, so
+			// names that could refer to other files are
+			// skipped.
+			name := path.Base(href.Path)
+			if !isLocalName(name) {
+				continue
+			}
+
+			files[name] = version
+		}
+	}
+
+	return files, nil
+}
+
+func (o *WebDAV) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := o.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusErr(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response - %w", err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", MaxSize)
+	}
+
+	return data, nil
+}
+
+func (o *WebDAV) Put(ctx context.Context, name string, data []byte) error {
+	status, err := o.put(ctx, name, data)
+	if err != nil {
+		return err
+	}
+
+	// Servers respond with 409 Conflict (or sometimes
+	// 404 Not Found) when the directory does not exist.
+	if status == http.StatusConflict || status == http.StatusNotFound {
+		err = o.mkcol(ctx, path.Dir(name))
+		if err != nil {
+			return err
+		}
+
+		status, err = o.put(ctx, name, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if status >= 300 {
+		return fmt.Errorf("server responded with %d %s", status, http.StatusText(status))
+	}
+
+	return nil
+}
+
+func (o *WebDAV) put(ctx context.Context, name string, data []byte) (int, error) {
+	resp, err := o.do(ctx, http.MethodPut, name, bytes.NewReader(data), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func (o *WebDAV) mkcol(ctx context.Context, dir string) error {
+	resp, err := o.do(ctx, "MKCOL", dir+"/", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 405 Method Not Allowed means that the directory exists.
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusMethodNotAllowed {
+		return fmt.Errorf("failed to create directory %s - %w", dir, statusErr(resp))
+	}
+
+	return nil
+}
+
+func (o *WebDAV) do(ctx context.Context, method string, name string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	u := o.BaseURL.ResolveReference(&url.URL{Path: name})
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if o.Username != "" || o.Password != "" {
+		req.SetBasicAuth(o.Username, o.Password)
+	}
+
+	client := o.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	}
+
+	return resp, nil
+}
+
+func statusErr(resp *http.Response) error {
+	return fmt.Errorf("server responded with %s", resp.Status)
+}
@@ -0,0 +1,101 @@
+// Package credui prompts the user for secrets using the
+// Windows credentials dialog.
+package credui
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	credui = syscall.NewLazyDLL("credui.dll")
+
+	pCredUIPromptForCredentialsW = credui.NewProc("CredUIPromptForCredentialsW")
+)
+
+// CREDUI_FLAGS values.
+const (
+	creduiFlagsDoNotPersist       = 0x00002
+	creduiFlagsAlwaysShowUI       = 0x00080
+	creduiFlagsGenericCredentials = 0x40000
+	creduiFlagsKeepUsername       = 0x100000
+)
+
+const (
+	creduiMaxUsernameLength = 513
+	creduiMaxPasswordLength = 256
+
+	errorCancelled syscall.Errno = 1223
+)
+
+// ErrCancelled is returned when the user closes the dialog.
+var ErrCancelled = errors.New("the prompt was cancelled")
+
+// creduiInfo is CREDUI_INFOW.
+type creduiInfo struct {
+	cbSize         uint32
+	hwndParent     uintptr
+	pszMessageText *uint16
+	pszCaptionText *uint16
+	hbmBanner      uintptr
+}
+
+// PromptPassword displays a dialog asking for a password. name is
+// displayed in the dialog's read-only user name field.
+func PromptPassword(caption string, message string, name string) (string, error) {
+	captionPtr, err := syscall.UTF16PtrFromString(caption)
+	if err != nil {
+		return "", err
+	}
+
+	messagePtr, err := syscall.UTF16PtrFromString(message)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+
+	username := make([]uint16, creduiMaxUsernameLength+1)
+	copy(username, syscall.StringToUTF16(name))
+
+	password := make([]uint16, creduiMaxPasswordLength+1)
+	defer func() {
+		for i := range password {
+			password[i] = 0
+		}
+	}()
+
+	info := creduiInfo{
+		pszMessageText: messagePtr,
+		pszCaptionText: captionPtr,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+
+	var save int32
+
+	ret, _, _ := pCredUIPromptForCredentialsW.Call(
+		uintptr(unsafe.Pointer(&info)),
+		uintptr(unsafe.Pointer(target)),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&username[0])),
+		uintptr(len(username)),
+		uintptr(unsafe.Pointer(&password[0])),
+		uintptr(len(password)),
+		uintptr(unsafe.Pointer(&save)),
+		creduiFlagsGenericCredentials|creduiFlagsKeepUsername|
+			creduiFlagsDoNotPersist|creduiFlagsAlwaysShowUI)
+	switch syscall.Errno(ret) {
+	case 0:
+		return syscall.UTF16ToString(password), nil
+	case errorCancelled:
+		return "", ErrCancelled
+	default:
+		return "", fmt.Errorf("failed to prompt for password - %w", syscall.Errno(ret))
+	}
+}
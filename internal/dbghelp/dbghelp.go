@@ -0,0 +1,85 @@
+package dbghelp
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+var (
+	dbghelp = syscall.NewLazyDLL("dbghelp.dll")
+
+	pMiniDumpWriteDump = dbghelp.NewProc("MiniDumpWriteDump")
+)
+
+// MINIDUMP_TYPE flags.
+const (
+	miniDumpWithDataSegs                = 0x00000001
+	miniDumpWithFullMemory              = 0x00000002
+	miniDumpWithPrivateReadWriteMemory  = 0x00000200
+	miniDumpWithFullMemoryInfo          = 0x00000800
+	miniDumpWithIndirectlyReferencedMem = 0x00000040
+)
+
+// DumpType controls how much of a process's memory
+// is included in a minidump.
+type DumpType string
+
+const (
+	// SmallDump includes the data sections of each module, which
+	// contain static variables. Pointer chains that leave the
+	// data sections cannot be followed.
+	SmallDump DumpType = "small"
+
+	// HeapDump also includes the process's private read-write
+	// memory, which includes the heap. This is usually enough to
+	// follow pointer chains and is much smaller than a full dump.
+	HeapDump DumpType = "heap"
+
+	// FullDump includes all of the process's memory.
+	FullDump DumpType = "full"
+)
+
+// ParseDumpType returns the DumpType named by str (case-insensitive).
+func ParseDumpType(str string) (DumpType, error) {
+	dumpType := DumpType(strings.ToLower(str))
+	switch dumpType {
+	case SmallDump, HeapDump, FullDump:
+		return dumpType, nil
+	default:
+		return "", fmt.Errorf("unknown dump type: %q (must be %s, %s, or %s)",
+			str, SmallDump, HeapDump, FullDump)
+	}
+}
+
+func (o DumpType) flags() uintptr {
+	switch o {
+	case SmallDump:
+		return miniDumpWithDataSegs | miniDumpWithIndirectlyReferencedMem
+	case FullDump:
+		return miniDumpWithFullMemory | miniDumpWithFullMemoryInfo
+	default:
+		return miniDumpWithDataSegs | miniDumpWithPrivateReadWriteMemory |
+			miniDumpWithIndirectlyReferencedMem
+	}
+}
+
+// WriteMiniDump writes a minidump of the process to file.
+//
+// The process handle must be opened with
+// windows.PROCESS_VM_READ | windows.PROCESS_QUERY_INFORMATION
+func WriteMiniDump(process syscall.Handle, pid uint32, file syscall.Handle, dumpType DumpType) error {
+	ok, _, err := pMiniDumpWriteDump.Call(
+		uintptr(process),
+		uintptr(pid),
+		uintptr(file),
+		dumpType.flags(),
+		0,
+		0,
+		0)
+	if ok == 0 {
+		return fmt.Errorf("failed to write minidump - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package hexview formats memory as an editable hex dump
+// and parses edited hex dumps.
+package hexview
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BytesPerLine is the number of bytes displayed on each line.
+const BytesPerLine = 16
+
+// commentPrefix starts lines that are ignored by Parse.
+const commentPrefix = "#"
+
+// Write writes data, which starts at addr, to w as a hex dump.
+// Each line contains an address, up to BytesPerLine hex-encoded
+// bytes, and the bytes' printable ASCII characters. header is
+// written first as comment lines.
+func Write(w io.Writer, addr uintptr, data []byte, header []string) error {
+	bw := bufio.NewWriter(w)
+
+	for _, line := range header {
+		fmt.Fprintf(bw, "%s %s\r\n", commentPrefix, line)
+	}
+
+	for i := 0; i < len(data); i += BytesPerLine {
+		end := i + BytesPerLine
+		if end > len(data) {
+			end = len(data)
+		}
+
+		line := data[i:end]
+
+		fmt.Fprintf(bw, "%016X ", addr+uintptr(i))
+		for j := 0; j < BytesPerLine; j++ {
+			if j%8 == 0 {
+				bw.WriteByte(' ')
+			}
+
+			if j < len(line) {
+				fmt.Fprintf(bw, "%02X ", line[j])
+			} else {
+				bw.WriteString("   ")
+			}
+		}
+
+		bw.WriteString(" |")
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7f {
+				bw.WriteByte(b)
+			} else {
+				bw.WriteByte('.')
+			}
+		}
+		bw.WriteString("|\r\n")
+	}
+
+	return bw.Flush()
+}
+
+// Parse parses a hex dump created by Write. Only the address and
+// hex columns are used, so the ASCII column does not need to be
+// updated when bytes are edited. The lines must be contiguous.
+func Parse(r io.Reader) (uintptr, []byte, error) {
+	var start uintptr
+	var data []byte
+	lineNum := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+
+		if i := strings.Index(line, "|"); i > -1 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0, nil, fmt.Errorf("line %d: missing address or bytes", lineNum)
+		}
+
+		addr, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("line %d: failed to parse address - %w", lineNum, err)
+		}
+
+		if data == nil {
+			start = uintptr(addr)
+		} else if uintptr(addr) != start+uintptr(len(data)) {
+			return 0, nil, fmt.Errorf("line %d: expected address %X, got %X",
+				lineNum, start+uintptr(len(data)), addr)
+		}
+
+		for _, field := range fields[1:] {
+			if len(field) != 2 {
+				return 0, nil, fmt.Errorf("line %d: invalid byte: %q", lineNum, field)
+			}
+
+			b, err := hex.DecodeString(field)
+			if err != nil {
+				return 0, nil, fmt.Errorf("line %d: invalid byte: %q", lineNum, field)
+			}
+
+			data = append(data, b[0])
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return start, data, nil
+}
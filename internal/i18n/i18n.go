@@ -0,0 +1,103 @@
+// Package i18n provides translations for user-facing strings.
+//
+// Messages are identified by their English text, which also serves
+// as the fallback when the current language does not have a translation.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language used when no language is configured.
+const DefaultLanguage = "en"
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLanguage
+)
+
+// Message is a translatable string. Its value is the English text
+// of the message, which may contain fmt verbs.
+type Message string
+
+// SetLanguage sets the language used by T, Sprintf, and Errorf.
+//
+// An error is returned if the language is not supported, in which
+// case the current language is left unchanged.
+func SetLanguage(language string) error {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	if !IsSupported(language) {
+		return fmt.Errorf("unsupported language: %q (supported languages are: %s)",
+			language, strings.Join(Languages(), ", "))
+	}
+
+	mu.Lock()
+	current = language
+	mu.Unlock()
+
+	return nil
+}
+
+// Language returns the current language.
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return current
+}
+
+// IsSupported returns true if the language has a message catalog.
+func IsSupported(language string) bool {
+	if language == DefaultLanguage {
+		return true
+	}
+
+	_, hasIt := catalogs[language]
+	return hasIt
+}
+
+// Languages returns the supported languages in sorted order.
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+
+	return languages
+}
+
+// T returns the translation of msg in the current language, falling
+// back to the English text if no translation exists.
+func T(msg Message) string {
+	mu.RLock()
+	language := current
+	mu.RUnlock()
+
+	translated, hasIt := catalogs[language][msg]
+	if !hasIt {
+		return string(msg)
+	}
+
+	return translated
+}
+
+// Sprintf is like fmt.Sprintf, but uses the translation of msg
+// as the format string.
+func Sprintf(msg Message, a ...interface{}) string {
+	return fmt.Sprintf(T(msg), a...)
+}
+
+// Errorf is like fmt.Errorf, but uses the translation of msg
+// as the format string.
+func Errorf(msg Message, a ...interface{}) error {
+	return fmt.Errorf(T(msg), a...)
+}
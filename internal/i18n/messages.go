@@ -0,0 +1,158 @@
+package i18n
+
+// Tray menu strings.
+const (
+	ProgramsMenu              Message = "Programs"
+	ErrorLogMenu              Message = "Error Log"
+	ClearLogMenu              Message = "Clear"
+	ClearLogMenuTooltip       Message = "Remove every entry from the error log"
+	RepeatedLogEntry          Message = "%s (x%d)"
+	QuitMenu                  Message = "Quit"
+	QuitMenuTooltip           Message = "Quit the application"
+	SafeModeMenu              Message = "Safe mode (writers disabled)"
+	ExportSessionMenu         Message = "Export session log"
+	ExportSessionMenuTooltip  Message = "Save a timeline of this session's actions to a file"
+	ExportSessionJSONMenu     Message = "As JSON"
+	ExportSessionCSVMenu      Message = "As CSV"
+	SupportBundleMenu         Message = "Create support bundle"
+	SupportBundleMenuTooltip  Message = "Save logs, configs, and system information to a zip file for bug reports"
+	SupportBundleRedactedMenu Message = "Redact offsets"
+	SupportBundleOffsetsMenu  Message = "Include offsets"
+	CopyAddressMenu           Message = "Copy resolved address"
+	OpenInToolMenu            Message = "Open in %s"
+	ViewMemoryMenu            Message = "View memory"
+	SaveDumpMenu              Message = "Save memory dump"
+	SaveDumpMenuTooltip       Message = "Save a minidump of the program for offline testing or bug reports"
+	CopyTokenMenu             Message = "Copy API token"
+	CopyTokenMenuTooltip      Message = "Copy the token that clients must use to connect to blaj"
+	RestoreBackupMenu         Message = "Restore config backup"
+	RestoreBackupMenuTooltip  Message = "Roll a config file back to an earlier version"
+	RestoreBackupItemTooltip  Message = "Replace the config file with this version and reload"
+	NoBackupsMenu             Message = "No backups"
+	SyncNowMenu               Message = "Sync now"
+	SyncNowMenuTooltip        Message = "Sync configs and saved states with remote storage"
+	EnabledProgramsMenu       Message = "Enabled programs"
+	EnabledProgramsTooltip    Message = "Uncheck a program to disable its config without editing it"
+	EnabledProgramTooltip     Message = "Enable or disable this config and reload"
+	ReportErrorMenu           Message = "Report this"
+	ReportErrorMenuTooltip    Message = "Open a GitHub issue about this error (offsets are not included)"
+	WriterOn                  Message = "ON"
+	WriterOff                 Message = "OFF"
+	CompareResultMenu         Message = "%s: %d of %d values equal the saved state"
+	CompareNoStateMenu        Message = "%s: no state has been saved"
+	UnhealthyPointersMenu     Message = "⚠ Unhealthy pointers: %d"
+	TooltipAttached           Message = "%d attached"
+	TooltipError              Message = "%d error"
+	TooltipErrors             Message = "%d errors"
+	SessionTooltip            Message = "Attached for %s (%s in total)"
+)
+
+// Common error messages.
+const (
+	ErrHomeDir       Message = "failed to get user home dir - %w"
+	ErrMakeConfigDir Message = "failed to make config directory at '%s' - %w"
+	ErrOpenLogFile   Message = "failed to open log file - %w"
+	ErrReadConfigDir Message = "failed to read config directory - %w"
+	ErrProgramConfig Message = "failed to create program config from path - %w"
+	ErrNoConfigFiles Message = "no .conf files found in %s"
+	ErrProgramExited Message = "%s exited - %w"
+)
+
+var catalogs = map[string]map[Message]string{
+	"ja": {
+		ProgramsMenu:              "プログラム",
+		ErrorLogMenu:              "エラーログ",
+		ClearLogMenu:              "クリア",
+		ClearLogMenuTooltip:       "エラーログのすべての項目を削除する",
+		RepeatedLogEntry:          "%s (%d回)",
+		QuitMenu:                  "終了",
+		QuitMenuTooltip:           "アプリケーションを終了する",
+		ExportSessionMenu:         "セッションログをエクスポート",
+		ExportSessionMenuTooltip:  "このセッションの操作履歴をファイルに保存する",
+		ExportSessionJSONMenu:     "JSON形式",
+		ExportSessionCSVMenu:      "CSV形式",
+		SupportBundleMenu:         "サポートバンドルを作成",
+		SupportBundleMenuTooltip:  "バグ報告用にログ、設定、システム情報をzipファイルに保存する",
+		SupportBundleRedactedMenu: "オフセットを伏せる",
+		SupportBundleOffsetsMenu:  "オフセットを含める",
+		CopyAddressMenu:           "解決済みアドレスをコピー",
+		OpenInToolMenu:            "%s で開く",
+		ViewMemoryMenu:            "メモリを表示",
+		SaveDumpMenu:              "メモリダンプを保存",
+		SaveDumpMenuTooltip:       "オフラインテストやバグ報告用にミニダンプを保存する",
+		CopyTokenMenu:             "APIトークンをコピー",
+		CopyTokenMenuTooltip:      "blaj に接続するためのトークンをコピーする",
+		RestoreBackupMenu:         "設定のバックアップを復元",
+		RestoreBackupMenuTooltip:  "設定ファイルを以前のバージョンに戻す",
+		RestoreBackupItemTooltip:  "設定ファイルをこのバージョンに置き換えて再読み込みする",
+		NoBackupsMenu:             "バックアップなし",
+		SyncNowMenu:               "今すぐ同期",
+		SyncNowMenuTooltip:        "設定と保存した状態をリモートストレージと同期する",
+		EnabledProgramsMenu:       "有効なプログラム",
+		EnabledProgramsTooltip:    "チェックを外すと、設定ファイルを編集せずに無効にできます",
+		EnabledProgramTooltip:     "この設定を有効または無効にして再読み込みする",
+		ReportErrorMenu:           "報告する",
+		WriterOn:                  "オン",
+		WriterOff:                 "オフ",
+		CompareResultMenu:         "%s: %d / %d 個の値が保存した状態と一致",
+		CompareNoStateMenu:        "%s: 保存された状態がありません",
+		UnhealthyPointersMenu:     "⚠ 異常なポインタ: %d",
+		SessionTooltip:            "接続時間: %s (合計 %s)",
+		ReportErrorMenuTooltip:    "このエラーについてGitHubのissueを開く (オフセットは含まれません)",
+		ErrHomeDir:                "ユーザーのホームディレクトリを取得できませんでした - %w",
+		ErrMakeConfigDir:          "設定ディレクトリ '%s' を作成できませんでした - %w",
+		ErrOpenLogFile:            "ログファイルを開けませんでした - %w",
+		ErrReadConfigDir:          "設定ディレクトリを読み込めませんでした - %w",
+		ErrProgramConfig:          "設定ファイルを読み込めませんでした - %w",
+		ErrNoConfigFiles:          "%s に .conf ファイルが見つかりません",
+		ErrProgramExited:          "%s が終了しました - %w",
+	},
+	"ko": {
+		ProgramsMenu:              "프로그램",
+		ErrorLogMenu:              "오류 로그",
+		ClearLogMenu:              "지우기",
+		ClearLogMenuTooltip:       "오류 로그의 모든 항목을 삭제",
+		RepeatedLogEntry:          "%s (%d회)",
+		QuitMenu:                  "종료",
+		QuitMenuTooltip:           "애플리케이션 종료",
+		ExportSessionMenu:         "세션 로그 내보내기",
+		ExportSessionMenuTooltip:  "이번 세션의 작업 기록을 파일로 저장",
+		ExportSessionJSONMenu:     "JSON 형식",
+		ExportSessionCSVMenu:      "CSV 형식",
+		SupportBundleMenu:         "지원 번들 만들기",
+		SupportBundleMenuTooltip:  "버그 보고를 위해 로그, 설정, 시스템 정보를 zip 파일로 저장",
+		SupportBundleRedactedMenu: "오프셋 가리기",
+		SupportBundleOffsetsMenu:  "오프셋 포함",
+		CopyAddressMenu:           "해석된 주소 복사",
+		OpenInToolMenu:            "%s에서 열기",
+		ViewMemoryMenu:            "메모리 보기",
+		SaveDumpMenu:              "메모리 덤프 저장",
+		SaveDumpMenuTooltip:       "오프라인 테스트나 버그 보고를 위해 미니덤프를 저장",
+		CopyTokenMenu:             "API 토큰 복사",
+		CopyTokenMenuTooltip:      "blaj에 연결할 때 사용하는 토큰을 복사",
+		RestoreBackupMenu:         "설정 백업 복원",
+		RestoreBackupMenuTooltip:  "설정 파일을 이전 버전으로 되돌리기",
+		RestoreBackupItemTooltip:  "설정 파일을 이 버전으로 바꾸고 다시 불러오기",
+		NoBackupsMenu:             "백업 없음",
+		SyncNowMenu:               "지금 동기화",
+		SyncNowMenuTooltip:        "설정과 저장된 상태를 원격 저장소와 동기화",
+		EnabledProgramsMenu:       "활성화된 프로그램",
+		EnabledProgramsTooltip:    "체크를 해제하면 설정 파일을 편집하지 않고 비활성화합니다",
+		EnabledProgramTooltip:     "이 설정을 활성화 또는 비활성화하고 다시 불러오기",
+		ReportErrorMenu:           "신고하기",
+		WriterOn:                  "켜짐",
+		WriterOff:                 "꺼짐",
+		CompareResultMenu:         "%s: %d / %d개의 값이 저장된 상태와 일치",
+		CompareNoStateMenu:        "%s: 저장된 상태가 없음",
+		UnhealthyPointersMenu:     "⚠ 비정상 포인터: %d",
+		SessionTooltip:            "연결 시간: %s (총 %s)",
+		ReportErrorMenuTooltip:    "이 오류에 대한 GitHub 이슈 열기 (오프셋은 포함되지 않음)",
+		ErrHomeDir:                "사용자 홈 디렉터리를 가져오지 못했습니다 - %w",
+		ErrMakeConfigDir:          "설정 디렉터리 '%s'을(를) 만들지 못했습니다 - %w",
+		ErrOpenLogFile:            "로그 파일을 열지 못했습니다 - %w",
+		ErrReadConfigDir:          "설정 디렉터리를 읽지 못했습니다 - %w",
+		ErrProgramConfig:          "설정 파일을 불러오지 못했습니다 - %w",
+		ErrNoConfigFiles:          "%s에서 .conf 파일을 찾을 수 없습니다",
+		ErrProgramExited:          "%s이(가) 종료되었습니다 - %w",
+	},
+}
@@ -0,0 +1,93 @@
+package icon
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+var (
+	badgeColor     = color.NRGBA{R: 0xd9, G: 0x1e, B: 0x2b, A: 0xff}
+	badgeTextColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// glyphs is a 3x5 pixel font used to draw badge text.
+// Each string is one row, where '#' is a set pixel.
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+}
+
+// WithBadge returns a size x size copy of img with a circular badge
+// containing n drawn in the bottom-right corner. Numbers larger than
+// nine are drawn as a plus sign.
+func WithBadge(img image.Image, size int, n int) *image.NRGBA {
+	dst := Resize(img, size)
+
+	glyph := '+'
+	if n >= 0 && n <= 9 {
+		glyph = rune('0' + n)
+	}
+
+	// The badge covers a little over half of the icon so that
+	// the number remains legible at 16x16.
+	diameter := size * 9 / 16
+	if diameter < 9 {
+		diameter = 9
+	}
+
+	badge := image.Rect(size-diameter, size-diameter, size, size)
+	drawCircle(dst, badge, badgeColor)
+
+	scale := diameter / 7
+	if scale < 1 {
+		scale = 1
+	}
+
+	textWidth := 3 * scale
+	textHeight := 5 * scale
+	origin := image.Pt(
+		badge.Min.X+(diameter-textWidth)/2,
+		badge.Min.Y+(diameter-textHeight)/2)
+
+	rows := glyphs[glyph]
+	for y, row := range rows {
+		for x, pixel := range row {
+			if pixel != '#' {
+				continue
+			}
+
+			r := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale).Add(origin)
+			draw.Draw(dst, r, image.NewUniform(badgeTextColor), image.Point{}, draw.Src)
+		}
+	}
+
+	return dst
+}
+
+func drawCircle(dst draw.Image, r image.Rectangle, c color.Color) {
+	// Work in doubled coordinates to keep the circle
+	// centered on even diameters.
+	cx := r.Min.X*2 + r.Dx()
+	cy := r.Min.Y*2 + r.Dy()
+	radius := r.Dx()
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dx := x*2 + 1 - cx
+			dy := y*2 + 1 - cy
+			if dx*dx+dy*dy <= radius*radius {
+				dst.Set(x, y, c)
+			}
+		}
+	}
+}
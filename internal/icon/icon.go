@@ -0,0 +1,244 @@
+// Package icon decodes, composes, and encodes Windows .ico files.
+package icon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// icoDirEntry is an entry in an .ico file's image directory.
+//
+// Refer to the following document for more information:
+// https://learn.microsoft.com/en-us/previous-versions/ms997538(v=msdn.10)
+type icoDirEntry struct {
+	Width      uint8
+	Height     uint8
+	ColorCount uint8
+	Reserved   uint8
+	Planes     uint16
+	BitCount   uint16
+	BytesInRes uint32
+	ImageOff   uint32
+}
+
+type icoHeader struct {
+	Reserved uint16
+	Type     uint16
+	Count    uint16
+}
+
+// Decode decodes the largest image contained in an .ico file.
+//
+// Only PNG and 32-bit uncompressed bitmap images are supported.
+func Decode(ico []byte) (image.Image, error) {
+	r := bytes.NewReader(ico)
+
+	var header icoHeader
+	err := binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ico header - %w", err)
+	}
+
+	if header.Type != 1 || header.Count == 0 {
+		return nil, errors.New("data is not an ico file")
+	}
+
+	var largest icoDirEntry
+	largestSize := -1
+	for i := 0; i < int(header.Count); i++ {
+		var entry icoDirEntry
+		err := binary.Read(r, binary.LittleEndian, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ico directory entry %d - %w", i, err)
+		}
+
+		size := int(entry.Width)
+		if size == 0 {
+			size = 256
+		}
+
+		if size > largestSize {
+			largest = entry
+			largestSize = size
+		}
+	}
+
+	end := uint64(largest.ImageOff) + uint64(largest.BytesInRes)
+	if end > uint64(len(ico)) {
+		return nil, errors.New("ico image data is out of bounds")
+	}
+
+	data := ico[largest.ImageOff:end]
+	if bytes.HasPrefix(data, []byte("\x89PNG")) {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode png ico image - %w", err)
+		}
+
+		return img, nil
+	}
+
+	img, err := decodeBitmap(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bitmap ico image - %w", err)
+	}
+
+	return img, nil
+}
+
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+// decodeBitmap decodes a 32-bit BGRA bitmap as stored in an .ico file
+// (i.e., a BITMAPINFOHEADER followed by bottom-up rows).
+func decodeBitmap(data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+
+	var info bitmapInfoHeader
+	err := binary.Read(r, binary.LittleEndian, &info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bitmap header - %w", err)
+	}
+
+	if info.BitCount != 32 || info.Compression != 0 {
+		return nil, fmt.Errorf("unsupported bitmap format (%d bits per pixel, compression %d)",
+			info.BitCount, info.Compression)
+	}
+
+	// The height includes the AND mask, which is unused
+	// for 32-bit images.
+	width := int(info.Width)
+	height := int(info.Height) / 2
+	if width <= 0 || height <= 0 || width > 256 || height > 256 {
+		return nil, fmt.Errorf("invalid bitmap dimensions %dx%d", width, height)
+	}
+
+	pixels := data[info.Size:]
+	if len(pixels) < width*height*4 {
+		return nil, errors.New("bitmap pixel data is truncated")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := pixels[(height-1-y)*width*4:]
+		for x := 0; x < width; x++ {
+			p := row[x*4 : x*4+4]
+			img.SetNRGBA(x, y, color.NRGBA{R: p[2], G: p[1], B: p[0], A: p[3]})
+		}
+	}
+
+	return img, nil
+}
+
+// Encode encodes the provided images as an .ico file. Each image
+// is stored as a PNG, which is supported by Windows Vista and newer.
+func Encode(images ...image.Image) ([]byte, error) {
+	if len(images) == 0 {
+		return nil, errors.New("no images were provided")
+	}
+
+	pngs := make([][]byte, len(images))
+	for i, img := range images {
+		bounds := img.Bounds()
+		if bounds.Dx() > 256 || bounds.Dy() > 256 {
+			return nil, fmt.Errorf("image %d is larger than 256x256", i)
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := png.Encode(buf, img)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode image %d as png - %w", i, err)
+		}
+
+		pngs[i] = buf.Bytes()
+	}
+
+	out := bytes.NewBuffer(nil)
+	_ = binary.Write(out, binary.LittleEndian, icoHeader{
+		Type:  1,
+		Count: uint16(len(images)),
+	})
+
+	offset := 6 + 16*len(images)
+	for i, img := range images {
+		bounds := img.Bounds()
+
+		_ = binary.Write(out, binary.LittleEndian, icoDirEntry{
+			// A value of zero means 256 pixels.
+			Width:      uint8(bounds.Dx()),
+			Height:     uint8(bounds.Dy()),
+			Planes:     1,
+			BitCount:   32,
+			BytesInRes: uint32(len(pngs[i])),
+			ImageOff:   uint32(offset),
+		})
+
+		offset += len(pngs[i])
+	}
+
+	for _, data := range pngs {
+		out.Write(data)
+	}
+
+	return out.Bytes(), nil
+}
+
+// Resize scales img to a size x size square using an area average.
+func Resize(img image.Image, size int) *image.NRGBA {
+	src := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+
+	for dy := 0; dy < size; dy++ {
+		y0 := src.Min.Y + dy*src.Dy()/size
+		y1 := src.Min.Y + (dy+1)*src.Dy()/size
+		if y1 == y0 {
+			y1++
+		}
+
+		for dx := 0; dx < size; dx++ {
+			x0 := src.Min.X + dx*src.Dx()/size
+			x1 := src.Min.X + (dx+1)*src.Dx()/size
+			if x1 == x0 {
+				x1++
+			}
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					// RGBA returns alpha-premultiplied values.
+					pr, pg, pb, pa := img.At(x, y).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
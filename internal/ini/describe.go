@@ -0,0 +1,183 @@
+package ini
+
+import (
+	"strings"
+)
+
+// SchemaDescriber is optionally implemented by a Schema to
+// describe the sections that it accepts.
+//
+// Descriptions let programs generate documentation and forms
+// for a Schema. The parser uses them to suggest the name of a
+// known section when it encounters an unknown section.
+type SchemaDescriber interface {
+	// DescribeSections returns a description of each section
+	// that the Schema accepts, in the order that they should
+	// be presented to users.
+	DescribeSections() []SectionDescription
+}
+
+// SectionDescription describes a section.
+type SectionDescription struct {
+	// Name is the section's name as it should be written
+	// in an INI blob (e.g. "SaveRestore").
+	Name string
+
+	// Help is a short description of the section.
+	Help string
+}
+
+// ParamDescriber is optionally implemented by a SectionSchema
+// to describe the parameters that it accepts.
+//
+// The parser uses the descriptions to suggest the name of a
+// known parameter when it encounters an unknown parameter.
+type ParamDescriber interface {
+	// DescribeParams returns a description of each parameter
+	// that the section accepts, in the order that they should
+	// be presented to users.
+	DescribeParams() []ParamDescription
+}
+
+// ParamDescription describes a parameter.
+type ParamDescription struct {
+	// Name is the parameter's name as it should be written
+	// in an INI blob. Angle brackets denote a part of the
+	// name that the user chooses (e.g. "<nickname>Label").
+	Name string
+
+	// Example is a parameter name that matches Name. It must
+	// be set if Name contains a part that the user chooses.
+	Example string
+
+	// Type describes the parameter's value (e.g. "string").
+	Type string
+
+	// Default is the parameter's default value, if any.
+	Default string
+
+	// Help is a short description of the parameter.
+	Help string
+
+	// Required is true if the parameter is required. It must be
+	// set for the parameters returned by SectionSchema.RequiredParams,
+	// and for parameters that are required but are not returned by it,
+	// such as parameters whose names are chosen by the user.
+	Required bool
+}
+
+// ExampleName returns Example if it is set, or Name otherwise.
+func (o ParamDescription) ExampleName() string {
+	if o.Example != "" {
+		return o.Example
+	}
+
+	return o.Name
+}
+
+// hasPlaceholder returns true if part of the
+// parameter's name is chosen by the user.
+func (o ParamDescription) hasPlaceholder() bool {
+	return strings.Contains(o.Name, "<")
+}
+
+// suggestSection returns the name of the section described by
+// schema that is most similar to name, or an empty string if no
+// section is similar enough.
+func suggestSection(schema Schema, name string) string {
+	describer, isDescriber := schema.(SchemaDescriber)
+	if !isDescriber {
+		return ""
+	}
+
+	var candidates []string
+	for _, section := range describer.DescribeSections() {
+		candidates = append(candidates, section.Name)
+	}
+
+	return closestName(name, candidates)
+}
+
+// suggestParam returns the name of the parameter described by
+// section that is most similar to name, or an empty string if no
+// parameter is similar enough. Parameters whose names are chosen
+// by the user are not suggested.
+func suggestParam(section SectionSchema, name string) string {
+	describer, isDescriber := section.(ParamDescriber)
+	if !isDescriber {
+		return ""
+	}
+
+	var candidates []string
+	for _, param := range describer.DescribeParams() {
+		if !param.hasPlaceholder() {
+			candidates = append(candidates, param.Name)
+		}
+	}
+
+	return closestName(name, candidates)
+}
+
+// closestName returns the candidate with the smallest case-insensitive
+// edit distance from name. An empty string is returned if the closest
+// candidate differs by more than a third of its length, since it is
+// unlikely to be what the user meant.
+func closestName(name string, candidates []string) string {
+	name = strings.ToLower(name)
+
+	best := ""
+	bestDistance := -1
+
+	for _, candidate := range candidates {
+		distance := editDistance(name, strings.ToLower(candidate))
+		if distance > len(candidate)/3 {
+			continue
+		}
+
+		if bestDistance == -1 || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
@@ -0,0 +1,88 @@
+package ini
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SetParam returns data, the contents of an INI blob, with the first
+// parameter named by paramName in the first section named by sectionName
+// set to value. Names are compared case-insensitively.
+//
+// If the section does not have the parameter, it is added after the
+// section's header. If the section does not exist, it is appended to
+// data. Unlike INI.String, the rest of data (including comments) is
+// left as is.
+func SetParam(data []byte, sectionName string, paramName string, value string) []byte {
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+
+	paramLine := []byte(paramName + " = " + value + newline)
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	headerIndex := -1
+
+	for i, line := range lines {
+		withoutSpaces := bytes.TrimSpace(line)
+
+		if len(withoutSpaces) == 0 || withoutSpaces[0] == '#' {
+			continue
+		}
+
+		if withoutSpaces[0] == '[' {
+			if headerIndex > -1 {
+				// End of the section.
+				break
+			}
+
+			header, err := parseSectionLine(withoutSpaces)
+			if err == nil && strings.EqualFold(header, sectionName) {
+				headerIndex = i
+			}
+
+			continue
+		}
+
+		if headerIndex < 0 {
+			continue
+		}
+
+		name, _, err := parseParamLine(withoutSpaces)
+		if err == nil && strings.EqualFold(name, paramName) {
+			lines[i] = paramLine
+			return bytes.Join(lines, nil)
+		}
+	}
+
+	if headerIndex > -1 {
+		header := lines[headerIndex]
+		if !bytes.HasSuffix(header, []byte("\n")) {
+			header = append(header[:len(header):len(header)], newline...)
+		}
+
+		edited := make([][]byte, 0, len(lines)+1)
+		edited = append(edited, lines[:headerIndex]...)
+		edited = append(edited, header, paramLine)
+		edited = append(edited, lines[headerIndex+1:]...)
+
+		return bytes.Join(edited, nil)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data)
+
+	if len(data) > 0 {
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			buf.WriteString(newline)
+		}
+
+		buf.WriteString(newline)
+	}
+
+	buf.WriteString("[" + sectionName + "]" + newline)
+	buf.Write(paramLine)
+
+	return buf.Bytes()
+}
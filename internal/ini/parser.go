@@ -51,6 +51,29 @@ type Schema interface {
 	Validate() error
 }
 
+// UnknownHandler is optionally implemented by a Schema to decide
+// how unknown global parameters, sections, and parameters are
+// handled when ParserRules do not allow them. It is called while
+// parsing, so its decision may depend on parameters that were
+// parsed earlier (e.g. a global parameter that disables strict
+// parsing).
+type UnknownHandler interface {
+	// OnUnknown receives the error describing the unknown entity.
+	// Returning a nil error ignores the entity (and the parameters
+	// of an unknown section). Returning a non-nil error stops
+	// parsing.
+	OnUnknown(err error) error
+}
+
+// Includer is optionally implemented by a Schema to support
+// including the sections of other INI blobs (see
+// ParserRules.IncludeParam).
+type Includer interface {
+	// OnInclude returns the INI blob identified by the
+	// value of an include parameter.
+	OnInclude(value string) (io.Reader, error)
+}
+
 // ParserRules tells the parser how to handle several possible
 // scenarios while parsing an INI blob.
 type ParserRules struct {
@@ -91,6 +114,29 @@ type ParserRules struct {
 	//
 	// A nil slice means no sections are required.
 	RequiredSections []string
+
+	// IncludeParam is the name of a global parameter whose value
+	// identifies an INI blob to include. The Schema must implement
+	// Includer. The included blob's sections are parsed as if they
+	// appeared before the first section of the including blob.
+	// Included blobs cannot contain global parameters.
+	//
+	// An empty string disables includes.
+	IncludeParam string
+
+	// MaxLineSize is the maximum length of a line in bytes.
+	// Lines exceeding this length cause the parser to fail.
+	//
+	// A value of zero means bufio.MaxScanTokenSize (64 KiB).
+	MaxLineSize int
+
+	// AllowSectionNames allows section headers to name the
+	// section after its type (e.g. [SaveRestore "boss2"]).
+	// The section's type is passed to Schema.OnSection as the
+	// section name and the unmodified header is passed as the
+	// canonical name, from which SectionInstanceName extracts
+	// the section's name.
+	AllowSectionNames bool
 }
 
 // SchemaRule configures individual schema requirements.
@@ -164,7 +210,12 @@ type parser struct {
 	rules        ParserRules
 	mangleNameFn func(name string) string
 
+	// included is true if the parser is parsing
+	// an included blob.
+	included bool
+
 	line            int
+	sawSection      bool
 	currSectionLine int
 	currSectionName string
 	currSectionObj  SectionSchema
@@ -175,7 +226,35 @@ type parser struct {
 }
 
 func (o *parser) parse(r io.Reader) error {
+	err := o.parseLines(r)
+	if err != nil {
+		return err
+	}
+
+	for _, required := range o.rules.RequiredSections {
+		_, hasIt := o.seenSections[required]
+		if !hasIt {
+			return fmt.Errorf("missing required section: %q", required)
+		}
+	}
+
+	err = o.schema.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate config - %w", err)
+	}
+
+	return nil
+}
+
+// parseLines parses each line of r and validates the last section.
+func (o *parser) parseLines(r io.Reader) error {
+	maxLineSize := o.rules.MaxLineSize
+	if maxLineSize <= 0 {
+		maxLineSize = bufio.MaxScanTokenSize
+	}
+
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
 
 	for scanner.Scan() {
 		o.line++
@@ -187,7 +266,7 @@ func (o *parser) parse(r io.Reader) error {
 		}
 
 		if withoutSpaces[0] == '[' {
-			if len(o.seenSections) == 0 {
+			if !o.sawSection && !o.included {
 				// Global params finished.
 				for _, required := range o.rules.RequiredGlobalParams {
 					_, hasIt := o.seenGlobals[required]
@@ -198,6 +277,8 @@ func (o *parser) parse(r io.Reader) error {
 				}
 			}
 
+			o.sawSection = true
+
 			err := o.startSection(withoutSpaces)
 			if err != nil {
 				return err
@@ -206,7 +287,7 @@ func (o *parser) parse(r io.Reader) error {
 			continue
 		}
 
-		if len(o.seenSections) > 0 && o.currSectionObj == nil {
+		if o.sawSection && o.currSectionObj == nil {
 			// Unknown section which was permitted by user.
 			continue
 		}
@@ -233,39 +314,36 @@ func (o *parser) parse(r io.Reader) error {
 
 	err := scanner.Err()
 	if err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line %d - line is too long (maximum is %d bytes)",
+				o.line+1, maxLineSize)
+		}
+
 		return err
 	}
 
 	// This is needed because the final section will not
 	// fall down the code path leading to the validation
 	// function.
-	err = o.validateCurrentSection()
-	if err != nil {
-		return err
-	}
-
-	for _, required := range o.rules.RequiredSections {
-		_, hasIt := o.seenSections[required]
-		if !hasIt {
-			return fmt.Errorf("missing required section: %q", required)
-		}
-	}
-
-	err = o.schema.Validate()
-	if err != nil {
-		return fmt.Errorf("failed to validate config - %w", err)
-	}
-
-	return nil
+	return o.validateCurrentSection()
 }
 
 func (o *parser) startSection(withoutSpaces []byte) error {
-	name, err := parseSectionLine(withoutSpaces)
+	header, err := parseSectionLine(withoutSpaces)
 	if err != nil {
 		return fmt.Errorf("line %d - failed to parse section header - %w",
 			o.line, err)
 	}
 
+	name := header
+	if o.rules.AllowSectionNames {
+		name, _, err = splitSectionHeader(header)
+		if err != nil {
+			return fmt.Errorf("line %d - failed to parse section header - %w",
+				o.line, err)
+		}
+	}
+
 	mangledName := o.mangleNameFn(name)
 
 	o.seenSections[mangledName]++
@@ -276,15 +354,25 @@ func (o *parser) startSection(withoutSpaces []byte) error {
 		return err
 	}
 
-	newSectionFn, rule := o.schema.OnSection(mangledName, name)
+	newSectionFn, rule := o.schema.OnSection(mangledName, header)
 	if newSectionFn == nil {
 		if o.rules.AllowUnknownSections {
 			o.currSectionObj = nil
 			return nil
+		}
+
+		var err error
+		suggestion := suggestSection(o.schema, name)
+		if suggestion != "" {
+			err = fmt.Errorf("line %d - unknown section: %q (did you mean %q?)",
+				o.line, name, suggestion)
 		} else {
-			return fmt.Errorf("line %d - unknown section: %q",
+			err = fmt.Errorf("line %d - unknown section: %q",
 				o.line, name)
 		}
+
+		o.currSectionObj = nil
+		return o.onUnknown(err)
 	}
 
 	numInstances := o.seenSections[mangledName]
@@ -312,14 +400,26 @@ func (o *parser) startSection(withoutSpaces []byte) error {
 }
 
 func (o *parser) globalParam(mangledName string, paramName string, paramValue string) error {
+	if o.included {
+		return fmt.Errorf("line %d - global parameters are not supported in included files", o.line)
+	}
+
+	if o.rules.IncludeParam != "" && mangledName == o.rules.IncludeParam {
+		return o.include(paramValue)
+	}
+
 	if !o.rules.AllowGlobalParams {
 		return fmt.Errorf("line %d - global parameters are not supported", o.line)
 	}
 
 	paramSchemaFn, rule := o.schema.OnGlobalParam(mangledName)
-	if paramSchemaFn == nil && !o.rules.AllowUnknownGlobalParams {
-		return fmt.Errorf("line %d - unknown global parameter: %q",
-			o.line, paramName)
+	if paramSchemaFn == nil {
+		if o.rules.AllowUnknownGlobalParams {
+			return nil
+		}
+
+		return o.onUnknown(fmt.Errorf("line %d - unknown global parameter: %q",
+			o.line, paramName))
 	}
 
 	o.seenGlobals[mangledName]++
@@ -350,9 +450,19 @@ func (o *parser) globalParam(mangledName string, paramName string, paramValue st
 func (o *parser) param(mangledName string, paramName string, paramValue string) error {
 	paramSchemaFn, rule := o.currSectionObj.OnParam(mangledName)
 
-	if paramSchemaFn == nil && !o.rules.AllowUnknownParams {
-		return fmt.Errorf("line %d - unknown parameter: %q",
-			o.line, paramName)
+	if paramSchemaFn == nil {
+		if o.rules.AllowUnknownParams {
+			return nil
+		}
+
+		suggestion := suggestParam(o.currSectionObj, paramName)
+		if suggestion != "" {
+			return o.onUnknown(fmt.Errorf("line %d - unknown parameter: %q (did you mean %q?)",
+				o.line, paramName, suggestion))
+		}
+
+		return o.onUnknown(fmt.Errorf("line %d - unknown parameter: %q",
+			o.line, paramName))
 	}
 
 	o.seenCurrSectionParams[mangledName]++
@@ -380,6 +490,49 @@ func (o *parser) param(mangledName string, paramName string, paramValue string)
 	return nil
 }
 
+// include parses the sections of the INI blob identified by value.
+func (o *parser) include(value string) error {
+	includer, isIncluder := o.schema.(Includer)
+	if !isIncluder {
+		return fmt.Errorf("line %d - includes are not supported", o.line)
+	}
+
+	r, err := includer.OnInclude(value)
+	if err != nil {
+		return fmt.Errorf("line %d - failed to include %q - %w", o.line, value, err)
+	}
+
+	// The included blob shares the including blob's
+	// section counts so that section limits apply
+	// across both.
+	sub := &parser{
+		schema:       o.schema,
+		rules:        o.rules,
+		mangleNameFn: o.mangleNameFn,
+		included:     true,
+		seenGlobals:  make(map[string]int),
+		seenSections: o.seenSections,
+	}
+
+	err = sub.parseLines(r)
+	if err != nil {
+		return fmt.Errorf("line %d - failed to parse %q - %w", o.line, value, err)
+	}
+
+	return nil
+}
+
+// onUnknown returns err unless the schema's
+// UnknownHandler decides to ignore it.
+func (o *parser) onUnknown(err error) error {
+	handler, isHandler := o.schema.(UnknownHandler)
+	if !isHandler {
+		return err
+	}
+
+	return handler.OnUnknown(err)
+}
+
 func (o *parser) validateCurrentSection() error {
 	if o.currSectionObj == nil {
 		return nil
@@ -424,6 +577,41 @@ func parseSectionLine(line []byte) (string, error) {
 	return string(line), nil
 }
 
+// SectionInstanceName returns the name of a named section (e.g.
+// "boss2" for [SaveRestore "boss2"]) given the canonical section
+// name that is passed to Schema.OnSection. An empty string is
+// returned if the section is not named.
+func SectionInstanceName(canonicalName string) string {
+	_, instanceName, _ := splitSectionHeader(canonicalName)
+	return instanceName
+}
+
+// splitSectionHeader splits the contents of a section header
+// into the section's type and its optional double-quoted name.
+func splitSectionHeader(header string) (string, string, error) {
+	sectionType, quotedName, hasName := strings.Cut(header, " ")
+	if !hasName {
+		return header, "", nil
+	}
+
+	quotedName = strings.TrimSpace(quotedName)
+	if len(quotedName) < 2 || quotedName[0] != '"' || quotedName[len(quotedName)-1] != '"' {
+		return "", "", fmt.Errorf("section name must be in double quotes (e.g. [%s \"name\"])",
+			sectionType)
+	}
+
+	instanceName := strings.TrimSpace(quotedName[1 : len(quotedName)-1])
+	if instanceName == "" {
+		return "", "", errors.New("section name is empty")
+	}
+
+	if strings.ContainsRune(instanceName, '"') {
+		return "", "", errors.New("section name cannot contain double quotes")
+	}
+
+	return sectionType, instanceName, nil
+}
+
 func parseParamLine(line []byte) (string, string, error) {
 	if !bytes.Contains(line, []byte{'='}) {
 		return string(line), "", nil
@@ -0,0 +1,124 @@
+package ini
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte("global = 1\n[Section]\nparam = value\n"))
+	f.Add([]byte("# comment\n[SaveRestore \"boss2\"]\nxPointer_4 = 0x1 0x2\n"))
+	f.Add([]byte("[\n]\n=\n[ \"\" ]\nparam\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		config, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		for _, section := range config.Sections {
+			if section.Name == "" {
+				t.Fatalf("parsed a section with an empty name from %q", data)
+			}
+		}
+	})
+}
+
+func FuzzParseSectionLine(f *testing.F) {
+	f.Add([]byte("[General]"))
+	f.Add([]byte("[SaveRestore \"boss2\"]"))
+	f.Add([]byte("[ ]"))
+	f.Add([]byte("["))
+
+	f.Fuzz(func(t *testing.T, line []byte) {
+		name, err := parseSectionLine(line)
+		if err != nil {
+			return
+		}
+
+		if name == "" || strings.TrimSpace(name) != name {
+			t.Fatalf("parsed invalid section name %q from %q", name, line)
+		}
+	})
+}
+
+func FuzzParseParamLine(f *testing.F) {
+	f.Add([]byte("param = value"))
+	f.Add([]byte("param = a = b"))
+	f.Add([]byte("param"))
+	f.Add([]byte(" = value"))
+
+	f.Fuzz(func(t *testing.T, line []byte) {
+		name, value, err := parseParamLine(line)
+		if err != nil || !bytes.ContainsRune(line, '=') {
+			return
+		}
+
+		if name == "" || strings.TrimSpace(name) != name {
+			t.Fatalf("parsed invalid param name %q from %q", name, line)
+		}
+
+		if value == "" || strings.TrimSpace(value) != value {
+			t.Fatalf("parsed invalid param value %q from %q", value, line)
+		}
+	})
+}
+
+func TestParseSectionNameWithSpaces(t *testing.T) {
+	config, err := Parse(strings.NewReader("[My Section]\na = b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Sections) != 1 || config.Sections[0].Name != "My Section" {
+		t.Fatalf("expected one section named %q - got %+v", "My Section", config.Sections)
+	}
+}
+
+// namedSectionsINI is an INI that allows named sections.
+type namedSectionsINI struct {
+	INI
+	headers []string
+}
+
+func (o *namedSectionsINI) Rules() ParserRules {
+	return ParserRules{
+		AllowSectionNames: true,
+	}
+}
+
+func (o *namedSectionsINI) OnSection(sectionName string, canonicalName string) (func() (SectionSchema, error), SchemaRule) {
+	o.headers = append(o.headers, canonicalName)
+
+	return o.INI.OnSection(sectionName, canonicalName)
+}
+
+func TestParseNamedSections(t *testing.T) {
+	config := &namedSectionsINI{}
+
+	err := ParseSchema(strings.NewReader("[Writer \"name\"]\na = b\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Sections) != 1 || config.Sections[0].Name != "Writer" {
+		t.Fatalf("expected one section named %q - got %+v", "Writer", config.Sections)
+	}
+
+	instanceName := SectionInstanceName(config.headers[0])
+	if instanceName != "name" {
+		t.Fatalf("expected section instance name %q - got %q", "name", instanceName)
+	}
+}
+
+func TestParseNamedSectionsUnquotedName(t *testing.T) {
+	err := ParseSchema(strings.NewReader("[Writer name]\na = b\n"), &namedSectionsINI{})
+	if err == nil {
+		t.Fatal("expected an error for an unquoted section name")
+	}
+
+	if !strings.Contains(err.Error(), "must be in double quotes") {
+		t.Fatalf("expected an unquoted name error - got %q", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package backend names the sources of keyboard input that package
+// input can use. It does not depend on Windows, so that config files
+// that select a backend can be parsed on any platform.
+package backend
+
+import (
+	"strings"
+)
+
+// Backend names.
+const (
+	// Hook receives input using a low-level keyboard hook
+	// (WH_KEYBOARD_LL). It is the default backend, and the
+	// only backend that supports suppressing events.
+	Hook = "hook"
+
+	// RawInput receives input using the Raw Input API
+	// (WM_INPUT), which is not affected by software that
+	// interferes with low-level hooks.
+	RawInput = "rawinput"
+)
+
+// IsBackend returns true if name is the name of a backend.
+// Names are case-insensitive.
+func IsBackend(name string) bool {
+	switch strings.ToLower(name) {
+	case Hook, RawInput:
+		return true
+	default:
+		return false
+	}
+}
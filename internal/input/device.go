@@ -0,0 +1,123 @@
+package input
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	pGetRawInputDeviceList  = user32.NewProc("GetRawInputDeviceList")
+	pGetRawInputDeviceInfoW = user32.NewProc("GetRawInputDeviceInfoW")
+)
+
+const ridiDeviceName = 0x20000007
+
+type rawInputDeviceList struct {
+	hDevice uintptr
+	dwType  uint32
+}
+
+var (
+	// deviceNames caches the name of each device handle
+	// that a keyboard event has been received from.
+	deviceNamesMu sync.Mutex
+	deviceNames   = make(map[uintptr]string)
+)
+
+// Keyboards returns the names of the keyboards that are connected,
+// which can be matched against KeyEvent.Device.
+func Keyboards() ([]string, error) {
+	var num uint32
+	ret, _, err := pGetRawInputDeviceList.Call(
+		0,
+		uintptr(unsafe.Pointer(&num)),
+		unsafe.Sizeof(rawInputDeviceList{}))
+	if int32(ret) == -1 {
+		return nil, fmt.Errorf("failed to get number of input devices - %w", err)
+	}
+
+	if num == 0 {
+		return nil, nil
+	}
+
+	devices := make([]rawInputDeviceList, num)
+	ret, _, err = pGetRawInputDeviceList.Call(
+		uintptr(unsafe.Pointer(&devices[0])),
+		uintptr(unsafe.Pointer(&num)),
+		unsafe.Sizeof(rawInputDeviceList{}))
+	if int32(ret) == -1 {
+		return nil, fmt.Errorf("failed to get input devices - %w", err)
+	}
+
+	var names []string
+	for _, device := range devices[:ret] {
+		if device.dwType != rimTypeKeyboard {
+			continue
+		}
+
+		name, err := rawDeviceName(device.hDevice)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// cachedDeviceName returns the name of the device identified by
+// hDevice, or an empty string if it cannot be determined (e.g.
+// the keyboard events sent by SendInput have no device).
+func cachedDeviceName(hDevice uintptr) string {
+	if hDevice == 0 {
+		return ""
+	}
+
+	deviceNamesMu.Lock()
+	defer deviceNamesMu.Unlock()
+
+	name, hasIt := deviceNames[hDevice]
+	if hasIt {
+		return name
+	}
+
+	// Failures are cached too so that the
+	// lookup is not repeated on every key.
+	name, _ = rawDeviceName(hDevice)
+	deviceNames[hDevice] = name
+
+	return name
+}
+
+// rawDeviceName returns the name of the device identified by
+// hDevice (e.g. \\?\HID#VID_046D&PID_C33A&MI_00#...).
+func rawDeviceName(hDevice uintptr) (string, error) {
+	var size uint32
+	ret, _, err := pGetRawInputDeviceInfoW.Call(
+		hDevice,
+		ridiDeviceName,
+		0,
+		uintptr(unsafe.Pointer(&size)))
+	if int32(ret) < 0 {
+		return "", fmt.Errorf("failed to get input device name length - %w", err)
+	}
+
+	if size == 0 {
+		return "", nil
+	}
+
+	name := make([]uint16, size)
+	ret, _, err = pGetRawInputDeviceInfoW.Call(
+		hDevice,
+		ridiDeviceName,
+		uintptr(unsafe.Pointer(&name[0])),
+		uintptr(unsafe.Pointer(&size)))
+	if int32(ret) < 0 {
+		return "", fmt.Errorf("failed to get input device name - %w", err)
+	}
+
+	return syscall.UTF16ToString(name), nil
+}
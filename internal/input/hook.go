@@ -0,0 +1,152 @@
+package input
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	pSetWindowsHookExW   = user32.NewProc("SetWindowsHookExW")
+	pUnhookWindowsHookEx = user32.NewProc("UnhookWindowsHookEx")
+	pCallNextHookEx      = user32.NewProc("CallNextHookEx")
+	pPostThreadMessageW  = user32.NewProc("PostThreadMessageW")
+	pGetCurrentThreadId  = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	whKeyboardLL = 13
+	wmQuit       = 0x0012
+
+	llkhfExtended = 0x01
+)
+
+var (
+	// hookProcCallback is shared by all hooks because callbacks
+	// created by syscall.NewCallback are never released.
+	hookProcCallback = syscall.NewCallback(hookProc)
+
+	// hookBackends maps the IDs of the threads that installed
+	// hooks to their backends. A low-level hook is called by
+	// the thread that installed it.
+	hookBackendsMu sync.Mutex
+	hookBackends   = make(map[uintptr]*hookBackend)
+)
+
+// kbdllHookStruct is KBDLLHOOKSTRUCT.
+type kbdllHookStruct struct {
+	vkCode      uint32
+	scanCode    uint32
+	flags       uint32
+	time        uint32
+	dwExtraInfo uintptr
+}
+
+// hookBackend is a Backend that uses a low-level keyboard hook.
+// Unlike the Raw Input API, a hook can prevent a key press from
+// reaching other programs.
+type hookBackend struct {
+	fn       HandlerFunc
+	hook     uintptr
+	threadID uintptr
+	done     chan error
+}
+
+func newHookBackend(fn HandlerFunc, highPriority bool) (*hookBackend, error) {
+	backend := &hookBackend{
+		fn:   fn,
+		done: make(chan error, 1),
+	}
+
+	ready := make(chan error)
+
+	go func() {
+		// Low-level hooks are called by the thread that installed
+		// them, which must retrieve messages for the hook to work.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		if highPriority {
+			raiseThreadPriority()
+		}
+
+		backend.threadID, _, _ = pGetCurrentThreadId.Call()
+
+		hookBackendsMu.Lock()
+		hookBackends[backend.threadID] = backend
+		hookBackendsMu.Unlock()
+
+		defer func() {
+			hookBackendsMu.Lock()
+			delete(hookBackends, backend.threadID)
+			hookBackendsMu.Unlock()
+		}()
+
+		var err error
+		backend.hook, _, err = pSetWindowsHookExW.Call(
+			whKeyboardLL,
+			hookProcCallback,
+			0,
+			0)
+		if backend.hook == 0 {
+			ready <- fmt.Errorf("failed to set keyboard hook - %w", err)
+			return
+		}
+
+		ready <- nil
+
+		err = messageLoop()
+		_, _, _ = pUnhookWindowsHookEx.Call(backend.hook)
+		backend.done <- err
+	}()
+
+	err := <-ready
+	if err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// hookProc is a LowLevelKeyboardProc. Returning a non-zero
+// value prevents the event from reaching other programs.
+func hookProc(nCode int, wParam uintptr, lParam uintptr) uintptr {
+	threadID, _, _ := pGetCurrentThreadId.Call()
+
+	hookBackendsMu.Lock()
+	o, hasIt := hookBackends[threadID]
+	hookBackendsMu.Unlock()
+
+	if !hasIt {
+		ret, _, _ := pCallNextHookEx.Call(0, uintptr(nCode), wParam, lParam)
+		return ret
+	}
+
+	if nCode == 0 {
+		info := *(**kbdllHookStruct)(unsafe.Pointer(&lParam))
+
+		suppress := o.fn(KeyEvent{
+			VirtualKey: byte(info.vkCode),
+			ScanCode:   scanCode(uint16(info.scanCode), info.flags&llkhfExtended != 0),
+			Down:       wParam == wmKeyDown || wParam == wmSysKeyDown,
+			Time:       time.Now(),
+		})
+		if suppress {
+			return 1
+		}
+	}
+
+	ret, _, _ := pCallNextHookEx.Call(o.hook, uintptr(nCode), wParam, lParam)
+	return ret
+}
+
+func (o *hookBackend) Done() <-chan error {
+	return o.done
+}
+
+func (o *hookBackend) Release() {
+	_, _, _ = pPostThreadMessageW.Call(o.threadID, wmQuit, 0, 0)
+}
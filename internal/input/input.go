@@ -0,0 +1,333 @@
+// Package input shares a single keyboard input source between
+// every program that blaj controls.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/input/backend"
+	windows "github.com/SeungKang/blaj/internal/user32"
+	"github.com/SeungKang/blaj/internal/winutil"
+)
+
+// ErrClosed is returned when subscribing to a Dispatcher
+// whose backend has exited.
+var ErrClosed = errors.New("keyboard input is closed")
+
+// Backend names (see package backend).
+const (
+	HookBackend     = backend.Hook
+	RawInputBackend = backend.RawInput
+)
+
+// KeyEvent is a keyboard event.
+type KeyEvent struct {
+	// VirtualKey is the key's virtual-key code.
+	VirtualKey byte
+
+	// ScanCode is the key's scan code. Extended keys
+	// have a 0xE0 prefix (e.g. 0xE048 for the up arrow).
+	ScanCode uint16
+
+	// Down is true if the key was pressed
+	// and false if it was released.
+	Down bool
+
+	// Time is when the event was received from Windows.
+	Time time.Time
+
+	// Device is the name of the keyboard that the event came
+	// from (see Keyboards). It is empty if the backend cannot
+	// tell keyboards apart, which is the case for HookBackend.
+	Device string
+
+	// Char is the unshifted character that the key produces in
+	// the foreground window's keyboard layout, or 0 if it does
+	// not produce a character. Letters are uppercase.
+	Char rune
+
+	// Shift is true if a shift key was held down
+	// when the event was dispatched.
+	Shift bool
+
+	// ForegroundPID is the PID of the process that owned the
+	// foreground window when the event occurred, or 0 if
+	// there was no foreground window.
+	ForegroundPID int
+}
+
+// scanCode returns a scan code with the 0xE0
+// prefix added if the key is an extended key.
+func scanCode(code uint16, extended bool) uint16 {
+	if extended {
+		return 0xe000 | code
+	}
+
+	return code
+}
+
+// HandlerFunc handles a keyboard event. It returns true if the
+// event should be prevented from reaching other programs.
+type HandlerFunc func(event KeyEvent) bool
+
+// Backend is a source of keyboard events.
+type Backend interface {
+	// Done returns a channel that receives the error that
+	// caused the backend to exit. A nil error is sent if
+	// the backend exited because Release was called.
+	Done() <-chan error
+
+	// Release stops the backend.
+	Release()
+}
+
+// IsBackend returns true if name is the name of a Backend.
+func IsBackend(name string) bool {
+	return backend.IsBackend(name)
+}
+
+func newBackend(name string, fn HandlerFunc, highPriority bool) (Backend, error) {
+	switch strings.ToLower(name) {
+	case "", HookBackend:
+		return newHookBackend(fn, highPriority)
+	case RawInputBackend:
+		return newRawInputBackend(fn, highPriority)
+	default:
+		return nil, fmt.Errorf("unknown input backend: %q", name)
+	}
+}
+
+// raiseThreadPriority raises the priority of the calling thread,
+// which must be locked to its goroutine, so that keyboard events
+// are handled promptly while other programs use every CPU core.
+func raiseThreadPriority() {
+	err := winutil.SetCurrentThreadPriority(winutil.ThreadPriorityHighest)
+	if err != nil {
+		log.Printf("failed to raise keyboard input thread priority - %s", err)
+	}
+}
+
+// Dispatcher owns a single keyboard input Backend and routes its
+// events to every subscribed handler. Sharing one backend rather
+// than installing one per program reduces input latency and the
+// chance of Windows silently removing a slow hook.
+type Dispatcher struct {
+	backendName  string
+	highPriority bool
+
+	// deferred is true if the backend only runs while
+	// at least one handler is subscribed.
+	deferred bool
+
+	mu       sync.Mutex
+	backend  Backend
+	nextID   int
+	handlers []subscription
+	closed   bool
+	done     chan struct{}
+	err      error
+}
+
+type subscription struct {
+	id int
+	fn HandlerFunc
+}
+
+// NewDispatcher starts the Backend identified by backendName.
+// An empty backendName selects HookBackend. If highPriority is
+// true, the priority of the thread that receives keyboard events
+// is raised.
+//
+// If deferred is true, the backend is started when the first
+// handler subscribes and stopped when the last handler
+// unsubscribes, so that no keyboard hook is installed
+// while no program is attached.
+func NewDispatcher(backendName string, highPriority bool, deferred bool) (*Dispatcher, error) {
+	dispatcher := &Dispatcher{
+		backendName:  backendName,
+		highPriority: highPriority,
+		deferred:     deferred,
+		done:         make(chan struct{}),
+	}
+
+	if deferred {
+		if backendName != "" && !IsBackend(backendName) {
+			return nil, fmt.Errorf("unknown input backend: %q", backendName)
+		}
+
+		return dispatcher, nil
+	}
+
+	dispatcher.mu.Lock()
+	defer dispatcher.mu.Unlock()
+
+	err := dispatcher.startLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	return dispatcher, nil
+}
+
+// startLocked starts the backend. mu must be held by the caller.
+func (o *Dispatcher) startLocked() error {
+	backend, err := newBackend(o.backendName, o.dispatch, o.highPriority)
+	if err != nil {
+		return err
+	}
+
+	o.backend = backend
+
+	go o.waitForBackend(backend)
+
+	return nil
+}
+
+// waitForBackend closes the Dispatcher when backend exits,
+// unless it was stopped because every handler unsubscribed.
+func (o *Dispatcher) waitForBackend(backend Backend) {
+	err := <-backend.Done()
+
+	o.mu.Lock()
+	if o.backend != backend {
+		o.mu.Unlock()
+		return
+	}
+
+	if err == nil {
+		err = errors.New("keyboard input exited without error")
+	}
+
+	o.err = err
+	o.closed = true
+	o.handlers = nil
+	o.mu.Unlock()
+
+	close(o.done)
+}
+
+// dispatch calls every handler and returns true if
+// any of them requested that the event be suppressed.
+func (o *Dispatcher) dispatch(event KeyEvent) bool {
+	o.mu.Lock()
+	handlers := o.handlers
+	o.mu.Unlock()
+
+	if len(handlers) == 0 {
+		return false
+	}
+
+	// The foreground window is looked up once per event
+	// rather than by each handler.
+	var layout uintptr
+	event.ForegroundPID, layout = windows.ForegroundWindow()
+	event.Char = windows.VirtualKeyToChar(event.VirtualKey, layout)
+	event.Shift = windows.IsKeyDown(windows.VK_SHIFT)
+
+	suppress := false
+
+	// Handlers are called in the order that they subscribed.
+	for _, handler := range handlers {
+		if handler.fn(event) {
+			suppress = true
+		}
+	}
+
+	return suppress
+}
+
+// Subscribe calls fn for each keyboard event until the
+// returned function is called. ErrClosed is returned
+// if the backend has exited.
+func (o *Dispatcher) Subscribe(fn HandlerFunc) (func(), error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil, ErrClosed
+	}
+
+	if o.backend == nil {
+		err := o.startLocked()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start keyboard input - %w", err)
+		}
+	}
+
+	id := o.nextID
+	o.nextID++
+
+	// The slice is copied rather than appended to in place
+	// so that dispatch can iterate over it without holding mu.
+	o.handlers = append(o.handlers[:len(o.handlers):len(o.handlers)],
+		subscription{id: id, fn: fn})
+
+	return func() {
+		o.mu.Lock()
+
+		for i, handler := range o.handlers {
+			if handler.id == id {
+				handlers := make([]subscription, 0, len(o.handlers)-1)
+				handlers = append(handlers, o.handlers[:i]...)
+				o.handlers = append(handlers, o.handlers[i+1:]...)
+				break
+			}
+		}
+
+		var idle Backend
+		if o.deferred && len(o.handlers) == 0 {
+			idle = o.backend
+			o.backend = nil
+		}
+
+		o.mu.Unlock()
+
+		// The backend is released without holding mu since
+		// its thread may be waiting for mu in dispatch.
+		if idle != nil {
+			idle.Release()
+		}
+	}, nil
+}
+
+// Done returns a channel that is closed when the backend exits.
+func (o *Dispatcher) Done() <-chan struct{} {
+	return o.done
+}
+
+// Err returns the error that caused the backend to exit.
+func (o *Dispatcher) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.err
+}
+
+// Release stops the backend.
+func (o *Dispatcher) Release() {
+	o.mu.Lock()
+	backend := o.backend
+
+	if backend == nil && !o.closed {
+		// A deferred backend is not running, so
+		// there is nothing to wait for.
+		o.err = errors.New("keyboard input was released")
+		o.closed = true
+		o.handlers = nil
+		o.mu.Unlock()
+
+		close(o.done)
+		return
+	}
+
+	o.mu.Unlock()
+
+	if backend != nil {
+		backend.Release()
+	}
+}
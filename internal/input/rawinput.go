@@ -0,0 +1,337 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	pGetModuleHandleW        = kernel32.NewProc("GetModuleHandleW")
+	pRegisterClassExW        = user32.NewProc("RegisterClassExW")
+	pCreateWindowExW         = user32.NewProc("CreateWindowExW")
+	pDefWindowProcW          = user32.NewProc("DefWindowProcW")
+	pDestroyWindow           = user32.NewProc("DestroyWindow")
+	pGetMessageW             = user32.NewProc("GetMessageW")
+	pDispatchMessageW        = user32.NewProc("DispatchMessageW")
+	pPostMessageW            = user32.NewProc("PostMessageW")
+	pPostQuitMessage         = user32.NewProc("PostQuitMessage")
+	pRegisterRawInputDevices = user32.NewProc("RegisterRawInputDevices")
+	pGetRawInputData         = user32.NewProc("GetRawInputData")
+)
+
+const (
+	wmDestroy    = 0x0002
+	wmClose      = 0x0010
+	wmInput      = 0x00ff
+	wmKeyDown    = 0x0100
+	wmSysKeyDown = 0x0104
+
+	ridInput        = 0x10000003
+	rimTypeKeyboard = 1
+	riKeyE0         = 0x02
+	ridevInputSink  = 0x00000100
+	ridevRemove     = 0x00000001
+
+	hidUsagePageGeneric = 0x01
+	hidUsageKeyboard    = 0x06
+
+	rawInputClassName = "blajRawInput"
+)
+
+// hwndMessage is HWND_MESSAGE, the parent of message-only windows.
+var hwndMessage = ^uintptr(2)
+
+type wndClassEx struct {
+	cbSize        uint32
+	style         uint32
+	lpfnWndProc   uintptr
+	cbClsExtra    int32
+	cbWndExtra    int32
+	hInstance     uintptr
+	hIcon         uintptr
+	hCursor       uintptr
+	hbrBackground uintptr
+	lpszMenuName  *uint16
+	lpszClassName *uint16
+	hIconSm       uintptr
+}
+
+type msg struct {
+	hwnd     uintptr
+	message  uint32
+	wParam   uintptr
+	lParam   uintptr
+	time     uint32
+	ptX      int32
+	ptY      int32
+	lPrivate uint32
+}
+
+type rawInputDevice struct {
+	usUsagePage uint16
+	usUsage     uint16
+	dwFlags     uint32
+	hwndTarget  uintptr
+}
+
+type rawInputHeader struct {
+	dwType  uint32
+	dwSize  uint32
+	hDevice uintptr
+	wParam  uintptr
+}
+
+type rawKeyboard struct {
+	makeCode         uint16
+	flags            uint16
+	reserved         uint16
+	vKey             uint16
+	message          uint32
+	extraInformation uint32
+}
+
+type rawInputKeyboard struct {
+	header   rawInputHeader
+	keyboard rawKeyboard
+}
+
+var (
+	// Window classes and callbacks created by syscall.NewCallback
+	// cannot be freed, so they are shared by every backend.
+	registerClassOnce sync.Once
+	registerClassErr  error
+	className         *uint16
+
+	rawInputMu       sync.Mutex
+	rawInputBackends = make(map[uintptr]*rawInputBackend)
+
+	// rawInputTarget is the window that the keyboard is registered
+	// to. Raw input registrations are per process, so registering a
+	// new window replaces the registration of the previous one.
+	rawInputTarget uintptr
+)
+
+// rawInputBackend is a Backend that uses the Raw Input API. It
+// creates a message-only window that receives WM_INPUT messages
+// for keyboard input, even when blaj is not in the foreground.
+type rawInputBackend struct {
+	fn   HandlerFunc
+	hwnd uintptr
+	done chan error
+}
+
+func newRawInputBackend(fn HandlerFunc, highPriority bool) (*rawInputBackend, error) {
+	registerClassOnce.Do(registerRawInputClass)
+	if registerClassErr != nil {
+		return nil, registerClassErr
+	}
+
+	backend := &rawInputBackend{
+		fn:   fn,
+		done: make(chan error, 1),
+	}
+
+	ready := make(chan error)
+
+	go func() {
+		// The window's messages must be retrieved by
+		// the thread that created the window.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		if highPriority {
+			raiseThreadPriority()
+		}
+
+		err := backend.createWindow()
+		ready <- err
+		if err != nil {
+			return
+		}
+
+		backend.done <- messageLoop()
+	}()
+
+	err := <-ready
+	if err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+func registerRawInputClass() {
+	className, registerClassErr = syscall.UTF16PtrFromString(rawInputClassName)
+	if registerClassErr != nil {
+		return
+	}
+
+	instance, _, _ := pGetModuleHandleW.Call(0)
+
+	class := wndClassEx{
+		lpfnWndProc:   syscall.NewCallback(rawInputWndProc),
+		hInstance:     instance,
+		lpszClassName: className,
+	}
+	class.cbSize = uint32(unsafe.Sizeof(class))
+
+	atom, _, err := pRegisterClassExW.Call(uintptr(unsafe.Pointer(&class)))
+	if atom == 0 {
+		registerClassErr = fmt.Errorf("failed to register window class - %w", err)
+	}
+}
+
+func (o *rawInputBackend) createWindow() error {
+	instance, _, _ := pGetModuleHandleW.Call(0)
+
+	hwnd, _, err := pCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(className)),
+		0,
+		0,
+		0, 0, 0, 0,
+		hwndMessage,
+		0,
+		instance,
+		0)
+	if hwnd == 0 {
+		return fmt.Errorf("failed to create raw input window - %w", err)
+	}
+
+	o.hwnd = hwnd
+
+	rawInputMu.Lock()
+	rawInputBackends[hwnd] = o
+
+	device := rawInputDevice{
+		usUsagePage: hidUsagePageGeneric,
+		usUsage:     hidUsageKeyboard,
+		dwFlags:     ridevInputSink,
+		hwndTarget:  hwnd,
+	}
+
+	ok, _, err := pRegisterRawInputDevices.Call(
+		uintptr(unsafe.Pointer(&device)),
+		1,
+		unsafe.Sizeof(device))
+	if ok == 0 {
+		rawInputMu.Unlock()
+
+		// WM_DESTROY is sent before DestroyWindow returns,
+		// so rawInputMu must not be held.
+		_, _, _ = pDestroyWindow.Call(hwnd)
+		return fmt.Errorf("failed to register raw input device - %w", err)
+	}
+
+	rawInputTarget = hwnd
+	rawInputMu.Unlock()
+
+	return nil
+}
+
+// messageLoop retrieves and dispatches the calling thread's
+// messages until WM_QUIT is received.
+func messageLoop() error {
+	var m msg
+	for {
+		ret, _, err := pGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		switch int32(ret) {
+		case 0:
+			return nil
+		case -1:
+			return fmt.Errorf("failed to get window message - %w", err)
+		}
+
+		_, _, _ = pDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+	}
+}
+
+func (o *rawInputBackend) Done() <-chan error {
+	return o.done
+}
+
+func (o *rawInputBackend) Release() {
+	_, _, _ = pPostMessageW.Call(o.hwnd, wmClose, 0, 0)
+}
+
+func rawInputWndProc(hwnd uintptr, message uint32, wParam uintptr, lParam uintptr) uintptr {
+	switch message {
+	case wmInput:
+		rawInputMu.Lock()
+		backend := rawInputBackends[hwnd]
+		rawInputMu.Unlock()
+
+		if backend != nil {
+			event, err := readRawKeyboard(lParam)
+			if err == nil {
+				// Raw input cannot prevent the key
+				// from reaching other programs.
+				_ = backend.fn(event)
+			}
+		}
+	case wmDestroy:
+		rawInputMu.Lock()
+		delete(rawInputBackends, hwnd)
+
+		// The keyboard is only unregistered if it is still registered
+		// to this window. Otherwise, a backend that was started before
+		// this one finished exiting (e.g. when a program is quickly
+		// re-attached to) would stop receiving input.
+		if rawInputTarget == hwnd {
+			device := rawInputDevice{
+				usUsagePage: hidUsagePageGeneric,
+				usUsage:     hidUsageKeyboard,
+				dwFlags:     ridevRemove,
+			}
+
+			_, _, _ = pRegisterRawInputDevices.Call(
+				uintptr(unsafe.Pointer(&device)),
+				1,
+				unsafe.Sizeof(device))
+
+			rawInputTarget = 0
+		}
+		rawInputMu.Unlock()
+
+		_, _, _ = pPostQuitMessage.Call(0)
+		return 0
+	}
+
+	ret, _, _ := pDefWindowProcW.Call(hwnd, uintptr(message), wParam, lParam)
+	return ret
+}
+
+func readRawKeyboard(hRawInput uintptr) (KeyEvent, error) {
+	var input rawInputKeyboard
+	size := uint32(unsafe.Sizeof(input))
+
+	ret, _, err := pGetRawInputData.Call(
+		hRawInput,
+		ridInput,
+		uintptr(unsafe.Pointer(&input)),
+		uintptr(unsafe.Pointer(&size)),
+		unsafe.Sizeof(input.header))
+	if int32(ret) <= 0 {
+		return KeyEvent{}, fmt.Errorf("failed to get raw input data - %w", err)
+	}
+
+	if input.header.dwType != rimTypeKeyboard {
+		return KeyEvent{}, errors.New("raw input is not from a keyboard")
+	}
+
+	return KeyEvent{
+		VirtualKey: byte(input.keyboard.vKey),
+		ScanCode:   scanCode(input.keyboard.makeCode, input.keyboard.flags&riKeyE0 != 0),
+		Down:       input.keyboard.message == wmKeyDown || input.keyboard.message == wmSysKeyDown,
+		Time:       time.Now(),
+		Device:     cachedDeviceName(input.header.hDevice),
+	}, nil
+}
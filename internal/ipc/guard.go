@@ -0,0 +1,203 @@
+package ipc
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRequestsPerSecond = 5
+	defaultBurst             = 10
+
+	// maxRateLimitSources limits the memory used to track
+	// request sources. Idle sources are forgotten first.
+	maxRateLimitSources = 1024
+)
+
+// RateLimit limits how often a single source can make requests.
+// The zero value uses the default limits.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that can be made at once
+	// after a period of inactivity.
+	Burst int
+}
+
+func (o RateLimit) withDefaults() RateLimit {
+	if o.RequestsPerSecond <= 0 {
+		o.RequestsPerSecond = defaultRequestsPerSecond
+	}
+
+	if o.Burst <= 0 {
+		o.Burst = defaultBurst
+	}
+
+	return o
+}
+
+// guard authorizes, rate limits, and audits every request before it
+// reaches a handler. All request handlers must be wrapped by it so
+// that remote control cannot bypass these checks.
+type guard struct {
+	token       string
+	allowRemote bool
+	limiter     *rateLimiter
+	auditMu     sync.Mutex
+	auditLog    io.Writer
+}
+
+func newGuard(token string, allowRemote bool, limit RateLimit, auditLog io.Writer) *guard {
+	return &guard{
+		token:       token,
+		allowRemote: allowRemote,
+		limiter:     newRateLimiter(limit.withDefaults()),
+		auditLog:    auditLog,
+	}
+}
+
+func (o *guard) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		source, err := requestSource(r, o.allowRemote)
+		if err != nil {
+			o.audit(r, r.RemoteAddr, "denied - "+err.Error())
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		// Rate limit before checking the token so that
+		// tokens cannot be guessed quickly.
+		if !o.limiter.allow(source, time.Now()) {
+			o.audit(r, source, "rate limited")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		if !o.hasValidToken(r) {
+			o.audit(r, source, "denied - invalid token")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		o.audit(r, source, "allowed")
+		next(w, r)
+	}
+}
+
+// audit writes a line to the audit log describing the request.
+func (o *guard) audit(r *http.Request, source string, outcome string) {
+	if o.auditLog == nil {
+		return
+	}
+
+	o.auditMu.Lock()
+	defer o.auditMu.Unlock()
+
+	_, _ = fmt.Fprintf(o.auditLog, "%s %s %s %s %q %s\n",
+		time.Now().Format(time.RFC3339), source, r.Method, r.URL.Path,
+		r.UserAgent(), outcome)
+}
+
+func (o *guard) hasValidToken(r *http.Request) bool {
+	const prefix = "Bearer "
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, prefix)
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(o.token)) == 1
+}
+
+// requestSource returns the IP address of the request's client.
+// Unless allowRemote is true, only clients on the loopback
+// interface are allowed.
+func requestSource(r *http.Request, allowRemote bool) (string, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "", fmt.Errorf("invalid remote address: %q", r.RemoteAddr)
+	}
+
+	if allowRemote {
+		return host, nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return "", fmt.Errorf("%s is not a loopback address", host)
+	}
+
+	return host, nil
+}
+
+// rateLimiter is a token bucket rate limiter keyed by request source.
+type rateLimiter struct {
+	limit RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow returns true if source may make a request at now.
+func (o *rateLimiter) allow(source string, now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	b, hasIt := o.buckets[source]
+	if !hasIt {
+		if len(o.buckets) >= maxRateLimitSources {
+			o.forgetOldest()
+		}
+
+		b = &bucket{tokens: float64(o.limit.Burst), last: now}
+		o.buckets[source] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * o.limit.RequestsPerSecond
+	if b.tokens > float64(o.limit.Burst) {
+		b.tokens = float64(o.limit.Burst)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+func (o *rateLimiter) forgetOldest() {
+	var oldest string
+	var oldestTime time.Time
+
+	for source, b := range o.buckets {
+		if oldest == "" || b.last.Before(oldestTime) {
+			oldest = source
+			oldestTime = b.last
+		}
+	}
+
+	delete(o.buckets, oldest)
+}
@@ -0,0 +1,414 @@
+// Package ipc allows command line invocations of blaj to communicate
+// with the instance running in the systray.
+//
+// The running instance serves HTTP on the loopback interface unless
+// remote clients are explicitly allowed. Its address and an access
+// token are written to files in the config directory so that local
+// clients can find and authenticate with it. Every request is checked
+// by a central guard that verifies the client's address and token,
+// rate limits each client, and records the request in an audit log.
+package ipc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddrFileName is the name of the file containing the address
+// of the running instance's IPC server.
+const AddrFileName = "ipc.addr"
+
+// TokenFileName is the name of the file containing the token
+// that clients must present to the IPC server.
+const TokenFileName = "ipc.token"
+
+// DefaultListenAddr is the address that the server listens on
+// when none is specified.
+const DefaultListenAddr = "127.0.0.1:0"
+
+const (
+	statusPath  = "/status"
+	capturePath = "/capture"
+)
+
+// MaxCaptureKeys is the maximum number of
+// keys that a capture request can record.
+const MaxCaptureKeys = 10
+
+// CaptureTimeout is how long a capture request waits for keys.
+const CaptureTimeout = 30 * time.Second
+
+// tokenSize is the number of random bytes in a token.
+const tokenSize = 32
+
+// ErrNotRunning is returned by clients when no running
+// instance could be found.
+var ErrNotRunning = errors.New("blaj does not appear to be running")
+
+// Status describes the state of the running instance.
+type Status struct {
+	Version  string          `json:"version"`
+	Programs []ProgramStatus `json:"programs"`
+	Errors   []string        `json:"errors"`
+
+	// ConfigRepo is non-nil when the config
+	// directory is a git repository.
+	ConfigRepo *ConfigRepoStatus `json:"config_repo,omitempty"`
+}
+
+// ConfigRepoStatus describes the git repository
+// that contains the config files.
+type ConfigRepoStatus struct {
+	// Head is the abbreviated hash and subject
+	// of the most recent commit.
+	Head string `json:"head,omitempty"`
+
+	// Dirty is true if any config file
+	// has uncommitted changes.
+	Dirty bool `json:"dirty"`
+
+	// Changes are the config files that have uncommitted
+	// changes, in git's short status format.
+	Changes []string `json:"changes,omitempty"`
+
+	// Error is set if the status could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+// ProgramStatus describes a single configured program.
+type ProgramStatus struct {
+	ExeName string `json:"exe_name"`
+
+	// State is one of waiting, attached, or error. It is
+	// kept for scripts written before StateDetail existed.
+	State string `json:"state"`
+
+	// StateDetail is one of searching, attaching, attached,
+	// degraded, idle, detached, or error.
+	StateDetail string `json:"state_detail"`
+
+	LastError string          `json:"last_error,omitempty"`
+	Sections  []SectionStatus `json:"sections"`
+
+	// PointerErrors contains the most recent error of each
+	// pointer that failed the last time that it was accessed.
+	// The keys are the pointers' names.
+	PointerErrors map[string]string `json:"pointer_errors,omitempty"`
+
+	// AttachedAt is when blaj attached to the program. It is
+	// nil if the program is not attached.
+	AttachedAt *time.Time `json:"attached_at,omitempty"`
+
+	// TotalAttached is the total time spent attached to
+	// the program across sessions, in seconds. It does
+	// not include the current session.
+	TotalAttached int64 `json:"total_attached,omitempty"`
+
+	// Process is the resource usage of the attached program.
+	// It is nil if the program is not attached or is idle.
+	Process *ProcessStatus `json:"process,omitempty"`
+}
+
+// ProcessStatus is the resource usage of an attached program.
+type ProcessStatus struct {
+	// WorkingSet is the program's physical memory in bytes.
+	WorkingSet uint64 `json:"working_set"`
+
+	// CPUPercent is the percentage of the total CPU time that
+	// the program used since the previous status request.
+	CPUPercent float64 `json:"cpu_percent"`
+}
+
+// SectionStatus describes a section of a program's config.
+type SectionStatus struct {
+	Type     string            `json:"type"`
+	Label    string            `json:"label,omitempty"`
+	Keybinds map[string]string `json:"keybinds"`
+}
+
+// Capture contains the keys that were pressed during a capture
+// request, which helps config authors choose keybinds.
+type Capture struct {
+	Keys []CapturedKey `json:"keys"`
+
+	// Suggestions are keybinds that are not used by any loaded
+	// config, preferring the keys that were captured.
+	Suggestions []string `json:"suggestions"`
+}
+
+// CapturedKey describes a key that was pressed during a capture.
+type CapturedKey struct {
+	// Char, VirtualKey, and ScanCode are the keybinds that match
+	// the key, in the format used by config files. Char is empty
+	// if the key does not produce a character.
+	Char       string `json:"char,omitempty"`
+	VirtualKey string `json:"virtual_key"`
+	ScanCode   string `json:"scan_code,omitempty"`
+
+	// ForegroundExe is the exe name of the program that owned
+	// the foreground window when the key was pressed.
+	ForegroundExe string `json:"foreground_exe,omitempty"`
+
+	// GameFocused is true if ForegroundExe is the exe
+	// name of one of the loaded configs.
+	GameFocused bool `json:"game_focused"`
+
+	// Conflicts describes the sections that
+	// the key is already bound to.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// Server serves the running instance's status.
+type Server struct {
+	// AddrFilePath is the path to write the server's address to.
+	AddrFilePath string
+
+	// ListenAddr is the address to listen on. It defaults to
+	// DefaultListenAddr.
+	ListenAddr string
+
+	// AllowRemote allows requests from clients that are not on
+	// the loopback interface. It must be true if ListenAddr is
+	// not a loopback address.
+	AllowRemote bool
+
+	// Token is the token that clients must present in the
+	// Authorization header. It must not be empty.
+	Token string
+
+	// StatusFn returns the current Status.
+	StatusFn func() Status
+
+	// CaptureFn records the next count keys that are pressed,
+	// returning early with the keys recorded so far if ctx
+	// is done. count is between 1 and MaxCaptureKeys.
+	CaptureFn func(ctx context.Context, count int) (Capture, error)
+
+	// RateLimit limits how often each client can make requests.
+	RateLimit RateLimit
+
+	// AuditLog, if non-nil, receives a line describing
+	// every request and whether it was allowed.
+	AuditLog io.Writer
+}
+
+// Serve listens on ListenAddr and serves requests until ctx is
+// done. The address file is removed when Serve returns.
+func (o *Server) Serve(ctx context.Context) error {
+	if o.Token == "" {
+		return errors.New("a token is required")
+	}
+
+	listenAddr := o.ListenAddr
+	if listenAddr == "" {
+		listenAddr = DefaultListenAddr
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen - %w", err)
+	}
+
+	err = os.WriteFile(o.AddrFilePath, []byte(localAddr(listener.Addr())), 0o600)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to write address file - %w", err)
+	}
+	defer os.Remove(o.AddrFilePath)
+
+	guard := newGuard(o.Token, o.AllowRemote, o.RateLimit, o.AuditLog)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(statusPath, guard.wrap(o.handleStatus))
+	mux.HandleFunc(capturePath, guard.wrap(o.handleCapture))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+func (o *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(o.StatusFn())
+	if err != nil {
+		log.Printf("ipc: failed to write status response - %s", err)
+	}
+}
+
+func (o *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if o.CaptureFn == nil {
+		http.Error(w, "capturing keys is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 1 || count > MaxCaptureKeys {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", MaxCaptureKeys),
+			http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancelFn := context.WithTimeout(r.Context(), CaptureTimeout)
+	defer cancelFn()
+
+	capture, err := o.CaptureFn(ctx, count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(capture)
+	if err != nil {
+		log.Printf("ipc: failed to write capture response - %s", err)
+	}
+}
+
+// localAddr returns the address that local clients should use
+// to connect to a listener bound to addr.
+func localAddr(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if ok && tcpAddr.IP.IsUnspecified() {
+		return net.JoinHostPort("127.0.0.1", strconv.Itoa(tcpAddr.Port))
+	}
+
+	return addr.String()
+}
+
+// LoadOrCreateToken returns the token stored in the file at filePath.
+// If the file does not exist, it is created with a new random token.
+func LoadOrCreateToken(filePath string) (string, error) {
+	token, err := os.ReadFile(filePath)
+	if err == nil {
+		return strings.TrimSpace(string(token)), nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read token file - %w", err)
+	}
+
+	random := make([]byte, tokenSize)
+	_, err = rand.Read(random)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token - %w", err)
+	}
+
+	newToken := hex.EncodeToString(random)
+
+	err = os.WriteFile(filePath, []byte(newToken), 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write token file - %w", err)
+	}
+
+	return newToken, nil
+}
+
+// GetStatus requests the Status of the running instance whose
+// address and token are stored in addrFilePath and tokenFilePath.
+func GetStatus(addrFilePath string, tokenFilePath string) (Status, error) {
+	var status Status
+	err := request(addrFilePath, tokenFilePath, http.MethodGet, statusPath, 5*time.Second, &status)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return status, nil
+}
+
+// CaptureKeys asks the running instance to record the next count
+// keys that are pressed. It waits for at most CaptureTimeout.
+func CaptureKeys(addrFilePath string, tokenFilePath string, count int) (Capture, error) {
+	var capture Capture
+	err := request(addrFilePath, tokenFilePath, http.MethodPost,
+		capturePath+"?count="+strconv.Itoa(count), CaptureTimeout+5*time.Second, &capture)
+	if err != nil {
+		return Capture{}, err
+	}
+
+	return capture, nil
+}
+
+// request makes a request to the running instance whose address
+// and token are stored in addrFilePath and tokenFilePath, and
+// decodes the JSON response into v.
+func request(addrFilePath string, tokenFilePath string, method string, path string, timeout time.Duration, v interface{}) error {
+	addr, err := os.ReadFile(addrFilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotRunning
+		}
+
+		return fmt.Errorf("failed to read address file - %w", err)
+	}
+
+	token, err := os.ReadFile(tokenFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read token file - %w", err)
+	}
+
+	req, err := http.NewRequest(method,
+		"http://"+strings.TrimSpace(string(addr))+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w (%s)", ErrNotRunning, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code: %d (%s)",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode response - %w", err)
+	}
+
+	return nil
+}
@@ -1,9 +1,12 @@
 package kernel32
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -15,6 +18,10 @@ var (
 	pEnumProcessModulesEx = kernel32.NewProc("K32EnumProcessModulesEx")
 	pGetModuleFileNameExW = kernel32.NewProc("K32GetModuleFileNameExW")
 	pGetModuleInformation = kernel32.NewProc("K32GetModuleInformation")
+	pAttachConsole        = kernel32.NewProc("AttachConsole")
+	pGetProcessMemoryInfo = kernel32.NewProc("K32GetProcessMemoryInfo")
+
+	pCheckRemoteDebuggerPresent = kernel32.NewProc("CheckRemoteDebuggerPresent")
 )
 
 func IsProcess32Bit(processHandle syscall.Handle) (bool, error) {
@@ -27,6 +34,72 @@ func IsProcess32Bit(processHandle syscall.Handle) (bool, error) {
 	return isProcess32Bit, nil
 }
 
+// OpenProcess opens the process identified by pid with
+// the access rights in access (e.g. windows.PROCESS_VM_READ).
+func OpenProcess(pid uint32, access uint32) (syscall.Handle, error) {
+	handle, err := windows.OpenProcess(access, false, pid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process %d with access 0x%x - %w", pid, access, err)
+	}
+
+	return syscall.Handle(handle), nil
+}
+
+// ReadProcessMemory reads size bytes at addr in the process. If only
+// some of the bytes can be read, an error that wraps
+// windows.ERROR_PARTIAL_COPY is returned (see IsPartialCopy).
+//
+// the process handle must be opened with windows.PROCESS_VM_READ
+func ReadProcessMemory(hProcess syscall.Handle, addr uintptr, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+
+	data := make([]byte, size)
+
+	var n uintptr
+	err := windows.ReadProcessMemory(windows.Handle(hProcess), addr, &data[0], uintptr(size), &n)
+	if err == nil && n != uintptr(size) {
+		err = windows.ERROR_PARTIAL_COPY
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %d bytes at 0x%x - %w", size, addr, err)
+	}
+
+	return data, nil
+}
+
+// WriteProcessMemory writes data to addr in the process. If only
+// some of the bytes can be written, an error that wraps
+// windows.ERROR_PARTIAL_COPY is returned (see IsPartialCopy).
+//
+// the process handle must be opened with
+// windows.PROCESS_VM_WRITE | windows.PROCESS_VM_OPERATION
+func WriteProcessMemory(hProcess syscall.Handle, addr uintptr, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var n uintptr
+	err := windows.WriteProcessMemory(windows.Handle(hProcess), addr, &data[0], uintptr(len(data)), &n)
+	if err == nil && n != uintptr(len(data)) {
+		err = windows.ERROR_PARTIAL_COPY
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %d bytes at 0x%x - %w", len(data), addr, err)
+	}
+
+	return nil
+}
+
+// IsPartialCopy returns true if err is ERROR_PARTIAL_COPY, which
+// ReadProcessMemory and WriteProcessMemory return when memory is
+// only partially accessible (for example, while the program is
+// changing its memory's protection during a loading screen).
+func IsPartialCopy(err error) bool {
+	return errors.Is(err, windows.ERROR_PARTIAL_COPY)
+}
+
 type Module struct {
 	Filepath string
 	Filename string
@@ -132,3 +205,178 @@ func GetModuleInformation(hProcess syscall.Handle, hModule syscall.Handle, lpmod
 
 	return nil
 }
+
+type PROCESS_MEMORY_COUNTERS struct {
+	Cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// GetProcessMemoryInfo retrieves the memory usage of the process.
+//
+// the process handle must be opened with
+// windows.PROCESS_VM_READ | windows.PROCESS_QUERY_INFORMATION
+func GetProcessMemoryInfo(hProcess syscall.Handle, counters *PROCESS_MEMORY_COUNTERS) error {
+	counters.Cb = uint32(unsafe.Sizeof(*counters))
+
+	r, _, err := pGetProcessMemoryInfo.Call(
+		uintptr(hProcess),
+		uintptr(unsafe.Pointer(counters)),
+		uintptr(counters.Cb))
+	if r == 0 {
+		return fmt.Errorf("failed to get process memory info - %w", err)
+	}
+
+	return nil
+}
+
+// GetProcessTimes returns when the process was created and the
+// total time that its threads have spent executing in kernel
+// mode and in user mode.
+//
+// the process handle must be opened with
+// windows.PROCESS_QUERY_INFORMATION
+func GetProcessTimes(hProcess syscall.Handle) (time.Time, time.Duration, error) {
+	var creation, exit, kernel, user windows.Filetime
+	err := windows.GetProcessTimes(windows.Handle(hProcess), &creation, &exit, &kernel, &user)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to get process times - %w", err)
+	}
+
+	return time.Unix(0, creation.Nanoseconds()), filetimeDuration(kernel) + filetimeDuration(user), nil
+}
+
+// filetimeDuration converts a FILETIME that contains
+// an amount of time, rather than a date, to a Duration.
+func filetimeDuration(ft windows.Filetime) time.Duration {
+	// FILETIMEs are in 100-nanosecond intervals.
+	return time.Duration(int64(ft.HighDateTime)<<32|int64(ft.LowDateTime)) * 100
+}
+
+// CheckRemoteDebuggerPresent returns true if a debugger
+// is attached to the process.
+//
+// the process handle must be opened with
+// windows.PROCESS_QUERY_INFORMATION
+func CheckRemoteDebuggerPresent(hProcess syscall.Handle) (bool, error) {
+	var isPresent int32
+
+	r, _, err := pCheckRemoteDebuggerPresent.Call(
+		uintptr(hProcess),
+		uintptr(unsafe.Pointer(&isPresent)))
+	if r == 0 {
+		return false, fmt.Errorf("failed to check for a debugger - %w", err)
+	}
+
+	return isPresent != 0, nil
+}
+
+// SYSTEM_THREAD_INFORMATION follows a SYSTEM_PROCESS_INFORMATION
+// for each of the process's threads.
+type SYSTEM_THREAD_INFORMATION struct {
+	KernelTime      int64
+	UserTime        int64
+	CreateTime      int64
+	WaitTime        uint32
+	StartAddress    uintptr
+	UniqueProcess   uintptr
+	UniqueThread    uintptr
+	Priority        int32
+	BasePriority    int32
+	ContextSwitches uint32
+	ThreadState     uint32
+	WaitReason      uint32
+}
+
+const (
+	threadStateWaiting   = 5
+	waitReasonSuspended  = 5
+	maxProcessInfoBuffer = 64 << 20
+)
+
+// IsProcessSuspended returns true if every thread of the process
+// identified by pid is suspended. This is the case when a debugger
+// has stopped the process (e.g. at a breakpoint or while stepping
+// through it) and when the process was suspended by a tool such
+// as Process Explorer.
+func IsProcessSuspended(pid uint32) (bool, error) {
+	size := uint32(512 << 10)
+
+	var buf []byte
+	for {
+		buf = make([]byte, size)
+
+		err := windows.NtQuerySystemInformation(windows.SystemProcessInformation,
+			unsafe.Pointer(&buf[0]), size, &size)
+		if err == nil {
+			break
+		}
+
+		if err != windows.STATUS_INFO_LENGTH_MISMATCH || size > maxProcessInfoBuffer {
+			return false, fmt.Errorf("failed to query process information - %w", err)
+		}
+
+		// Processes may start between calls.
+		size += 64 << 10
+	}
+
+	offset := uint32(0)
+	for {
+		process := (*windows.SYSTEM_PROCESS_INFORMATION)(unsafe.Pointer(&buf[offset]))
+
+		if process.UniqueProcessID == uintptr(pid) {
+			if process.NumberOfThreads == 0 {
+				return false, nil
+			}
+
+			threadsOffset := offset + uint32(unsafe.Sizeof(*process))
+			threads := unsafe.Slice(
+				(*SYSTEM_THREAD_INFORMATION)(unsafe.Pointer(&buf[threadsOffset])),
+				process.NumberOfThreads)
+
+			for _, thread := range threads {
+				if thread.ThreadState != threadStateWaiting || thread.WaitReason != waitReasonSuspended {
+					return false, nil
+				}
+			}
+
+			return true, nil
+		}
+
+		if process.NextEntryOffset == 0 {
+			return false, fmt.Errorf("process %d was not found", pid)
+		}
+
+		offset += process.NextEntryOffset
+	}
+}
+
+// AttachParentConsole attaches the calling process to the console of
+// its parent process (if any) and redirects os.Stdout and os.Stderr
+// to it. This allows GUI subsystem executables to print output when
+// they are started from a terminal.
+func AttachParentConsole() error {
+	const attachParentProcess = ^uintptr(0) // (DWORD)-1
+
+	r, _, err := pAttachConsole.Call(attachParentProcess)
+	if r == 0 {
+		return fmt.Errorf("failed to attach to parent console - %w", err)
+	}
+
+	console, err := os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open console output - %w", err)
+	}
+
+	os.Stdout = console
+	os.Stderr = console
+
+	return nil
+}
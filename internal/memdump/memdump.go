@@ -0,0 +1,106 @@
+// Package memdump reads process memory from dump files, which allows
+// pointer chains to be tested without the target process running.
+package memdump
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Module is a module that was loaded in the dumped process.
+type Module struct {
+	// Name is the module's file name (e.g. "game.exe").
+	Name     string
+	BaseAddr uintptr
+	Size     int
+}
+
+// Region is a range of the dumped process's memory.
+type Region struct {
+	Addr uintptr
+	Size int
+
+	// offset is the region's offset in the dump file.
+	offset int64
+}
+
+// Dump is the memory of a process that was saved to a file.
+// Its methods satisfy progctl.ProcessIO.
+type Dump struct {
+	Modules []Module
+
+	// Is32Bit is true if the dumped process was 32-bit.
+	Is32Bit bool
+
+	r       io.ReaderAt
+	regions []Region
+}
+
+// NewRaw returns a Dump of a file containing a single contiguous
+// range of memory, which starts at addr in the dumped process.
+func NewRaw(r io.ReaderAt, size int64, addr uintptr) *Dump {
+	return newDump(r, []Region{{Addr: addr, Size: int(size)}})
+}
+
+func newDump(r io.ReaderAt, regions []Region) *Dump {
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Addr < regions[j].Addr
+	})
+
+	return &Dump{
+		r:       r,
+		regions: regions,
+	}
+}
+
+// Regions returns the ranges of memory contained in the dump.
+func (o *Dump) Regions() []Region {
+	return o.regions
+}
+
+// ReadBytes reads size bytes at addr. The memory may span
+// several adjacent regions.
+func (o *Dump) ReadBytes(addr uintptr, size int) ([]byte, error) {
+	data := make([]byte, 0, size)
+	next := addr
+
+	i := sort.Search(len(o.regions), func(i int) bool {
+		region := o.regions[i]
+		return region.Addr+uintptr(region.Size) > next
+	})
+
+	for ; i < len(o.regions) && len(data) < size; i++ {
+		region := o.regions[i]
+		if region.Addr > next {
+			break
+		}
+
+		start := next - region.Addr
+		n := region.Size - int(start)
+		if n > size-len(data) {
+			n = size - len(data)
+		}
+
+		chunk := make([]byte, n)
+		_, err := o.r.ReadAt(chunk, region.offset+int64(start))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dump file at 0x%x - %w", addr, err)
+		}
+
+		data = append(data, chunk...)
+		next += uintptr(n)
+	}
+
+	if len(data) < size {
+		return nil, fmt.Errorf("memory at 0x%x (%d bytes) is not in the dump", addr, size)
+	}
+
+	return data, nil
+}
+
+// WriteBytes always fails, since dumps are read-only.
+func (o *Dump) WriteBytes(addr uintptr, data []byte) error {
+	return errors.New("memory dumps are read-only")
+}
@@ -0,0 +1,262 @@
+package memdump
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// Minidump stream types. Refer to MINIDUMP_STREAM_TYPE.
+const (
+	moduleListStream   = 4
+	memoryListStream   = 5
+	systemInfoStream   = 7
+	memory64ListStream = 9
+)
+
+const (
+	minidumpSignature = 0x504d444d // "MDMP"
+
+	processorArchitectureIntel = 0
+
+	moduleEntrySize = 108
+	maxModuleName   = 32 << 10
+
+	// These limits prevent a corrupt file
+	// from causing huge allocations.
+	maxStreams = 1 << 16
+	maxModules = 1 << 16
+	maxRanges  = 1 << 20
+)
+
+// OpenMinidump parses the minidump file in r (as written by
+// MiniDumpWriteDump). Memory is read from r as needed, so r
+// must remain open while the Dump is in use.
+func OpenMinidump(r io.ReaderAt) (*Dump, error) {
+	header := make([]byte, 32)
+	_, err := r.ReadAt(header, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read minidump header - %w", err)
+	}
+
+	if binary.LittleEndian.Uint32(header) != minidumpSignature {
+		return nil, errors.New("file is not a minidump")
+	}
+
+	numStreams := binary.LittleEndian.Uint32(header[8:])
+	dirRVA := int64(binary.LittleEndian.Uint32(header[12:]))
+	if numStreams > maxStreams {
+		return nil, fmt.Errorf("too many streams: %d", numStreams)
+	}
+
+	dir := make([]byte, 12*int(numStreams))
+	_, err = r.ReadAt(dir, dirRVA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read minidump stream directory - %w", err)
+	}
+
+	var modules []Module
+	var regions []Region
+	is32Bit := false
+
+	for i := 0; i < int(numStreams); i++ {
+		entry := dir[i*12:]
+		streamType := binary.LittleEndian.Uint32(entry)
+		dataSize := int64(binary.LittleEndian.Uint32(entry[4:]))
+		rva := int64(binary.LittleEndian.Uint32(entry[8:]))
+
+		switch streamType {
+		case moduleListStream:
+			modules, err = readModuleList(r, rva)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read module list - %w", err)
+			}
+		case memoryListStream:
+			memRegions, err := readMemoryList(r, rva)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read memory list - %w", err)
+			}
+
+			regions = append(regions, memRegions...)
+		case memory64ListStream:
+			memRegions, err := readMemory64List(r, rva)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read memory64 list - %w", err)
+			}
+
+			regions = append(regions, memRegions...)
+		case systemInfoStream:
+			if dataSize < 2 {
+				return nil, errors.New("system info stream is too small")
+			}
+
+			arch := make([]byte, 2)
+			_, err = r.ReadAt(arch, rva)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read system info - %w", err)
+			}
+
+			is32Bit = binary.LittleEndian.Uint16(arch) == processorArchitectureIntel
+		}
+	}
+
+	dump := newDump(r, regions)
+	dump.Modules = modules
+	dump.Is32Bit = is32Bit
+
+	return dump, nil
+}
+
+func readModuleList(r io.ReaderAt, rva int64) ([]Module, error) {
+	numModules, err := readUint32(r, rva)
+	if err != nil {
+		return nil, err
+	}
+
+	if numModules > maxModules {
+		return nil, fmt.Errorf("too many modules: %d", numModules)
+	}
+
+	entries := make([]byte, moduleEntrySize*int(numModules))
+	_, err = r.ReadAt(entries, rva+4)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make([]Module, numModules)
+	for i := range modules {
+		entry := entries[i*moduleEntrySize:]
+
+		name, err := readMinidumpString(r, int64(binary.LittleEndian.Uint32(entry[20:])))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module name - %w", err)
+		}
+
+		modules[i] = Module{
+			Name:     baseName(name),
+			BaseAddr: uintptr(binary.LittleEndian.Uint64(entry)),
+			Size:     int(binary.LittleEndian.Uint32(entry[8:])),
+		}
+	}
+
+	return modules, nil
+}
+
+func readMemoryList(r io.ReaderAt, rva int64) ([]Region, error) {
+	numRanges, err := readUint32(r, rva)
+	if err != nil {
+		return nil, err
+	}
+
+	if numRanges > maxRanges {
+		return nil, fmt.Errorf("too many memory ranges: %d", numRanges)
+	}
+
+	descriptors := make([]byte, 16*int(numRanges))
+	_, err = r.ReadAt(descriptors, rva+4)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]Region, numRanges)
+	for i := range regions {
+		descriptor := descriptors[i*16:]
+		regions[i] = Region{
+			Addr:   uintptr(binary.LittleEndian.Uint64(descriptor)),
+			Size:   int(binary.LittleEndian.Uint32(descriptor[8:])),
+			offset: int64(binary.LittleEndian.Uint32(descriptor[12:])),
+		}
+	}
+
+	return regions, nil
+}
+
+func readMemory64List(r io.ReaderAt, rva int64) ([]Region, error) {
+	header := make([]byte, 16)
+	_, err := r.ReadAt(header, rva)
+	if err != nil {
+		return nil, err
+	}
+
+	numRanges := binary.LittleEndian.Uint64(header)
+	offset := int64(binary.LittleEndian.Uint64(header[8:]))
+	if numRanges > maxRanges {
+		return nil, fmt.Errorf("too many memory ranges: %d", numRanges)
+	}
+
+	descriptors := make([]byte, 16*int(numRanges))
+	_, err = r.ReadAt(descriptors, rva+16)
+	if err != nil {
+		return nil, err
+	}
+
+	// The memory of each range is stored back-to-back,
+	// starting at the list's base RVA.
+	regions := make([]Region, numRanges)
+	for i := range regions {
+		descriptor := descriptors[i*16:]
+		size := int64(binary.LittleEndian.Uint64(descriptor[8:]))
+		if size < 0 {
+			return nil, fmt.Errorf("invalid memory range size: %d", uint64(size))
+		}
+
+		regions[i] = Region{
+			Addr:   uintptr(binary.LittleEndian.Uint64(descriptor)),
+			Size:   int(size),
+			offset: offset,
+		}
+
+		offset += size
+	}
+
+	return regions, nil
+}
+
+// readMinidumpString reads a MINIDUMP_STRING, which is a
+// length-prefixed UTF-16 string.
+func readMinidumpString(r io.ReaderAt, rva int64) (string, error) {
+	length, err := readUint32(r, rva)
+	if err != nil {
+		return "", err
+	}
+
+	if length > maxModuleName || length%2 != 0 {
+		return "", fmt.Errorf("invalid string length: %d", length)
+	}
+
+	data := make([]byte, length)
+	_, err = r.ReadAt(data, rva+4)
+	if err != nil {
+		return "", err
+	}
+
+	chars := make([]uint16, length/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	return string(utf16.Decode(chars)), nil
+}
+
+func readUint32(r io.ReaderAt, offset int64) (uint32, error) {
+	b := make([]byte, 4)
+	_, err := r.ReadAt(b, offset)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// baseName returns the file name at the end of a Windows path.
+func baseName(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '\\' || path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+
+	return path
+}
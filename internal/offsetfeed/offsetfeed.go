@@ -0,0 +1,261 @@
+// Package offsetfeed implements signed offset manifests, which let
+// config authors publish updated addresses after a game is patched.
+//
+// A feed is a JSON document containing a manifest, the Ed25519 public
+// key of its signer, and a signature of the manifest. Programs pin the
+// signer's public key in their config so that a compromised web server
+// cannot supply arbitrary addresses. The manifest also names the game
+// it is for and has a serial number that must increase with each
+// update, so that a feed cannot be replayed for another game or rolled
+// back to an older manifest.
+package offsetfeed
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SeungKang/blaj/internal/remoteconf"
+)
+
+// Manifest describes the addresses of a version of a game.
+type Manifest struct {
+	// Version identifies the manifest. It is usually the
+	// version of the game that the addresses are for.
+	Version string `json:"version"`
+
+	// ExeName is the exeName of the program config
+	// that the addresses are for.
+	ExeName string `json:"exeName"`
+
+	// Serial must be increased each time the manifest is
+	// updated. A manifest with a lower serial than the last
+	// accepted one is rejected.
+	Serial uint64 `json:"serial"`
+
+	// Addresses maps the names of [Addresses] section
+	// parameters to their values (e.g. "mono.dll 0x1F40B8").
+	Addresses map[string]string `json:"addresses"`
+}
+
+// feed is the signed encoding of a Manifest.
+type feed struct {
+	Manifest  []byte `json:"manifest"`
+	Signer    []byte `json:"signer"`
+	Signature []byte `json:"signature"`
+}
+
+// Sign signs the JSON-encoded manifest with key and returns the
+// feed document to publish.
+func Sign(manifestJSON []byte, key ed25519.PrivateKey) ([]byte, error) {
+	_, err := parseManifest(manifestJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(feed{
+		Manifest:  manifestJSON,
+		Signer:    key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(key, manifestJSON),
+	}, "", "  ")
+}
+
+// Verify parses a feed document and verifies that its manifest
+// was signed by signer and is for the program named exeName.
+func Verify(data []byte, signer ed25519.PublicKey, exeName string) (*Manifest, error) {
+	var f feed
+	err := json.Unmarshal(data, &f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed - %w", err)
+	}
+
+	if !ed25519.PublicKey(f.Signer).Equal(signer) {
+		return nil, errors.New("feed is not signed by the expected signer")
+	}
+
+	if !ed25519.Verify(signer, f.Manifest, f.Signature) {
+		return nil, errors.New("feed signature is invalid")
+	}
+
+	manifest, err := parseManifest(f.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(manifest.ExeName, exeName) {
+		return nil, fmt.Errorf("feed is for %q, not %q", manifest.ExeName, exeName)
+	}
+
+	return manifest, nil
+}
+
+func parseManifest(manifestJSON []byte) (*Manifest, error) {
+	var manifest Manifest
+	err := json.Unmarshal(manifestJSON, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest - %w", err)
+	}
+
+	if manifest.Version == "" {
+		return nil, errors.New("manifest version cannot be empty")
+	}
+
+	if manifest.ExeName == "" {
+		return nil, errors.New("manifest exeName cannot be empty")
+	}
+
+	if manifest.Serial == 0 {
+		return nil, errors.New("manifest serial must be greater than 0")
+	}
+
+	// Parameter names are case-insensitive.
+	addresses := make(map[string]string, len(manifest.Addresses))
+	for name, value := range manifest.Addresses {
+		addresses[strings.ToLower(name)] = value
+	}
+	manifest.Addresses = addresses
+
+	return &manifest, nil
+}
+
+// Result is a fetched Manifest.
+type Result struct {
+	Manifest *Manifest
+
+	// Stale is the error that prevented the feed from being
+	// downloaded when the last accepted manifest was used instead.
+	Stale error
+}
+
+// CacheDir is the directory that accepted feeds are saved in.
+// It defaults to the cache/feeds directory of the .blaj directory.
+var CacheDir string
+
+// acceptedMu serializes reading and writing accepted feeds.
+var acceptedMu sync.Mutex
+
+// Fetch downloads the feed at rawURL and verifies that it was signed
+// by signer for the program named exeName. The feed is rejected if its
+// serial is lower than that of the last accepted feed, which is
+// returned instead (with Result.Stale set) if the feed cannot be
+// downloaded or is rejected.
+//
+// Fetch blocks while the feed is downloaded, so it should not be
+// called while configs are parsed. Use Cached instead.
+func Fetch(rawURL string, signer ed25519.PublicKey, exeName string) (*Result, error) {
+	acceptedMu.Lock()
+	defer acceptedMu.Unlock()
+
+	accepted, acceptedErr := loadAccepted(rawURL, signer, exeName)
+
+	manifest, data, err := download(rawURL, signer, exeName)
+	if err == nil && accepted != nil && manifest.Serial < accepted.Serial {
+		err = fmt.Errorf("feed serial %d is lower than the accepted serial %d",
+			manifest.Serial, accepted.Serial)
+	}
+	if err != nil {
+		if acceptedErr != nil {
+			return nil, err
+		}
+
+		return &Result{Manifest: accepted, Stale: err}, nil
+	}
+
+	err = saveAccepted(rawURL, data)
+	if err != nil {
+		// The manifest is still usable, but it
+		// will not be available while offline.
+		return &Result{Manifest: manifest, Stale: err}, nil
+	}
+
+	return &Result{Manifest: manifest}, nil
+}
+
+// Cached returns the last feed at rawURL that was accepted by Fetch,
+// after verifying it again. It does not download anything.
+func Cached(rawURL string, signer ed25519.PublicKey, exeName string) (*Manifest, error) {
+	acceptedMu.Lock()
+	defer acceptedMu.Unlock()
+
+	return loadAccepted(rawURL, signer, exeName)
+}
+
+func download(rawURL string, signer ed25519.PublicKey, exeName string) (*Manifest, []byte, error) {
+	fetched, err := remoteconf.Fetch(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fetched.Stale != nil {
+		return nil, nil, fetched.Stale
+	}
+
+	manifest, err := Verify(fetched.Data, signer, exeName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return manifest, fetched.Data, nil
+}
+
+func loadAccepted(rawURL string, signer ed25519.PublicKey, exeName string) (*Manifest, error) {
+	acceptedPath, err := acceptedPath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(acceptedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return Verify(data, signer, exeName)
+}
+
+func saveAccepted(rawURL string, data []byte) error {
+	acceptedPath, err := acceptedPath(rawURL)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(acceptedPath)
+	if err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(acceptedPath), 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create feed cache directory - %w", err)
+	}
+
+	err = os.WriteFile(acceptedPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to save accepted feed - %w", err)
+	}
+
+	return nil
+}
+
+func acceptedPath(rawURL string) (string, error) {
+	dir := CacheDir
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory - %w", err)
+		}
+
+		dir = filepath.Join(homeDir, ".blaj", "cache", "feeds")
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
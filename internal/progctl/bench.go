@@ -0,0 +1,64 @@
+package progctl
+
+import (
+	"encoding/binary"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// benchBase is the base address of the module
+// in the processes of LookupAddrBench.
+const benchBase = 0x140000000
+
+// LookupAddrBench returns a function that follows a pointer chain
+// with depth offsets through a MockProcess, which measures pointer
+// resolution without the cost of reading a real process. It is
+// called repeatedly by BenchmarkLookupAddr and the bench command.
+func LookupAddrBench(depth int, is32Bit bool) func() error {
+	mock, ptr := benchChain(depth, is32Bit)
+	addrFn := addrFnFor(mock, is32Bit)
+
+	return func() error {
+		_, err := lookupAddr(benchBase, ptr, addrFn)
+		return err
+	}
+}
+
+// benchChain returns a MockProcess containing a chain of depth
+// pointers, each in its own region, and the pointer that follows it.
+func benchChain(depth int, is32Bit bool) (*MockProcess, appconfig.Pointer) {
+	size := 8
+	if is32Bit {
+		size = 4
+	}
+
+	mock := NewMockProcess()
+	ptr := appconfig.Pointer{Name: "bench", NBytes: 4}
+
+	// The regions are spaced apart so that they are not merged,
+	// which keeps MockProcess's lookups similar to a process
+	// with many mapped regions.
+	addr := uintptr(benchBase + 0x1000)
+	ptr.Addrs = append(ptr.Addrs, addr-benchBase)
+
+	for i := 0; i < depth; i++ {
+		const offset = 0x10
+		next := uintptr(0x10000000 + i*0x10000)
+
+		data := make([]byte, size)
+		if is32Bit {
+			binary.LittleEndian.PutUint32(data, uint32(next))
+		} else {
+			binary.LittleEndian.PutUint64(data, uint64(next))
+		}
+
+		mock.SetBytes(addr, data)
+		ptr.Addrs = append(ptr.Addrs, offset)
+
+		addr = next + offset
+	}
+
+	mock.SetBytes(addr, make([]byte, ptr.NBytes))
+
+	return mock, ptr
+}
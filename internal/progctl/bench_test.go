@@ -0,0 +1,24 @@
+package progctl
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkLookupAddr(b *testing.B) {
+	for _, is32Bit := range []bool{false, true} {
+		b.Run(fmt.Sprintf("depth=8/32bit=%t", is32Bit), func(b *testing.B) {
+			lookup := LookupAddrBench(8, is32Bit)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				err := lookup()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
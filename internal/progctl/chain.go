@@ -0,0 +1,85 @@
+package progctl
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// handleChained performs section's action for pressedKey, and then
+// triggers the section's onSuccess or onFailure section.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) handleChained(section interface{}, pressedKey appconfig.Key) error {
+	return o.runChain(section, o.handleSection(section, pressedKey))
+}
+
+// runChain triggers the onSuccess section of section if err is nil,
+// or its onFailure section otherwise. A failure that is followed by
+// an onFailure section is logged rather than returned, so it only
+// stops the routine if the onFailure section fails as well.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) runChain(section interface{}, err error) error {
+	chain := appconfig.SectionChain(section)
+	if chain == nil {
+		return err
+	}
+
+	if err == nil {
+		if chain.OnSuccess == nil {
+			return nil
+		}
+
+		err = o.triggerChained(chain.OnSuccess)
+		if err != nil {
+			return fmt.Errorf("failed to trigger onSuccess section %s - %w",
+				o.program.SectionID(chain.OnSuccess), err)
+		}
+
+		return nil
+	}
+
+	if chain.OnFailure == nil {
+		return err
+	}
+
+	log.Printf("%s failed, triggering %s - %s",
+		o.program.SectionID(section), o.program.SectionID(chain.OnFailure), err)
+
+	err = o.triggerChained(chain.OnFailure)
+	if err != nil {
+		return fmt.Errorf("failed to trigger onFailure section %s - %w",
+			o.program.SectionID(chain.OnFailure), err)
+	}
+
+	return nil
+}
+
+// triggerChained performs the action of a section that was triggered
+// by another section's onSuccess or onFailure param: a SaveRestore
+// section is restored, a Writer is written (or turned on, if it is a
+// freeze writer), and a Counter is incremented. The chains of the
+// triggered section are followed as well. ProgramConfig.Validate
+// ensures that chains do not loop.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) triggerChained(section interface{}) error {
+	log.Printf("triggering %s", o.program.SectionID(section))
+
+	var err error
+	switch v := section.(type) {
+	case *appconfig.SaveRestore:
+		err = o.doRestore(v, 0)
+		if err == nil {
+			err = o.scheduleAfterRestore(v)
+		}
+	case *appconfig.Writer:
+		err = o.doAfterRestore(v)
+	case *appconfig.Counter:
+		o.doCount(v)
+	}
+
+	return o.runChain(section, err)
+}
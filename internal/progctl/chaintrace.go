@@ -0,0 +1,32 @@
+package progctl
+
+import (
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// chainTracingAddrFn returns an addrFn that logs each address that
+// addrFn reads while the chain of ptr is followed, along with the
+// value that was read. It is used when the General section's
+// traceChains param is set, which helps find the wrong offset
+// in a chain that does not lead to the expected value.
+func chainTracingAddrFn(ptr appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) func(uintptr) (uintptr, error) {
+	step := 0
+
+	return func(addr uintptr) (uintptr, error) {
+		step++
+
+		value, err := addrFn(addr)
+		if err != nil {
+			log.Printf("chain %s: step %d: failed to read 0x%x - %s",
+				ptr.DisplayName(), step, addr, err)
+			return 0, err
+		}
+
+		log.Printf("chain %s: step %d: read 0x%x -> 0x%x",
+			ptr.DisplayName(), step, addr, value)
+
+		return value, nil
+	}
+}
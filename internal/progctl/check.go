@@ -0,0 +1,138 @@
+package progctl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/kernel32"
+)
+
+// Snapshot is the memory of a process that is not running,
+// such as a memory dump.
+type Snapshot struct {
+	Mem ProcessIO
+
+	// Modules maps module file names to their base addresses.
+	// It must contain the program's exe name.
+	Modules map[string]uintptr
+
+	Is32Bit bool
+}
+
+// PointerResult is the result of resolving a pointer
+// in a Snapshot.
+type PointerResult struct {
+	// Section identifies the section containing the pointer
+	// (e.g. "saverestore#1").
+	Section string
+	Pointer appconfig.Pointer
+	Addr    uintptr
+
+	// Data is the memory at Addr. It is the size of the
+	// pointer, or the size of the data for writers.
+	Data []byte
+	Err  error
+}
+
+// CheckPointers resolves each of the program's pointers against
+// snapshot and reads the memory they point to. This allows the
+// offsets in a config file to be tested against a memory dump.
+func CheckPointers(program *appconfig.ProgramConfig, snapshot Snapshot) ([]PointerResult, error) {
+	mods := make(map[string]kernel32.Module, len(snapshot.Modules))
+	for name, base := range snapshot.Modules {
+		name = strings.ToLower(name)
+		mods[name] = kernel32.Module{Filename: name, BaseAddr: base}
+	}
+
+	exe, hasIt := mods[program.General.ExeName]
+	if !hasIt {
+		return nil, fmt.Errorf("snapshot does not contain module %q", program.General.ExeName)
+	}
+
+	checker := &runningProgramRoutine{
+		program: program,
+		base:    exe.BaseAddr,
+		is32b:   snapshot.Is32Bit,
+		mods:    mods,
+		mem:     snapshot.Mem,
+		addrFn:  addrFnFor(snapshot.Mem, snapshot.Is32Bit),
+		done:    make(chan struct{}),
+	}
+
+	var results []PointerResult
+	for _, saveRestore := range program.SaveRestores {
+		for _, pointer := range saveRestore.Pointers {
+			result := PointerResult{
+				Section: program.SectionID(saveRestore),
+				Pointer: pointer,
+			}
+
+			result.Addr, result.Err = checker.resolve(pointer)
+			if result.Err == nil {
+				result.Data, result.Err = checker.readPointer(result.Addr, pointer)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	for _, writer := range program.Writers {
+		names := make([]string, 0, len(writer.Pointers))
+		for name := range writer.Pointers {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			writePointer := writer.Pointers[name]
+			result := PointerResult{
+				Section: program.SectionID(writer),
+				Pointer: writePointer.Pointer,
+			}
+
+			result.Addr, result.Err = checker.resolve(writePointer.Pointer)
+			if result.Err == nil {
+				result.Data, result.Err = snapshot.Mem.ReadBytes(result.Addr, len(writePointer.Data))
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	for _, nudge := range program.Nudges {
+		result := PointerResult{
+			Section: program.SectionID(nudge),
+			Pointer: nudge.Pointer,
+		}
+
+		result.Addr, result.Err = checker.resolve(nudge.Pointer)
+		if result.Err == nil {
+			result.Data, result.Err = checker.readPointer(result.Addr, nudge.Pointer)
+		}
+
+		results = append(results, result)
+	}
+
+	for _, counter := range program.Counters {
+		if counter.Trigger == nil {
+			continue
+		}
+
+		result := PointerResult{
+			Section: program.SectionID(counter),
+			Pointer: counter.Trigger.Pointer,
+		}
+
+		result.Addr, result.Err = checker.resolve(counter.Trigger.Pointer)
+		if result.Err == nil {
+			result.Data, result.Err = checker.readPointer(result.Addr, counter.Trigger.Pointer)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package progctl
+
+import (
+	"fmt"
+)
+
+// pageSize is the size of a page of memory on Windows. Memory is
+// protected one page at a time, so a read that crosses into a page
+// that cannot be read (such as a guard page) fails entirely.
+const pageSize = 0x1000
+
+// PartialReadError is returned when only the start of a range
+// of memory could be read. It describes the part of the range
+// that could not be read.
+type PartialReadError struct {
+	// Addr is the address of the first byte that
+	// could not be read.
+	Addr uintptr
+
+	// Size is the number of bytes from Addr to the
+	// end of the range that were not read.
+	Size int
+
+	Err error
+}
+
+func (o *PartialReadError) Error() string {
+	return fmt.Sprintf("failed to read 0x%x-0x%x (%d bytes) - %s",
+		o.Addr, o.Addr+uintptr(o.Size), o.Size, o.Err)
+}
+
+func (o *PartialReadError) Unwrap() error {
+	return o.Err
+}
+
+// readPages reads size bytes at addr from mem. Ranges that cross a
+// page boundary are read one page at a time, so that a range whose
+// end is in an unreadable page can be read up to that page. In that
+// case, the bytes that were read are returned along with a
+// *PartialReadError.
+func readPages(mem ProcessIO, addr uintptr, size int) ([]byte, error) {
+	pageEnd := (addr | (pageSize - 1)) + 1
+	if addr+uintptr(size) <= pageEnd {
+		return mem.ReadBytes(addr, size)
+	}
+
+	data := make([]byte, 0, size)
+	for len(data) < size {
+		chunkAddr := addr + uintptr(len(data))
+
+		chunkSize := int((chunkAddr | (pageSize - 1)) + 1 - chunkAddr)
+		if remaining := size - len(data); chunkSize > remaining {
+			chunkSize = remaining
+		}
+
+		chunk, err := mem.ReadBytes(chunkAddr, chunkSize)
+		if err != nil {
+			return data, &PartialReadError{
+				Addr: chunkAddr,
+				Size: size - len(data),
+				Err:  err,
+			}
+		}
+
+		data = append(data, chunk...)
+	}
+
+	return data, nil
+}
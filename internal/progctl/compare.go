@@ -0,0 +1,128 @@
+package progctl
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// CompareResult is the result of comparing the current
+// value of a pointer with its saved value.
+type CompareResult struct {
+	// Pointer is the pointer's display name.
+	Pointer string
+
+	// Equal is true if the current value equals the value
+	// that restoring the saved state would write.
+	Equal bool
+
+	// Saved and Current are the formatted saved
+	// and current values.
+	Saved   string
+	Current string
+}
+
+// StateCompareNotifier is optionally implemented by a Notifier to be
+// notified when a SaveRestore section's compareState keybind is pressed.
+// results is empty if no state has been saved.
+type StateCompareNotifier interface {
+	StateCompared(exename string, saveRestore *appconfig.SaveRestore, results []CompareResult)
+}
+
+// doCompare logs whether the current values of the section's pointers
+// equal their saved values. This shows whether a restore took effect
+// in games whose display lags behind their memory.
+func (o *runningProgramRoutine) doCompare(v *appconfig.SaveRestore) error {
+	o.trace.action(traceOpCompare, o.program.SectionID(v))
+
+	var results []CompareResult
+	numEqual := 0
+	for _, pointer := range v.Pointers {
+		state, hasIt := o.states[pointer.Name]
+		if !hasIt || !state.stateSet {
+			continue
+		}
+
+		result, err := o.compareState(pointer.DisplayName(), state)
+		if err != nil {
+			return err
+		}
+
+		if result.Equal {
+			numEqual++
+		}
+
+		results = append(results, result)
+	}
+
+	name := v.DisplayName()
+	if name == "" {
+		name = o.program.SectionID(v)
+	}
+
+	if len(results) == 0 {
+		log.Printf("cannot compare '%s' - no state has been saved", name)
+	} else {
+		log.Printf("compared '%s' - %d of %d values equal the saved state",
+			name, numEqual, len(results))
+	}
+
+	compareNotif, ok := o.notif.(StateCompareNotifier)
+	if ok {
+		compareNotif.StateCompared(o.program.General.ExeName, v, results)
+	}
+
+	o.notifyAction(ActionCompare, o.program.SectionID(v), v.DisplayName())
+
+	return nil
+}
+
+func (o *runningProgramRoutine) compareState(name string, state *programState) (CompareResult, error) {
+	stateAddr, err := o.resolve(state.pointer)
+	if err != nil {
+		return CompareResult{}, fmt.Errorf("failed to get memory address of state %s - %w",
+			name, err)
+	}
+
+	o.trace.resolved(state.pointer.Name, stateAddr)
+
+	current, err := o.readPointer(stateAddr, state.pointer)
+	if err != nil {
+		return CompareResult{}, fmt.Errorf("failed to read from %s at 0x%x - %w",
+			name, stateAddr, err)
+	}
+
+	// The saved value is compared after applying the pointer's
+	// value options, since that is what a restore writes.
+	saved, err := state.pointer.Filter.Apply(state.savedState)
+	if err != nil {
+		return CompareResult{}, fmt.Errorf("failed to apply value options to %s - %w", name, err)
+	}
+
+	// Bits outside of the pointer's mask are not restored,
+	// so they are not compared either.
+	saved = state.pointer.Masked(current, saved)
+
+	// Only the text of strings is compared, not the
+	// contents of their buffers after the text.
+	saved = state.pointer.TerminateString(saved)
+	current = state.pointer.TerminateString(current)
+
+	result := CompareResult{
+		Pointer: name,
+		Equal:   bytes.Equal(saved, current),
+		Saved:   state.pointer.FormatValue(saved),
+		Current: state.pointer.FormatValue(current),
+	}
+
+	if result.Equal {
+		log.Printf("%s equals the saved state (%s)", name, result.Current)
+	} else {
+		log.Printf("%s differs from the saved state (saved %s, current %s)",
+			name, result.Saved, result.Current)
+	}
+
+	return result, nil
+}
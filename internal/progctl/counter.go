@@ -0,0 +1,86 @@
+package progctl
+
+import (
+	"log"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// watchCounterTrigger reads the memory of v's trigger every
+// trigger interval until the routine exits, and increments v each
+// time that the trigger fires (see appconfig.CounterTrigger.Fires).
+// The first value that is read does not increment v, so that
+// attaching to the program is not counted.
+func (o *runningProgramRoutine) watchCounterTrigger(v *appconfig.Counter) {
+	trigger := v.Trigger
+
+	ticker := time.NewTicker(trigger.Interval)
+	defer ticker.Stop()
+
+	// The process is read directly, rather than through
+	// o.mem, so that the reads are not recorded in traces.
+	addrFn := addrFnFor(o.proc, o.is32b)
+
+	var prev []byte
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		// Reading would reopen the handle of an idle program,
+		// and the value cannot change while it is halted.
+		if o.proc.released() || o.haltReason() != "" {
+			continue
+		}
+
+		// The pointer is often invalid until the game reaches
+		// a certain point (e.g. a loading screen), so failing
+		// to read it is not an error.
+		var current []byte
+		addr, err := o.resolveWith(trigger.Pointer, addrFn)
+		if err == nil {
+			current, err = o.proc.ReadBytes(addr, trigger.Pointer.NBytes)
+		}
+		if err != nil {
+			prev = nil
+			continue
+		}
+
+		fires := prev != nil && trigger.Fires(prev, current)
+		prev = current
+
+		if fires && !o.countTriggered(v) {
+			return
+		}
+	}
+}
+
+// countTriggered increments v and follows its chain after its
+// trigger fired. It returns false if the routine exited.
+func (o *runningProgramRoutine) countTriggered(v *appconfig.Counter) bool {
+	o.actionMu.Lock()
+	defer o.actionMu.Unlock()
+
+	select {
+	case <-o.done:
+		return false
+	default:
+	}
+
+	log.Printf("%s was triggered by %s", o.program.SectionID(v), v.Trigger.Pointer.DisplayName())
+
+	o.doCount(v)
+
+	// A chain that fails is handled like the
+	// chain of a keybind that failed.
+	err := o.runChain(v, nil)
+	if err != nil {
+		o.exited(o.sectionError(v, err))
+		return false
+	}
+
+	return true
+}
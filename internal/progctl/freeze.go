@@ -0,0 +1,273 @@
+package progctl
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/user32"
+)
+
+// toggleFreeze turns a Writer whose Freeze field is true on or off.
+// Turning it on writes its data like doWrite and then rewrites it every
+// FreezeInterval until it is turned off or the routine exits.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) toggleFreeze(v *appconfig.Writer) error {
+	stop, isOn := o.freezes[v]
+	if isOn {
+		close(stop)
+		delete(o.freezes, v)
+
+		log.Printf("unfroze %s", o.writerName(v))
+		o.notifyWriterState(v, false)
+
+		return nil
+	}
+
+	// Writing once before starting the loop reports errors
+	// right away and records the write in the trace.
+	err := o.doWrite(v)
+	if err != nil {
+		return err
+	}
+
+	if o.safe {
+		return nil
+	}
+
+	if o.freezes == nil {
+		o.freezes = make(map[*appconfig.Writer]chan struct{})
+	}
+
+	stop = make(chan struct{})
+	o.freezes[v] = stop
+
+	goLabeled(o.program.General.ExeName, "freeze", func() {
+		o.freeze(v, stop)
+	})
+
+	log.Printf("froze %s", o.writerName(v))
+	o.notifyWriterState(v, true)
+
+	return nil
+}
+
+// stopFreezes turns off every frozen writer.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) stopFreezes() {
+	for writer := range o.freezes {
+		// Turning a writer off cannot fail.
+		_ = o.toggleFreeze(writer)
+	}
+}
+
+// turnOnWriter writes v, or turns it on if it is a freeze writer.
+// The frozen writers that are mutually exclusive with v are only
+// turned off once v has been written, so a write that fails does
+// not leave every writer in the group off.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) turnOnWriter(v *appconfig.Writer) error {
+	var err error
+	if v.Freeze {
+		err = o.toggleFreeze(v)
+	} else {
+		err = o.doWrite(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	o.stopExclusive(v)
+
+	return nil
+}
+
+// stopExclusive turns off the frozen writers that are mutually
+// exclusive with v. It is called after v is written or turned on.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) stopExclusive(v *appconfig.Writer) {
+	for _, other := range o.program.ExclusiveWriters(v) {
+		_, isOn := o.freezes[other]
+		if isOn {
+			log.Printf("turning off %s since it is in the same group as %s",
+				o.writerName(other), o.writerName(v))
+
+			// Turning a writer off cannot fail.
+			_ = o.toggleFreeze(other)
+		}
+	}
+}
+
+// freeze rewrites the writer's data every FreezeInterval until stop
+// is closed or the routine exits. If a write fails, the routine exits
+// like it does when a keybind's action fails.
+func (o *runningProgramRoutine) freeze(v *appconfig.Writer, stop <-chan struct{}) {
+	// The process is accessed directly, rather than through
+	// o.mem, so that the rewrites are not recorded in traces.
+	addrFn := addrFnFor(o.proc, o.is32b)
+
+	interval := v.FreezeInterval
+	if interval < o.minFreezeInterval {
+		interval = o.minFreezeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		o.actionMu.Lock()
+
+		// The writer may have been toggled off, or the
+		// routine may have exited, while waiting for the lock.
+		select {
+		case <-stop:
+			o.actionMu.Unlock()
+			return
+		case <-o.done:
+			o.actionMu.Unlock()
+			return
+		default:
+		}
+
+		if o.isPaused(addrFn) {
+			o.actionMu.Unlock()
+			continue
+		}
+
+		err := o.rewrite(v, addrFn)
+		if err != nil {
+			// Writes fail once the program exits, which
+			// is reported by the routine rather than here.
+			select {
+			case <-o.done:
+				o.actionMu.Unlock()
+				return
+			default:
+			}
+		}
+
+		o.actionMu.Unlock()
+
+		if err != nil {
+			o.exited(o.sectionError(v, fmt.Errorf("failed to freeze %s - %w", o.writerName(v), err)))
+			return
+		}
+	}
+}
+
+// isPaused returns true if frozen writers should not write because
+// the program is halted, minimized, or its paused pointer is set (see
+// haltReason and appconfig.General). Changes to the paused state are
+// logged.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) isPaused(addrFn func(uintptr) (uintptr, error)) bool {
+	paused, reason := o.pauseReason(addrFn)
+	if paused != o.paused {
+		o.paused = paused
+
+		if paused {
+			log.Printf("pausing frozen writers for %s - %s",
+				o.program.General.ExeName, reason)
+		} else {
+			log.Printf("resuming frozen writers for %s", o.program.General.ExeName)
+		}
+	}
+
+	return paused
+}
+
+func (o *runningProgramRoutine) pauseReason(addrFn func(uintptr) (uintptr, error)) (bool, string) {
+	general := o.program.General
+
+	reason := o.haltReason()
+	if reason != "" {
+		return true, reason
+	}
+
+	if general.PauseWhenMinimized && user32.IsMinimized(int(o.proc.PID)) {
+		return true, "the program is minimized"
+	}
+
+	if general.PausedPointer != nil {
+		// The pointer may not be valid until the game
+		// reaches a certain point, which is not an error.
+		addr, err := o.resolveWith(*general.PausedPointer, addrFn)
+		if err != nil {
+			return false, ""
+		}
+
+		data, err := o.proc.ReadBytes(addr, 1)
+		if err == nil && data[0] != 0 {
+			return true, "the game is paused"
+		}
+	}
+
+	return false, ""
+}
+
+// rewrite writes the data of each of the writer's
+// pointers without logging.
+func (o *runningProgramRoutine) rewrite(v *appconfig.Writer, addrFn func(uintptr) (uintptr, error)) error {
+	for _, pointer := range v.Pointers {
+		writeAddr, err := o.resolveWith(pointer.Pointer, addrFn)
+		if err != nil {
+			return fmt.Errorf("failed to lookup write address %s - %w",
+				pointer.Pointer.DisplayName(), err)
+		}
+
+		data, err := pointer.Pointer.Filter.Apply(pointer.Data)
+		if err != nil {
+			return fmt.Errorf("failed to apply value options to %s - %w",
+				pointer.Pointer.DisplayName(), err)
+		}
+
+		if pointer.Pointer.Mask != nil {
+			current, err := o.proc.ReadBytes(writeAddr, len(data))
+			if err != nil {
+				return fmt.Errorf("failed to read masked value at %s (0x%x) - %w",
+					pointer.Pointer.DisplayName(), writeAddr, err)
+			}
+
+			data = pointer.Pointer.Masked(current, data)
+		}
+
+		err = o.proc.WriteBytes(writeAddr, data)
+		if err != nil {
+			return fmt.Errorf("failed to write bytes at %s (0x%x) - %w",
+				pointer.Pointer.DisplayName(), writeAddr, err)
+		}
+	}
+
+	return nil
+}
+
+// writerName returns the writer's label, or its section ID
+// if it does not have a label.
+func (o *runningProgramRoutine) writerName(v *appconfig.Writer) string {
+	if v.DisplayName() != "" {
+		return v.DisplayName()
+	}
+
+	return o.program.SectionID(v)
+}
+
+func (o *runningProgramRoutine) notifyWriterState(v *appconfig.Writer, on bool) {
+	stateNotif, ok := o.notif.(WriterStateNotifier)
+	if ok {
+		stateNotif.WriterStateChanged(o.program.General.ExeName, v, on)
+	}
+}
@@ -0,0 +1,82 @@
+package progctl
+
+import (
+	"log"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// graceRetryInterval is how often a failed action is
+// retried during the attach grace period.
+const graceRetryInterval = time.Second
+
+// maxGraceRetries limits how many times a section's action is
+// retried during the attach grace period, in case the period
+// is long.
+const maxGraceRetries = 60
+
+// graceRetryKey identifies the pending retry of a section's
+// action in the scheduler.
+type graceRetryKey struct {
+	section interface{}
+}
+
+// retryInGracePeriod schedules section's action to be retried if
+// the program was attached to less than AttachGracePeriod ago.
+// Many games only allocate the structures that pointers lead to
+// once they reach the main menu, so failures shortly after
+// attaching are not reported until the grace period ends.
+//
+// A section has at most one pending retry, which is cancelled when
+// the routine exits, and it is retried at most maxGraceRetries times.
+//
+// It returns false if err should be handled normally.
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) retryInGracePeriod(section interface{}, pressedKey appconfig.Key, err error) bool {
+	gracePeriod := o.program.General.AttachGracePeriod
+	if gracePeriod <= 0 || time.Since(o.attachedAt) >= gracePeriod {
+		delete(o.graceRetries, section)
+		return false
+	}
+
+	if o.graceRetries == nil {
+		o.graceRetries = make(map[interface{}]int)
+	}
+
+	attempts := o.graceRetries[section]
+	if attempts >= maxGraceRetries {
+		delete(o.graceRetries, section)
+		return false
+	}
+
+	o.graceRetries[section] = attempts + 1
+
+	log.Printf("%s failed during attach grace period, retrying - %s",
+		o.program.SectionID(section), err)
+
+	o.setState(StateDegraded, err)
+
+	// A retry that is pending when the keybind is
+	// pressed again is replaced by the new one.
+	o.schedule(graceRetryKey{section: section}, graceRetryInterval, func() {
+		err := o.handleChained(section, pressedKey)
+		if err == nil {
+			delete(o.graceRetries, section)
+			o.setState(StateAttached, nil)
+			return
+		}
+
+		if !o.retryInGracePeriod(section, pressedKey, err) {
+			o.exited(o.sectionError(section, err))
+		}
+	})
+
+	return true
+}
+
+func (o *runningProgramRoutine) setState(state State, err error) {
+	if o.onState != nil {
+		o.onState(state, err)
+	}
+}
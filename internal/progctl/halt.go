@@ -0,0 +1,269 @@
+package progctl
+
+import (
+	"log"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Andoryuuta/kiwi"
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/kernel32"
+)
+
+const (
+	// haltCheckInterval is how often watchHalt checks
+	// whether a debugger is attached to the program.
+	haltCheckInterval = 250 * time.Millisecond
+
+	// suspendCheckInterval is how often the program's threads
+	// are checked for being suspended when a debugger is not
+	// attached, since the check queries every process.
+	suspendCheckInterval = time.Second
+
+	// haltPollInterval is how often deferred actions
+	// check whether the program has resumed.
+	haltPollInterval = 250 * time.Millisecond
+
+	// maxDeferredPresses is the number of key presses that are
+	// deferred while the program is halted. Older presses are
+	// discarded once it is exceeded.
+	maxDeferredPresses = 8
+)
+
+// haltState is whether the program is halted (see haltReason),
+// and the key presses that are waiting for it to resume.
+type haltState struct {
+	// reason is set by watchHalt. It is guarded by mu rather
+	// than actionMu so that watchHalt never waits for an action.
+	mu     sync.Mutex
+	reason string
+
+	// queue contains the key presses that were deferred
+	// while the program was halted. It is guarded by actionMu.
+	queue []deferredPress
+}
+
+// deferredPress is a key press that was deferred
+// while the program was halted.
+type deferredPress struct {
+	pressed []boundSection
+	trigger *ActionTrigger
+}
+
+// haltReason returns why writes to the program are deferred, or an
+// empty string if they are not. Writes are deferred while every
+// thread of the program is suspended, such as when a debugger has
+// stopped it at a breakpoint, so that blaj does not fight with
+// someone stepping through the game.
+//
+// It only returns the result of the last check by watchHalt, so it
+// is cheap enough to call before every action.
+func (o *runningProgramRoutine) haltReason() string {
+	o.halt.mu.Lock()
+	defer o.halt.mu.Unlock()
+
+	return o.halt.reason
+}
+
+// watchHalt checks whether the program is halted every
+// haltCheckInterval until the routine exits. Changes are logged.
+//
+// Checking for suspended threads queries every thread on the
+// system, so it is only done every suspendCheckInterval unless
+// a debugger is attached, and it is never done on the keyboard
+// hook's thread.
+func (o *runningProgramRoutine) watchHalt() {
+	ticker := time.NewTicker(haltCheckInterval)
+	defer ticker.Stop()
+
+	var debugged, suspended bool
+	var suspendCheckedAt time.Time
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		// The program cannot be written to while its handle
+		// is released, and checking would require reopening it.
+		if o.proc.released() {
+			continue
+		}
+
+		var isDebugged bool
+		err := o.proc.useOpen(func(proc *kiwi.Process) error {
+			var err error
+			isDebugged, err = kernel32.CheckRemoteDebuggerPresent(syscall.Handle(proc.Handle))
+			return err
+		})
+		if err == nil && isDebugged != debugged {
+			debugged = isDebugged
+
+			if debugged {
+				log.Printf("a debugger attached to %s - writes are deferred while it is stopped",
+					o.program.General.ExeName)
+			} else {
+				log.Printf("a debugger detached from %s", o.program.General.ExeName)
+			}
+		}
+
+		now := time.Now()
+		if debugged || now.Sub(suspendCheckedAt) >= suspendCheckInterval {
+			suspendCheckedAt = now
+
+			isSuspended, err := kernel32.IsProcessSuspended(uint32(o.proc.PID))
+			if err == nil {
+				suspended = isSuspended
+			}
+		}
+
+		var reason string
+		switch {
+		case suspended && debugged:
+			reason = "the program is stopped in a debugger"
+		case suspended:
+			reason = "the program is suspended"
+		}
+
+		o.setHaltReason(reason)
+	}
+}
+
+// setHaltReason records the result of checking
+// whether the program is halted, logging changes.
+func (o *runningProgramRoutine) setHaltReason(reason string) {
+	o.halt.mu.Lock()
+	defer o.halt.mu.Unlock()
+
+	if reason == o.halt.reason {
+		return
+	}
+
+	o.halt.reason = reason
+
+	if reason != "" {
+		log.Printf("deferring writes to %s - %s", o.program.General.ExeName, reason)
+	} else {
+		log.Printf("%s resumed", o.program.General.ExeName)
+	}
+}
+
+// deferWhileHalted queues the actions of a key press if the program
+// is halted and any of the actions write to its memory. The queued
+// key presses are performed in order once the program resumes. It
+// returns false if the actions should be performed now.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) deferWhileHalted(pressed []boundSection) bool {
+	writes := false
+	for _, bound := range pressed {
+		if writesOnKey(bound.section, bound.key) {
+			writes = true
+			break
+		}
+	}
+
+	if !writes {
+		return false
+	}
+
+	reason := o.haltReason()
+	if reason == "" {
+		return false
+	}
+
+	log.Printf("deferring %s until %s resumes", o.program.SectionID(pressed[0].section),
+		o.program.General.ExeName)
+
+	// Pressing the same keybind again (including by holding it
+	// down) replaces its earlier press, so that the press is only
+	// performed once when the program resumes.
+	queue := o.halt.queue[:0]
+	for _, press := range o.halt.queue {
+		if !samePress(press.pressed, pressed) {
+			queue = append(queue, press)
+		}
+	}
+
+	queue = append(queue, deferredPress{
+		pressed: pressed,
+		trigger: o.trigger,
+	})
+
+	if len(queue) > maxDeferredPresses {
+		log.Printf("discarding %d of the key presses deferred until %s resumes",
+			len(queue)-maxDeferredPresses, o.program.General.ExeName)
+
+		queue = append(queue[:0], queue[len(queue)-maxDeferredPresses:]...)
+	}
+
+	o.halt.queue = queue
+
+	// The scheduler does not perform actions
+	// while the program is halted.
+	o.schedule(&o.halt, haltPollInterval, o.runDeferred)
+
+	return true
+}
+
+// runDeferred performs the key presses that were
+// deferred while the program was halted.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) runDeferred() {
+	queue := o.halt.queue
+	o.halt.queue = nil
+
+	for _, press := range queue {
+		o.trigger = press.trigger
+		ok := o.runPressed(press.pressed)
+		o.trigger = nil
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// samePress returns true if a and b are the same
+// sections bound to the same keys.
+func samePress(a []boundSection, b []boundSection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writesOnKey returns true if the action of section for key
+// may write to the program's memory, including the actions
+// of the sections that it chains to.
+func writesOnKey(section interface{}, key appconfig.Key) bool {
+	chain := appconfig.SectionChain(section)
+	if chain != nil && (chain.OnSuccess != nil || chain.OnFailure != nil) {
+		return true
+	}
+
+	switch v := section.(type) {
+	case *appconfig.SaveRestore:
+		if key == v.SaveState || key == v.CompareState {
+			return false
+		}
+
+		_, save, isSlotKey := v.Slot(key)
+		return !isSlotKey || !save
+	case *appconfig.Counter:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,231 @@
+package progctl
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"syscall"
+
+	"github.com/Andoryuuta/kiwi"
+	"github.com/Andoryuuta/kiwi/w32"
+	"github.com/SeungKang/blaj/internal/kernel32"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	errHandleClosed   = errors.New("process handle is closed")
+	errHandleReleased = errors.New("process handle is released while the program is idle")
+)
+
+const (
+	// accessRead allows the process's modules to be
+	// listed and its memory to be read and dumped.
+	accessRead = windows.PROCESS_QUERY_INFORMATION | windows.PROCESS_VM_READ
+
+	// accessWrite additionally allows the
+	// process's memory to be written.
+	accessWrite = accessRead | windows.PROCESS_VM_WRITE | windows.PROCESS_VM_OPERATION
+)
+
+// processHandle is a ProcessIO that manages the handle of a process
+// and the access rights that it was opened with. Every operation
+// requests the rights that it needs, and the handle is reopened with
+// more rights if it lacks them. This is the only place where blaj
+// opens a handle to a program.
+//
+// The handle can also be released while the program is idle (see
+// releaseWhenIdle), in which case it is reopened the next time that
+// memory is accessed.
+type processHandle struct {
+	// PID is the process's ID.
+	PID uint64
+
+	// onReopen, if non-nil, is called after
+	// a released handle is reopened.
+	onReopen func()
+
+	// minAccess are the rights that the handle is
+	// always opened with, in addition to the rights
+	// requested by the current operation.
+	minAccess uint32
+
+	mu     sync.RWMutex
+	proc   kiwi.Process
+	access uint32
+	closed bool
+}
+
+// openProcessHandle opens the process identified by pid with
+// minAccess, which is either accessRead or accessWrite.
+func openProcessHandle(pid int, minAccess uint32) (*processHandle, error) {
+	handle := &processHandle{
+		PID:       uint64(pid),
+		minAccess: minAccess,
+	}
+
+	handle.mu.Lock()
+	defer handle.mu.Unlock()
+
+	err := handle.openLocked(handle.minAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	return handle, nil
+}
+
+func (o *processHandle) ReadBytes(addr uintptr, size int) ([]byte, error) {
+	var data []byte
+
+	err := o.use(accessRead, func(proc *kiwi.Process) error {
+		var err error
+		data, err = kernel32.ReadProcessMemory(syscall.Handle(proc.Handle), addr, size)
+		return err
+	})
+
+	return data, err
+}
+
+func (o *processHandle) WriteBytes(addr uintptr, data []byte) error {
+	return o.use(accessWrite, func(proc *kiwi.Process) error {
+		return kernel32.WriteProcessMemory(syscall.Handle(proc.Handle), addr, data)
+	})
+}
+
+// use calls fn with the open process, first reopening the handle
+// if it was released or if it lacks the access rights in access.
+func (o *processHandle) use(access uint32, fn func(proc *kiwi.Process) error) error {
+	for {
+		o.mu.RLock()
+		if o.proc.Handle != 0 && o.access&access == access {
+			defer o.mu.RUnlock()
+			return fn(&o.proc)
+		}
+		o.mu.RUnlock()
+
+		err := o.reopen(access)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// useOpen calls fn with the open process. Unlike use, a released
+// handle is not reopened, and errHandleReleased is returned instead,
+// so that querying the process does not undo releaseWhenIdle. The
+// handle only has its minimum access rights.
+func (o *processHandle) useOpen(fn func(proc *kiwi.Process) error) error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.proc.Handle == 0 {
+		if o.closed {
+			return errHandleClosed
+		}
+
+		return errHandleReleased
+	}
+
+	return fn(&o.proc)
+}
+
+// reopen opens the handle with access, keeping
+// the rights of the current handle, if any.
+func (o *processHandle) reopen(access uint32) error {
+	o.mu.Lock()
+
+	if o.closed {
+		o.mu.Unlock()
+		return errHandleClosed
+	}
+
+	wasReleased := o.proc.Handle == 0
+	if !wasReleased {
+		if o.access&access == access {
+			o.mu.Unlock()
+			return nil
+		}
+
+		access |= o.access
+	}
+
+	err := o.openLocked(access)
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if wasReleased && o.onReopen != nil {
+		o.onReopen()
+	}
+
+	return nil
+}
+
+// openLocked replaces the handle with one that has access in addition
+// to minAccess. mu must be held by the caller.
+func (o *processHandle) openLocked(access uint32) error {
+	access |= o.minAccess
+
+	handle, err := kernel32.OpenProcess(uint32(o.PID), access)
+	if err != nil {
+		return err
+	}
+
+	if o.proc.Handle != 0 {
+		log.Printf("reopened process %d with access 0x%x (was 0x%x)", o.PID, access, o.access)
+
+		_ = windows.CloseHandle(windows.Handle(o.proc.Handle))
+	}
+
+	o.proc = kiwi.Process{
+		ProcPlatAttribs: kiwi.ProcPlatAttribs{Handle: w32.HANDLE(handle)},
+		PID:             o.PID,
+	}
+	o.access = access
+
+	return nil
+}
+
+// released returns true if the handle was released
+// and has not been reopened yet.
+func (o *processHandle) released() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.proc.Handle == 0 && !o.closed
+}
+
+// release closes the handle until it is used again. It returns
+// false if the handle was already released or closed.
+func (o *processHandle) release() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.proc.Handle == 0 {
+		return false
+	}
+
+	_ = windows.CloseHandle(windows.Handle(o.proc.Handle))
+	o.proc.Handle = 0
+	o.access = 0
+
+	return true
+}
+
+// close closes the handle permanently.
+func (o *processHandle) close() {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.proc.Handle != 0 {
+		_ = windows.CloseHandle(windows.Handle(o.proc.Handle))
+		o.proc.Handle = 0
+	}
+
+	o.closed = true
+}
@@ -0,0 +1,156 @@
+package progctl
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// UnhealthyPointer is a pointer that failed a health check.
+type UnhealthyPointer struct {
+	// Section is the ID of the section that
+	// contains the pointer (see ProgramConfig.SectionID).
+	Section string
+
+	Pointer appconfig.Pointer
+	Err     error
+}
+
+// PointerHealthNotifier is optionally implemented by a Notifier to be
+// notified when the set of pointers that fail health checks changes.
+// unhealthy is empty once every pointer passes.
+type PointerHealthNotifier interface {
+	PointerHealthChanged(exename string, unhealthy []UnhealthyPointer)
+}
+
+// checkPointerHealth periodically resolves each of the program's
+// pointers and reads the value that it points to until the routine
+// exits. The notifier is told which pointers fail, so that a config
+// that broke after a game update is noticed before its keybinds are
+// needed.
+func (o *runningProgramRoutine) checkPointerHealth(interval time.Duration) {
+	notif, ok := o.notif.(PointerHealthNotifier)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev map[string]struct{}
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		// Reading would reopen the handle of an idle program.
+		if o.proc.released() {
+			continue
+		}
+
+		unhealthy := o.unhealthyPointers()
+
+		current := make(map[string]struct{}, len(unhealthy))
+		for _, pointer := range unhealthy {
+			current[pointer.Section+"."+pointer.Pointer.Name] = struct{}{}
+		}
+
+		if sameKeys(prev, current) {
+			continue
+		}
+
+		prev = current
+
+		for _, pointer := range unhealthy {
+			log.Printf("health check of %s in %s failed - %s",
+				pointer.Pointer.DisplayName(), pointer.Section, pointer.Err)
+		}
+
+		if len(unhealthy) == 0 {
+			log.Printf("health check of every pointer passed")
+		}
+
+		notif.PointerHealthChanged(o.program.General.ExeName, unhealthy)
+	}
+}
+
+// unhealthyPointers checks every pointer in the program's
+// SaveRestore and Writer sections and returns the ones that
+// cannot be resolved or read.
+func (o *runningProgramRoutine) unhealthyPointers() []UnhealthyPointer {
+	// The process is read directly, rather than through
+	// o.mem, so that health checks are not recorded in
+	// traces or retried.
+	addrFn := addrFnFor(o.proc, o.is32b)
+
+	var unhealthy []UnhealthyPointer
+	check := func(section interface{}, pointer appconfig.Pointer) {
+		err := o.checkPointer(pointer, addrFn)
+		if err != nil {
+			unhealthy = append(unhealthy, UnhealthyPointer{
+				Section: o.program.SectionID(section),
+				Pointer: pointer,
+				Err:     err,
+			})
+		}
+	}
+
+	for _, saveRestore := range o.program.SaveRestores {
+		for _, pointer := range saveRestore.Pointers {
+			check(saveRestore, pointer)
+		}
+	}
+
+	for _, writer := range o.program.Writers {
+		names := make([]string, 0, len(writer.Pointers))
+		for name := range writer.Pointers {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			check(writer, writer.Pointers[name].Pointer)
+		}
+	}
+
+	return unhealthy
+}
+
+// checkPointer resolves pointer and reads the first byte of its value.
+func (o *runningProgramRoutine) checkPointer(pointer appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) error {
+	addr, err := o.resolveWith(pointer, addrFn)
+	if err != nil {
+		return fmt.Errorf("failed to lookup address - %w", err)
+	}
+
+	if len(pointer.Fields) > 0 {
+		addr += pointer.Fields[0]
+	}
+
+	_, err = o.proc.ReadBytes(addr, 1)
+	if err != nil {
+		return fmt.Errorf("failed to read value at 0x%x - %w", addr, err)
+	}
+
+	return nil
+}
+
+func sameKeys(a map[string]struct{}, b map[string]struct{}) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+
+	for key := range a {
+		if _, hasIt := b[key]; !hasIt {
+			return false
+		}
+	}
+
+	return true
+}
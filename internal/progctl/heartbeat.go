@@ -0,0 +1,36 @@
+package progctl
+
+import (
+	"fmt"
+	"time"
+)
+
+// heartbeat periodically reads a byte from the program's exe module
+// until the routine exits. If the read fails, the routine exits so
+// that the program is reattached to, rather than failing the next
+// time a keybind is pressed. This catches process handles that have
+// silently become invalid.
+func (o *runningProgramRoutine) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+			// An idle program's handle was closed on purpose.
+			if o.proc.released() {
+				continue
+			}
+
+			// The process is read directly, rather than through
+			// o.mem, so that heartbeats are not recorded in traces.
+			_, err := o.proc.ReadBytes(o.base, 1)
+			if err != nil {
+				o.exited(fmt.Errorf("heartbeat read at 0x%x failed - %w", o.base, err))
+				return
+			}
+		}
+	}
+}
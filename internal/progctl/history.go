@@ -0,0 +1,127 @@
+package progctl
+
+import (
+	"math"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+const (
+	// historySamples is the number of values recorded for
+	// each graphed pointer. Values are sampled every
+	// General.ValueHistory divided by historySamples.
+	historySamples = 20
+
+	// minHistorySampleInterval limits how often
+	// the values of graphed pointers are read.
+	minHistorySampleInterval = 50 * time.Millisecond
+)
+
+// ValueHistory contains the recent values of a graphed pointer
+// (see appconfig.ProgramConfig.GraphedPointers).
+type ValueHistory struct {
+	appconfig.GraphedPointer
+
+	// Values are the recorded values, oldest first. A value is
+	// NaN if the pointer could not be read when it was sampled.
+	Values []float64
+}
+
+// ValueHistoryNotifier is optionally implemented by a Notifier to be
+// notified each time the values of graphed pointers are sampled.
+type ValueHistoryNotifier interface {
+	ValueHistoryChanged(exename string, history []ValueHistory)
+}
+
+// recordHistory samples the values of the graphed pointers until the
+// routine exits, keeping the values from the last window of time.
+func (o *runningProgramRoutine) recordHistory(window time.Duration) {
+	pointers := o.program.GraphedPointers()
+	if len(pointers) == 0 {
+		return
+	}
+
+	notif, ok := o.notif.(ValueHistoryNotifier)
+	if !ok {
+		return
+	}
+
+	interval := window / historySamples
+	if interval < minHistorySampleInterval {
+		interval = minHistorySampleInterval
+	}
+
+	// The process is read directly, rather than through
+	// o.mem, so that samples are not recorded in traces.
+	addrFn := addrFnFor(o.proc, o.is32b)
+
+	history := make([]ValueHistory, len(pointers))
+	for i, pointer := range pointers {
+		history[i] = ValueHistory{
+			GraphedPointer: pointer,
+			Values:         make([]float64, 0, historySamples),
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		// Reading would reopen the handle of an idle program.
+		if o.proc.released() {
+			continue
+		}
+
+		snapshot := make([]ValueHistory, len(history))
+		for i := range history {
+			values := history[i].Values
+			if len(values) == historySamples {
+				values = append(values[:0], values[1:]...)
+			}
+
+			history[i].Values = append(values, o.sample(history[i].Pointer, addrFn))
+
+			// The values are copied since they
+			// are modified by the next sample.
+			snapshot[i] = ValueHistory{
+				GraphedPointer: history[i].GraphedPointer,
+				Values:         append([]float64(nil), history[i].Values...),
+			}
+		}
+
+		notif.ValueHistoryChanged(o.program.General.ExeName, snapshot)
+	}
+}
+
+// sample returns the first value at pointer, or NaN if it cannot
+// be read. Pointers are often invalid until the game reaches a
+// certain point (e.g. a loading screen), so this is not an error.
+func (o *runningProgramRoutine) sample(pointer appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) float64 {
+	addr, err := o.resolveWith(pointer, addrFn)
+	if err != nil {
+		return math.NaN()
+	}
+
+	if len(pointer.Fields) > 0 {
+		addr += pointer.Fields[0]
+	}
+
+	data, err := o.proc.ReadBytes(addr, pointer.NBytes)
+	if err != nil {
+		return math.NaN()
+	}
+
+	value, ok := pointer.Display.Number(data)
+	if !ok {
+		return math.NaN()
+	}
+
+	return value
+}
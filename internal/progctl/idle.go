@@ -0,0 +1,52 @@
+package progctl
+
+import (
+	"log"
+	"time"
+)
+
+// idleCheckInterval is how often releaseWhenIdle checks
+// whether the program has become idle.
+const idleCheckInterval = time.Second
+
+// releaseWhenIdle closes the process handle once no keybind has been
+// pressed for timeout and no frozen writers or scheduled actions are
+// active, until the routine exits. Keeping a handle with memory
+// access open for hours is one of the things that anti-virus
+// heuristics flag, so an idle routine only looks up the keys of
+// keyboard events. The handle is reopened by the next keybind.
+func (o *runningProgramRoutine) releaseWhenIdle(timeout time.Duration) {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		o.actionMu.Lock()
+
+		idle := len(o.freezes) == 0 &&
+			len(o.scheduler.timers) == 0 &&
+			time.Since(o.lastActive) >= timeout
+
+		if idle && o.proc.release() {
+			log.Printf("%s has been idle for %s - released process handle",
+				o.program.General.ExeName, timeout)
+
+			o.setState(StateIdle, nil)
+		}
+
+		o.actionMu.Unlock()
+	}
+}
+
+// handleReopened is called when the process handle
+// is reopened after being released while idle.
+func (o *runningProgramRoutine) handleReopened() {
+	log.Printf("reopened process handle of %s", o.program.General.ExeName)
+
+	o.setState(StateAttached, nil)
+}
@@ -0,0 +1,18 @@
+package progctl
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// goLabeled starts fn in a new goroutine with pprof labels that
+// identify the program and the goroutine's task. The labels are
+// inherited by goroutines that fn starts, which makes it possible
+// to tell which program leaked a goroutine in a goroutine profile.
+func goLabeled(exeName string, task string, fn func()) {
+	labels := pprof.Labels("program", exeName, "task", task)
+
+	go pprof.Do(context.Background(), labels, func(context.Context) {
+		fn()
+	})
+}
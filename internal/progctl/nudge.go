@@ -0,0 +1,55 @@
+package progctl
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// doNudge adds v's step to its value, or subtracts it if increase
+// is false. The value is read and then written back, so only the
+// nudged value changes.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) doNudge(v *appconfig.Nudge, increase bool) error {
+	if o.safe {
+		log.Printf("skipping nudge %s (safe mode is enabled)", o.program.SectionID(v))
+		return nil
+	}
+
+	op := traceOpDecrease
+	if increase {
+		op = traceOpIncrease
+	}
+
+	o.trace.action(op, o.program.SectionID(v))
+
+	addr, err := o.resolve(v.Pointer)
+	if err != nil {
+		return fmt.Errorf("failed to lookup address of %s - %w", v.Pointer.DisplayName(), err)
+	}
+
+	o.trace.resolved(v.Pointer.Name, addr)
+
+	current, err := o.mem.ReadBytes(addr, v.Pointer.NBytes)
+	if err != nil {
+		return fmt.Errorf("failed to read %s at 0x%x - %w", v.Pointer.DisplayName(), addr, err)
+	}
+
+	nudged := v.Nudged(current, increase)
+
+	err = o.mem.WriteBytes(addr, nudged)
+	if err != nil {
+		return fmt.Errorf("failed to write %s at 0x%x - %w", v.Pointer.DisplayName(), addr, err)
+	}
+
+	log.Printf("nudged %s from %s to %s at 0x%x", v.Pointer.DisplayName(),
+		v.Pointer.FormatValue(current), v.Pointer.FormatValue(nudged), addr)
+
+	o.overlaps.wrote("", v.Pointer, addr, len(nudged))
+
+	o.notifyAction(ActionNudge, o.program.SectionID(v), v.DisplayName())
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package progctl
+
+import (
+	"fmt"
+
+	"github.com/SeungKang/blaj/internal/anticheat"
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// OneShot is a temporary attachment to a running program that performs
+// actions on request rather than in response to keybinds.
+type OneShot struct {
+	// SafeMode makes Write return ErrSafeMode
+	// rather than writing memory when set to true.
+	SafeMode bool
+
+	running *runningProgramRoutine
+}
+
+// NewOneShot attaches to the running program described by program.
+// The caller must call Close when finished.
+//
+// A nil guard only uses the built-in anti-cheat lists.
+func NewOneShot(program *appconfig.ProgramConfig, guard *anticheat.Guard) (*OneShot, error) {
+	pid, err := FindPID(program.General.ExeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if pid == -1 {
+		return nil, fmt.Errorf("%s is not running", program.General.ExeName)
+	}
+
+	running, err := newRunningProgramRoutine(program, pid, nil, guard, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to %s - %w", program.General.ExeName, err)
+	}
+
+	return &OneShot{running: running}, nil
+}
+
+// ResolveAddr returns the absolute address that pointer
+// currently points to.
+func (o *OneShot) ResolveAddr(pointer appconfig.Pointer) (uintptr, error) {
+	return o.running.resolve(pointer)
+}
+
+// Close detaches from the program.
+func (o *OneShot) Close() {
+	o.running.Stop()
+}
+
+// Save reads the current value of each pointer in the section.
+// The returned map is keyed by pointer name.
+func (o *OneShot) Save(section *appconfig.SaveRestore) (map[string][]byte, error) {
+	return o.SaveSlot(section, 0)
+}
+
+// SaveSlot is like Save, except that the returned map is keyed by
+// the names of the pointers' states in save slot number (see
+// SlotStateName), so that they can be restored by RestoreSlot.
+func (o *OneShot) SaveSlot(section *appconfig.SaveRestore, slot int) (map[string][]byte, error) {
+	saved := make(map[string][]byte, len(section.Pointers))
+
+	for _, pointer := range section.Pointers {
+		state := o.running.states[pointer.Name]
+
+		err := o.running.saveState(pointer.DisplayName(), state)
+		if err != nil {
+			return nil, err
+		}
+
+		// The state's buffer is reused by the next save.
+		saved[SlotStateName(pointer.Name, slot)] = append([]byte(nil), state.savedState...)
+	}
+
+	return saved, nil
+}
+
+// Restore writes previously saved values (keyed by pointer name) to
+// each pointer in the section. Pointers without a saved value are
+// skipped.
+func (o *OneShot) Restore(section *appconfig.SaveRestore, saved map[string][]byte) error {
+	return o.RestoreSlot(section, 0, saved)
+}
+
+// RestoreSlot is like Restore, except that the values are read from
+// the pointers' states in save slot number (e.g. "health#slot1"),
+// such as the states written by Routine.SavedStates.
+func (o *OneShot) RestoreSlot(section *appconfig.SaveRestore, slot int, saved map[string][]byte) error {
+	for _, pointer := range section.Pointers {
+		data, hasIt := saved[SlotStateName(pointer.Name, slot)]
+		if !hasIt {
+			continue
+		}
+
+		if len(data) != pointer.Size() {
+			return fmt.Errorf("saved value for %s%s is %d bytes, expected %d",
+				pointer.DisplayName(), slotSuffix(slot), len(data), pointer.Size())
+		}
+
+		state := o.running.states[pointer.Name]
+		o.running.storeState(state, data)
+
+		err := o.running.restoreState(pointer.DisplayName(), state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write writes each of the writer's pointers.
+// ErrSafeMode is returned if SafeMode is enabled.
+func (o *OneShot) Write(writer *appconfig.Writer) error {
+	if o.SafeMode {
+		return ErrSafeMode
+	}
+
+	for _, pointer := range writer.Pointers {
+		err := o.running.write(writer, pointer)
+		if err != nil {
+			return fmt.Errorf("failed to write to %s - %w", pointer.Pointer.DisplayName(), err)
+		}
+	}
+
+	return nil
+}
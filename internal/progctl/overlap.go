@@ -0,0 +1,64 @@
+package progctl
+
+import (
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// writeRange is the range of bytes most recently
+// written by a writer pointer.
+type writeRange struct {
+	pointer appconfig.Pointer
+	group   string
+	addr    uintptr
+	size    int
+}
+
+// overlapChecker warns when writer pointers write to overlapping
+// bytes, which usually means two writers are fighting over the
+// same value. Each pair of pointers is only reported once. Pointers
+// of writers in the same group are not reported, since the writers
+// are mutually exclusive.
+type overlapChecker struct {
+	ranges map[string]writeRange
+	warned map[[2]string]struct{}
+}
+
+func (o *overlapChecker) wrote(group string, pointer appconfig.Pointer, addr uintptr, size int) {
+	if o.ranges == nil {
+		o.ranges = make(map[string]writeRange)
+		o.warned = make(map[[2]string]struct{})
+	}
+
+	for name, other := range o.ranges {
+		if name == pointer.Name || (group != "" && other.group == group) {
+			continue
+		}
+
+		if !appconfig.RangesOverlap(addr, size, other.addr, other.size) {
+			continue
+		}
+
+		pair := [2]string{name, pointer.Name}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+
+		if _, hasIt := o.warned[pair]; hasIt {
+			continue
+		}
+
+		o.warned[pair] = struct{}{}
+
+		log.Printf("warning: %s (0x%x) and %s (0x%x) write to overlapping bytes",
+			pointer.DisplayName(), addr, other.pointer.DisplayName(), other.addr)
+	}
+
+	o.ranges[pointer.Name] = writeRange{
+		pointer: pointer,
+		group:   group,
+		addr:    addr,
+		size:    size,
+	}
+}
@@ -0,0 +1,33 @@
+package progctl
+
+// setLastErr records the result of accessing the state's pointer.
+// A nil err clears the previous error.
+func (o *programState) setLastErr(err error) {
+	o.errMu.Lock()
+	o.lastErr = err
+	o.errMu.Unlock()
+}
+
+// PointerErrors returns the most recent error of each saved pointer
+// that failed the last time that it was resolved, read, or written.
+// The map's keys are the pointers' display names. ErrNotAttached
+// is returned if the program is not running.
+func (o *Routine) PointerErrors() (map[string]error, error) {
+	current, err := o.attached()
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make(map[string]error)
+	for _, state := range current.states {
+		state.errMu.Lock()
+		lastErr := state.lastErr
+		state.errMu.Unlock()
+
+		if lastErr != nil {
+			errs[state.pointer.DisplayName()] = lastErr
+		}
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,22 @@
+package progctl
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/SeungKang/blaj/internal/winutil"
+)
+
+// lowerThreadPriority locks the calling goroutine to its OS thread
+// and lowers the thread's priority so that polling does not compete
+// with the keyboard input thread. The thread is intentionally never
+// unlocked, which makes the runtime terminate it when the goroutine
+// exits instead of running other goroutines at the lower priority.
+func lowerThreadPriority(exeName string, task string) {
+	runtime.LockOSThread()
+
+	err := winutil.SetCurrentThreadPriority(winutil.ThreadPriorityBelowNormal)
+	if err != nil {
+		log.Printf("failed to lower priority of %s %s thread - %s", exeName, task, err)
+	}
+}
@@ -0,0 +1,131 @@
+package progctl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// ProcessIO reads and writes the memory of a target process.
+//
+// *kiwi.Process satisfies this interface. MockProcess provides
+// an in-memory implementation.
+type ProcessIO interface {
+	ReadBytes(addr uintptr, size int) ([]byte, error)
+	WriteBytes(addr uintptr, data []byte) error
+}
+
+// addrFnFor returns a function that reads a pointer-sized
+// value from mem.
+func addrFnFor(mem ProcessIO, is32Bit bool) func(uintptr) (uintptr, error) {
+	if is32Bit {
+		return func(u uintptr) (uintptr, error) {
+			data, err := mem.ReadBytes(u, 4)
+			if err != nil {
+				return 0, err
+			}
+
+			return uintptr(binary.LittleEndian.Uint32(data)), nil
+		}
+	}
+
+	return func(u uintptr) (uintptr, error) {
+		data, err := mem.ReadBytes(u, 8)
+		if err != nil {
+			return 0, err
+		}
+
+		return uintptr(binary.LittleEndian.Uint64(data)), nil
+	}
+}
+
+// NewMockProcess returns an empty MockProcess.
+func NewMockProcess() *MockProcess {
+	return &MockProcess{}
+}
+
+// MockProcess is an in-memory ProcessIO made up of non-overlapping
+// regions. Accessing memory outside of a region fails, much like
+// accessing unmapped memory in a real process.
+type MockProcess struct {
+	regions []mockRegion
+}
+
+type mockRegion struct {
+	start uintptr
+	data  []byte
+}
+
+func (o *mockRegion) end() uintptr {
+	return o.start + uintptr(len(o.data))
+}
+
+// SetBytes maps data at addr, replacing any existing data in that
+// range. Adjacent and overlapping regions are merged.
+func (o *MockProcess) SetBytes(addr uintptr, data []byte) {
+	merged := mockRegion{start: addr, data: append([]byte(nil), data...)}
+
+	var kept []mockRegion
+	for _, region := range o.regions {
+		if region.end() < merged.start || region.start > merged.end() {
+			kept = append(kept, region)
+			continue
+		}
+
+		start := region.start
+		if merged.start < start {
+			start = merged.start
+		}
+
+		end := region.end()
+		if merged.end() > end {
+			end = merged.end()
+		}
+
+		combined := make([]byte, end-start)
+		copy(combined[region.start-start:], region.data)
+		copy(combined[merged.start-start:], merged.data)
+
+		merged = mockRegion{start: start, data: combined}
+	}
+
+	kept = append(kept, merged)
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].start < kept[j].start
+	})
+
+	o.regions = kept
+}
+
+func (o *MockProcess) region(addr uintptr, size int) ([]byte, error) {
+	for _, region := range o.regions {
+		if addr >= region.start && addr+uintptr(size) <= region.end() {
+			offset := addr - region.start
+			return region.data[offset : offset+uintptr(size)], nil
+		}
+	}
+
+	return nil, fmt.Errorf("memory at 0x%x (%d bytes) is not mapped", addr, size)
+}
+
+// ReadBytes implements ProcessIO.
+func (o *MockProcess) ReadBytes(addr uintptr, size int) ([]byte, error) {
+	data, err := o.region(addr, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+// WriteBytes implements ProcessIO.
+func (o *MockProcess) WriteBytes(addr uintptr, data []byte) error {
+	dst, err := o.region(addr, len(data))
+	if err != nil {
+		return err
+	}
+
+	copy(dst, data)
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package progctl
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/Andoryuuta/kiwi"
+	"github.com/SeungKang/blaj/internal/kernel32"
+)
+
+// ProcessStats is the resource usage of a running program.
+type ProcessStats struct {
+	// WorkingSet is the size of the program's
+	// working set (its physical memory) in bytes.
+	WorkingSet uint64
+
+	// CPUPercent is the percentage of the total CPU time of
+	// every core that the program used since the previous call
+	// to Routine.ProcessStats, or since the program started.
+	CPUPercent float64
+}
+
+// ProcessStats returns the memory and CPU usage of the running
+// program. ErrNotAttached is returned if the program is not running.
+// An error is also returned if the program's process handle was
+// released because it is idle (see General.IdleTimeout), since the
+// stats are not worth reopening the handle for.
+func (o *Routine) ProcessStats() (ProcessStats, error) {
+	current, err := o.attached()
+	if err != nil {
+		return ProcessStats{}, err
+	}
+
+	return current.processStats()
+}
+
+func (o *runningProgramRoutine) processStats() (ProcessStats, error) {
+	var stats ProcessStats
+	var created time.Time
+	var cpuTime time.Duration
+
+	err := o.proc.useOpen(func(proc *kiwi.Process) error {
+		handle := syscall.Handle(proc.Handle)
+
+		var counters kernel32.PROCESS_MEMORY_COUNTERS
+		err := kernel32.GetProcessMemoryInfo(handle, &counters)
+		if err != nil {
+			return err
+		}
+
+		stats.WorkingSet = uint64(counters.WorkingSetSize)
+
+		created, cpuTime, err = kernel32.GetProcessTimes(handle)
+		return err
+	})
+	if err != nil {
+		return ProcessStats{}, err
+	}
+
+	now := time.Now()
+
+	o.cpuMu.Lock()
+	defer o.cpuMu.Unlock()
+
+	// The first sample is compared to the
+	// program's usage since it started.
+	prevAt, prevCPUTime := o.cpuSampledAt, o.cpuTime
+	if prevAt.IsZero() {
+		prevAt, prevCPUTime = created, 0
+	}
+
+	elapsed := now.Sub(prevAt)
+	if elapsed > 0 && cpuTime >= prevCPUTime {
+		stats.CPUPercent = 100 * float64(cpuTime-prevCPUTime) /
+			float64(elapsed) / float64(runtime.NumCPU())
+	}
+
+	o.cpuSampledAt = now
+	o.cpuTime = cpuTime
+
+	return stats, nil
+}
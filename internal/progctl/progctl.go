@@ -6,35 +6,410 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/Andoryuuta/kiwi"
+	"github.com/SeungKang/blaj/internal/anticheat"
 	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/dbghelp"
+	"github.com/SeungKang/blaj/internal/input"
 	"github.com/SeungKang/blaj/internal/kernel32"
+	"github.com/SeungKang/blaj/internal/stats"
+	"github.com/SeungKang/blaj/internal/user32"
 	"github.com/mitchellh/go-ps"
-	"github.com/stephen-fox/user32util"
 )
 
+// largeReadSize is the pointer size above which
+// a warning is logged when attaching to a program.
+const largeReadSize = 64 << 10
+
+// pressQueueSize is the number of key presses that can wait for
+// their actions to be performed before further presses are ignored.
+const pressQueueSize = 16
+
+// reattachPollInterval is how often the program is looked for
+// during its reattach grace period.
+const reattachPollInterval = 500 * time.Millisecond
+
+// blajPID is the PID of this process, which is used
+// to check if keybinds in appconfig.BlajScope are active.
+var blajPID = os.Getpid()
+
 var (
 	programExitedNormallyErr = errors.New("program exited without error")
+
+	// ErrNotAttached is returned when an operation requires
+	// the program to be running.
+	ErrNotAttached = errors.New("program is not running")
+
+	// ErrSafeMode is returned when writing memory
+	// while safe mode is enabled.
+	ErrSafeMode = errors.New("writing is disabled in safe mode")
+
+	// ErrProcessChanged is returned by WriteMemoryOf when the
+	// running program is not the process that it expects.
+	ErrProcessChanged = errors.New("program was restarted")
 )
 
+// SectionError is the error that stops a routine when a section's
+// action fails. Its message is the message of the wrapped error.
+type SectionError struct {
+	// Section identifies the section (see appconfig.ProgramConfig.SectionID).
+	Section string
+
+	// Label is the section's display name, if it has one.
+	Label string
+
+	Err error
+}
+
+func (o *SectionError) Error() string {
+	return o.Err.Error()
+}
+
+func (o *SectionError) Unwrap() error {
+	return o.Err
+}
+
+func (o *runningProgramRoutine) sectionError(section interface{}, err error) error {
+	sectionErr := &SectionError{
+		Section: o.program.SectionID(section),
+		Err:     err,
+	}
+
+	named, ok := section.(interface{ DisplayName() string })
+	if ok {
+		sectionErr.Label = named.DisplayName()
+	}
+
+	return sectionErr
+}
+
 type Notifier interface {
 	ProgramStarted(exename string)
 	ProgramStopped(exename string, err error)
 }
 
+// CounterNotifier is optionally implemented by a Notifier to be
+// notified when the total of a Counter section changes.
+type CounterNotifier interface {
+	CounterChanged(exename string, counter *appconfig.Counter, total int)
+}
+
+// WriterStateNotifier is optionally implemented by a Notifier to be
+// notified when a frozen Writer is toggled on or off. Frozen writers
+// are off when the program is attached to.
+type WriterStateNotifier interface {
+	WriterStateChanged(exename string, writer *appconfig.Writer, on bool)
+}
+
+// ActionNotifier is optionally implemented by a Notifier to be
+// notified when a section's keybind is successfully handled.
+// op is one of the Action constants. trigger is nil if the
+// action was not triggered by a key press (e.g. a retry
+// after the attach grace period).
+type ActionNotifier interface {
+	ActionPerformed(exename string, op string, section string, label string, trigger *ActionTrigger)
+}
+
+// ActionTrigger describes the key press that triggered an action.
+type ActionTrigger struct {
+	// KeyTime is when the key press was received.
+	KeyTime time.Time
+
+	// ForegroundPID is the PID of the process that owned the
+	// foreground window, or 0 if there was no foreground window.
+	ForegroundPID int
+
+	// Foreground is true if the program was in the foreground.
+	Foreground bool
+}
+
+const (
+	ActionSave    = "save"
+	ActionRestore = "restore"
+	ActionWrite   = "write"
+	ActionCount   = "count"
+	ActionCompare = "compare"
+	ActionReroll  = "reroll"
+	ActionNudge   = "nudge"
+)
+
 type Routine struct {
 	Program *appconfig.ProgramConfig
-	User32  *user32util.User32DLL
 	Notif   Notifier
-	timer   *time.Timer
-	current *runningProgramRoutine
-	done    chan struct{}
-	err     error
+
+	// Keyboard is the keyboard hook shared by every Routine.
+	Keyboard *input.Dispatcher
+
+	// Guard prevents attaching to programs protected by anti-cheat
+	// software. A nil Guard only uses the built-in lists.
+	Guard *anticheat.Guard
+
+	// Counters stores the totals of the program's Counter sections.
+	// It must be non-nil if the program has Counter sections.
+	Counters *stats.Counters
+
+	// SafeMode disables all Writer sections when set to true.
+	// SaveRestore sections are unaffected.
+	SafeMode bool
+
+	// ScanInterval is how often the program is checked for
+	// when it is not running. Five seconds is used if zero.
+	ScanInterval time.Duration
+
+	// WindowPollInterval is how often the program is checked
+	// for a window when General.WaitForWindow is true. One
+	// second is used if zero.
+	WindowPollInterval time.Duration
+
+	// MinFreezeInterval is the minimum interval between
+	// the writes of frozen writers.
+	MinFreezeInterval time.Duration
+
+	// LowerPollingPriority lowers the priority of the threads
+	// that check whether the program is running and responsive,
+	// leaving more CPU time for the keyboard input thread.
+	LowerPollingPriority bool
+
+	// MinimalAccess opens the program with read access and
+	// only requests write access when memory is first written.
+	MinimalAccess bool
+
+	// TraceDir, when non-empty, is the directory where a trace of
+	// each attachment's memory operations is recorded.
+	// Refer to ReplayTrace for more information.
+	TraceDir string
+
+	timer *time.Timer
+
+	// prevStates are the states of the previous process,
+	// which are reused if a new process is found before
+	// reattachDeadline.
+	prevStates       map[string]*programState
+	reattachDeadline time.Time
+
+	// waitingForWindow is true if the program is running, but
+	// WaitForWindow is enabled and it has no window yet.
+	waitingForWindow bool
+
+	// refusedPID is the PID of a process that the Routine refused
+	// to attach to because of its anti-cheat software. It is not
+	// checked again until it exits.
+	refusedPID int
+
+	mu       sync.Mutex
+	current  *runningProgramRoutine
+	state    State
+	stateErr error
+	done     chan struct{}
+	err      error
+}
+
+// ResolveAddr returns the absolute address that ptr currently
+// points to in the running program. ErrNotAttached is returned
+// if the program is not running.
+func (o *Routine) ResolveAddr(ptr appconfig.Pointer) (uintptr, error) {
+	current, err := o.attached()
+	if err != nil {
+		return 0, err
+	}
+
+	return current.resolve(ptr)
+}
+
+// PID returns the process ID of the running program.
+// ErrNotAttached is returned if the program is not running.
+func (o *Routine) PID() (int, error) {
+	current, err := o.attached()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(current.proc.PID), nil
+}
+
+// AttachedAt returns when the Routine attached to the running
+// program. ErrNotAttached is returned if the program is not running.
+func (o *Routine) AttachedAt() (time.Time, error) {
+	current, err := o.attached()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return current.attachedAt, nil
+}
+
+// ExePath returns the path of the running program's exe file.
+// ErrNotAttached is returned if the program is not running.
+func (o *Routine) ExePath() (string, error) {
+	current, err := o.attached()
+	if err != nil {
+		return "", err
+	}
+
+	return current.mods[o.Program.General.ExeName].Filepath, nil
+}
+
+// SavedStates returns a copy of the states saved in the running
+// program, keyed by pointer name. The states of save slots are
+// keyed by their slot's name (e.g. "health#slot1"). Pointers that
+// have not been saved are omitted. ErrNotAttached is returned if
+// the program is not running.
+func (o *Routine) SavedStates() (map[string][]byte, error) {
+	current, err := o.attached()
+	if err != nil {
+		return nil, err
+	}
+
+	current.actionMu.Lock()
+	defer current.actionMu.Unlock()
+
+	saved := make(map[string][]byte, len(current.states))
+	for name, state := range current.states {
+		if state.stateSet {
+			saved[name] = append([]byte(nil), state.savedState...)
+		}
+	}
+
+	return saved, nil
+}
+
+// ReadMemory reads size bytes at addr in the running program.
+// ErrNotAttached is returned if the program is not running.
+//
+// If the end of the range cannot be read, the bytes before it
+// are returned along with a *PartialReadError.
+func (o *Routine) ReadMemory(addr uintptr, size int) ([]byte, error) {
+	current, err := o.attached()
+	if err != nil {
+		return nil, err
+	}
+
+	return readPages(current.mem, addr, size)
+}
+
+// WriteMemory writes data to addr in the running program.
+// ErrNotAttached is returned if the program is not running,
+// and ErrSafeMode is returned if SafeMode is enabled.
+func (o *Routine) WriteMemory(addr uintptr, data []byte) error {
+	if o.SafeMode {
+		return ErrSafeMode
+	}
+
+	current, err := o.attached()
+	if err != nil {
+		return err
+	}
+
+	return current.mem.WriteBytes(addr, data)
+}
+
+// ProcessID identifies the process of a running program. Unlike
+// a PID, it does not match a process that reuses the PID of a
+// process that exited.
+type ProcessID struct {
+	PID     int
+	Created time.Time
+}
+
+// ProcessID returns the ProcessID of the running program.
+// ErrNotAttached is returned if the program is not running.
+func (o *Routine) ProcessID() (ProcessID, error) {
+	current, err := o.attached()
+	if err != nil {
+		return ProcessID{}, err
+	}
+
+	return current.processID()
+}
+
+// WriteMemoryOf is like WriteMemory, except that ErrProcessChanged
+// is returned if the running program is not the process identified
+// by id (e.g. because the program was restarted after id was
+// returned by ProcessID).
+func (o *Routine) WriteMemoryOf(id ProcessID, addr uintptr, data []byte) error {
+	if o.SafeMode {
+		return ErrSafeMode
+	}
+
+	current, err := o.attached()
+	if err != nil {
+		return err
+	}
+
+	currentID, err := current.processID()
+	if err != nil {
+		return err
+	}
+
+	if currentID.PID != id.PID || !currentID.Created.Equal(id.Created) {
+		return ErrProcessChanged
+	}
+
+	return current.mem.WriteBytes(addr, data)
+}
+
+func (o *runningProgramRoutine) processID() (ProcessID, error) {
+	var created time.Time
+	err := o.proc.use(accessRead, func(proc *kiwi.Process) error {
+		var err error
+		created, _, err = kernel32.GetProcessTimes(syscall.Handle(proc.Handle))
+		return err
+	})
+	if err != nil {
+		return ProcessID{}, fmt.Errorf("failed to get process creation time - %w", err)
+	}
+
+	return ProcessID{PID: int(o.proc.PID), Created: created}, nil
+}
+
+// WriteMiniDump writes a minidump of the running program to file.
+// ErrNotAttached is returned if the program is not running.
+func (o *Routine) WriteMiniDump(file *os.File, dumpType dbghelp.DumpType) error {
+	current, err := o.attached()
+	if err != nil {
+		return err
+	}
+
+	return current.proc.use(accessRead, func(proc *kiwi.Process) error {
+		return dbghelp.WriteMiniDump(
+			syscall.Handle(proc.Handle),
+			uint32(proc.PID),
+			syscall.Handle(file.Fd()),
+			dumpType)
+	})
+}
+
+func (o *Routine) attached() (*runningProgramRoutine, error) {
+	o.mu.Lock()
+	current := o.current
+	o.mu.Unlock()
+
+	if current == nil {
+		return nil, ErrNotAttached
+	}
+
+	select {
+	case <-current.Done():
+		return nil, ErrNotAttached
+	default:
+	}
+
+	return current, nil
+}
+
+// setCurrent must only be called by the loop goroutine,
+// which may read current without holding mu.
+func (o *Routine) setCurrent(current *runningProgramRoutine) {
+	o.mu.Lock()
+	o.current = current
+	o.mu.Unlock()
 }
 
 func (o *Routine) Done() <-chan struct{} {
@@ -49,7 +424,13 @@ func (o *Routine) Start(ctx context.Context) {
 	o.done = make(chan struct{})
 	o.timer = time.NewTimer(time.Millisecond)
 
-	go o.loop(ctx)
+	goLabeled(o.Program.General.ExeName, "routine", func() {
+		if o.LowerPollingPriority {
+			lowerThreadPriority(o.Program.General.ExeName, "routine")
+		}
+
+		o.loop(ctx)
+	})
 }
 
 func (o *Routine) loop(ctx context.Context) {
@@ -58,6 +439,7 @@ func (o *Routine) loop(ctx context.Context) {
 	defer cancelFn()
 
 	o.err = o.loopWithError(ctx)
+	o.setState(StateError, o.err)
 	close(o.done)
 }
 
@@ -70,6 +452,7 @@ func (o *Routine) loopWithError(ctx context.Context) error {
 	}()
 
 	log.Printf("checking for program running with exe name: %s", o.Program.General.ExeName)
+	o.setState(StateSearching, nil)
 
 	for {
 		select {
@@ -82,7 +465,24 @@ func (o *Routine) loopWithError(ctx context.Context) error {
 			}
 		case <-o.current.Done():
 			log.Printf("%s routine exited - %s", o.Program.General.ExeName, o.current.Err())
-			o.timer.Reset(5 * time.Second)
+
+			// Cancels the pending actions and retries, which
+			// are not cancelled when the program exits.
+			o.current.Stop()
+
+			if o.Program.General.ReattachGracePeriod > 0 {
+				o.prevStates = o.current.states
+				o.reattachDeadline = time.Now().Add(o.Program.General.ReattachGracePeriod)
+				o.timer.Reset(reattachPollInterval)
+			} else {
+				o.timer.Reset(o.scanInterval())
+			}
+
+			if errors.Is(o.current.Err(), programExitedNormallyErr) {
+				o.setState(StateDetached, nil)
+			} else {
+				o.setState(StateError, o.current.Err())
+			}
 
 			if o.Notif != nil {
 				if errors.Is(o.current.Err(), programExitedNormallyErr) {
@@ -92,72 +492,266 @@ func (o *Routine) loopWithError(ctx context.Context) error {
 				}
 			}
 
-			o.current = nil
+			o.setCurrent(nil)
 		}
 	}
 }
 
+func (o *Routine) scanInterval() time.Duration {
+	if o.ScanInterval > 0 {
+		return o.ScanInterval
+	}
+
+	return 5 * time.Second
+}
+
 func (o *Routine) checkProgramRunning() error {
 	// TODO: logger to make prefix with exename
-	processes, err := ps.Processes()
+	possiblePID, err := FindPID(o.Program.General.ExeName)
 	if err != nil {
-		return fmt.Errorf("failed to get active processes - %w", err)
+		return err
 	}
 
-	possiblePID := -1
-	for _, process := range processes {
-		if strings.ToLower(process.Executable()) == o.Program.General.ExeName {
-			possiblePID = process.Pid()
-			break
-		}
+	reattaching := o.prevStates != nil && time.Now().Before(o.reattachDeadline)
+	if !reattaching {
+		o.prevStates = nil
 	}
 
 	if possiblePID == -1 {
-		o.timer.Reset(5 * time.Second)
+		state, _ := o.State()
+		if (state == StateDetached && !reattaching) || o.refusedPID != 0 {
+			o.refusedPID = 0
+			o.setState(StateSearching, nil)
+		}
+
+		if reattaching {
+			o.timer.Reset(reattachPollInterval)
+		} else {
+			o.timer.Reset(o.scanInterval())
+		}
+
+		return nil
+	}
+
+	if possiblePID == o.refusedPID {
+		o.timer.Reset(o.scanInterval())
+		return nil
+	}
+
+	o.setState(StateAttaching, nil)
+
+	if o.Program.General.WaitForWindow && !user32.HasVisibleWindow(possiblePID) {
+		if !o.waitingForWindow {
+			log.Printf("waiting for %s to create a window", o.Program.General.ExeName)
+			o.waitingForWindow = true
+		}
+
+		if o.WindowPollInterval > 0 {
+			o.timer.Reset(o.WindowPollInterval)
+		} else {
+			o.timer.Reset(time.Second)
+		}
+
 		return nil
 	}
 
-	runningProgram, err := newRunningProgramRoutine(o.Program, possiblePID, o.User32)
+	o.waitingForWindow = false
+
+	runningProgram, err := newRunningProgramRoutine(o.Program, possiblePID, o.Keyboard, o.Guard, o.MinimalAccess)
+	var antiCheatErr *anticheat.Error
+	if errors.As(err, &antiCheatErr) {
+		// Anti-cheat software may only be loaded by some
+		// launches of the program (e.g. not in offline
+		// mode), so the next process is checked again.
+		log.Printf("%s (PID %d) - %s", o.Program.General.ExeName, possiblePID, err)
+		o.refusedPID = possiblePID
+		o.setState(StateError, err)
+		o.timer.Reset(o.scanInterval())
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create new running program routine - %w", err)
 	}
 
-	o.current = runningProgram
+	if reattaching {
+		log.Printf("%s restarted with PID %d - keeping saved states",
+			o.Program.General.ExeName, possiblePID)
+
+		runningProgram.states = o.prevStates
+		o.prevStates = nil
+	}
+
+	runningProgram.safe = o.SafeMode
+	runningProgram.notif = o.Notif
+	runningProgram.onState = o.setState
+	runningProgram.counters = o.Counters
+	runningProgram.minFreezeInterval = o.MinFreezeInterval
+
+	if o.TraceDir != "" {
+		err = runningProgram.startTrace(o.TraceDir)
+		if err != nil {
+			runningProgram.Stop()
+			return fmt.Errorf("failed to start trace - %w", err)
+		}
+	}
+
+	o.setCurrent(runningProgram)
+	o.setState(StateAttached, nil)
 	if o.Notif != nil {
 		o.Notif.ProgramStarted(o.Program.General.ExeName)
 	}
 
+	if o.Program.General.HeartbeatInterval > 0 {
+		goLabeled(o.Program.General.ExeName, "heartbeat", func() {
+			if o.LowerPollingPriority {
+				lowerThreadPriority(o.Program.General.ExeName, "heartbeat")
+			}
+
+			runningProgram.heartbeat(o.Program.General.HeartbeatInterval)
+		})
+	}
+
+	if o.Program.General.ValueHistory > 0 {
+		goLabeled(o.Program.General.ExeName, "history", func() {
+			if o.LowerPollingPriority {
+				lowerThreadPriority(o.Program.General.ExeName, "history")
+			}
+
+			runningProgram.recordHistory(o.Program.General.ValueHistory)
+		})
+	}
+
+	// Only writes are deferred while the program is halted.
+	if o.Program.NeedsWriteAccess() {
+		goLabeled(o.Program.General.ExeName, "halt", func() {
+			if o.LowerPollingPriority {
+				lowerThreadPriority(o.Program.General.ExeName, "halt")
+			}
+
+			runningProgram.watchHalt()
+		})
+	}
+
+	if o.Program.General.IdleTimeout > 0 {
+		goLabeled(o.Program.General.ExeName, "idle", func() {
+			runningProgram.releaseWhenIdle(o.Program.General.IdleTimeout)
+		})
+	}
+
+	if o.Program.General.HealthCheckInterval > 0 {
+		goLabeled(o.Program.General.ExeName, "health", func() {
+			if o.LowerPollingPriority {
+				lowerThreadPriority(o.Program.General.ExeName, "health")
+			}
+
+			runningProgram.checkPointerHealth(o.Program.General.HealthCheckInterval)
+		})
+	}
+
+	for _, counter := range o.Program.Counters {
+		if counter.Trigger == nil {
+			continue
+		}
+
+		counter := counter
+		goLabeled(o.Program.General.ExeName, "counter", func() {
+			if o.LowerPollingPriority {
+				lowerThreadPriority(o.Program.General.ExeName, "counter")
+			}
+
+			runningProgram.watchCounterTrigger(counter)
+		})
+	}
+
 	return nil
 }
 
+// FindPID returns the PID of the first running process whose exe name
+// matches exeName (case-insensitive), or -1 if no such process exists.
+func FindPID(exeName string) (int, error) {
+	processes, err := ps.Processes()
+	if err != nil {
+		return -1, fmt.Errorf("failed to get active processes - %w", err)
+	}
+
+	exeName = strings.ToLower(exeName)
+	for _, process := range processes {
+		if strings.ToLower(process.Executable()) == exeName {
+			return process.Pid(), nil
+		}
+	}
+
+	return -1, nil
+}
+
 // TODO: make source file for running program stuff
-func newRunningProgramRoutine(program *appconfig.ProgramConfig, pid int, dll *user32util.User32DLL) (*runningProgramRoutine, error) {
-	proc, err := kiwi.GetProcessByPID(pid)
+func newRunningProgramRoutine(program *appconfig.ProgramConfig, pid int, keyboard *input.Dispatcher, guard *anticheat.Guard, minimalAccess bool) (*runningProgramRoutine, error) {
+	err := guard.CheckExe(program.General.ExeName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Programs are opened with the fewest rights that are needed,
+	// since protected processes may refuse to be opened with more.
+	// Write access is requested later if memory is written anyway
+	// (e.g. by the memory viewer).
+	access := uint32(accessWrite)
+	if minimalAccess || !program.NeedsWriteAccess() {
+		access = accessRead
+	}
+
+	proc, err := openProcessHandle(pid, access)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get process by PID - %w", err)
 	}
 
-	// TODO: changing to be map[*appconfig.pointer]*programState
-	programStates := make(map[string]*programState)
-	for _, saveRestore := range program.SaveRestores {
-		for _, pointer := range saveRestore.Pointers {
-			programStates[pointer.Name] = &programState{
-				pointer: pointer,
-			}
-		}
+	now := time.Now()
+
+	for _, overlap := range program.WriterOverlaps() {
+		log.Printf("warning: %s", overlap)
 	}
 
 	runningProgram := &runningProgramRoutine{
-		program: program,
-		proc:    proc,
-		states:  programStates,
-		done:    make(chan struct{}),
+		program:    program,
+		proc:       proc,
+		states:     newProgramStates(program),
+		attachedAt: now,
+		lastActive: now,
+		done:       make(chan struct{}),
+	}
+
+	proc.onReopen = runningProgram.handleReopened
+
+	var modules []kernel32.Module
+	var is32Bit bool
+	err = proc.use(accessRead, func(proc *kiwi.Process) error {
+		var err error
+		modules, err = kernel32.ProcessModules(syscall.Handle(proc.Handle))
+		if err != nil {
+			return fmt.Errorf("failed to get process modules - %w", err)
+		}
+
+		is32Bit, err = kernel32.IsProcess32Bit(syscall.Handle(proc.Handle))
+		if err != nil {
+			return fmt.Errorf("failed to determine if process is 32 bit - %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		runningProgram.Stop()
+		return nil, err
+	}
+
+	moduleNames := make([]string, len(modules))
+	for i, module := range modules {
+		moduleNames[i] = module.Filename
 	}
 
-	modules, err := kernel32.ProcessModules(syscall.Handle(proc.Handle))
+	err = guard.CheckModules(program.General.ExeName, moduleNames)
 	if err != nil {
 		runningProgram.Stop()
-		return nil, fmt.Errorf("failed to get process modules - %w", err)
+		return nil, err
 	}
 
 	baseAddr, requiredModules, err := getRequiredModules(program, modules)
@@ -168,32 +762,32 @@ func newRunningProgramRoutine(program *appconfig.ProgramConfig, pid int, dll *us
 
 	runningProgram.base = baseAddr
 	runningProgram.mods = requiredModules
-
-	is32Bit, err := kernel32.IsProcess32Bit(syscall.Handle(proc.Handle))
-	if err != nil {
-		runningProgram.Stop()
-		return nil, fmt.Errorf("failed to determine if process is 32 bit - %w", err)
-	}
 	runningProgram.is32b = is32Bit
 
-	if is32Bit {
-		runningProgram.addrFn = func(u uintptr) (uintptr, error) {
-			data, err := proc.ReadUint32(u)
-			return uintptr(data), err
-		}
-	} else {
-		runningProgram.addrFn = func(u uintptr) (uintptr, error) {
-			data, err := proc.ReadUint64(u)
-			return uintptr(data), err
+	runningProgram.mem = &retryingIO{inner: runningProgram.proc}
+	runningProgram.addrFn = addrFnFor(runningProgram.mem, is32Bit)
+
+	// A nil keyboard means the caller does not want keyboard input
+	// (e.g. when performing a single action from the command line).
+	if keyboard != nil {
+		runningProgram.presses = make(chan keyPress, pressQueueSize)
+		goLabeled(program.General.ExeName, "actions", runningProgram.handlePresses)
+
+		unsubscribe, err := keyboard.Subscribe(runningProgram.handleKeyboardEvent)
+		if err != nil {
+			runningProgram.Stop()
+			return nil, fmt.Errorf("failed to subscribe to keyboard events - %w", err)
 		}
-	}
+		runningProgram.unsubscribe = unsubscribe
 
-	listener, err := user32util.NewLowLevelKeyboardListener(runningProgram.handleKeyboardEvent, dll)
-	if err != nil {
-		runningProgram.Stop()
-		return nil, fmt.Errorf("failed to create listener - %s", err.Error())
+		goLabeled(program.General.ExeName, "keyboard-wait", func() {
+			select {
+			case <-keyboard.Done():
+				runningProgram.exited(keyboard.Err())
+			case <-runningProgram.done:
+			}
+		})
 	}
-	runningProgram.ln = listener
 
 	process, err := os.FindProcess(int(proc.PID))
 	if err != nil {
@@ -201,34 +795,77 @@ func newRunningProgramRoutine(program *appconfig.ProgramConfig, pid int, dll *us
 		return nil, fmt.Errorf("failed to find process with PID: %d - %w", proc.PID, err)
 	}
 
-	go func() {
+	goLabeled(program.General.ExeName, "process-wait", func() {
 		_, err := process.Wait()
 		if err == nil {
 			err = programExitedNormallyErr
 		}
 
 		runningProgram.exited(err)
-	}()
+	})
 
-	go func() {
-		err := <-listener.OnDone()
-		if err == nil {
-			err = errors.New("listener exited without error")
-		}
+	return runningProgram, nil
+}
 
-		runningProgram.exited(err)
-	}()
+func newProgramStates(program *appconfig.ProgramConfig) map[string]*programState {
+	// TODO: changing to be map[*appconfig.pointer]*programState
+	programStates := make(map[string]*programState)
+	for _, saveRestore := range program.SaveRestores {
+		for _, pointer := range saveRestore.Pointers {
+			if pointer.Size() > largeReadSize {
+				log.Printf("warning: %s reads %d bytes each time it is saved",
+					pointer.DisplayName(), pointer.Size())
+			}
 
-	return runningProgram, nil
+			programStates[pointer.Name] = &programState{
+				pointer: pointer,
+			}
+
+			for _, slot := range saveRestore.Slots {
+				programStates[SlotStateName(pointer.Name, slot.Number)] = &programState{
+					pointer: pointer,
+				}
+			}
+		}
+	}
+
+	return programStates
 }
 
 func getRequiredModules(program *appconfig.ProgramConfig, modules []kernel32.Module) (uintptr, map[string]kernel32.Module, error) {
 	needed := make(map[string]kernel32.Module)
 	needed[program.General.ExeName] = kernel32.Module{}
+	if program.General.PausedPointer != nil {
+		for _, module := range program.General.PausedPointer.Modules() {
+			needed[module] = kernel32.Module{}
+		}
+	}
 	for _, saveRestore := range program.SaveRestores {
 		for _, pointer := range saveRestore.Pointers {
-			if pointer.OptModule != "" {
-				needed[pointer.OptModule] = kernel32.Module{}
+			for _, module := range pointer.Modules() {
+				needed[module] = kernel32.Module{}
+			}
+		}
+	}
+
+	for _, writer := range program.Writers {
+		for _, pointer := range writer.Pointers {
+			for _, module := range pointer.Pointer.Modules() {
+				needed[module] = kernel32.Module{}
+			}
+		}
+	}
+
+	for _, nudge := range program.Nudges {
+		for _, module := range nudge.Pointer.Modules() {
+			needed[module] = kernel32.Module{}
+		}
+	}
+
+	for _, counter := range program.Counters {
+		if counter.Trigger != nil {
+			for _, module := range counter.Trigger.Pointer.Modules() {
+				needed[module] = kernel32.Module{}
 			}
 		}
 	}
@@ -264,15 +901,102 @@ type runningProgramRoutine struct {
 	is32b   bool
 	mods    map[string]kernel32.Module
 	addrFn  func(uintptr) (uintptr, error)
-	proc    kiwi.Process
-	states  map[string]*programState
-	once    sync.Once
-	ln      *user32util.LowLevelKeyboardEventListener
-	done    chan struct{}
-	err     error
+	proc    *processHandle
+	mem     ProcessIO
+	trace   *traceRecorder
+	safe    bool
+	notif   Notifier
+
+	counters *stats.Counters
+	overlaps overlapChecker
+
+	// attachedAt is when the routine attached to the program.
+	attachedAt time.Time
+
+	// cpuMu guards the previous CPU usage sample
+	// of the program (see processStats).
+	cpuMu        sync.Mutex
+	cpuSampledAt time.Time
+	cpuTime      time.Duration
+
+	// onState, if non-nil, is called when the routine
+	// becomes degraded or recovers.
+	onState func(State, error)
+
+	// actionMu serializes keybind actions, which may be
+	// retried on another goroutine (see retryInGracePeriod).
+	actionMu sync.Mutex
+
+	// freezes contains a channel for each frozen Writer
+	// that is closed when the writer is toggled off.
+	// It is guarded by actionMu.
+	freezes map[*appconfig.Writer]chan struct{}
+
+	// minFreezeInterval is the minimum interval
+	// between the writes of frozen writers.
+	minFreezeInterval time.Duration
+
+	// paused is true if frozen writers were paused the last
+	// time that they checked. It is guarded by actionMu.
+	paused bool
+
+	// scheduler performs the delayed second phase of
+	// restores (see scheduleAfterRestore) and the retries
+	// of failed actions (see retryInGracePeriod).
+	scheduler scheduler
+
+	// graceRetries contains the number of times that each
+	// section's action was retried during the attach grace
+	// period. It is guarded by actionMu.
+	graceRetries map[interface{}]int
+
+	// lastSeeds contains the seed that was last written by each
+	// Seed section since it was saved (see doReroll). It is
+	// guarded by actionMu.
+	lastSeeds map[*appconfig.Seed][]byte
+
+	// dataListIndexes contains the index of the value that is
+	// written next by each Writer that has a data list (see
+	// WritePointer.DataAt). It is guarded by actionMu.
+	dataListIndexes map[*appconfig.Writer]int
+
+	// trigger is the key press that triggered the current
+	// action, or nil. It is guarded by actionMu.
+	trigger *ActionTrigger
+
+	// halt is whether the program is halted, and the key
+	// presses that are deferred until it resumes.
+	halt haltState
+
+	// lastActive is when a keybind was last pressed, or when the
+	// routine attached. It is guarded by actionMu.
+	lastActive time.Time
+
+	// presses are the key presses whose actions are waiting to
+	// be performed by the actions goroutine (see handlePresses).
+	presses chan keyPress
+
+	// consumedKeys are the keys that were prevented from reaching
+	// the program when they were pressed, so that their releases
+	// are also prevented from reaching it (see consume).
+	consumedMu   sync.Mutex
+	consumedKeys map[physicalKey]struct{}
+
+	states      map[string]*programState
+	once        sync.Once
+	unsubscribe func()
+	done        chan struct{}
+	err         error
 }
 
+// Stop waits for the current action to finish, cancels scheduled
+// actions, turns off frozen writers, and then stops the routine.
 func (o *runningProgramRoutine) Stop() {
+	o.actionMu.Lock()
+	o.stopScheduled()
+	o.stopFreezes()
+	o.actionMu.Unlock()
+
 	o.exited(errors.New("stopped"))
 }
 
@@ -290,184 +1014,663 @@ func (o *runningProgramRoutine) Err() error {
 
 func (o *runningProgramRoutine) exited(err error) {
 	o.once.Do(func() {
-		_ = syscall.CloseHandle(syscall.Handle(o.proc.Handle))
-		if o.ln != nil {
-			o.ln.Release()
+		o.proc.close()
+		if o.unsubscribe != nil {
+			o.unsubscribe()
 		}
+		_ = o.trace.Close()
 		o.err = err
 		close(o.done)
 	})
 }
 
-func (o *runningProgramRoutine) handleKeyboardEvent(event user32util.LowLevelKeyboardEvent) {
-	err := o.handleKeyboardEventWithError(event)
-	if err != nil {
-		o.exited(err)
+// handleKeyboardEvent queues the actions bound to the pressed key
+// to be performed by the actions goroutine. It returns true if the
+// event should be prevented from reaching the program (see the
+// consumeKey param).
+//
+// It is called on the keyboard hook's thread, which Windows removes
+// if it does not return quickly, so it must never access the
+// program's memory or wait for actionMu.
+func (o *runningProgramRoutine) handleKeyboardEvent(event input.KeyEvent) bool {
+	if !o.fromInputDevice(event) {
+		return false
+	}
+
+	if !event.Down {
+		return o.releaseConsumed(event)
+	}
+
+	// A key can be bound by its character, its virtual-key
+	// code, and its scan code, so all of them are looked up.
+	// Keybinds that require shift take precedence over the
+	// key's other keybinds while shift is held, so that
+	// "shift+1" does not also trigger "1".
+	var pressed []boundSection
+	for _, key := range EventKeys(event) {
+		if !key.Shift && len(pressed) > 0 && pressed[0].key.Shift {
+			break
+		}
+
+		for _, section := range o.program.Keybinds[key] {
+			if appconfig.SectionScope(section).IsActive(event.ForegroundPID, int(o.proc.PID), blajPID) {
+				pressed = append(pressed, boundSection{section: section, key: key})
+			}
+		}
+	}
+
+	if len(pressed) == 0 {
+		return false
+	}
+
+	press := keyPress{
+		pressed: pressed,
+		trigger: &ActionTrigger{
+			KeyTime:       event.Time,
+			ForegroundPID: event.ForegroundPID,
+			Foreground:    event.ForegroundPID == int(o.proc.PID),
+		},
+	}
+
+	select {
+	case o.presses <- press:
+	default:
+		log.Printf("warning: ignoring %s because %d key presses are waiting to be handled",
+			pressed[0].key, cap(o.presses))
 	}
+
+	return o.consume(event, pressed)
 }
 
-func (o *runningProgramRoutine) handleKeyboardEventWithError(event user32util.LowLevelKeyboardEvent) error {
-	if event.KeyboardButtonAction() != user32util.WMKeyDown {
-		return nil
+// physicalKey identifies a key on the keyboard, regardless
+// of whether shift was held when it was pressed.
+type physicalKey struct {
+	virtualKey byte
+	scanCode   uint16
+}
+
+// consume returns true if the key press should be prevented from
+// reaching the program, which is the case if one of the sections
+// bound to it has the consumeKey param and the program is in the
+// foreground. The key is recorded so that its release is also
+// consumed (see releaseConsumed).
+func (o *runningProgramRoutine) consume(event input.KeyEvent, pressed []boundSection) bool {
+	if event.ForegroundPID != int(o.proc.PID) {
+		return false
 	}
 
-	pressedKey := event.Struct.VirtualKeyCode()
-	sections, hasKeybind := o.program.Keybinds[pressedKey]
-	if !hasKeybind {
-		return nil
+	consumes := false
+	for _, bound := range pressed {
+		if appconfig.SectionConsumesKey(bound.section) {
+			consumes = true
+			break
+		}
 	}
 
-	for _, section := range sections {
-		switch v := section.(type) {
-		case *appconfig.SaveRestore:
-			switch pressedKey {
-			case v.SaveState:
-				for _, pointer := range v.Pointers {
-					state, hasIt := o.states[pointer.Name]
-					if !hasIt {
-						continue
-					}
-					err := o.saveState(pointer.Name, state)
-					if err != nil {
-						return fmt.Errorf("failed to get %s state at %+#v to 0x%x",
-							pointer.Name, pointer, state.savedState)
-					}
-				}
-			case v.RestoreState:
-				for _, pointer := range v.Pointers {
-					state, hasIt := o.states[pointer.Name]
-					if !hasIt || !state.stateSet {
-						continue
-					}
-					err := o.restoreState(pointer.Name, state)
-					if err != nil {
-						return fmt.Errorf("failed to restore %s state at %+#v to 0x%x",
-							pointer.Name, state.pointer, state.savedState)
-					}
-				}
+	if !consumes {
+		return false
+	}
+
+	o.consumedMu.Lock()
+	defer o.consumedMu.Unlock()
+
+	if o.consumedKeys == nil {
+		o.consumedKeys = make(map[physicalKey]struct{})
+	}
+
+	o.consumedKeys[physicalKey{virtualKey: event.VirtualKey, scanCode: event.ScanCode}] = struct{}{}
+
+	return true
+}
+
+// releaseConsumed returns true if the release of a key should be
+// prevented from reaching the program, which is the case if the key
+// was consumed when it was pressed. Otherwise, the program would see
+// a key being released that was never pressed, or would never see a
+// key that it saw being pressed be released.
+func (o *runningProgramRoutine) releaseConsumed(event input.KeyEvent) bool {
+	o.consumedMu.Lock()
+	defer o.consumedMu.Unlock()
+
+	key := physicalKey{virtualKey: event.VirtualKey, scanCode: event.ScanCode}
+
+	_, wasConsumed := o.consumedKeys[key]
+	delete(o.consumedKeys, key)
+
+	return wasConsumed
+}
+
+// keyPress is a key press whose actions are
+// waiting to be performed (see handlePresses).
+type keyPress struct {
+	pressed []boundSection
+	trigger *ActionTrigger
+}
+
+// handlePresses performs the actions of the key presses queued by
+// handleKeyboardEvent, in the order that the keys were pressed,
+// until the routine exits.
+func (o *runningProgramRoutine) handlePresses() {
+	for {
+		select {
+		case <-o.done:
+			return
+		case press := <-o.presses:
+			o.handlePress(press)
+		}
+	}
+}
+
+// handlePress performs the actions of the sections bound to a key
+// press, unless the program is halted (see deferWhileHalted).
+func (o *runningProgramRoutine) handlePress(press keyPress) {
+	o.actionMu.Lock()
+	defer o.actionMu.Unlock()
+
+	// The routine may have exited while the press was queued.
+	select {
+	case <-o.done:
+		return
+	default:
+	}
+
+	o.lastActive = time.Now()
+
+	o.trigger = press.trigger
+	defer func() {
+		o.trigger = nil
+	}()
+
+	pressed := press.pressed
+
+	// The sections run in a deterministic order regardless
+	// of which of the key's formats they are bound by.
+	sort.SliceStable(pressed, func(i, j int) bool {
+		return o.program.RunsBefore(pressed[i].section, pressed[j].section)
+	})
+
+	if o.deferWhileHalted(pressed) {
+		return
+	}
+
+	o.runPressed(pressed)
+}
+
+// runPressed performs the actions of the sections bound to a pressed
+// key. It returns false if an action failed and the routine exited.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) runPressed(pressed []boundSection) bool {
+	for _, bound := range pressed {
+		err := o.handleChained(bound.section, bound.key)
+		if err != nil && !o.retryInGracePeriod(bound.section, bound.key, err) {
+			o.exited(o.sectionError(bound.section, err))
+			return false
+		}
+	}
+
+	return true
+}
+
+// boundSection is a section that is bound to a pressed key.
+type boundSection struct {
+	section interface{}
+	key     appconfig.Key
+}
+
+// fromInputDevice returns true if event came from the keyboard
+// identified by the program's InputDevice, or if it has none.
+func (o *runningProgramRoutine) fromInputDevice(event input.KeyEvent) bool {
+	device := o.program.General.InputDevice
+	if device == "" {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(event.Device), strings.ToLower(device))
+}
+
+// EventKeys returns the Keys that event can match. If shift was
+// held, the keys that require shift are returned first.
+func EventKeys(event input.KeyEvent) []appconfig.Key {
+	keys := make([]appconfig.Key, 0, 8)
+	if event.Char != 0 {
+		keys = append(keys, appconfig.Key{Char: unicode.ToUpper(event.Char)})
+	}
+
+	// The digit keys produce symbols without shift in some
+	// layouts (e.g. AZERTY), but their virtual-key codes are
+	// always the digits, so digit keybinds also match them.
+	if event.VirtualKey >= '0' && event.VirtualKey <= '9' && event.Char != rune(event.VirtualKey) {
+		keys = append(keys, appconfig.Key{Char: rune(event.VirtualKey)})
+	}
+
+	if event.VirtualKey != 0 {
+		keys = append(keys, appconfig.Key{VirtualKey: event.VirtualKey})
+	}
+
+	if event.ScanCode != 0 {
+		keys = append(keys, appconfig.Key{ScanCode: event.ScanCode})
+	}
+
+	if event.Shift {
+		shifted := make([]appconfig.Key, 0, len(keys)*2)
+		for _, key := range keys {
+			shifted = append(shifted, key.Shifted())
+		}
+
+		keys = append(shifted, keys...)
+	}
+
+	return keys
+}
+
+// handleSection performs the action of section for the pressed key.
+func (o *runningProgramRoutine) handleSection(section interface{}, pressedKey appconfig.Key) error {
+	switch v := section.(type) {
+	case *appconfig.SaveRestore:
+		switch pressedKey {
+		case v.SaveState:
+			return o.doSave(v, 0)
+		case v.RestoreState:
+			err := o.doRestore(v, 0)
+			if err != nil {
+				return err
 			}
-		case *appconfig.Writer:
-			for _, pointer := range v.Pointers {
-				err := o.write(pointer)
-				if err != nil {
-					return fmt.Errorf("failed to write to %s - %w", pointer.Pointer.Name, err)
-				}
+
+			return o.scheduleAfterRestore(v)
+		case v.CompareState:
+			return o.doCompare(v)
+		}
+
+		slot, save, isSlotKey := v.Slot(pressedKey)
+		if isSlotKey {
+			if save {
+				return o.doSave(v, slot.Number)
+			}
+
+			err := o.doRestore(v, slot.Number)
+			if err != nil {
+				return err
 			}
+
+			return o.scheduleAfterRestore(v)
+		}
+	case *appconfig.Writer:
+		_, isOn := o.freezes[v]
+		if isOn {
+			return o.toggleFreeze(v)
 		}
+
+		return o.turnOnWriter(v)
+	case *appconfig.Counter:
+		o.doCount(v)
+	case *appconfig.Seed:
+		return o.handleSeed(v, pressedKey)
+	case *appconfig.Nudge:
+		return o.doNudge(v, pressedKey == v.Increase)
 	}
 
 	return nil
 }
 
-func (o *runningProgramRoutine) saveState(name string, state *programState) error {
-	baseAddr := o.base
-	if state.pointer.OptModule != "" {
-		module, hasIt := o.mods[state.pointer.OptModule]
+func (o *runningProgramRoutine) doCount(v *appconfig.Counter) {
+	if o.counters == nil {
+		return
+	}
+
+	total, err := o.counters.Increment(v.Label)
+	if err != nil {
+		// Failing to persist a counter should not
+		// prevent the user from practicing.
+		log.Printf("failed to save counter %q - %s", v.Label, err)
+	}
+
+	log.Printf("%s counter is now %d", v.Label, total)
+
+	counterNotif, ok := o.notif.(CounterNotifier)
+	if ok {
+		counterNotif.CounterChanged(o.program.General.ExeName, v, total)
+	}
+
+	o.notifyAction(ActionCount, o.program.SectionID(v), v.DisplayName())
+}
+
+func (o *runningProgramRoutine) notifyAction(op string, section string, label string) {
+	actionNotif, ok := o.notif.(ActionNotifier)
+	if ok {
+		actionNotif.ActionPerformed(o.program.General.ExeName, op, section, label, o.trigger)
+	}
+}
+
+// doSave saves the section's pointers to save slot number slot,
+// where slot 0 is the state restored by the restoreState keybind.
+func (o *runningProgramRoutine) doSave(v *appconfig.SaveRestore, slot int) error {
+	o.trace.slotAction(traceOpSave, o.program.SectionID(v), slot)
+
+	for _, pointer := range v.Pointers {
+		state, hasIt := o.states[SlotStateName(pointer.Name, slot)]
 		if !hasIt {
-			return fmt.Errorf("unknown module %q", state.pointer.OptModule)
+			continue
 		}
+		err := o.saveState(pointer.DisplayName(), state)
+		state.setLastErr(err)
+		if err != nil {
+			return fmt.Errorf("failed to get %s state at %+#v to 0x%x - %w",
+				pointer.DisplayName(), pointer, state.savedState, err)
+		}
+	}
 
-		baseAddr = module.BaseAddr
+	// Rerolls continue from the newly saved seed.
+	if v.Seed != nil {
+		delete(o.lastSeeds, v.Seed)
+	}
+
+	if v.DisplayName() != "" {
+		log.Printf("saved '%s'%s", v.DisplayName(), slotSuffix(slot))
+	}
+
+	o.notifyAction(ActionSave, o.program.SectionID(v), v.DisplayName())
+
+	return nil
+}
+
+// doRestore restores the section's pointers from save slot number
+// slot. Pointers that have not been saved to the slot are skipped.
+func (o *runningProgramRoutine) doRestore(v *appconfig.SaveRestore, slot int) error {
+	o.trace.slotAction(traceOpRestore, o.program.SectionID(v), slot)
+
+	for _, pointer := range v.Pointers {
+		state, hasIt := o.states[SlotStateName(pointer.Name, slot)]
+		if !hasIt || !state.stateSet {
+			continue
+		}
+		err := o.restoreState(pointer.DisplayName(), state)
+		state.setLastErr(err)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s state at %+#v to 0x%x - %w",
+				pointer.DisplayName(), state.pointer, state.savedState, err)
+		}
+	}
+
+	if v.DisplayName() != "" {
+		log.Printf("restored '%s'%s", v.DisplayName(), slotSuffix(slot))
+	}
+
+	o.notifyAction(ActionRestore, o.program.SectionID(v), v.DisplayName())
+
+	return nil
+}
+
+func (o *runningProgramRoutine) doWrite(v *appconfig.Writer) error {
+	if o.safe {
+		log.Printf("skipping writer %s (safe mode is enabled)", o.program.SectionID(v))
+		return nil
+	}
+
+	o.trace.action(traceOpWrite, o.program.SectionID(v))
+
+	index := o.dataListIndexes[v]
+
+	for _, pointer := range v.Pointers {
+		pointer.Data = pointer.DataAt(index)
+
+		err := o.write(v, pointer)
+		if err != nil {
+			return fmt.Errorf("failed to write to %s - %w", pointer.Pointer.DisplayName(), err)
+		}
 	}
 
-	stateAddr, err := lookupAddr(baseAddr, state.pointer, o.addrFn)
+	// The next value is only written once this one
+	// succeeds, so a failed write can be retried.
+	if v.HasDataList() {
+		if o.dataListIndexes == nil {
+			o.dataListIndexes = make(map[*appconfig.Writer]int)
+		}
+
+		o.dataListIndexes[v] = index + 1
+	}
+
+	if v.DisplayName() != "" {
+		log.Printf("wrote '%s'", v.DisplayName())
+	}
+
+	o.notifyAction(ActionWrite, o.program.SectionID(v), v.DisplayName())
+
+	return nil
+}
+
+func (o *runningProgramRoutine) saveState(name string, state *programState) error {
+	stateAddr, err := o.resolve(state.pointer)
 	if err != nil {
 		return fmt.Errorf("failed to lookup address of state %s - %w",
 			name, err)
 	}
 
-	savedState, err := o.proc.ReadBytes(stateAddr, state.pointer.NBytes)
+	o.trace.resolved(state.pointer.Name, stateAddr)
+
+	savedState, err := o.readPointer(stateAddr, state.pointer)
 	if err != nil {
 		// TODO: update with INI name
 		return fmt.Errorf("failed to read from %s at 0x%x - %w",
 			name, stateAddr, err)
 	}
 
-	state.savedState = savedState
-	state.stateSet = true
-	log.Printf("saved %s state at 0x%x", name, stateAddr)
+	o.storeState(state, savedState)
+
+	if state.pointer.Display != "" {
+		log.Printf("saved %s = %s at 0x%x", name, state.pointer.FormatValue(savedState), stateAddr)
+	} else {
+		log.Printf("saved %s state at 0x%x", name, stateAddr)
+	}
 
 	return nil
 }
 
 func (o *runningProgramRoutine) restoreState(name string, state *programState) error {
-	baseAddr := o.base
-	if state.pointer.OptModule != "" {
-		module, hasIt := o.mods[state.pointer.OptModule]
-		if !hasIt {
-			return fmt.Errorf("unknown module %q", state.pointer.OptModule)
-		}
-
-		baseAddr = module.BaseAddr
-	}
-
-	stateAddr, err := lookupAddr(baseAddr, state.pointer, o.addrFn)
+	stateAddr, err := o.resolve(state.pointer)
 	if err != nil {
 		return fmt.Errorf("failed to get memory address of state %s - %w",
 			name, err)
 	}
 
-	err = o.proc.WriteBytes(stateAddr, state.savedState)
+	o.trace.resolved(state.pointer.Name, stateAddr)
+
+	data, err := state.pointer.Filter.Apply(state.savedState)
+	if err != nil {
+		return fmt.Errorf("failed to apply value options to %s - %w", name, err)
+	}
+
+	// Strings are padded with null characters so that the end
+	// of a longer string does not remain after a shorter one.
+	data = state.pointer.TerminateString(data)
+
+	err = o.writePointer(stateAddr, state.pointer, data)
 	if err != nil {
 		return fmt.Errorf("failed to write to %s at 0x%x - %w",
 			name, stateAddr, err)
 	}
 
-	log.Printf("restored %s state at 0x%x", name, stateAddr)
+	if state.pointer.Display != "" {
+		log.Printf("restored %s = %s at 0x%x", name, state.pointer.FormatValue(data), stateAddr)
+	} else {
+		log.Printf("restored %s state at 0x%x", name, stateAddr)
+	}
+
 	return nil
 }
 
-func lookupAddr(base uintptr, ptr appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) (uintptr, error) {
-	start := ptr.Addrs[0]
-	if len(ptr.Addrs) == 1 {
-		return base + start, nil
+// readPointer reads the pointer's data from addr. The values of
+// composite pointers are concatenated in the order of their fields.
+//
+// Large values are read one page at a time, so that a value that
+// is partially unreadable results in an error that describes the
+// part that could not be read.
+func (o *runningProgramRoutine) readPointer(addr uintptr, pointer appconfig.Pointer) ([]byte, error) {
+	if len(pointer.Fields) == 0 {
+		data, err := readPages(o.mem, addr, pointer.NBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return data, nil
 	}
 
-	addr, err := addrFn(base + start)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read from target process at 0x%x - %w",
-			addr, err)
+	data := make([]byte, 0, pointer.Size())
+	for _, field := range pointer.Fields {
+		value, err := readPages(o.mem, addr+field, pointer.NBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read field at 0x%x - %w", addr+field, err)
+		}
+
+		data = append(data, value...)
 	}
 
-	var offsets = ptr.Addrs[1:]
-	for _, offset := range offsets[:len(offsets)-1] {
-		addr, err = addrFn(addr + offset)
+	return data, nil
+}
+
+// writePointer writes data previously returned by readPointer to addr.
+func (o *runningProgramRoutine) writePointer(addr uintptr, pointer appconfig.Pointer, data []byte) error {
+	// Masked pointers are read first so that
+	// only the masked bits are changed.
+	if pointer.Mask != nil {
+		current, err := o.readPointer(addr, pointer)
 		if err != nil {
-			return 0, fmt.Errorf("failed to read from target process at 0x%x - %w",
-				addr, err)
+			return fmt.Errorf("failed to read masked value - %w", err)
 		}
+
+		data = pointer.Masked(current, data)
 	}
 
-	addr += offsets[len(offsets)-1]
+	if len(pointer.Fields) == 0 {
+		return o.mem.WriteBytes(addr, data)
+	}
 
-	return addr, nil
+	if len(data) != pointer.Size() {
+		return fmt.Errorf("expected %d bytes of data, got %d", pointer.Size(), len(data))
+	}
+
+	for i, field := range pointer.Fields {
+		err := o.mem.WriteBytes(addr+field, data[i*pointer.NBytes:(i+1)*pointer.NBytes])
+		if err != nil {
+			return fmt.Errorf("failed to write field at 0x%x - %w", addr+field, err)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the absolute address that ptr currently points to.
+func (o *runningProgramRoutine) resolve(ptr appconfig.Pointer) (uintptr, error) {
+	if !o.program.General.TraceChains {
+		return o.resolveWith(ptr, o.addrFn)
+	}
+
+	addr, err := o.resolveWith(ptr, chainTracingAddrFn(ptr, o.addrFn))
+	if err == nil {
+		log.Printf("chain %s: resolved to 0x%x", ptr.DisplayName(), addr)
+	}
+
+	return addr, err
 }
 
-func (o *runningProgramRoutine) write(pointer appconfig.WritePointer) error {
+// resolveWith is like resolve, but reads pointers using addrFn.
+func (o *runningProgramRoutine) resolveWith(ptr appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) (uintptr, error) {
 	baseAddr := o.base
-	if pointer.Pointer.OptModule != "" {
-		module, hasIt := o.mods[pointer.Pointer.OptModule]
+	if ptr.OptModule != "" {
+		module, hasIt := o.mods[ptr.OptModule]
 		if !hasIt {
-			return fmt.Errorf("unknown module %q", pointer.Pointer.OptModule)
+			return 0, fmt.Errorf("unknown module %q", ptr.OptModule)
 		}
 
 		baseAddr = module.BaseAddr
 	}
 
-	writeAddr, err := lookupAddr(baseAddr, pointer.Pointer, o.addrFn)
+	addr, err := lookupAddr(baseAddr, ptr, addrFn)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, step := range ptr.Steps {
+		module, hasIt := o.mods[step.Module]
+		if !hasIt {
+			return 0, fmt.Errorf("unknown module %q", step.Module)
+		}
+
+		// The value is an offset from the start of the module.
+		offset, err := addrFn(addr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read offset into %s at 0x%x - %w",
+				step.Module, addr, err)
+		}
+
+		addr, err = lookupAddr(module.BaseAddr+offset, appconfig.Pointer{Addrs: step.Addrs}, addrFn)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return addr, nil
+}
+
+// lookupAddr follows the pointer chain ptr.Addrs starting at base.
+// If a pointer in the chain cannot be read, the error includes the
+// number of the chain step (starting at 1) and the unreadable address.
+func lookupAddr(base uintptr, ptr appconfig.Pointer, addrFn func(uintptr) (uintptr, error)) (uintptr, error) {
+	addr := base + ptr.Addrs[0]
+
+	for i, offset := range ptr.Addrs[1:] {
+		next, err := addrFn(addr)
+		if err != nil {
+			return 0, fmt.Errorf("failed at chain step %d (0x%x unreadable) - %w",
+				i+1, addr, err)
+		}
+
+		addr = next + offset
+	}
+
+	return addr, nil
+}
+
+func (o *runningProgramRoutine) write(v *appconfig.Writer, pointer appconfig.WritePointer) error {
+	writeAddr, err := o.resolve(pointer.Pointer)
 	if err != nil {
 		return fmt.Errorf("failed to lookup write address %s - %w",
-			pointer.Pointer.Name, err)
+			pointer.Pointer.DisplayName(), err)
 	}
 
-	err = o.proc.WriteBytes(writeAddr, pointer.Data)
+	o.trace.resolved(pointer.Pointer.Name, writeAddr)
+
+	data, err := pointer.Pointer.Filter.Apply(pointer.Data)
+	if err != nil {
+		return fmt.Errorf("failed to apply value options to %s - %w",
+			pointer.Pointer.DisplayName(), err)
+	}
+
+	if pointer.Pointer.Mask != nil {
+		current, err := o.mem.ReadBytes(writeAddr, len(data))
+		if err != nil {
+			return fmt.Errorf("failed to read masked value at %s (0x%x) - %w",
+				pointer.Pointer.DisplayName(), writeAddr, err)
+		}
+
+		data = pointer.Pointer.Masked(current, data)
+	}
+
+	err = o.mem.WriteBytes(writeAddr, data)
 	if err != nil {
 		// TODO: update with INI name
 		return fmt.Errorf("failed to write bytes at %s (0x%x) - %w",
-			pointer.Pointer.Name, writeAddr, err)
+			pointer.Pointer.DisplayName(), writeAddr, err)
+	}
+
+	if pointer.Pointer.Display != "" {
+		log.Printf("wrote %s = %s at 0x%x", pointer.Pointer.DisplayName(),
+			pointer.Pointer.FormatValue(data), writeAddr)
+	} else {
+		log.Printf("wrote bytes at %s (0x%x)", pointer.Pointer.DisplayName(), writeAddr)
 	}
 
-	log.Printf("wrote bytes at %s (0x%x)", pointer.Pointer.Name, writeAddr)
+	o.overlaps.wrote(v.Group, pointer.Pointer, writeAddr, len(data))
 
 	return nil
 }
@@ -476,4 +1679,12 @@ type programState struct {
 	pointer    appconfig.Pointer
 	stateSet   bool
 	savedState []byte
+	savedAt    time.Time
+
+	// lastErr is the error of the most recent failed attempt to
+	// resolve, read, or write the pointer. It is cleared when the
+	// pointer is next accessed successfully. It is guarded by errMu
+	// rather than actionMu so that the UI can read it at any time.
+	errMu   sync.Mutex
+	lastErr error
 }
@@ -0,0 +1,65 @@
+package progctl
+
+import (
+	"time"
+
+	"github.com/SeungKang/blaj/internal/kernel32"
+)
+
+const (
+	// memRetryAttempts is the maximum number of times
+	// that a memory operation is attempted.
+	memRetryAttempts = 3
+
+	// memRetryBackoff is the delay before the first retry of a
+	// memory operation. The delay doubles after each attempt, so
+	// an operation that always fails gives up after 30ms.
+	memRetryBackoff = 10 * time.Millisecond
+)
+
+// retryingIO is a ProcessIO that retries failed operations on the
+// underlying ProcessIO. Reads and writes of memory that the program
+// is modifying (e.g. during a loading screen) sometimes fail with
+// ERROR_PARTIAL_COPY and then succeed on the next attempt.
+//
+// Only ERROR_PARTIAL_COPY is retried. Other errors, such as memory
+// that is not mapped or a handle that was closed, are returned
+// immediately.
+type retryingIO struct {
+	inner ProcessIO
+}
+
+func (o *retryingIO) ReadBytes(addr uintptr, size int) ([]byte, error) {
+	var data []byte
+
+	err := retryMemOp(func() error {
+		var err error
+		data, err = o.inner.ReadBytes(addr, size)
+		return err
+	})
+
+	return data, err
+}
+
+func (o *retryingIO) WriteBytes(addr uintptr, data []byte) error {
+	return retryMemOp(func() error {
+		return o.inner.WriteBytes(addr, data)
+	})
+}
+
+// retryMemOp calls fn until it succeeds, it fails with an error
+// other than ERROR_PARTIAL_COPY, or it has been called
+// memRetryAttempts times. The last error is returned.
+func retryMemOp(fn func() error) error {
+	backoff := memRetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == memRetryAttempts || !kernel32.IsPartialCopy(err) {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,120 @@
+package progctl
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// scheduler performs actions after a delay. Each action is identified
+// by a key, and scheduling an action replaces the pending action with
+// the same key, so that pressing a keybind again restarts its timing
+// rather than performing the action twice.
+//
+// It is guarded by actionMu.
+type scheduler struct {
+	timers map[interface{}]*time.Timer
+}
+
+// schedule calls fn with actionMu held after delay, unless the routine
+// exits, the action is replaced, or stop is called first. If the
+// program is halted (see haltReason), fn is called once it resumes.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) schedule(key interface{}, delay time.Duration, fn func()) {
+	if o.scheduler.timers == nil {
+		o.scheduler.timers = make(map[interface{}]*time.Timer)
+	}
+
+	pending, hasIt := o.scheduler.timers[key]
+	if hasIt {
+		pending.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		select {
+		case <-o.done:
+			return
+		default:
+		}
+
+		o.actionMu.Lock()
+		defer o.actionMu.Unlock()
+
+		// The action may have been replaced or
+		// stopped while waiting for the lock.
+		if o.scheduler.timers[key] != timer {
+			return
+		}
+
+		// Actions are deferred while the program is
+		// halted (e.g. stopped in a debugger).
+		if o.haltReason() != "" {
+			timer.Reset(haltPollInterval)
+			return
+		}
+
+		delete(o.scheduler.timers, key)
+
+		fn()
+	})
+
+	o.scheduler.timers[key] = timer
+}
+
+// stopScheduled cancels every pending action.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) stopScheduled() {
+	for key, timer := range o.scheduler.timers {
+		timer.Stop()
+		delete(o.scheduler.timers, key)
+	}
+}
+
+// scheduleAfterRestore performs the second phase of a restore: the
+// section's AfterRestore writer is written (or turned on, if it is a
+// freeze writer) AfterRestoreDelay after the section was restored.
+// This allows, for example, a timer to be restored and then frozen
+// again once the game has caught up with the restored values.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) scheduleAfterRestore(v *appconfig.SaveRestore) error {
+	writer := v.AfterRestore
+	if writer == nil {
+		return nil
+	}
+
+	if v.AfterRestoreDelay <= 0 {
+		return o.doAfterRestore(writer)
+	}
+
+	o.schedule(v, v.AfterRestoreDelay, func() {
+		err := o.doAfterRestore(writer)
+		if err != nil {
+			o.exited(o.sectionError(writer, fmt.Errorf("failed to write %s after restoring %s - %w",
+				o.writerName(writer), o.program.SectionID(v), err)))
+		}
+	})
+
+	log.Printf("scheduled %s in %s", o.writerName(writer), v.AfterRestoreDelay)
+
+	return nil
+}
+
+// doAfterRestore writes writer, or turns it on if it is
+// a freeze writer that is off. It is also used to trigger
+// writers from onSuccess and onFailure params.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) doAfterRestore(writer *appconfig.Writer) error {
+	_, isOn := o.freezes[writer]
+	if isOn {
+		return nil
+	}
+
+	return o.turnOnWriter(writer)
+}
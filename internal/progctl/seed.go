@@ -0,0 +1,150 @@
+package progctl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+)
+
+// handleSeed performs the action of a Seed section's
+// writeValue or reroll keybind.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) handleSeed(v *appconfig.Seed, pressedKey appconfig.Key) error {
+	switch pressedKey {
+	case v.WriteValue:
+		return o.doWriteSeed(v)
+	case v.Reroll:
+		return o.doReroll(v)
+	}
+
+	return nil
+}
+
+// doWriteSeed writes the seed's fixed value.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) doWriteSeed(v *appconfig.Seed) error {
+	if o.safe {
+		log.Printf("skipping seed %s (safe mode is enabled)", o.program.SectionID(v.SaveRestore))
+		return nil
+	}
+
+	o.trace.action(traceOpWriteSeed, o.program.SectionID(v.SaveRestore))
+
+	err := o.writeSeed(v, v.Value)
+	o.states[v.Pointer().Name].setLastErr(err)
+	if err != nil {
+		return err
+	}
+
+	o.notifyAction(ActionWrite, o.program.SectionID(v.SaveRestore), v.DisplayName())
+
+	return nil
+}
+
+// doReroll writes the seed after the one that blaj last wrote. If
+// no seed has been written since the seed was saved, the next seed
+// is the one after the saved seed (or the current seed, if it has
+// not been saved). This allows the seeds that follow a saved seed
+// to be tried one at a time by restoring and rerolling.
+//
+// The seed is treated as a little-endian unsigned integer.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) doReroll(v *appconfig.Seed) error {
+	if o.safe {
+		log.Printf("skipping seed %s (safe mode is enabled)", o.program.SectionID(v.SaveRestore))
+		return nil
+	}
+
+	o.trace.action(traceOpReroll, o.program.SectionID(v.SaveRestore))
+
+	pointer := v.Pointer()
+
+	last, hasIt := o.lastSeeds[v]
+	if !hasIt {
+		state := o.states[pointer.Name]
+		if state.stateSet {
+			last = state.savedState
+		} else {
+			addr, err := o.resolve(pointer)
+			if err != nil {
+				err = fmt.Errorf("failed to lookup address of %s - %w", pointer.DisplayName(), err)
+				o.states[pointer.Name].setLastErr(err)
+				return err
+			}
+
+			o.trace.resolved(pointer.Name, addr)
+
+			last, err = o.readPointer(addr, pointer)
+			if err != nil {
+				err = fmt.Errorf("failed to read from %s at 0x%x - %w", pointer.DisplayName(), addr, err)
+				o.states[pointer.Name].setLastErr(err)
+				return err
+			}
+		}
+	}
+
+	err := o.writeSeed(v, nextSeed(last))
+	o.states[pointer.Name].setLastErr(err)
+	if err != nil {
+		return err
+	}
+
+	o.notifyAction(ActionReroll, o.program.SectionID(v.SaveRestore), v.DisplayName())
+
+	return nil
+}
+
+// nextSeed returns seed plus one, wrapping around
+// to zero if seed is the largest value of its size.
+func nextSeed(seed []byte) []byte {
+	padded := make([]byte, 8)
+	copy(padded, seed)
+
+	binary.LittleEndian.PutUint64(padded, binary.LittleEndian.Uint64(padded)+1)
+
+	return padded[:len(seed)]
+}
+
+// writeSeed writes data to the seed's pointer
+// and remembers it for the next reroll.
+//
+// actionMu must be held by the caller.
+func (o *runningProgramRoutine) writeSeed(v *appconfig.Seed, data []byte) error {
+	pointer := v.Pointer()
+
+	addr, err := o.resolve(pointer)
+	if err != nil {
+		return fmt.Errorf("failed to lookup address of %s - %w", pointer.DisplayName(), err)
+	}
+
+	o.trace.resolved(pointer.Name, addr)
+
+	filtered, err := pointer.Filter.Apply(data)
+	if err != nil {
+		return fmt.Errorf("failed to apply value options to %s - %w", pointer.DisplayName(), err)
+	}
+
+	err = o.writePointer(addr, pointer, filtered)
+	if err != nil {
+		return fmt.Errorf("failed to write to %s at 0x%x - %w", pointer.DisplayName(), addr, err)
+	}
+
+	if o.lastSeeds == nil {
+		o.lastSeeds = make(map[*appconfig.Seed][]byte)
+	}
+
+	o.lastSeeds[v] = filtered
+
+	if pointer.Display != "" {
+		log.Printf("wrote seed %s = %s at 0x%x", pointer.DisplayName(), pointer.FormatValue(filtered), addr)
+	} else {
+		log.Printf("wrote seed %s at 0x%x", pointer.DisplayName(), addr)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package progctl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/Andoryuuta/kiwi"
+)
+
+// selfTestData is read from this process's memory by CheckMemoryAccess.
+var selfTestData = [8]byte{'b', 'l', 'a', 'j', 't', 'e', 's', 't'}
+
+// CheckMemoryAccess opens this process the same way that programs
+// are opened and reads a known value from its memory. An error means
+// that something, such as antivirus software, prevents blaj from
+// reading other processes' memory.
+func CheckMemoryAccess() error {
+	proc, err := kiwi.GetProcessByPID(os.Getpid())
+	if err != nil {
+		return fmt.Errorf("failed to open a process - %w", err)
+	}
+	defer syscall.CloseHandle(syscall.Handle(proc.Handle))
+
+	data, err := proc.ReadBytes(uintptr(unsafe.Pointer(&selfTestData[0])), len(selfTestData))
+	if err != nil {
+		return fmt.Errorf("failed to read process memory - %w", err)
+	}
+
+	if !bytes.Equal(data, selfTestData[:]) {
+		return fmt.Errorf("read unexpected data from process memory: %x", data)
+	}
+
+	return nil
+}
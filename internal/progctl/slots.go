@@ -0,0 +1,28 @@
+package progctl
+
+import (
+	"fmt"
+)
+
+// SlotStateName returns the name of the state that holds the value of
+// the pointer named pointerName in save slot number. Slot 0 is the
+// state saved by the section's saveState keybind, which is stored
+// under the pointer's name. Saved states are keyed by these names
+// (see Routine.SavedStates).
+func SlotStateName(pointerName string, slot int) string {
+	if slot == 0 {
+		return pointerName
+	}
+
+	return fmt.Sprintf("%s#slot%d", pointerName, slot)
+}
+
+// slotSuffix returns the text that is appended to log messages
+// about the save slot number, or an empty string for slot 0.
+func slotSuffix(slot int) string {
+	if slot == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (slot %d)", slot)
+}
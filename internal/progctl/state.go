@@ -0,0 +1,89 @@
+package progctl
+
+import (
+	"log"
+)
+
+// State is the state of a Routine.
+type State string
+
+const (
+	// StateSearching means that the program is not running.
+	StateSearching State = "searching"
+
+	// StateAttaching means that the program is running, but the
+	// Routine has not attached to it yet (e.g. it is waiting for
+	// the program's window).
+	StateAttaching State = "attaching"
+
+	// StateAttached means that keybinds are being handled.
+	StateAttached State = "attached"
+
+	// StateDegraded means that the Routine is attached, but an
+	// action failed and is being retried during the attach
+	// grace period.
+	StateDegraded State = "degraded"
+
+	// StateIdle means that the Routine is attached, but its
+	// process handle was released because no keybinds were
+	// pressed for General.IdleTimeout.
+	StateIdle State = "idle"
+
+	// StateDetached means that the program exited. Saved states
+	// are kept until the reattach grace period ends.
+	StateDetached State = "detached"
+
+	// StateError means that the Routine stopped controlling the
+	// program due to an error, or that the Routine exited. It is
+	// also used while the Routine refuses to attach to a process
+	// that loaded anti-cheat software, until the process exits.
+	StateError State = "error"
+)
+
+// StateNotifier is optionally implemented by a Notifier
+// to be notified when the Routine's State changes. err is
+// non-nil if state is StateDegraded or StateError.
+type StateNotifier interface {
+	StateChanged(exename string, state State, err error)
+}
+
+// State returns the current state of the Routine and the
+// error that caused it, if any.
+func (o *Routine) State() (State, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.state == "" {
+		return StateSearching, nil
+	}
+
+	return o.state, o.stateErr
+}
+
+// setState transitions the Routine to state. It may be
+// called from any goroutine.
+func (o *Routine) setState(state State, err error) {
+	o.mu.Lock()
+	if o.state == state && o.stateErr == err {
+		o.mu.Unlock()
+		return
+	}
+
+	prev := o.state
+	o.state = state
+	o.stateErr = err
+	o.mu.Unlock()
+
+	if err != nil {
+		log.Printf("%s state changed from %s to %s - %s",
+			o.Program.General.ExeName, prev, state, err)
+	} else {
+		log.Printf("%s state changed from %s to %s",
+			o.Program.General.ExeName, prev, state)
+	}
+
+	stateNotif, ok := o.Notif.(StateNotifier)
+	if ok {
+		stateNotif.StateChanged(o.Program.General.ExeName, state, err)
+	}
+}
@@ -0,0 +1,66 @@
+package progctl
+
+import (
+	"log"
+	"time"
+)
+
+// storeState saves data as state's saved value. The state's existing
+// buffer is reused when it is large enough, so that saving the same
+// pointer repeatedly does not allocate a new buffer each time.
+//
+// If the program's MaxStateMemory is exceeded, the least recently
+// saved states are discarded until the total size of the saved
+// states is within the limit.
+func (o *runningProgramRoutine) storeState(state *programState, data []byte) {
+	if cap(state.savedState) >= len(data) {
+		state.savedState = append(state.savedState[:0], data...)
+	} else {
+		state.savedState = append([]byte(nil), data...)
+	}
+
+	state.stateSet = true
+	state.savedAt = time.Now()
+
+	o.enforceStateMemory(state)
+}
+
+// enforceStateMemory discards saved states, other than keep, until
+// the total size of the saved states is within the limit.
+func (o *runningProgramRoutine) enforceStateMemory(keep *programState) {
+	limit := o.program.General.MaxStateMemory
+	if limit <= 0 {
+		return
+	}
+
+	total := 0
+	for _, state := range o.states {
+		total += cap(state.savedState)
+	}
+
+	for total > limit {
+		var oldest *programState
+		for _, state := range o.states {
+			if state == keep || state.savedState == nil {
+				continue
+			}
+
+			if oldest == nil || state.savedAt.Before(oldest.savedAt) {
+				oldest = state
+			}
+		}
+
+		if oldest == nil {
+			log.Printf("warning: %s uses %d bytes, which is more than maxStateMemory (%d bytes)",
+				keep.pointer.DisplayName(), cap(keep.savedState), limit)
+			return
+		}
+
+		log.Printf("discarding saved state of %s to stay within maxStateMemory (%d bytes)",
+			oldest.pointer.DisplayName(), limit)
+
+		total -= cap(oldest.savedState)
+		oldest.savedState = nil
+		oldest.stateSet = false
+	}
+}
@@ -0,0 +1,464 @@
+package progctl
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/kernel32"
+)
+
+const (
+	traceOpAttach      = "attach"
+	traceOpSave        = "save"
+	traceOpRestore     = "restore"
+	traceOpWrite       = "write"
+	traceOpCompare     = "compare"
+	traceOpWriteSeed   = "write_seed"
+	traceOpReroll      = "reroll"
+	traceOpIncrease    = "increase"
+	traceOpDecrease    = "decrease"
+	traceOpResolve     = "resolve"
+	traceOpReadMemory  = "read_memory"
+	traceOpWriteMemory = "write_memory"
+)
+
+// TraceEvent is a single entry in a trace file. Trace files contain
+// one JSON-encoded TraceEvent per line.
+type TraceEvent struct {
+	Op      string            `json:"op"`
+	Section string            `json:"section,omitempty"`
+	Slot    int               `json:"slot,omitempty"`
+	Pointer string            `json:"pointer,omitempty"`
+	Addr    uint64            `json:"addr,omitempty"`
+	Size    int               `json:"size,omitempty"`
+	Data    string            `json:"data,omitempty"`
+	Err     string            `json:"err,omitempty"`
+	ExeName string            `json:"exe_name,omitempty"`
+	Base    uint64            `json:"base,omitempty"`
+	Is32Bit bool              `json:"is_32_bit,omitempty"`
+	Modules map[string]uint64 `json:"modules,omitempty"`
+}
+
+func (o TraceEvent) isAction() bool {
+	switch o.Op {
+	case traceOpSave, traceOpRestore, traceOpWrite, traceOpCompare, traceOpIncrease, traceOpDecrease:
+		return true
+	default:
+		return false
+	}
+}
+
+func (o TraceEvent) String() string {
+	str := fmt.Sprintf("%s 0x%x", o.Op, o.Addr)
+	if o.Pointer != "" {
+		str += " " + o.Pointer
+	}
+
+	if o.Size > 0 {
+		str += fmt.Sprintf(" (%d bytes)", o.Size)
+	}
+
+	if o.Data != "" {
+		str += " data=" + o.Data
+	}
+
+	if o.Err != "" {
+		str += " err=" + o.Err
+	}
+
+	return str
+}
+
+// traceRecorder records TraceEvents. A nil *traceRecorder
+// discards all events.
+type traceRecorder struct {
+	mu     sync.Mutex
+	emitFn func(TraceEvent) error
+	closer io.Closer
+	failed bool
+}
+
+// newTraceFile creates a trace file for exeName in dirPath.
+func newTraceFile(dirPath string, exeName string) (*traceRecorder, error) {
+	err := os.MkdirAll(dirPath, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace directory - %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.trace", exeName, time.Now().Format("20060102-150405"))
+
+	f, err := os.OpenFile(filepath.Join(dirPath, fileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file - %w", err)
+	}
+
+	encoder := json.NewEncoder(f)
+
+	return &traceRecorder{
+		emitFn: func(event TraceEvent) error {
+			return encoder.Encode(event)
+		},
+		closer: f,
+	}, nil
+}
+
+func (o *traceRecorder) emit(event TraceEvent) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.emitFn(event)
+	if err != nil && !o.failed {
+		o.failed = true
+		log.Printf("failed to write trace event - %s", err)
+	}
+}
+
+func (o *traceRecorder) action(op string, sectionID string) {
+	o.emit(TraceEvent{Op: op, Section: sectionID})
+}
+
+// slotAction records an action that uses
+// a SaveRestore section's save slot.
+func (o *traceRecorder) slotAction(op string, sectionID string, slot int) {
+	o.emit(TraceEvent{Op: op, Section: sectionID, Slot: slot})
+}
+
+func (o *traceRecorder) resolved(pointerName string, addr uintptr) {
+	o.emit(TraceEvent{Op: traceOpResolve, Pointer: pointerName, Addr: uint64(addr)})
+}
+
+func (o *traceRecorder) Close() error {
+	if o == nil || o.closer == nil {
+		return nil
+	}
+
+	return o.closer.Close()
+}
+
+// recordingIO is a ProcessIO that records each operation
+// performed on the underlying ProcessIO.
+type recordingIO struct {
+	inner ProcessIO
+	trace *traceRecorder
+}
+
+func (o *recordingIO) ReadBytes(addr uintptr, size int) ([]byte, error) {
+	data, err := o.inner.ReadBytes(addr, size)
+
+	event := TraceEvent{
+		Op:   traceOpReadMemory,
+		Addr: uint64(addr),
+		Size: size,
+		Data: hex.EncodeToString(data),
+	}
+	if err != nil {
+		event.Err = err.Error()
+	}
+
+	o.trace.emit(event)
+
+	return data, err
+}
+
+func (o *recordingIO) WriteBytes(addr uintptr, data []byte) error {
+	err := o.inner.WriteBytes(addr, data)
+
+	event := TraceEvent{
+		Op:   traceOpWriteMemory,
+		Addr: uint64(addr),
+		Size: len(data),
+		Data: hex.EncodeToString(data),
+	}
+	if err != nil {
+		event.Err = err.Error()
+	}
+
+	o.trace.emit(event)
+
+	return err
+}
+
+// startTrace records the routine's memory operations to a new
+// trace file in dirPath.
+func (o *runningProgramRoutine) startTrace(dirPath string) error {
+	trace, err := newTraceFile(dirPath, o.program.General.ExeName)
+	if err != nil {
+		return err
+	}
+
+	o.useTrace(trace)
+
+	return nil
+}
+
+func (o *runningProgramRoutine) useTrace(trace *traceRecorder) {
+	modules := make(map[string]uint64, len(o.mods))
+	for name, module := range o.mods {
+		modules[name] = uint64(module.BaseAddr)
+	}
+
+	trace.emit(TraceEvent{
+		Op:      traceOpAttach,
+		ExeName: o.program.General.ExeName,
+		Base:    uint64(o.base),
+		Is32Bit: o.is32b,
+		Modules: modules,
+	})
+
+	o.trace = trace
+	o.mem = &recordingIO{inner: o.mem, trace: trace}
+	o.addrFn = addrFnFor(o.mem, o.is32b)
+}
+
+// ReplayResult is the result of replaying a trace.
+type ReplayResult struct {
+	// NumActions is the number of actions that were replayed.
+	NumActions int
+
+	// Mismatches describes each difference between the
+	// recorded and replayed memory operations.
+	Mismatches []string
+}
+
+// ReplayTrace replays each action in a trace file against a
+// MockProcess using the provided program config. The mock's memory
+// is populated using the reads recorded in the trace. The resulting
+// memory operations are compared to those in the trace, which allows
+// changes to pointer resolution to be verified against real sessions.
+//
+// An error is returned if an action's section does not support the
+// action, which happens when the trace was recorded with a different
+// config.
+func ReplayTrace(program *appconfig.ProgramConfig, r io.Reader) (*ReplayResult, error) {
+	events, err := readTrace(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 || events[0].Op != traceOpAttach {
+		return nil, errors.New("trace does not start with an attach event")
+	}
+
+	attach := events[0]
+	if attach.ExeName != program.General.ExeName {
+		return nil, fmt.Errorf("trace was recorded for %q, but config is for %q",
+			attach.ExeName, program.General.ExeName)
+	}
+
+	mods := make(map[string]kernel32.Module, len(attach.Modules))
+	for name, base := range attach.Modules {
+		mods[name] = kernel32.Module{Filename: name, BaseAddr: uintptr(base)}
+	}
+
+	mock := NewMockProcess()
+
+	var replayed []TraceEvent
+	replayer := &runningProgramRoutine{
+		program: program,
+		base:    uintptr(attach.Base),
+		is32b:   attach.Is32Bit,
+		mods:    mods,
+		states:  newProgramStates(program),
+		mem:     mock,
+		done:    make(chan struct{}),
+	}
+	replayer.useTrace(&traceRecorder{
+		emitFn: func(event TraceEvent) error {
+			replayed = append(replayed, event)
+			return nil
+		},
+	})
+
+	result := &ReplayResult{}
+	actions := splitActions(events[1:])
+	for _, recorded := range actions {
+		action := recorded[0]
+
+		// Writes are mapped first so that the mock has memory
+		// at the written addresses. Reads are mapped afterwards
+		// because they reflect memory prior to the action.
+		for _, op := range []string{traceOpWriteMemory, traceOpReadMemory} {
+			for _, event := range recorded[1:] {
+				if event.Op != op || event.Err != "" {
+					continue
+				}
+
+				data, err := hex.DecodeString(event.Data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode data at 0x%x - %w", event.Addr, err)
+				}
+
+				mock.SetBytes(uintptr(event.Addr), data)
+			}
+		}
+
+		section, err := program.SectionByName(action.Section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find section for %s action - %w", action.Op, err)
+		}
+
+		// The section may have a different type if the
+		// trace was recorded with a different config.
+		var saveRestore *appconfig.SaveRestore
+		var writer *appconfig.Writer
+		var nudge *appconfig.Nudge
+		var isType bool
+		switch action.Op {
+		case traceOpSave, traceOpRestore, traceOpCompare:
+			saveRestore, isType = section.(*appconfig.SaveRestore)
+		case traceOpWriteSeed, traceOpReroll:
+			saveRestore, isType = section.(*appconfig.SaveRestore)
+			isType = isType && saveRestore.Seed != nil
+		case traceOpWrite:
+			writer, isType = section.(*appconfig.Writer)
+		case traceOpIncrease, traceOpDecrease:
+			nudge, isType = section.(*appconfig.Nudge)
+		default:
+			return nil, fmt.Errorf("unknown action: %q", action.Op)
+		}
+
+		if !isType {
+			return nil, fmt.Errorf("section %s does not support %s actions", action.Section, action.Op)
+		}
+
+		replayed = nil
+		switch action.Op {
+		case traceOpSave:
+			err = replayer.doSave(saveRestore, action.Slot)
+		case traceOpRestore:
+			err = replayer.doRestore(saveRestore, action.Slot)
+		case traceOpWrite:
+			err = replayer.doWrite(writer)
+		case traceOpCompare:
+			err = replayer.doCompare(saveRestore)
+		case traceOpWriteSeed:
+			err = replayer.doWriteSeed(saveRestore.Seed)
+		case traceOpReroll:
+			err = replayer.doReroll(saveRestore.Seed)
+		case traceOpIncrease, traceOpDecrease:
+			err = replayer.doNudge(nudge, action.Op == traceOpIncrease)
+		}
+		if err != nil {
+			// Failures are compared below, since the
+			// recorded action may have failed too.
+			log.Printf("replayed %s %s failed - %s", action.Op, action.Section, err)
+		}
+
+		result.NumActions++
+		result.Mismatches = append(result.Mismatches,
+			compareEvents(result.NumActions, action, recorded[1:], replayed[1:])...)
+	}
+
+	return result, nil
+}
+
+// TraceExeName returns the exe name of the program that a trace
+// was recorded for.
+func TraceExeName(r io.Reader) (string, error) {
+	var attach TraceEvent
+	err := json.NewDecoder(r).Decode(&attach)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse trace event - %w", err)
+	}
+
+	if attach.Op != traceOpAttach {
+		return "", errors.New("trace does not start with an attach event")
+	}
+
+	return attach.ExeName, nil
+}
+
+func readTrace(r io.Reader) ([]TraceEvent, error) {
+	var events []TraceEvent
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var event TraceEvent
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, fmt.Errorf("line %d - failed to parse trace event - %w", line, err)
+		}
+
+		events = append(events, event)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace - %w", err)
+	}
+
+	return events, nil
+}
+
+// splitActions groups events by the action that caused them.
+// The first event in each group is the action itself.
+func splitActions(events []TraceEvent) [][]TraceEvent {
+	var actions [][]TraceEvent
+	for _, event := range events {
+		if event.isAction() {
+			actions = append(actions, []TraceEvent{event})
+			continue
+		}
+
+		if len(actions) > 0 {
+			actions[len(actions)-1] = append(actions[len(actions)-1], event)
+		}
+	}
+
+	return actions
+}
+
+// compareEvents compares the recorded and replayed events of
+// a single action. The order of events is not compared because
+// the order in which a writer's pointers are written is not defined.
+func compareEvents(index int, action TraceEvent, recorded []TraceEvent, replayed []TraceEvent) []string {
+	counts := make(map[string]int)
+	for _, event := range recorded {
+		counts[event.String()]++
+	}
+
+	for _, event := range replayed {
+		counts[event.String()]--
+	}
+
+	var mismatches []string
+	for str, count := range counts {
+		switch {
+		case count > 0:
+			mismatches = append(mismatches, fmt.Sprintf("action %d (%s %s): missing %s",
+				index, action.Op, action.Section, str))
+		case count < 0:
+			mismatches = append(mismatches, fmt.Sprintf("action %d (%s %s): unexpected %s",
+				index, action.Op, action.Section, str))
+		}
+	}
+
+	sort.Strings(mismatches)
+
+	return mismatches
+}
@@ -0,0 +1,291 @@
+// Package remoteconf downloads config files that are included by
+// URL and caches them so that configs still load while offline.
+//
+// Configs are parsed using only the cached copies (see Cached), so
+// that parsing never waits for the network. Files are downloaded
+// separately, either before parsing when a file has not been
+// downloaded yet (see FetchMissing), or in the background.
+package remoteconf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxSize is the maximum size of a downloaded config file.
+const MaxSize = 1 << 20
+
+// Result is a downloaded or cached config file.
+type Result struct {
+	// Data is the config file's contents.
+	Data []byte
+
+	// Stale is the error that prevented the file from
+	// being downloaded when the cached copy was used
+	// instead. It is nil if the cached copy is known
+	// to be up to date.
+	Stale error
+
+	// Changed is true if the downloaded file differs
+	// from the previously cached copy.
+	Changed bool
+}
+
+// NotCachedError is returned by Cached if
+// a file has not been downloaded yet.
+type NotCachedError struct {
+	URL string
+}
+
+func (o *NotCachedError) Error() string {
+	return fmt.Sprintf("%s has not been downloaded yet", o.URL)
+}
+
+// maxRedirects is the number of redirects
+// that are followed when downloading a file.
+const maxRedirects = 10
+
+// Fetcher downloads config files and caches them in a directory.
+// Cached files are revalidated using their ETag each time they
+// are fetched.
+type Fetcher struct {
+	// CacheDir is the directory that files are cached in.
+	CacheDir string
+
+	// Client is the HTTP client used to download files.
+	// http.DefaultClient is used if nil. Redirects to
+	// URLs that are not HTTPS are always rejected.
+	Client *http.Client
+
+	mu sync.Mutex
+}
+
+// DefaultFetcher caches files in the cache/includes
+// directory of the .blaj directory.
+var DefaultFetcher = &Fetcher{
+	Client: &http.Client{Timeout: 10 * time.Second},
+}
+
+// Fetch downloads the config file at rawURL using DefaultFetcher.
+func Fetch(rawURL string) (*Result, error) {
+	return DefaultFetcher.Fetch(rawURL)
+}
+
+// Cached returns the copy of the config file at rawURL
+// that is cached by DefaultFetcher.
+func Cached(rawURL string) (*Result, error) {
+	return DefaultFetcher.Cached(rawURL)
+}
+
+// FetchMissing downloads the file that err reports has not been
+// downloaded yet using DefaultFetcher. It returns true if the file
+// was downloaded, meaning that whatever failed with err can be
+// retried, and false if err is not a NotCachedError.
+func FetchMissing(err error) (bool, error) {
+	var notCached *NotCachedError
+	if !errors.As(err, &notCached) {
+		return false, nil
+	}
+
+	result, fetchErr := DefaultFetcher.Fetch(notCached.URL)
+	if fetchErr == nil {
+		// The file is only usable once it is cached.
+		fetchErr = result.Stale
+	}
+	if fetchErr != nil {
+		return false, fmt.Errorf("failed to download %s - %w", notCached.URL, fetchErr)
+	}
+
+	return true, nil
+}
+
+// Cached returns the cached copy of the config file at rawURL
+// without downloading it. A *NotCachedError is returned if the
+// file has not been downloaded yet.
+func (o *Fetcher) Cached(rawURL string) (*Result, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	cachePath, err := o.cachePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := os.ReadFile(cachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &NotCachedError{URL: rawURL}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached copy of %s - %w", rawURL, err)
+	}
+
+	return &Result{Data: cached}, nil
+}
+
+// Fetch downloads the config file at rawURL. Only HTTPS URLs
+// are supported. If the file cannot be downloaded, the cached
+// copy is returned with Result.Stale set to the reason.
+func (o *Fetcher) Fetch(rawURL string) (*Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url - %w", err)
+	}
+
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("only https urls can be included (got %q)", u.Scheme)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	cachePath, err := o.cachePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	etagPath := cachePath + ".etag"
+
+	cached, cacheErr := os.ReadFile(cachePath)
+	hasCache := cacheErr == nil
+
+	var etag string
+	if hasCache {
+		etagBytes, err := os.ReadFile(etagPath)
+		if err == nil {
+			etag = strings.TrimSpace(string(etagBytes))
+		}
+	}
+
+	data, newETag, notModified, err := o.download(rawURL, etag)
+	switch {
+	case err != nil && hasCache:
+		return &Result{Data: cached, Stale: err}, nil
+	case err != nil:
+		return nil, err
+	case notModified && hasCache:
+		return &Result{Data: cached}, nil
+	case notModified:
+		return nil, errors.New("server reported that the file was not modified, but it is not cached")
+	}
+
+	changed := !hasCache || !bytes.Equal(cached, data)
+
+	err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+	if err == nil {
+		err = os.WriteFile(cachePath, data, 0o600)
+	}
+	if err == nil {
+		if newETag != "" {
+			err = os.WriteFile(etagPath, []byte(newETag), 0o600)
+		} else {
+			err = os.Remove(etagPath)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		// The download is still usable, but it
+		// will not be available while offline.
+		return &Result{Data: data, Stale: fmt.Errorf("failed to cache file - %w", err), Changed: changed}, nil
+	}
+
+	return &Result{Data: data, Changed: changed}, nil
+}
+
+func (o *Fetcher) download(rawURL string, etag string) ([]byte, string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := http.DefaultClient
+	if o.Client != nil {
+		client = o.Client
+	}
+
+	// A copy of the client is used so that
+	// its CheckRedirect is not modified.
+	withRedirectCheck := *client
+	withRedirectCheck.CheckRedirect = checkRedirect
+
+	resp, err := withRedirectCheck.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", true, nil
+	default:
+		return nil, "", false, fmt.Errorf("server responded with %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to read response - %w", err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, "", false, fmt.Errorf("file is larger than %d bytes", MaxSize)
+	}
+
+	return data, resp.Header.Get("ETag"), false, nil
+}
+
+// checkRedirect implements http.Client.CheckRedirect. Only HTTPS
+// URLs can be included, so redirects to other schemes are rejected
+// rather than downgrading the download to plain HTTP.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing to follow redirect to a %q url", req.URL.Scheme)
+	}
+
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	return nil
+}
+
+// cachePath returns the path of the cached copy of rawURL.
+func (o *Fetcher) cachePath(rawURL string) (string, error) {
+	cacheDir, err := o.cacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:])), nil
+}
+
+func (o *Fetcher) cacheDir() (string, error) {
+	if o.CacheDir != "" {
+		return o.CacheDir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory - %w", err)
+	}
+
+	return filepath.Join(homeDir, ".blaj", "cache", "includes"), nil
+}
@@ -0,0 +1,198 @@
+// Package session records a timeline of the actions performed
+// while blaj is attached to a program.
+package session
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is a single entry in a Session.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`
+	Section string    `json:"section,omitempty"`
+	Label   string    `json:"label,omitempty"`
+	Input   *Input    `json:"input,omitempty"`
+}
+
+// Input is the key press that triggered an Event.
+type Input struct {
+	// KeyTime is when the key press was received.
+	KeyTime time.Time `json:"key_time"`
+
+	// Foreground is true if the program was in the foreground.
+	Foreground bool `json:"foreground"`
+
+	// ForegroundPID is the PID of the process that
+	// owned the foreground window, or 0 if none did.
+	ForegroundPID int `json:"foreground_pid"`
+}
+
+// Session is the period between attaching to and
+// detaching from a program.
+type Session struct {
+	ExeName string     `json:"exe_name"`
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+	Error   string     `json:"error,omitempty"`
+	Events  []Event    `json:"events"`
+}
+
+// Timeline contains the Sessions of one or more programs.
+// It is safe for concurrent use.
+type Timeline struct {
+	mu       sync.Mutex
+	sessions []*Session
+	current  map[string]*Session
+}
+
+// Begin starts a new session for exeName, ending the
+// previous session if one is still in progress.
+func (o *Timeline) Begin(exeName string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+
+	o.endLocked(exeName, now, nil)
+
+	if o.current == nil {
+		o.current = make(map[string]*Session)
+	}
+
+	session := &Session{
+		ExeName: exeName,
+		Start:   now,
+	}
+
+	o.sessions = append(o.sessions, session)
+	o.current[exeName] = session
+}
+
+// Record adds an event to exeName's current session. input is nil
+// if the event was not triggered by a key press. The event is
+// discarded if there is no session in progress.
+func (o *Timeline) Record(exeName string, op string, section string, label string, input *Input) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session, hasIt := o.current[exeName]
+	if !hasIt {
+		return
+	}
+
+	session.Events = append(session.Events, Event{
+		Time:    time.Now(),
+		Op:      op,
+		Section: section,
+		Label:   label,
+		Input:   input,
+	})
+}
+
+// End ends exeName's current session. err is the reason
+// the session ended, or nil if the program exited normally.
+func (o *Timeline) End(exeName string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.endLocked(exeName, time.Now(), err)
+}
+
+func (o *Timeline) endLocked(exeName string, now time.Time, err error) {
+	session, hasIt := o.current[exeName]
+	if !hasIt {
+		return
+	}
+
+	session.End = &now
+	if err != nil {
+		session.Error = err.Error()
+	}
+
+	delete(o.current, exeName)
+}
+
+// Len returns the number of recorded sessions.
+func (o *Timeline) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.sessions)
+}
+
+// WriteJSON writes the sessions to w as an indented JSON array.
+func (o *Timeline) WriteJSON(w io.Writer) error {
+	o.mu.Lock()
+	data, err := json.MarshalIndent(o.sessions, "", "  ")
+	o.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions - %w", err)
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write sessions - %w", err)
+	}
+
+	return nil
+}
+
+// csvHeader is the first row written by WriteCSV.
+var csvHeader = []string{
+	"exe_name",
+	"session_start",
+	"time",
+	"op",
+	"section",
+	"label",
+	"key_time",
+	"foreground",
+	"foreground_pid",
+}
+
+// WriteCSV writes the events of every session to w as CSV, one
+// row per event. Times are RFC 3339 with nanoseconds so that
+// they can be lined up with a split timer's log. The input
+// columns are empty if the event was not triggered by a key press.
+func (o *Timeline) WriteCSV(w io.Writer) error {
+	o.mu.Lock()
+	rows := [][]string{csvHeader}
+	for _, session := range o.sessions {
+		for _, event := range session.Events {
+			row := []string{
+				session.ExeName,
+				session.Start.Format(time.RFC3339Nano),
+				event.Time.Format(time.RFC3339Nano),
+				event.Op,
+				event.Section,
+				event.Label,
+				"",
+				"",
+				"",
+			}
+
+			if event.Input != nil {
+				row[6] = event.Input.KeyTime.Format(time.RFC3339Nano)
+				row[7] = strconv.FormatBool(event.Input.Foreground)
+				row[8] = strconv.Itoa(event.Input.ForegroundPID)
+			}
+
+			rows = append(rows, row)
+		}
+	}
+	o.mu.Unlock()
+
+	err := csv.NewWriter(w).WriteAll(rows)
+	if err != nil {
+		return fmt.Errorf("failed to write sessions - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package shell32 extracts the icons of executable files.
+package shell32
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	shell32 = syscall.NewLazyDLL("shell32.dll")
+	user32  = syscall.NewLazyDLL("user32.dll")
+	gdi32   = syscall.NewLazyDLL("gdi32.dll")
+
+	pExtractIconExW = shell32.NewProc("ExtractIconExW")
+
+	pGetIconInfo = user32.NewProc("GetIconInfo")
+	pDestroyIcon = user32.NewProc("DestroyIcon")
+	pGetDC       = user32.NewProc("GetDC")
+	pReleaseDC   = user32.NewProc("ReleaseDC")
+
+	pGetObjectW   = gdi32.NewProc("GetObjectW")
+	pGetDIBits    = gdi32.NewProc("GetDIBits")
+	pDeleteObject = gdi32.NewProc("DeleteObject")
+)
+
+const (
+	biRGB        = 0
+	dibRGBColors = 0
+)
+
+// iconInfo is an ICONINFO structure.
+type iconInfo struct {
+	FIcon    int32
+	XHotspot uint32
+	YHotspot uint32
+	HbmMask  uintptr
+	HbmColor uintptr
+}
+
+// bitmap is a BITMAP structure.
+type bitmap struct {
+	Type       int32
+	Width      int32
+	Height     int32
+	WidthBytes int32
+	Planes     uint16
+	BitsPixel  uint16
+	Bits       uintptr
+}
+
+// bitmapInfo is a BITMAPINFO structure with
+// room for a single color table entry.
+type bitmapInfo struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+	Colors        [1]uint32
+}
+
+// ExeIcon returns the first large icon of the executable at exePath,
+// which is the icon that Explorer displays for the file.
+func ExeIcon(exePath string) (*image.NRGBA, error) {
+	path, err := syscall.UTF16PtrFromString(exePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode path - %w", err)
+	}
+
+	var hIcon uintptr
+	n, _, err := pExtractIconExW.Call(
+		uintptr(unsafe.Pointer(path)),
+		0,
+		uintptr(unsafe.Pointer(&hIcon)),
+		0,
+		1)
+	if uint32(n) == ^uint32(0) {
+		return nil, fmt.Errorf("failed to extract icon - %w", err)
+	}
+
+	if n == 0 || hIcon == 0 {
+		return nil, errors.New("the file does not contain an icon")
+	}
+	defer pDestroyIcon.Call(hIcon)
+
+	return iconImage(hIcon)
+}
+
+// iconImage returns the color image of hIcon. If the image has no
+// alpha channel, the icon's mask determines which pixels are
+// transparent.
+func iconImage(hIcon uintptr) (*image.NRGBA, error) {
+	var info iconInfo
+	ok, _, err := pGetIconInfo.Call(hIcon, uintptr(unsafe.Pointer(&info)))
+	if ok == 0 {
+		return nil, fmt.Errorf("failed to get icon info - %w", err)
+	}
+	defer pDeleteObject.Call(info.HbmMask)
+
+	if info.HbmColor == 0 {
+		return nil, errors.New("monochrome icons are not supported")
+	}
+	defer pDeleteObject.Call(info.HbmColor)
+
+	var bm bitmap
+	ret, _, err := pGetObjectW.Call(info.HbmColor, unsafe.Sizeof(bm), uintptr(unsafe.Pointer(&bm)))
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to get icon bitmap - %w", err)
+	}
+
+	width, height := int(bm.Width), int(bm.Height)
+
+	pixels, err := bitmapPixels(info.HbmColor, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read icon colors - %w", err)
+	}
+
+	hasAlpha := false
+	for i := 3; i < len(pixels); i += 4 {
+		if pixels[i] != 0 {
+			hasAlpha = true
+			break
+		}
+	}
+
+	var mask []byte
+	if !hasAlpha {
+		mask, err = bitmapPixels(info.HbmMask, width, height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read icon mask - %w", err)
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 4
+			p := pixels[i : i+4]
+
+			alpha := p[3]
+			if !hasAlpha {
+				// Set bits in the mask are transparent.
+				alpha = 0xff
+				if mask[i] != 0 {
+					alpha = 0
+				}
+			}
+
+			img.SetNRGBA(x, y, color.NRGBA{R: p[2], G: p[1], B: p[0], A: alpha})
+		}
+	}
+
+	return img, nil
+}
+
+// bitmapPixels returns the pixels of hBitmap as top-down rows
+// of 32-bit BGRA values.
+func bitmapPixels(hBitmap uintptr, width int, height int) ([]byte, error) {
+	hdc, _, err := pGetDC.Call(0)
+	if hdc == 0 {
+		return nil, fmt.Errorf("failed to get device context - %w", err)
+	}
+	defer pReleaseDC.Call(0, hdc)
+
+	// A negative height requests top-down rows.
+	info := bitmapInfo{
+		Width:       int32(width),
+		Height:      -int32(height),
+		Planes:      1,
+		BitCount:    32,
+		Compression: biRGB,
+	}
+	info.Size = uint32(unsafe.Offsetof(info.Colors))
+
+	pixels := make([]byte, width*height*4)
+	ret, _, err := pGetDIBits.Call(
+		hdc,
+		hBitmap,
+		0,
+		uintptr(height),
+		uintptr(unsafe.Pointer(&pixels[0])),
+		uintptr(unsafe.Pointer(&info)),
+		dibRGBColors)
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to get bitmap bits - %w", err)
+	}
+
+	return pixels, nil
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// OpenAttachedTimes loads the total time spent attached to each
+// program from the file at filePath. The file is created when the
+// first session is added.
+func OpenAttachedTimes(filePath string) (*AttachedTimes, error) {
+	times := &AttachedTimes{
+		filePath: filePath,
+		seconds:  make(map[string]int64),
+	}
+
+	err := readJSONFile(filePath, "attached times", &times.seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return times, nil
+}
+
+// AttachedTimes contains the total time spent attached to each
+// program across sessions, which is useful for tracking practice
+// time. The totals are keyed by exe name and stored in seconds.
+type AttachedTimes struct {
+	filePath string
+	mu       sync.Mutex
+	seconds  map[string]int64
+}
+
+// Get returns the total time spent attached to exeName.
+func (o *AttachedTimes) Get(exeName string) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return time.Duration(o.seconds[exeName]) * time.Second
+}
+
+// Add adds the duration of a session to exeName's total, saves the
+// totals to disk, and returns the new total.
+func (o *AttachedTimes) Add(exeName string, session time.Duration) (time.Duration, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seconds[exeName] += int64(session.Round(time.Second) / time.Second)
+
+	total := time.Duration(o.seconds[exeName]) * time.Second
+
+	return total, writeJSONFile(o.filePath, "attached times", o.seconds)
+}
@@ -0,0 +1,108 @@
+// Package stats persists practice statistics, such as the
+// totals of Counter sections and the time spent attached
+// to each program.
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// OpenCounters loads the counter totals stored in the file at
+// filePath. The file is created when a counter is first incremented.
+func OpenCounters(filePath string) (*Counters, error) {
+	counters := &Counters{
+		filePath: filePath,
+		totals:   make(map[string]int),
+	}
+
+	err := readJSONFile(filePath, "counters", &counters.totals)
+	if err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
+
+// Counters contains named counter totals.
+type Counters struct {
+	filePath string
+	mu       sync.Mutex
+	totals   map[string]int
+}
+
+// Get returns the total for the named counter.
+func (o *Counters) Get(name string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.totals[name]
+}
+
+// Increment increments the named counter, saves the totals to disk,
+// and returns the new total.
+func (o *Counters) Increment(name string) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.totals[name]++
+
+	return o.totals[name], o.saveLocked()
+}
+
+func (o *Counters) saveLocked() error {
+	return writeJSONFile(o.filePath, "counters", o.totals)
+}
+
+// readJSONFile decodes the JSON file at filePath into v. v is left
+// as is if the file does not exist. what describes the file's
+// contents in error messages (e.g. "counters").
+func readJSONFile(filePath string, what string, v interface{}) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read %s file - %w", what, err)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s file %s - %w", what, filePath, err)
+	}
+
+	return nil
+}
+
+// writeJSONFile replaces the file at filePath with v encoded as JSON.
+func writeJSONFile(filePath string, what string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s - %w", what, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create %s directory - %w", what, err)
+	}
+
+	// Write to a temporary file first so that a crash
+	// does not leave a truncated file behind.
+	tmpPath := filePath + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write %s file - %w", what, err)
+	}
+
+	err = os.Rename(tmpPath, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to replace %s file - %w", what, err)
+	}
+
+	return nil
+}
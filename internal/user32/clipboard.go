@@ -0,0 +1,74 @@
+package user32
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	pGlobalAlloc   = kernel32.NewProc("GlobalAlloc")
+	pGlobalFree    = kernel32.NewProc("GlobalFree")
+	pGlobalLock    = kernel32.NewProc("GlobalLock")
+	pGlobalUnlock  = kernel32.NewProc("GlobalUnlock")
+	pRtlMoveMemory = kernel32.NewProc("RtlMoveMemory")
+
+	pOpenClipboard    = user32.NewProc("OpenClipboard")
+	pCloseClipboard   = user32.NewProc("CloseClipboard")
+	pEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	pSetClipboardData = user32.NewProc("SetClipboardData")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// SetClipboardText replaces the contents of the clipboard with text.
+func SetClipboardText(text string) error {
+	utf16, err := syscall.UTF16FromString(text)
+	if err != nil {
+		return fmt.Errorf("failed to encode text - %w", err)
+	}
+
+	size := uintptr(len(utf16)) * unsafe.Sizeof(utf16[0])
+
+	mem, _, err := pGlobalAlloc.Call(gmemMoveable, size)
+	if mem == 0 {
+		return fmt.Errorf("failed to allocate clipboard memory - %w", err)
+	}
+
+	ptr, _, err := pGlobalLock.Call(mem)
+	if ptr == 0 {
+		_, _, _ = pGlobalFree.Call(mem)
+		return fmt.Errorf("failed to lock clipboard memory - %w", err)
+	}
+
+	_, _, _ = pRtlMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&utf16[0])), size)
+
+	_, _, _ = pGlobalUnlock.Call(mem)
+
+	ok, _, err := pOpenClipboard.Call(0)
+	if ok == 0 {
+		_, _, _ = pGlobalFree.Call(mem)
+		return fmt.Errorf("failed to open clipboard - %w", err)
+	}
+	defer pCloseClipboard.Call()
+
+	ok, _, err = pEmptyClipboard.Call()
+	if ok == 0 {
+		_, _, _ = pGlobalFree.Call(mem)
+		return fmt.Errorf("failed to empty clipboard - %w", err)
+	}
+
+	// The system owns the memory once SetClipboardData succeeds.
+	ok, _, err = pSetClipboardData.Call(cfUnicodeText, mem)
+	if ok == 0 {
+		_, _, _ = pGlobalFree.Call(mem)
+		return fmt.Errorf("failed to set clipboard data - %w", err)
+	}
+
+	return nil
+}
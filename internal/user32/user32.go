@@ -0,0 +1,25 @@
+package user32
+
+import (
+	"syscall"
+)
+
+var (
+	user32 = syscall.NewLazyDLL("user32.dll")
+
+	pGetAsyncKeyState = user32.NewProc("GetAsyncKeyState")
+)
+
+const (
+	VK_SHIFT = 0x10
+)
+
+// IsKeyDown returns true if the key identified by the virtual-key
+// code vk is currently held down.
+func IsKeyDown(vk int) bool {
+	state, _, _ := pGetAsyncKeyState.Call(uintptr(vk))
+
+	// The most significant bit of the SHORT return
+	// value is set if the key is down.
+	return uint16(state)&0x8000 != 0
+}
@@ -0,0 +1,181 @@
+package user32
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	pEnumWindows              = user32.NewProc("EnumWindows")
+	pGetForegroundWindow      = user32.NewProc("GetForegroundWindow")
+	pGetKeyboardLayout        = user32.NewProc("GetKeyboardLayout")
+	pMapVirtualKeyExW         = user32.NewProc("MapVirtualKeyExW")
+	pGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
+	pIsWindowVisible          = user32.NewProc("IsWindowVisible")
+	pIsIconic                 = user32.NewProc("IsIconic")
+	pGetWindowRect            = user32.NewProc("GetWindowRect")
+	pMonitorFromWindow        = user32.NewProc("MonitorFromWindow")
+	pGetMonitorInfoW          = user32.NewProc("GetMonitorInfoW")
+)
+
+var (
+	// Callbacks created by syscall.NewCallback are never freed,
+	// so a single callback is shared by every call to
+	// HasVisibleWindow and IsMinimized. enumMu protects its state.
+	enumMu           sync.Mutex
+	enumPID          uint32
+	enumFound        bool
+	enumFindRestored bool
+	enumRestored     bool
+	enumCallback     = syscall.NewCallback(enumWindowsProc)
+)
+
+// HasVisibleWindow returns true if the process identified by pid
+// has a visible top-level window.
+func HasVisibleWindow(pid int) bool {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+
+	enumPID = uint32(pid)
+	enumFound = false
+	enumFindRestored = false
+
+	_, _, _ = pEnumWindows.Call(enumCallback, 0)
+
+	return enumFound
+}
+
+// IsMinimized returns true if the process identified by pid has
+// visible top-level windows and all of them are minimized.
+func IsMinimized(pid int) bool {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+
+	enumPID = uint32(pid)
+	enumFound = false
+	enumFindRestored = true
+	enumRestored = false
+
+	_, _, _ = pEnumWindows.Call(enumCallback, 0)
+
+	return enumFound && !enumRestored
+}
+
+// monitorDefaultToNearest is MONITOR_DEFAULTTONEAREST.
+const monitorDefaultToNearest = 2
+
+type rect struct {
+	Left   int32
+	Top    int32
+	Right  int32
+	Bottom int32
+}
+
+// monitorInfo is a MONITORINFO structure.
+type monitorInfo struct {
+	Size    uint32
+	Monitor rect
+	Work    rect
+	Flags   uint32
+}
+
+// FullscreenForegroundPID returns the PID of the process that owns
+// the foreground window if the window covers its entire monitor
+// (e.g. a game running in fullscreen or borderless fullscreen mode).
+// Zero is returned otherwise.
+func FullscreenForegroundPID() int {
+	hwnd, _, _ := pGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return 0
+	}
+
+	var window rect
+	ok, _, _ := pGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&window)))
+	if ok == 0 {
+		return 0
+	}
+
+	monitor, _, _ := pMonitorFromWindow.Call(hwnd, monitorDefaultToNearest)
+	if monitor == 0 {
+		return 0
+	}
+
+	info := monitorInfo{}
+	info.Size = uint32(unsafe.Sizeof(info))
+	ok, _, _ = pGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&info)))
+	if ok == 0 {
+		return 0
+	}
+
+	if window.Left > info.Monitor.Left || window.Top > info.Monitor.Top ||
+		window.Right < info.Monitor.Right || window.Bottom < info.Monitor.Bottom {
+		return 0
+	}
+
+	var windowPID uint32
+	_, _, _ = pGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&windowPID)))
+
+	return int(windowPID)
+}
+
+// mapvkVKToChar is MAPVK_VK_TO_CHAR.
+const mapvkVKToChar = 2
+
+// ForegroundWindow returns the PID of the process that owns the
+// foreground window and the keyboard layout of the window's thread.
+// Zero values are returned if there is no foreground window.
+func ForegroundWindow() (pid int, layout uintptr) {
+	hwnd, _, _ := pGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return 0, 0
+	}
+
+	var windowPID uint32
+	tid, _, _ := pGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&windowPID)))
+
+	// Each thread can have its own keyboard layout, so the
+	// layout of the window that receives the key is used.
+	layout, _, _ = pGetKeyboardLayout.Call(tid)
+
+	return int(windowPID), layout
+}
+
+// VirtualKeyToChar returns the unshifted character that the key
+// identified by the virtual-key code vk produces in the keyboard
+// layout, or 0 if it does not produce a character. Letters are
+// returned in uppercase.
+func VirtualKeyToChar(vk byte, layout uintptr) rune {
+	ret, _, _ := pMapVirtualKeyExW.Call(uintptr(vk), mapvkVKToChar, layout)
+
+	// The most significant bit is set for dead keys
+	// (e.g. accents), which still identify the key.
+	return rune(uint32(ret) &^ 0x80000000)
+}
+
+func enumWindowsProc(hwnd uintptr, _ uintptr) uintptr {
+	var windowPID uint32
+	_, _, _ = pGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&windowPID)))
+	if windowPID != enumPID {
+		return 1
+	}
+
+	visible, _, _ := pIsWindowVisible.Call(hwnd)
+	if visible == 0 {
+		return 1
+	}
+
+	enumFound = true
+
+	if enumFindRestored {
+		iconic, _, _ := pIsIconic.Call(hwnd)
+		if iconic != 0 {
+			return 1
+		}
+
+		enumRestored = true
+	}
+
+	// Returning zero stops the enumeration.
+	return 0
+}
@@ -0,0 +1,62 @@
+// Package winutil contains helpers for Windows APIs that
+// golang.org/x/sys/windows does not provide.
+package winutil
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	pSetThreadPriority = kernel32.NewProc("SetThreadPriority")
+)
+
+// Thread priorities, which are relative to the
+// priority class of the thread's process.
+const (
+	ThreadPriorityLowest       = -2
+	ThreadPriorityBelowNormal  = -1
+	ThreadPriorityNormal       = 0
+	ThreadPriorityAboveNormal  = 1
+	ThreadPriorityHighest      = 2
+	ThreadPriorityTimeCritical = 15
+)
+
+// SetCurrentThreadPriority sets the priority of the calling OS
+// thread. The goroutine should be locked to its thread using
+// runtime.LockOSThread, otherwise the priority applies to
+// whichever goroutines the thread runs later.
+func SetCurrentThreadPriority(priority int) error {
+	thread, err := windows.GetCurrentThread()
+	if err != nil {
+		return fmt.Errorf("failed to get current thread - %w", err)
+	}
+
+	ret, _, err := pSetThreadPriority.Call(uintptr(thread), uintptr(priority))
+	if ret == 0 {
+		return fmt.Errorf("failed to set thread priority to %d - %w", priority, err)
+	}
+
+	return nil
+}
+
+// IsProcessPriorityLow returns true if the priority class of the
+// current process is below normal, in which case even its highest
+// priority threads run at a lower priority than a typical game's.
+func IsProcessPriorityLow() (bool, error) {
+	class, err := windows.GetPriorityClass(windows.CurrentProcess())
+	if err != nil {
+		return false, fmt.Errorf("failed to get process priority class - %w", err)
+	}
+
+	switch class {
+	case windows.IDLE_PRIORITY_CLASS, windows.BELOW_NORMAL_PRIORITY_CLASS:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -10,16 +12,38 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/SeungKang/blaj/internal/anticheat"
 	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/credui"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/input"
+	"github.com/SeungKang/blaj/internal/kernel32"
 	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/SeungKang/blaj/internal/session"
+	"github.com/SeungKang/blaj/internal/stats"
+	"github.com/SeungKang/blaj/internal/user32"
+	"github.com/SeungKang/blaj/internal/winutil"
 	"github.com/getlantern/systray"
-	"github.com/stephen-fox/user32util"
 )
 
-const appName = "blaj"
+const (
+	appName       = "blaj"
+	tracesDirName = "traces"
+	statsDirName  = "stats"
+
+	// attachedTimesFileName is the name of the file in the stats
+	// directory that contains the total time spent attached to
+	// each program.
+	attachedTimesFileName = "attached.json"
+
+	// shutdownTimeout is how long blaj waits for
+	// program routines to stop when it exits.
+	shutdownTimeout = 5 * time.Second
+)
 
 var (
 	//go:embed icons/shark_red.ico
@@ -44,27 +68,130 @@ var (
 )
 
 func main() {
-	a := &app{}
+	flag.Usage = func() {
+		_ = kernel32.AttachParentConsole()
+		fmt.Fprint(os.Stderr, usage)
+	}
+
+	safeMode := flag.Bool("safe", false, "")
+
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		err := runCommand(flag.Args(), *safeMode)
+		if err != nil {
+			log.Fatalln("fatal:", err)
+		}
+
+		return
+	}
+
+	a := &app{
+		// Holding shift while blaj starts is an escape hatch
+		// for users who cannot easily pass arguments.
+		safeMode: *safeMode || user32.IsKeyDown(user32.VK_SHIFT),
+	}
+
 	systray.Run(a.ready, a.exit)
 }
 
 type app struct {
 	errorLog *logUI
+	settings *appconfig.Blaj
+	tools    []*appconfig.Tool
+	hooks    []*appconfig.Hook
+	configs  *configLoader
+	safeMode bool
+	timeline session.Timeline
+
+	// attachedTimes contains the total time spent attached to
+	// each program. It is opened by the first call to startApp.
+	attachedTimes *stats.AttachedTimes
+
+	trayMu      sync.Mutex
+	baseIcon    []byte
+	numAttached int
+	numErrors   int
+	appFailed   bool
+	lastErr     error
+	lastErrTime time.Time
+
+	cancelFn  func()
+	exitOnce  sync.Once
+	trayIcons map[trayIconKey][]byte
+
+	programsMu sync.Mutex
+	programs   []*programUI
+	keyboard   *input.Dispatcher
+
+	// programsEnv is what the programs were started with, which
+	// programs that are enabled later are also started with. It
+	// is nil while the configs are not loaded. It is guarded by
+	// programsMu.
+	programsEnv *programsEnv
+
+	// enabledItems maps the names of the config files to their
+	// items in the enabled programs menu. It is guarded by
+	// programsMu.
+	enabledItems map[string]*pooledMenu
+
+	// programMenus contains the menu items of the programs,
+	// which are reused when the config files are reloaded.
+	programMenus *menuPool
+
+	// enabledMenus contains the items of the menu that
+	// enables and disables the config files.
+	enabledMenus *menuPool
+
+	// backupMenus contains the menu items of the config backups.
+	backupMenus *menuPool
+
+	// reload receives an error that reloads the config files
+	// when it is sent (e.g. errConfigRestored).
+	reload chan error
+
+	// hookEvents contains the events whose hooks
+	// have not been started yet (see runHooks).
+	hookEvents chan hookEvent
+
+	// syncMenu is the "Sync now" menu item, which
+	// is nil if syncing is not configured.
+	syncMenu *systray.MenuItem
 }
 
 func (o *app) ready() {
 	systray.SetTitle(appName + " " + version)
 	systray.SetIcon(systrayBlueIco)
 
+	o.reload = make(chan error, 1)
+	o.hookEvents = make(chan hookEvent, maxQueuedHookEvents)
+
+	err := o.loadAppConfig()
+	if err != nil {
+		log.Printf("failed to load app config - %s", err)
+	}
+
 	systray.AddMenuItem(appName+" "+version, "").Disable()
+	if o.safeMode {
+		log.Printf("starting in safe mode - writers are disabled")
+		systray.AddMenuItem(i18n.T(i18n.SafeModeMenu), "").Disable()
+	}
 	systray.AddSeparator()
-	o.errorLog = newLogUI("Error Log")
+	o.programMenus = newMenuPool(systray.AddMenuItem(i18n.T(i18n.ProgramsMenu), ""))
+	o.addEnabledProgramsMenu()
+	o.errorLog = newLogUI(i18n.T(i18n.ErrorLogMenu))
+	o.addExportSessionMenu()
+	o.addSupportBundleMenu()
+	o.addCopyTokenMenu()
+	o.addRestoreBackupMenu()
+	o.addSyncMenu()
 	o.setChecking()
 
-	quit := systray.AddMenuItem("Quit", "Quit the application")
+	quit := systray.AddMenuItem(i18n.T(i18n.QuitMenu), i18n.T(i18n.QuitMenuTooltip))
 	systray.AddSeparator()
 
 	ctx, cancelFn := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	o.cancelFn = cancelFn
 
 	go func() {
 		select {
@@ -76,19 +203,54 @@ func (o *app) ready() {
 		o.exit()
 	}()
 
+	go o.selfTest()
 	go o.loop(ctx)
+	go o.runHookQueue(ctx)
+	go o.serveIPC(ctx)
+	go o.serveDebug(ctx)
+	go o.syncFiles(ctx)
+	go o.refreshSessionTooltips(ctx)
 }
 
-func (o *app) setChecking() {
-	systray.SetIcon(systrayBlueIco)
+func (o *app) loadAppConfig() error {
+	appConfig, err := loadSettings()
+	o.settings = appConfig.Blaj
+	o.tools = appConfig.Tools
+	o.hooks = appConfig.Hooks
+	o.configs = &configLoader{settings: appConfig.Blaj}
+	if err != nil {
+		return err
+	}
+
+	err = i18n.SetLanguage(o.settings.Language)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (o *app) setRunning() {
-	systray.SetIcon(systrayGreenIco)
+// loadSettings loads the application settings file. The default
+// settings are returned along with any error.
+func loadSettings() (*appconfig.AppConfig, error) {
+	configDir, err := configDirPath()
+	if err != nil {
+		return appconfig.DefaultAppConfig(), err
+	}
+
+	appConfig, err := appconfig.AppConfigFromPath(filepath.Join(configDir, appconfig.AppConfigFileName))
+	if err != nil {
+		return appconfig.DefaultAppConfig(), err
+	}
+
+	return appConfig, nil
 }
 
-func (o *app) setError(err error) {
-	systray.SetIcon(systrayRedIco)
+func guardFromSettings(settings *appconfig.Blaj) *anticheat.Guard {
+	return &anticheat.Guard{
+		Allow: settings.AllowExes,
+		Deny:  settings.DenyExes,
+	}
 }
 
 func (o *app) loop(ctx context.Context) {
@@ -96,7 +258,7 @@ func (o *app) loop(ctx context.Context) {
 		programCtx, cancelProgramCtxFn := context.WithCancel(ctx)
 		defer cancelProgramCtxFn()
 
-		programUIs, programErrors, err := startApp(programCtx, o)
+		programErrors, err := startApp(programCtx, o)
 		if err != nil {
 			goto onProgramExit
 		}
@@ -104,6 +266,7 @@ func (o *app) loop(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 		case err = <-programErrors:
+		case err = <-o.reload:
 		}
 
 	onProgramExit:
@@ -111,8 +274,13 @@ func (o *app) loop(ctx context.Context) {
 
 		cancelProgramCtxFn()
 
+		if isReloadRequest(err) {
+			err = nil
+		}
+
 		if err != nil {
 			o.setError(err)
+			o.setAppFailed(true)
 		}
 
 		select {
@@ -120,87 +288,370 @@ func (o *app) loop(ctx context.Context) {
 			log.Printf("app loop exited - %s", ctx.Err())
 			return
 		case <-time.After(5 * time.Second):
-			for _, ui := range programUIs {
+			for _, ui := range o.setPrograms(nil, nil) {
 				ui.hide()
 			}
 
+			o.resetStatus()
+
 			continue
 		}
 	}
 }
 
+// isReloadRequest returns true if err was sent on a program error
+// channel or the reload channel only to reload the configs.
+func isReloadRequest(err error) bool {
+	return errors.Is(err, errOffsetsUpdated) ||
+		errors.Is(err, errIncludesUpdated) ||
+		errors.Is(err, errConfigRestored) ||
+		errors.Is(err, errConfigsSynced) ||
+		errors.Is(err, errProgramToggled)
+}
+
+// exit shuts down blaj. It is called when Quit is clicked and
+// when the systray exits, so it is safe to call more than once.
 func (o *app) exit() {
-	if log.Writer() != os.Stderr {
-		closer, ok := log.Writer().(io.Closer)
+	o.exitOnce.Do(o.shutdown)
+
+	systray.Quit()
+}
+
+// shutdown stops the program routines in order and waits up to
+// shutdownTimeout for them to finish. Each routine finishes its
+// current action, turns off its frozen writers, and closes its
+// trace file before it stops. The log file is flushed last so
+// that it includes the whole sequence.
+func (o *app) shutdown() {
+	log.Printf("shutting down")
+
+	if o.cancelFn != nil {
+		o.cancelFn()
+	}
+
+	o.programsMu.Lock()
+	programs := o.programs
+	o.programsMu.Unlock()
+
+	timeout := time.NewTimer(shutdownTimeout)
+	defer timeout.Stop()
+
+	for _, program := range programs {
+		select {
+		case <-program.routine.Done():
+		case <-timeout.C:
+			log.Printf("timed out after %s waiting for programs to stop", shutdownTimeout)
+			goto closeLog
+		}
+	}
+
+	log.Printf("all programs stopped")
+
+closeLog:
+	logWriter := log.Writer()
+	if logWriter != os.Stderr {
+		// The output is swapped before the file is closed, since
+		// routines that timed out may still be logging.
+		log.SetOutput(os.Stderr)
+
+		f, ok := logWriter.(*os.File)
+		if ok {
+			_ = f.Sync()
+		}
+
+		closer, ok := logWriter.(io.Closer)
 		if ok {
 			closer.Close()
 		}
 	}
-
-	systray.Quit()
 }
 
-func newProgramUI(program *appconfig.ProgramConfig, parent *app) *programUI {
+// newProgramUI creates the program's submenus from items claimed
+// from menu, which is the program's item in the menuPool.
+func newProgramUI(fileName string, program *appconfig.ProgramConfig, routine *progctl.Routine, parent *app, menu *pooledMenu) *programUI {
 	gui := &programUI{
-		app:         parent,
-		runningMenu: systray.AddMenuItem(program.General.ExeName, ""),
-		errorMenu:   systray.AddMenuItem(program.General.ExeName, ":c"),
+		app:      parent,
+		fileName: fileName,
+		program:  program,
+		routine:  routine,
+		state:    progctl.StateSearching,
+		menu:     menu,
 	}
 
+	routine.Notif = gui
+
+	gui.runningMenu = gui.menu.item
 	gui.runningMenu.SetIcon(statusCheckingIcon)
-	gui.errorSubMenu = gui.errorMenu.AddSubMenuItem("", "")
-	gui.errorMenu.Hide()
+
+	if len(program.Counters) > 0 {
+		gui.counterMenus = make(map[*appconfig.Counter]*systray.MenuItem)
+		for _, counter := range program.Counters {
+			gui.counterMenus[counter] = gui.menu.claim(
+				counterTitle(counter, routine.Counters.Get(counter.Label)), "").item
+		}
+	}
+
+	for _, writer := range program.Writers {
+		if writer.Freeze {
+			if gui.writerMenus == nil {
+				gui.writerMenus = make(map[*appconfig.Writer]*systray.MenuItem)
+			}
+
+			gui.writerMenus[writer] = gui.menu.claim(
+				gui.writerTitle(writer, false), "").item
+		}
+	}
+
+	for _, saveRestore := range program.SaveRestores {
+		if saveRestore.HasCompareState() {
+			// The result of the last comparison is shown
+			// after the compareState keybind is pressed.
+			gui.compareMenu = gui.menu.claim("", "").item
+			gui.compareMenu.Hide()
+			break
+		}
+	}
+
+	if program.General.ValueHistory > 0 {
+		// The graphs are shown once values are sampled.
+		gui.historyMenus = make(map[string]*systray.MenuItem)
+		for _, graphed := range program.GraphedPointers() {
+			menu := gui.menu.claim(graphed.Pointer.DisplayName(), "").item
+			menu.Disable()
+			menu.Hide()
+			gui.historyMenus[graphed.ID()] = menu
+		}
+	}
+
+	if program.General.HealthCheckInterval > 0 {
+		// Shown when a health check fails.
+		gui.healthMenu = gui.menu.claim("", "").item
+		gui.healthMenu.Hide()
+	}
+
+	gui.addPointerMenus()
+	gui.addDumpMenu()
 
 	return gui
 }
 
+func counterTitle(counter *appconfig.Counter, total int) string {
+	return fmt.Sprintf("%s: %d", counter.DisplayName(), total)
+}
+
+// writerTitle returns the menu title of a frozen writer,
+// which shows whether it is on (e.g. "∞ Health: ON").
+func (o *programUI) writerTitle(writer *appconfig.Writer, on bool) string {
+	name := writer.DisplayName()
+	if name == "" {
+		name = o.program.SectionID(writer)
+	}
+
+	state := i18n.T(i18n.WriterOff)
+	if on {
+		state = i18n.T(i18n.WriterOn)
+	}
+
+	return fmt.Sprintf("∞ %s: %s", name, state)
+}
+
 type programUI struct {
-	app          *app
+	app     *app
+	program *appconfig.ProgramConfig
+
+	// fileName is the name of the program's config file.
+	fileName string
+
+	// stop stops the program's routine without stopping
+	// the other programs (see app.stopProgram).
+	stop context.CancelFunc
+
+	// menu is the program's item in the menuPool, which
+	// contains the program's submenus. runningMenu is its
+	// systray menu item.
+	menu         *pooledMenu
 	runningMenu  *systray.MenuItem
-	errorMenu    *systray.MenuItem
-	errorSubMenu *systray.MenuItem
+	counterMenus map[*appconfig.Counter]*systray.MenuItem
+	writerMenus  map[*appconfig.Writer]*systray.MenuItem
+	compareMenu  *systray.MenuItem
+	historyMenus map[string]*systray.MenuItem
+	healthMenu   *systray.MenuItem
+	routine      *progctl.Routine
+	hasError     bool
+
+	mu      sync.Mutex
+	state   progctl.State
+	lastErr string
+
+	// attachedAt is when the program was attached to. It is
+	// the zero Time if the program is not attached.
+	attachedAt time.Time
+
+	// exeIcon is the icon of the program's exe file (see
+	// runningIcon). It is nil until the program is attached to.
+	exeIcon []byte
 }
 
 func (o *programUI) ProgramStarted(exename string) {
 	log.Printf("connected to %s", exename)
 
+	o.app.timeline.Begin(exename)
+
 	o.app.setRunning()
+	o.app.addAttached(1)
+	if o.hasError {
+		o.hasError = false
+		o.app.addErrors(-1)
+	}
 
-	o.runningMenu.SetIcon(statusRunningIcon)
+	o.runningMenu.SetIcon(o.runningIcon())
 	o.runningMenu.Show()
 
-	o.errorMenu.Hide()
+	o.sessionStarted()
+
+	pid, _ := o.routine.PID()
+	o.app.runHooks(hookEvent{
+		name:    appconfig.HookEventAttached,
+		exeName: exename,
+		pid:     pid,
+	})
 }
 
 func (o *programUI) ProgramStopped(exename string, err error) {
 	log.Printf("disconnected from %s", exename)
 
+	o.app.timeline.End(exename, err)
+
+	o.app.addAttached(-1)
+
+	o.sessionEnded()
+
+	// Frozen writers stop when the routine exits.
+	for writer, menu := range o.writerMenus {
+		menu.SetTitle(o.writerTitle(writer, false))
+	}
+
+	for _, menu := range o.historyMenus {
+		menu.Hide()
+	}
+
+	if o.healthMenu != nil {
+		o.healthMenu.Hide()
+	}
+
 	if err != nil {
 		o.app.setError(err)
-		o.app.errorLog.addEntry(exename + ": " + err.Error())
+		if !o.hasError {
+			o.hasError = true
+			o.app.addErrors(1)
+		}
+		o.app.errorLog.addReport(newErrorReport(exename, err))
 
 		o.runningMenu.Hide()
 	} else {
 		o.runningMenu.SetIcon(statusCheckingIcon)
 	}
+
+	event := appconfig.HookEventDetached
+	if err != nil {
+		event = appconfig.HookEventError
+	}
+
+	o.app.runHooks(hookEvent{
+		name:    event,
+		exeName: exename,
+		err:     err,
+	})
+}
+
+func (o *programUI) StateChanged(exename string, state progctl.State, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.state = state
+	if err == nil {
+		return
+	}
+
+	// The routine keeps running after refusing to attach,
+	// so the error is not reported when the routine exits.
+	var antiCheatErr *anticheat.Error
+	if errors.As(err, &antiCheatErr) && err.Error() != o.lastErr {
+		o.app.errorLog.addEntry(exename + ": " + err.Error())
+	}
+
+	o.lastErr = err.Error()
+}
+
+func (o *programUI) CounterChanged(exename string, counter *appconfig.Counter, total int) {
+	menu, hasIt := o.counterMenus[counter]
+	if hasIt {
+		menu.SetTitle(counterTitle(counter, total))
+	}
+}
+
+func (o *programUI) WriterStateChanged(exename string, writer *appconfig.Writer, on bool) {
+	menu, hasIt := o.writerMenus[writer]
+	if hasIt {
+		menu.SetTitle(o.writerTitle(writer, on))
+	}
+}
+
+func (o *programUI) StateCompared(exename string, saveRestore *appconfig.SaveRestore, results []progctl.CompareResult) {
+	if o.compareMenu == nil {
+		return
+	}
+
+	name := saveRestore.DisplayName()
+	if name == "" {
+		name = o.program.SectionID(saveRestore)
+	}
+
+	if len(results) == 0 {
+		o.compareMenu.SetTitle(i18n.Sprintf(i18n.CompareNoStateMenu, name))
+		o.compareMenu.SetTooltip("")
+	} else {
+		numEqual := 0
+		var differences []string
+		for _, result := range results {
+			if result.Equal {
+				numEqual++
+			} else {
+				differences = append(differences, fmt.Sprintf("%s: %s -> %s",
+					result.Pointer, result.Saved, result.Current))
+			}
+		}
+
+		o.compareMenu.SetTitle(i18n.Sprintf(i18n.CompareResultMenu, name, numEqual, len(results)))
+		o.compareMenu.SetTooltip(strings.Join(differences, "\n"))
+	}
+
+	o.compareMenu.Show()
 }
 
 func (o *programUI) hide() {
 	o.runningMenu.Hide()
-	o.errorMenu.Hide()
-	o.errorSubMenu.Hide()
 }
 
-func startApp(ctx context.Context, parent *app) ([]*programUI, <-chan error, error) {
+func configDirPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get user home dir - %w", err)
+		return "", i18n.Errorf(i18n.ErrHomeDir, err)
 	}
 
 	configDir := filepath.Join(homeDir, "."+appName)
 	err = os.MkdirAll(configDir, 0o700)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to make config directory at '%s' - %w", configDir, err)
+		return "", i18n.Errorf(i18n.ErrMakeConfigDir, configDir, err)
+	}
+
+	return configDir, nil
+}
+
+func startApp(ctx context.Context, parent *app) (<-chan error, error) {
+	configDir, err := configDirPath()
+	if err != nil {
+		return nil, err
 	}
 
 	if log.Writer() == os.Stderr && version != "" {
@@ -209,91 +660,281 @@ func startApp(ctx context.Context, parent *app) ([]*programUI, <-chan error, err
 			os.O_CREATE|os.O_WRONLY|os.O_APPEND,
 			0o600)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to open log file - %w", err)
+			return nil, i18n.Errorf(i18n.ErrOpenLogFile, err)
 		}
 
 		log.SetOutput(logFile)
 	}
 
-	user32, err := user32util.LoadUser32DLL()
+	keyboard, err := input.NewDispatcher(parent.settings.InputBackend, parent.settings.PrioritizeInput,
+		parent.settings.LowProfile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load user32.dll - %s", err.Error())
+		return nil, err
+	}
+
+	if parent.settings.PrioritizeInput {
+		// Thread priorities are relative to the process's
+		// priority class, so raising the input thread's
+		// priority does little if the class is lowered.
+		isLow, err := winutil.IsProcessPriorityLow()
+		if err != nil {
+			log.Printf("failed to check process priority - %s", err)
+		} else if isLow {
+			log.Printf("warning: %s is running at a below normal priority", appName)
+			parent.errorLog.addEntry(appName + " is running at a below normal priority, " +
+				"so prioritizeInput may not keep keybinds responsive")
+		}
 	}
 
+	go func() {
+		<-ctx.Done()
+		keyboard.Release()
+	}()
+
+	parent.setKeyboard(keyboard)
+
 	pathInfos, err := os.ReadDir(configDir)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read config directory - %w", err)
+		return nil, i18n.Errorf(i18n.ErrReadConfigDir, err)
 	}
 
-	var programConfigs []*appconfig.ProgramConfig
-	for _, pathInfo := range pathInfos {
-		if pathInfo.IsDir() {
-			continue
+	parent.backupConfigFiles(configDir, pathInfos)
+
+	guard := guardFromSettings(parent.settings)
+
+	config, err := appconfig.LoadAll(configDir, appconfig.LoadOptions{
+		Match: isConfigFileName,
+		Load:  parent.configs.load,
+		Check: func(program *appconfig.ProgramConfig) error {
+			return guard.CheckExe(program.General.ExeName)
+		},
+	})
+	if err != nil {
+		return nil, i18n.Errorf(i18n.ErrReadConfigDir, err)
+	}
+
+	// Cancelling a bundle's passphrase prompt only
+	// skips that bundle rather than failing every config.
+	for _, file := range config.Files {
+		if errors.Is(file.Err, credui.ErrCancelled) {
+			file.Skipped = file.Err
+			file.Err = nil
 		}
+	}
 
-		if strings.HasSuffix(pathInfo.Name(), ".conf") {
-			configPath := filepath.Join(configDir, pathInfo.Name())
-			programConfig, err := appconfig.ProgramConfigFromPath(configPath)
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to create program config from path - %w", err)
-			}
+	parent.renderEnabledProgramsMenu(configDir, config.Files)
 
-			if programConfig.General.Disabled {
-				log.Printf("%s set to disabled", pathInfo.Name())
-				continue
-			}
+	err = config.Err()
+	if err != nil {
+		return nil, i18n.Errorf(i18n.ErrProgramConfig, err)
+	}
 
-			programConfigs = append(programConfigs, programConfig)
+	for _, file := range config.Files {
+		switch {
+		case file.Disabled:
+			log.Printf("%s set to disabled", file.Name)
+			continue
+		case file.Skipped != nil:
+			log.Printf("skipping %s - %s", file.Name, file.Skipped)
+			parent.errorLog.addEntry(file.Name + ": " + file.Skipped.Error())
+			continue
 		}
+
+		parent.logWarnings(file)
 	}
 
+	programConfigs := config.Programs
+
 	if len(programConfigs) == 0 {
-		return nil, nil, fmt.Errorf("no .conf files found in %s", configDir)
+		return nil, i18n.Errorf(i18n.ErrNoConfigFiles, configDir)
 	}
 
-	programUIs := make([]*programUI, len(programConfigs))
-	programRoutinesExited := make(chan error, len(programConfigs))
+	env := &programsEnv{
+		ctx:       ctx,
+		configDir: configDir,
+		keyboard:  keyboard,
+		guard:     guard,
+		exited:    make(chan error, len(programConfigs)),
+	}
 
-	for i, program := range programConfigs {
-		program := program
+	go parent.watchIncludes(ctx, programConfigs, env.exited)
 
-		programUIs[i] = newProgramUI(program, parent)
+	// The totals are opened once, since the programs of the
+	// previous configs may still be adding their sessions.
+	if parent.attachedTimes == nil {
+		parent.attachedTimes, err = stats.OpenAttachedTimes(filepath.Join(configDir, statsDirName,
+			attachedTimesFileName))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		// TODO: write function that creates and starts program routine
-		programRoutine := &progctl.Routine{
-			Program: program,
-			User32:  user32,
-			Notif:   programUIs[i],
+	// The menus of the previous programs are reused.
+	menus := parent.programMenus.begin()
+	defer parent.programMenus.end()
+
+	var programUIs []*programUI
+
+	for _, file := range config.Files {
+		if file.Program == nil {
+			continue
 		}
 
-		programRoutine.Start(ctx)
+		routine, err := parent.newRoutine(env, file.Program)
+		if err != nil {
+			return nil, err
+		}
+
+		ui := newProgramUI(file.Name, file.Program, routine, parent,
+			menus.claim(file.Program.General.ExeName, ""))
 
-		go func() {
-			<-programRoutine.Done()
-			programRoutinesExited <- fmt.Errorf("%s exited - %w",
-				program.General.ExeName, programRoutine.Err())
-		}()
+		parent.startProgram(env, ui)
+
+		programUIs = append(programUIs, ui)
 	}
 
-	return programUIs, programRoutinesExited, nil
+	parent.setPrograms(programUIs, env)
+
+	go parent.watchConfigRemoval(ctx, configDir)
+
+	return env.exited, nil
 }
 
+const (
+	// maxLogEntries is the number of entries that a logUI keeps.
+	// Older entries are discarded.
+	maxLogEntries = 5
+
+	// logRepeatWindow is how long after an entry's message was last
+	// seen that the same message is counted as a repeat of the entry
+	// rather than added as a new entry.
+	logRepeatWindow = time.Minute
+)
+
 func newLogUI(menuItemName string) *logUI {
-	return &logUI{parent: systray.AddMenuItem(menuItemName, "")}
+	o := &logUI{
+		menus: newMenuPool(systray.AddMenuItem(menuItemName, "")),
+	}
+
+	o.render()
+
+	return o
 }
 
+// logUI displays the most recent log entries in a submenu. The
+// submenu's items are reused as entries are added and removed,
+// so the number of menu items does not grow over time.
 type logUI struct {
-	parent  *systray.MenuItem
-	entries []*systray.MenuItem
+	menus   *menuPool
+	mu      sync.Mutex
+	entries []*logEntry
+}
+
+// logEntry is an entry in a logUI. Messages that repeat within
+// logRepeatWindow of the entry's message last being seen are counted
+// instead of being added as new entries, so an error that keeps
+// happening (e.g. a program that fails each time it is attached to)
+// does not push every other entry out of the log.
+type logEntry struct {
+	report   errorReport
+	lastSeen time.Time
+	count    int
+}
+
+// title returns the entry's message, followed by the number of
+// times that it occurred if it was repeated.
+func (o *logEntry) title() string {
+	if o.count == 1 {
+		return o.report.message
+	}
+
+	return i18n.Sprintf(i18n.RepeatedLogEntry, o.report.message, o.count)
+}
+
+// recent returns the most recent log entries, oldest first.
+func (o *logUI) recent() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	messages := make([]string, len(o.entries))
+	for i, entry := range o.entries {
+		messages[i] = entry.title()
+	}
+
+	return messages
 }
 
 func (o *logUI) addEntry(message string) {
-	// TODO: make more efficient
-	newEntry := o.parent.AddSubMenuItem(message, "")
-	if len(o.entries) == 5 {
-		o.entries[0].Hide()
-		o.entries = append(o.entries[1:], newEntry)
-	} else {
-		o.entries = append(o.entries, newEntry)
+	o.addReport(errorReport{message: message})
+}
+
+// addReport adds an entry for the report's message. The entry
+// has a "Report this" item that opens a prefilled GitHub issue.
+// If the message repeats a recent entry, the entry's count is
+// incremented and it becomes the most recent entry.
+func (o *logUI) addReport(report errorReport) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+
+	for i, entry := range o.entries {
+		if entry.report.message == report.message && now.Sub(entry.lastSeen) < logRepeatWindow {
+			entry.count++
+			entry.lastSeen = now
+			o.entries = append(append(o.entries[:i], o.entries[i+1:]...), entry)
+			o.renderLocked()
+			return
+		}
+	}
+
+	o.entries = append(o.entries, &logEntry{
+		report:   report,
+		lastSeen: now,
+		count:    1,
+	})
+	if len(o.entries) > maxLogEntries {
+		o.entries = o.entries[1:]
+	}
+
+	o.renderLocked()
+}
+
+// clear removes every entry.
+func (o *logUI) clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = nil
+
+	o.renderLocked()
+}
+
+func (o *logUI) render() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.renderLocked()
+}
+
+// renderLocked updates the submenu to show the current entries,
+// oldest first, below a "Clear" item.
+func (o *logUI) renderLocked() {
+	root := o.menus.begin()
+	defer o.menus.end()
+
+	clearItem := root.claim(i18n.T(i18n.ClearLogMenu), i18n.T(i18n.ClearLogMenuTooltip))
+	clearItem.setOnClick(o.clear)
+	if len(o.entries) == 0 {
+		clearItem.item.Disable()
+	}
+
+	for _, entry := range o.entries {
+		report := entry.report
+
+		item := root.claim(entry.title(), "")
+		item.claim(i18n.T(i18n.ReportErrorMenu), i18n.T(i18n.ReportErrorMenuTooltip)).setOnClick(func() {
+			openURL(report.issueURL())
+		})
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/getlantern/systray"
+)
+
+// newMenuPool returns a menuPool whose items are submenus of root.
+func newMenuPool(root *systray.MenuItem) *menuPool {
+	return &menuPool{
+		root: pooledMenu{item: root},
+	}
+}
+
+// menuPool contains the items of a systray submenu that is rebuilt
+// when its contents change (e.g. the menus of the programs, which are
+// rebuilt when the config files are reloaded). systray cannot remove
+// menu items, so adding new items each time would leave more and more
+// hidden items behind. Instead, the menus are rebuilt by claiming
+// items from the pool in the order that they appear. An item that
+// was claimed in a previous build at the same position is reused,
+// and items that are not claimed by the current build are hidden.
+type menuPool struct {
+	mu   sync.Mutex
+	root pooledMenu
+}
+
+// begin starts rebuilding the menus. It returns the root of the
+// pool, which items are claimed from (see pooledMenu.claim). end
+// must be called once the menus are built.
+func (o *menuPool) begin() *pooledMenu {
+	o.mu.Lock()
+
+	o.root.resetClaims()
+
+	return &o.root
+}
+
+// end finishes rebuilding the menus
+// by hiding the unclaimed items.
+func (o *menuPool) end() {
+	o.root.hideUnclaimed()
+
+	o.mu.Unlock()
+}
+
+// pooledMenu is a menu item that is reused across builds of a
+// menuPool.
+type pooledMenu struct {
+	item *systray.MenuItem
+
+	// children are the submenu items that have been created.
+	// The first numClaimed children are used by the current build.
+	children   []*pooledMenu
+	numClaimed int
+
+	// released is true if the item was released (see
+	// menuPool.release) and has not been claimed since.
+	released bool
+
+	clickMu sync.Mutex
+	onClick func()
+}
+
+// claimOne claims a single item outside of a build, such as the menu
+// of a program that is enabled after the menus were built. An item
+// that was released is reused before a new item is claimed. build is
+// called to claim the item's submenus, and the item's submenus that
+// are not claimed by build are hidden. The item is released again if
+// build returns false.
+func (o *menuPool) claimOne(title string, tooltip string, build func(menu *pooledMenu) bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var menu *pooledMenu
+	for _, child := range o.root.children[:o.root.numClaimed] {
+		if child.released {
+			menu = child
+			menu.reset(title, tooltip)
+			break
+		}
+	}
+
+	if menu == nil {
+		menu = o.root.claim(title, tooltip)
+	}
+
+	if !build(menu) {
+		menu.releaseLocked()
+		return
+	}
+
+	menu.hideUnclaimed()
+}
+
+// release hides an item that was claimed from the pool, such as the
+// menu of a program that was disabled, so that claimOne can reuse it.
+func (o *menuPool) release(menu *pooledMenu) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	menu.releaseLocked()
+}
+
+func (o *pooledMenu) releaseLocked() {
+	o.item.Hide()
+	o.setOnClick(nil)
+	o.released = true
+}
+
+// claim returns the next submenu item, which is reset to a visible,
+// enabled item with the given title and tooltip and no click handler.
+func (o *pooledMenu) claim(title string, tooltip string) *pooledMenu {
+	if o.numClaimed < len(o.children) {
+		child := o.children[o.numClaimed]
+		o.numClaimed++
+
+		child.reset(title, tooltip)
+
+		return child
+	}
+
+	item := o.item.AddSubMenuItem(title, tooltip)
+
+	child := &pooledMenu{item: item}
+	o.children = append(o.children, child)
+	o.numClaimed++
+
+	go func() {
+		for range item.ClickedCh {
+			child.clickMu.Lock()
+			onClick := child.onClick
+			child.clickMu.Unlock()
+
+			if onClick != nil {
+				onClick()
+			}
+		}
+	}()
+
+	return child
+}
+
+// reset makes a reused item visible and enabled with the given
+// title and tooltip and no click handler. Its submenus are claimed
+// from the start again.
+func (o *pooledMenu) reset(title string, tooltip string) {
+	o.item.SetTitle(title)
+	o.item.SetTooltip(tooltip)
+	o.item.Enable()
+	o.item.Show()
+	o.setOnClick(nil)
+	o.released = false
+	o.resetClaims()
+}
+
+// setOnClick sets the function that is called when the
+// item is clicked, replacing the previous function.
+func (o *pooledMenu) setOnClick(fn func()) {
+	o.clickMu.Lock()
+	o.onClick = fn
+	o.clickMu.Unlock()
+}
+
+func (o *pooledMenu) resetClaims() {
+	o.numClaimed = 0
+
+	for _, child := range o.children {
+		child.resetClaims()
+	}
+}
+
+func (o *pooledMenu) hideUnclaimed() {
+	for i, child := range o.children {
+		if i >= o.numClaimed {
+			child.item.Hide()
+			child.setOnClick(nil)
+			continue
+		}
+
+		child.hideUnclaimed()
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/bundle"
+	"github.com/SeungKang/blaj/internal/offsetfeed"
+)
+
+// errOffsetsUpdated is sent on the program error channel to
+// reload the program configs after an offset feed is updated.
+var errOffsetsUpdated = errors.New("offset feed updated")
+
+// watchOffsetFeed downloads the program's offset feed when it starts
+// and then every OffsetFeedInterval, since configs only use the last
+// downloaded feed. Once a feed with a new serial is found and no
+// programs are attached, errOffsetsUpdated is sent on reload so that
+// the configs are loaded again with the new addresses.
+func (o *app) watchOffsetFeed(ctx context.Context, program *appconfig.ProgramConfig, reload chan<- error) {
+	general := program.General
+
+	var ticks <-chan time.Time
+	if general.OffsetFeedInterval > 0 {
+		ticker := time.NewTicker(general.OffsetFeedInterval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	pendingVersion := ""
+	first := true
+
+	for {
+		if first {
+			first = false
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+			}
+		}
+
+		if pendingVersion == "" {
+			result, err := offsetfeed.Fetch(general.OffsetFeed, general.OffsetFeedSigner, general.ExeName)
+			if err != nil {
+				log.Printf("failed to check offset feed for %s - %v", general.ExeName, err)
+				continue
+			}
+
+			if result.Stale != nil {
+				log.Printf("failed to update offset feed for %s - %v", general.ExeName, result.Stale)
+				continue
+			}
+
+			if result.Manifest.Serial == general.OffsetFeedSerial {
+				continue
+			}
+
+			pendingVersion = result.Manifest.Version
+
+			log.Printf("offsets for %s updated to version %q", general.ExeName, pendingVersion)
+			o.errorLog.addEntry(general.ExeName + ": offsets updated to version " + pendingVersion +
+				" (they will be applied once no programs are attached)")
+		}
+
+		// Reloading restarts every program routine,
+		// which would discard attached programs' state.
+		if o.attachedCount() > 0 {
+			if ticks == nil {
+				// Keep waiting for the programs to exit.
+				ticker := time.NewTicker(time.Minute)
+				defer ticker.Stop()
+
+				ticks = ticker.C
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case reload <- errOffsetsUpdated:
+		}
+
+		return
+	}
+}
+
+func runSignFeed(args []string) error {
+	flags := flag.NewFlagSet("signfeed", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s signfeed -key <key-file> [options] <manifest-file>\n\n"+
+			"signs an offset manifest so that it can be published as an offset feed.\n"+
+			"the feed is saved next to the manifest with a .feed.json extension\n\n",
+			appName)
+		flags.PrintDefaults()
+	}
+
+	keyPath := flags.String("key", "", "Sign the manifest with the private key in `key-file` (see keygen)")
+	outPath := flags.String("o", "", "The `path` to save the feed to")
+
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("please specify a manifest file")
+	}
+
+	if *keyPath == "" {
+		flags.Usage()
+		return errors.New("please specify a key file")
+	}
+
+	keyStr, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read private key file - %w", err)
+	}
+
+	key, err := bundle.ParsePrivateKey(string(keyStr))
+	if err != nil {
+		return err
+	}
+
+	manifestPath := flags.Arg(0)
+
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	feed, err := offsetfeed.Sign(manifest, key)
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		*outPath = strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath)) + ".feed.json"
+	}
+
+	err = os.WriteFile(*outPath, feed, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write feed - %w", err)
+	}
+
+	log.Printf("saved feed to %s", *outPath)
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package blaj exposes blaj's engine for use by other Go programs,
+// such as custom trainers and autosplitters, without the tray
+// front-end.
+//
+// A program config is loaded with LoadConfig or ParseConfig using the
+// same syntax as blaj's config files. Attach then attaches to the
+// running program, after which sections can be saved, restored, and
+// written by name:
+//
+//	config, err := blaj.LoadConfig(`C:\Users\me\.blaj\game.conf`)
+//	if err != nil {
+//		return err
+//	}
+//
+//	process, err := blaj.Attach(config)
+//	if err != nil {
+//		return err
+//	}
+//	defer process.Close()
+//
+//	state, err := process.Save("checkpoint")
+//	if err != nil {
+//		return err
+//	}
+//
+//	err = process.Restore("checkpoint", state)
+//
+// Sections are referred to by their name, their label, or by their type and
+// one-based index (e.g. "saverestore#1" or "writer#2").
+package blaj
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/SeungKang/blaj/internal/remoteconf"
+)
+
+// Config is a parsed program config.
+type Config struct {
+	program *appconfig.ProgramConfig
+}
+
+// LoadConfig parses the program config file at filePath. Included
+// config files that have not been downloaded yet are downloaded.
+func LoadConfig(filePath string) (*Config, error) {
+	return loadConfig(func() (*appconfig.ProgramConfig, error) {
+		return appconfig.ProgramConfigFromPath(filePath)
+	})
+}
+
+// ParseConfig parses a program config from r. Included config
+// files that have not been downloaded yet are downloaded.
+func ParseConfig(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadConfig(func() (*appconfig.ProgramConfig, error) {
+		return appconfig.ParseProgramConfig(bytes.NewReader(data))
+	})
+}
+
+// loadConfig calls load until the program config's missing
+// includes are downloaded, since configs are parsed using
+// the cached copies of their includes.
+func loadConfig(load func() (*appconfig.ProgramConfig, error)) (*Config, error) {
+	for {
+		program, err := load()
+		if err == nil {
+			return &Config{program: program}, nil
+		}
+
+		downloaded, fetchErr := remoteconf.FetchMissing(err)
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+
+		if !downloaded {
+			return nil, err
+		}
+	}
+}
+
+// ExeName returns the name of the program's executable.
+func (o *Config) ExeName() string {
+	return o.program.General.ExeName
+}
+
+// State is the saved value of each pointer in a SaveRestore
+// section, keyed by pointer name.
+type State map[string][]byte
+
+// Process is an attachment to a running program.
+// It is not safe for concurrent use.
+type Process struct {
+	config  *Config
+	oneShot *progctl.OneShot
+}
+
+// Attach attaches to the running program described by config.
+// The caller must call Close when finished.
+func Attach(config *Config) (*Process, error) {
+	oneShot, err := progctl.NewOneShot(config.program, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Process{
+		config:  config,
+		oneShot: oneShot,
+	}, nil
+}
+
+// Close detaches from the program.
+func (o *Process) Close() {
+	o.oneShot.Close()
+}
+
+// Save reads the current value of each pointer in the
+// SaveRestore section.
+func (o *Process) Save(section string) (State, error) {
+	saveRestore, err := o.saveRestore(section)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := o.oneShot.Save(saveRestore)
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// Restore writes the values in state to the pointers of the
+// SaveRestore section. Pointers without a value in state are
+// skipped.
+func (o *Process) Restore(section string, state State) error {
+	saveRestore, err := o.saveRestore(section)
+	if err != nil {
+		return err
+	}
+
+	return o.oneShot.Restore(saveRestore, state)
+}
+
+// Write writes the data of each pointer in the Writer section.
+func (o *Process) Write(section string) error {
+	found, err := o.config.program.SectionByName(section)
+	if err != nil {
+		return err
+	}
+
+	writer, isWriter := found.(*appconfig.Writer)
+	if !isWriter {
+		return fmt.Errorf("section %q is not a writer section", section)
+	}
+
+	return o.oneShot.Write(writer)
+}
+
+// Resolve returns the address that the named pointer currently
+// points to. The name may be a pointer's parameter name (e.g.
+// "xCoordPointer_4") or its label.
+func (o *Process) Resolve(pointerName string) (uintptr, error) {
+	pointer, err := o.pointer(pointerName)
+	if err != nil {
+		return 0, err
+	}
+
+	return o.oneShot.ResolveAddr(pointer)
+}
+
+func (o *Process) saveRestore(section string) (*appconfig.SaveRestore, error) {
+	found, err := o.config.program.SectionByName(section)
+	if err != nil {
+		return nil, err
+	}
+
+	saveRestore, isSaveRestore := found.(*appconfig.SaveRestore)
+	if !isSaveRestore {
+		return nil, fmt.Errorf("section %q is not a saverestore section", section)
+	}
+
+	return saveRestore, nil
+}
+
+func (o *Process) pointer(name string) (appconfig.Pointer, error) {
+	matches := func(pointer appconfig.Pointer) bool {
+		return strings.EqualFold(pointer.Name, name) ||
+			(pointer.Label != "" && strings.EqualFold(pointer.Label, name))
+	}
+
+	for _, saveRestore := range o.config.program.SaveRestores {
+		for _, pointer := range saveRestore.Pointers {
+			if matches(pointer) {
+				return pointer, nil
+			}
+		}
+	}
+
+	for _, writer := range o.config.program.Writers {
+		for _, writePointer := range writer.Pointers {
+			if matches(writePointer.Pointer) {
+				return writePointer.Pointer, nil
+			}
+		}
+	}
+
+	return appconfig.Pointer{}, fmt.Errorf("unknown pointer: %q", name)
+}
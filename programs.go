@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/anticheat"
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/input"
+	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/SeungKang/blaj/internal/stats"
+)
+
+// programsEnv is what the program routines of the loaded configs
+// share. It is kept so that a program that is enabled after the
+// configs are loaded is started the same way as the others.
+type programsEnv struct {
+	// ctx is done once the configs are reloaded.
+	ctx       context.Context
+	configDir string
+	keyboard  *input.Dispatcher
+	guard     *anticheat.Guard
+
+	// exited receives an error when a routine exits
+	// on its own, which reloads the configs.
+	exited chan error
+}
+
+// newRoutine creates the routine of a program. It is
+// started by startProgram once the program's UI is created.
+func (o *app) newRoutine(env *programsEnv, program *appconfig.ProgramConfig) (*progctl.Routine, error) {
+	var counters *stats.Counters
+	if len(program.Counters) > 0 {
+		var err error
+		counters, err = stats.OpenCounters(filepath.Join(env.configDir, statsDirName,
+			program.General.ExeName+".counters.json"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	routine := &progctl.Routine{
+		Program:  program,
+		Keyboard: env.keyboard,
+		Guard:    env.guard,
+		Counters: counters,
+		SafeMode: o.safeMode,
+
+		ScanInterval:         o.settings.ProcessScanInterval,
+		WindowPollInterval:   o.settings.WindowPollInterval,
+		MinFreezeInterval:    o.settings.MinFreezeInterval,
+		LowerPollingPriority: o.settings.PrioritizeInput,
+		MinimalAccess:        o.settings.LowProfile,
+	}
+
+	if o.settings.RecordTraces {
+		routine.TraceDir = filepath.Join(env.configDir, tracesDirName)
+	}
+
+	return routine, nil
+}
+
+// startProgram starts the routine of a program, along with the
+// checks of its offset feed. The routine can be stopped without
+// stopping the other programs using stopProgram.
+func (o *app) startProgram(env *programsEnv, ui *programUI) {
+	ctx, stop := context.WithCancel(env.ctx)
+	ui.stop = stop
+
+	program := ui.program
+
+	if program.General.OffsetFeed != "" {
+		go o.watchOffsetFeed(ctx, program, env.exited)
+	}
+
+	ui.routine.Start(ctx)
+
+	go func() {
+		<-ui.routine.Done()
+
+		// The program was removed or disabled.
+		if ctx.Err() != nil && env.ctx.Err() == nil {
+			return
+		}
+
+		select {
+		case env.exited <- i18n.Errorf(i18n.ErrProgramExited,
+			program.General.ExeName, ui.routine.Err()):
+		case <-env.ctx.Done():
+		}
+	}()
+}
+
+// stopProgram stops the routine of a program whose config file was
+// removed or disabled, and releases its menu, without reloading the
+// other programs.
+func (o *app) stopProgram(ui *programUI) {
+	ui.stop()
+
+	select {
+	case <-ui.routine.Done():
+	case <-time.After(shutdownTimeout):
+		log.Printf("timed out after %s waiting for %s to stop",
+			shutdownTimeout, ui.program.General.ExeName)
+	}
+
+	o.programsMu.Lock()
+	var programs []*programUI
+	for _, program := range o.programs {
+		if program != ui {
+			programs = append(programs, program)
+		}
+	}
+	o.programs = programs
+	o.programsMu.Unlock()
+
+	if ui.hasError {
+		ui.hasError = false
+		o.addErrors(-1)
+	}
+
+	o.programMenus.release(ui.menu)
+
+	log.Printf("stopped %s", ui.program.General.ExeName)
+}
+
+// programByFile returns the running program whose config
+// file is named fileName, or nil if there is none.
+func (o *app) programByFile(fileName string) *programUI {
+	o.programsMu.Lock()
+	defer o.programsMu.Unlock()
+
+	for _, program := range o.programs {
+		if program.fileName == fileName {
+			return program
+		}
+	}
+
+	return nil
+}
+
+// startProgramFile loads the config file named fileName and starts
+// its program alongside the running programs. It returns false if
+// the configs are not loaded, in which case they must be reloaded
+// to start the program.
+func (o *app) startProgramFile(fileName string) (bool, error) {
+	o.programsMu.Lock()
+	env := o.programsEnv
+	running := o.programs
+	o.programsMu.Unlock()
+
+	if env == nil {
+		return false, nil
+	}
+
+	program, err := o.configs.load(filepath.Join(env.configDir, fileName))
+	if err != nil {
+		return true, fmt.Errorf("failed to load %s - %w", fileName, err)
+	}
+
+	if program.General.Disabled {
+		return true, fmt.Errorf("%s is disabled by its config file", fileName)
+	}
+
+	err = env.guard.CheckExe(program.General.ExeName)
+	if err != nil {
+		return true, fmt.Errorf("%s - %w", fileName, err)
+	}
+
+	file := &appconfig.LoadedFile{
+		Name:    fileName,
+		Program: program,
+	}
+
+	var runningFiles []*appconfig.LoadedFile
+	for _, ui := range running {
+		runningFiles = append(runningFiles, &appconfig.LoadedFile{
+			Name:    ui.fileName,
+			Program: ui.program,
+		})
+	}
+
+	file.Conflicts = appconfig.Conflicts(program, runningFiles)
+
+	routine, err := o.newRoutine(env, program)
+	if err != nil {
+		return true, err
+	}
+
+	var ui *programUI
+	o.programMenus.claimOne(program.General.ExeName, "", func(menu *pooledMenu) bool {
+		o.programsMu.Lock()
+		defer o.programsMu.Unlock()
+
+		// The configs were reloaded while the
+		// program's config file was loaded.
+		if o.programsEnv != env {
+			return false
+		}
+
+		ui = newProgramUI(fileName, program, routine, o, menu)
+		o.startProgram(env, ui)
+		o.programs = append(append([]*programUI(nil), o.programs...), ui)
+
+		return true
+	})
+
+	if ui == nil {
+		return true, nil
+	}
+
+	log.Printf("started %s", program.General.ExeName)
+
+	o.logWarnings(file)
+
+	return true, nil
+}
+
+// logWarnings adds the warnings of a loaded config file
+// to the log and the error log.
+func (o *app) logWarnings(file *appconfig.LoadedFile) {
+	var warnings []string
+	warnings = append(warnings, file.Program.Warnings...)
+	warnings = append(warnings, file.Conflicts...)
+
+	if file.Program.General.InputDevice != "" &&
+		!strings.EqualFold(o.settings.InputBackend, input.RawInputBackend) {
+		warnings = append(warnings, "inputDevice requires the "+input.RawInputBackend+
+			" inputBackend, so none of its keybinds will work")
+	}
+
+	for _, warning := range warnings {
+		log.Printf("warning: %s - %s", file.Name, warning)
+		o.errorLog.addEntry(file.Name + ": " + warning)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/progctl"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	newIssueURL = "https://github.com/SeungKang/blaj/issues/new"
+
+	// maxReportMessageLen limits the length of the error message
+	// in issue URLs, since browsers limit the length of URLs.
+	maxReportMessageLen = 1000
+)
+
+// hexNumberRe matches hexadecimal numbers, which are removed from
+// reports because they are usually offsets or addresses.
+var hexNumberRe = regexp.MustCompile(`0[xX][0-9a-fA-F]+`)
+
+// errorReport is the information about an error log
+// entry that is included in a GitHub issue.
+type errorReport struct {
+	message string
+
+	// section and label identify the config section whose
+	// action failed. They are empty if the error is not
+	// caused by a section.
+	section string
+	label   string
+}
+
+// newErrorReport returns an errorReport for an error that
+// occurred while controlling the program named exeName.
+func newErrorReport(exeName string, err error) errorReport {
+	report := errorReport{message: exeName + ": " + err.Error()}
+
+	var sectionErr *progctl.SectionError
+	if errors.As(err, &sectionErr) {
+		report.section = sectionErr.Section
+		report.label = sectionErr.Label
+	}
+
+	return report
+}
+
+// issueURL returns the URL of a new GitHub issue that is
+// prefilled with the report and diagnostic information.
+// Offsets and the user's home directory are removed.
+func (o errorReport) issueURL() string {
+	homeDir, _ := os.UserHomeDir()
+	message := string(newPathSanitizer(homeDir)([]byte(o.message)))
+	message = hexNumberRe.ReplaceAllString(message, "0x...")
+	message = truncate(message, maxReportMessageLen)
+	title := truncate(message, 80)
+
+	body := &strings.Builder{}
+	fmt.Fprintf(body, "**Error:**\n\n```\n%s\n```\n\n", message)
+	if o.section != "" {
+		fmt.Fprintf(body, "**Section:** %s", o.section)
+		if o.label != "" {
+			fmt.Fprintf(body, " (%s)", o.label)
+		}
+		fmt.Fprint(body, "\n")
+	}
+
+	osVersion := windows.RtlGetVersion()
+	fmt.Fprintf(body, "**%s version:** %s\n", appName, version)
+	fmt.Fprintf(body, "**Windows version:** %d.%d.%d\n\n",
+		osVersion.MajorVersion, osVersion.MinorVersion, osVersion.BuildNumber)
+	fmt.Fprint(body, "**What were you doing when the error occurred?**\n\n")
+
+	query := url.Values{}
+	query.Set("title", title)
+	query.Set("body", body.String())
+
+	return newIssueURL + "?" + query.Encode()
+}
+
+// truncate shortens str to at most maxRunes runes.
+func truncate(str string, maxRunes int) string {
+	runes := []rune(str)
+	if len(runes) <= maxRunes {
+		return str
+	}
+
+	return string(runes[:maxRunes]) + "..."
+}
+
+// openURL opens rawURL in the default browser.
+func openURL(rawURL string) {
+	_ = exec.Command("rundll32.exe", "url.dll,FileProtocolHandler", rawURL).Start()
+}
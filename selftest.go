@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/SeungKang/blaj/internal/input"
+	"github.com/SeungKang/blaj/internal/progctl"
+)
+
+// selfTestCheck is a diagnostic that is run when blaj starts.
+type selfTestCheck struct {
+	name string
+	fn   func(*app) error
+
+	// hint tells the user how to fix a failure.
+	hint string
+}
+
+var selfTestChecks = []selfTestCheck{
+	{
+		name: "config directory",
+		fn:   checkConfigDirWritable,
+		hint: "make sure that the .blaj directory in your home directory is not read-only",
+	},
+	{
+		name: "keyboard input",
+		fn:   checkKeyboardInput,
+		hint: "try setting inputBackend to rawinput in the app settings, or check if other software blocks keyboard hooks",
+	},
+	{
+		name: "memory access",
+		fn: func(*app) error {
+			return progctl.CheckMemoryAccess()
+		},
+		hint: "check if antivirus software is blocking blaj, or add an exception for it",
+	},
+}
+
+// selfTest runs each selfTestCheck and adds failures to the error
+// log, so that problems that would otherwise cause confusing errors
+// in the middle of a session are reported when blaj starts.
+func (o *app) selfTest() {
+	failed := 0
+
+	for _, check := range selfTestChecks {
+		err := check.fn(o)
+		if err != nil {
+			failed++
+
+			log.Printf("self-test: %s check failed - %s", check.name, err)
+			o.errorLog.addEntry(fmt.Sprintf("self-test: %s check failed - %s (%s)",
+				check.name, err, check.hint))
+		}
+	}
+
+	if failed == 0 {
+		log.Printf("self-test: all %d checks passed", len(selfTestChecks))
+	}
+}
+
+func checkConfigDirWritable(*app) error {
+	configDir, err := configDirPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(configDir, ".selftest-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a file - %w", err)
+	}
+
+	_, err = f.WriteString(appName)
+	closeErr := f.Close()
+	removeErr := os.Remove(f.Name())
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to write a file - %w", err)
+	case closeErr != nil:
+		return fmt.Errorf("failed to close a file - %w", closeErr)
+	case removeErr != nil:
+		return fmt.Errorf("failed to remove a file - %w", removeErr)
+	}
+
+	return nil
+}
+
+func checkKeyboardInput(o *app) error {
+	// Raw input is registered for the whole process, so releasing
+	// a second raw input Dispatcher would also stop the app's
+	// Dispatcher from receiving input. The app's Dispatcher reports
+	// its own errors if raw input cannot be registered.
+	if strings.EqualFold(o.settings.InputBackend, input.RawInputBackend) {
+		log.Printf("self-test: skipping keyboard input check for the %s backend",
+			input.RawInputBackend)
+		return nil
+	}
+
+	keyboard, err := input.NewDispatcher(o.settings.InputBackend, false, false)
+	if err != nil {
+		return err
+	}
+
+	keyboard.Release()
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/SeungKang/blaj/internal/session"
+	"github.com/getlantern/systray"
+)
+
+const sessionsDirName = "sessions"
+
+func (o *app) addExportSessionMenu() {
+	parent := systray.AddMenuItem(i18n.T(i18n.ExportSessionMenu), i18n.T(i18n.ExportSessionMenuTooltip))
+	asJSON := parent.AddSubMenuItem(i18n.T(i18n.ExportSessionJSONMenu), "")
+	asCSV := parent.AddSubMenuItem(i18n.T(i18n.ExportSessionCSVMenu), "")
+
+	go func() {
+		for {
+			var asCSVFile bool
+			select {
+			case <-asJSON.ClickedCh:
+			case <-asCSV.ClickedCh:
+				asCSVFile = true
+			}
+
+			filePath, err := o.exportSessions(asCSVFile)
+			if err != nil {
+				log.Printf("failed to export session log - %s", err)
+				o.errorLog.addEntry(err.Error())
+				continue
+			}
+
+			log.Printf("exported session log to %s", filePath)
+
+			showInExplorer(filePath)
+		}
+	}()
+}
+
+// exportSessions writes the session timeline to a new JSON file,
+// or a CSV file if asCSV is true, in the sessions directory and
+// returns its path.
+func (o *app) exportSessions(asCSV bool) (string, error) {
+	configDir, err := configDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	sessionsDir := filepath.Join(configDir, sessionsDirName)
+	err = os.MkdirAll(sessionsDir, 0o700)
+	if err != nil {
+		return "", fmt.Errorf("failed to create sessions directory - %w", err)
+	}
+
+	ext := ".json"
+	if asCSV {
+		ext = ".csv"
+	}
+
+	filePath := filepath.Join(sessionsDir,
+		"session-"+time.Now().Format("20060102-150405")+ext)
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session log file - %w", err)
+	}
+	defer f.Close()
+
+	if asCSV {
+		err = o.timeline.WriteCSV(f)
+	} else {
+		err = o.timeline.WriteJSON(f)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return filePath, f.Close()
+}
+
+// showInExplorer opens an Explorer window with the file selected.
+func showInExplorer(filePath string) {
+	_ = exec.Command("explorer.exe", "/select,", filePath).Start()
+}
+
+func (o *programUI) ActionPerformed(exename string, op string, section string, label string, trigger *progctl.ActionTrigger) {
+	var input *session.Input
+	if trigger != nil {
+		input = &session.Input{
+			KeyTime:       trigger.KeyTime,
+			Foreground:    trigger.Foreground,
+			ForegroundPID: trigger.ForegroundPID,
+		}
+	}
+
+	o.app.timeline.Record(exename, op, section, label, input)
+
+	pid, _ := o.routine.PID()
+	o.app.runHooks(hookEvent{
+		name:    op,
+		exeName: exename,
+		pid:     pid,
+		section: section,
+		label:   label,
+	})
+}
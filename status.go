@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/ipc"
+	"github.com/SeungKang/blaj/internal/progctl"
+	"github.com/SeungKang/blaj/internal/user32"
+	"github.com/getlantern/systray"
+)
+
+// ipcAuditLogName is the name of the file in the config directory
+// that records every request made to the IPC server.
+const ipcAuditLogName = "ipc-audit.log"
+
+// setPrograms sets the running programs and the environment they
+// were started with, and returns the previous programs.
+func (o *app) setPrograms(programs []*programUI, env *programsEnv) []*programUI {
+	o.programsMu.Lock()
+	defer o.programsMu.Unlock()
+
+	previous := o.programs
+	o.programs = programs
+	o.programsEnv = env
+
+	return previous
+}
+
+func (o *app) serveIPC(ctx context.Context) {
+	configDir, err := configDirPath()
+	if err != nil {
+		log.Printf("failed to start ipc server - %s", err)
+		return
+	}
+
+	token, err := ipc.LoadOrCreateToken(filepath.Join(configDir, ipc.TokenFileName))
+	if err != nil {
+		log.Printf("failed to load ipc token - %s", err)
+		return
+	}
+
+	server := &ipc.Server{
+		AddrFilePath: filepath.Join(configDir, ipc.AddrFileName),
+		ListenAddr:   o.settings.IPCAddress,
+		AllowRemote:  o.settings.IPCAllowRemote,
+		Token:        token,
+		StatusFn:     o.status,
+		CaptureFn:    o.captureKeys,
+	}
+
+	// The server is still started without an audit log,
+	// since blaj status and the other commands need it.
+	auditLog, err := openAuditLogFile(filepath.Join(configDir, ipcAuditLogName),
+		maxIPCAuditLogSize)
+	if err != nil {
+		log.Printf("failed to open ipc audit log - %s", err)
+		o.errorLog.addEntry("failed to open ipc audit log - " + err.Error())
+	} else {
+		defer auditLog.Close()
+		server.AuditLog = auditLog
+	}
+
+	if o.settings.IPCAllowRemote {
+		log.Printf("ipc server accepts remote clients on %s", o.settings.IPCAddress)
+	}
+
+	err = server.Serve(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("ipc server exited - %s", err)
+	}
+}
+
+func (o *app) addCopyTokenMenu() {
+	copyToken := systray.AddMenuItem(i18n.T(i18n.CopyTokenMenu), i18n.T(i18n.CopyTokenMenuTooltip))
+
+	go func() {
+		for range copyToken.ClickedCh {
+			err := copyIPCToken()
+			if err != nil {
+				log.Printf("failed to copy ipc token - %s", err)
+				o.errorLog.addEntry(err.Error())
+				continue
+			}
+
+			log.Printf("copied ipc token to clipboard")
+		}
+	}()
+}
+
+func copyIPCToken() error {
+	configDir, err := configDirPath()
+	if err != nil {
+		return err
+	}
+
+	token, err := ipc.LoadOrCreateToken(filepath.Join(configDir, ipc.TokenFileName))
+	if err != nil {
+		return err
+	}
+
+	return user32.SetClipboardText(token)
+}
+
+func (o *app) status() ipc.Status {
+	status := ipc.Status{
+		Version: version,
+		Errors:  o.errorLog.recent(),
+	}
+
+	o.programsMu.Lock()
+	programs := o.programs
+	o.programsMu.Unlock()
+
+	for _, program := range programs {
+		status.Programs = append(status.Programs, program.status())
+	}
+
+	// Running git is only expected once
+	// the user opts into git snapshots.
+	if o.settings.GitSnapshots {
+		configDir, err := configDirPath()
+		if err == nil {
+			status.ConfigRepo = configRepoStatus(configDir)
+		}
+	}
+
+	return status
+}
+
+// legacyState maps state to one of the states that were reported
+// before the detailed states were added, so that scripts which
+// check the state field keep working.
+func legacyState(state progctl.State) string {
+	switch state {
+	case progctl.StateAttached, progctl.StateDegraded, progctl.StateIdle:
+		return "attached"
+	case progctl.StateError:
+		return "error"
+	default:
+		return "waiting"
+	}
+}
+
+func (o *programUI) status() ipc.ProgramStatus {
+	o.mu.Lock()
+	status := ipc.ProgramStatus{
+		ExeName:     o.program.General.ExeName,
+		State:       legacyState(o.state),
+		StateDetail: string(o.state),
+		LastError:   o.lastErr,
+	}
+
+	if !o.attachedAt.IsZero() {
+		attachedAt := o.attachedAt
+		status.AttachedAt = &attachedAt
+	}
+	o.mu.Unlock()
+
+	total := o.app.attachedTimes.Get(o.program.General.ExeName)
+	status.TotalAttached = int64(total / time.Second)
+
+	processStats, err := o.routine.ProcessStats()
+	if err == nil {
+		status.Process = &ipc.ProcessStatus{
+			WorkingSet: processStats.WorkingSet,
+			CPUPercent: processStats.CPUPercent,
+		}
+	}
+
+	pointerErrs, err := o.routine.PointerErrors()
+	if err == nil && len(pointerErrs) > 0 {
+		status.PointerErrors = make(map[string]string, len(pointerErrs))
+		for name, pointerErr := range pointerErrs {
+			status.PointerErrors[name] = pointerErr.Error()
+		}
+	}
+
+	for _, saveRestore := range o.program.SaveRestores {
+		section := ipc.SectionStatus{
+			Type:     "SaveRestore",
+			Label:    saveRestore.Label,
+			Keybinds: make(map[string]string),
+		}
+
+		// saveState and restoreState are optional
+		// if the section has slots.
+		if saveRestore.SaveState != (appconfig.Key{}) {
+			section.Keybinds["saveState"] = saveRestore.SaveState.String()
+		}
+
+		if saveRestore.RestoreState != (appconfig.Key{}) {
+			section.Keybinds["restoreState"] = saveRestore.RestoreState.String()
+		}
+
+		if len(saveRestore.Slots) > 0 {
+			section.Keybinds["slotKeys"] = saveRestore.SlotRange()
+		}
+
+		if seed := saveRestore.Seed; seed != nil {
+			section.Type = "Seed"
+
+			if seed.HasWriteValue() {
+				section.Keybinds["writeValue"] = seed.WriteValue.String()
+			}
+
+			if seed.HasReroll() {
+				section.Keybinds["reroll"] = seed.Reroll.String()
+			}
+		}
+
+		status.Sections = append(status.Sections, section)
+	}
+
+	for _, writer := range o.program.Writers {
+		status.Sections = append(status.Sections, ipc.SectionStatus{
+			Type:  "Writer",
+			Label: writer.Label,
+			Keybinds: map[string]string{
+				"keybind": writer.Keybind.String(),
+			},
+		})
+	}
+
+	return status
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/appconfig"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/getlantern/systray"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	supportDirName = "support"
+
+	// maxSupportLogSize is the number of bytes at the end
+	// of the log file that are included in support bundles.
+	maxSupportLogSize = 1 << 20
+)
+
+func (o *app) addSupportBundleMenu() {
+	parent := systray.AddMenuItem(i18n.T(i18n.SupportBundleMenu), i18n.T(i18n.SupportBundleMenuTooltip))
+	redacted := parent.AddSubMenuItem(i18n.T(i18n.SupportBundleRedactedMenu), "")
+	withOffsets := parent.AddSubMenuItem(i18n.T(i18n.SupportBundleOffsetsMenu), "")
+
+	go func() {
+		for {
+			var redactOffsets bool
+			select {
+			case <-redacted.ClickedCh:
+				redactOffsets = true
+			case <-withOffsets.ClickedCh:
+			}
+
+			filePath, err := o.createSupportBundle(redactOffsets)
+			if err != nil {
+				log.Printf("failed to create support bundle - %s", err)
+				o.errorLog.addEntry(err.Error())
+				continue
+			}
+
+			log.Printf("created support bundle at %s", filePath)
+
+			showInExplorer(filePath)
+		}
+	}()
+}
+
+// createSupportBundle writes a zip file containing information that
+// is useful for bug reports to the support directory and returns its
+// path. Nothing is sent anywhere, the user decides what to do with it.
+//
+// The bundle contains the end of the log file, recent errors, a dump
+// of the goroutines' stacks, version and OS information, and the
+// config files. Config bundles are skipped since they are encrypted.
+// The user's home directory is replaced in every file so that their
+// username is not revealed.
+func (o *app) createSupportBundle(redactOffsets bool) (string, error) {
+	configDir, err := configDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	supportDir := filepath.Join(configDir, supportDirName)
+	err = os.MkdirAll(supportDir, 0o700)
+	if err != nil {
+		return "", fmt.Errorf("failed to create support directory - %w", err)
+	}
+
+	filePath := filepath.Join(supportDir,
+		"support-"+time.Now().Format("20060102-150405")+".zip")
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create support bundle file - %w", err)
+	}
+	defer f.Close()
+
+	homeDir, _ := os.UserHomeDir()
+	sanitize := newPathSanitizer(homeDir)
+
+	zw := zip.NewWriter(f)
+
+	addFile := func(name string, data []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to support bundle - %w", name, err)
+		}
+
+		_, err = w.Write(sanitize(data))
+		if err != nil {
+			return fmt.Errorf("failed to write %s to support bundle - %w", name, err)
+		}
+
+		return nil
+	}
+
+	err = addFile("info.txt", o.supportInfo(redactOffsets))
+	if err != nil {
+		return "", err
+	}
+
+	err = addFile("errors.txt", o.supportErrors())
+	if err != nil {
+		return "", err
+	}
+
+	stacks := make([]byte, 1<<20)
+	err = addFile("goroutines.txt", stacks[:runtime.Stack(stacks, true)])
+	if err != nil {
+		return "", err
+	}
+
+	logData, err := readFileTail(filepath.Join(configDir, appName+".log"), maxSupportLogSize)
+	if err == nil {
+		err = addFile(appName+".log", logData)
+		if err != nil {
+			return "", err
+		}
+	} else if !os.IsNotExist(err) {
+		log.Printf("failed to read log file for support bundle - %s", err)
+	}
+
+	pathInfos, err := os.ReadDir(configDir)
+	if err != nil {
+		return "", i18n.Errorf(i18n.ErrReadConfigDir, err)
+	}
+
+	for _, pathInfo := range pathInfos {
+		name := pathInfo.Name()
+		if pathInfo.IsDir() {
+			continue
+		}
+
+		isProgramConfig := strings.HasSuffix(name, ".conf")
+		if !isProgramConfig && name != appconfig.AppConfigFileName {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(configDir, name))
+		if err != nil {
+			log.Printf("failed to read %s for support bundle - %s", name, err)
+			continue
+		}
+
+		if isProgramConfig && redactOffsets {
+			data = appconfig.RedactOffsets(data)
+		}
+
+		err = addFile("configs/"+name, data)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write support bundle - %w", err)
+	}
+
+	return filePath, f.Close()
+}
+
+func (o *app) supportInfo(redactOffsets bool) []byte {
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, "%s version: %s\n", appName, version)
+	fmt.Fprintf(buf, "go version: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	osVersion := windows.RtlGetVersion()
+	fmt.Fprintf(buf, "windows version: %d.%d.%d\n",
+		osVersion.MajorVersion, osVersion.MinorVersion, osVersion.BuildNumber)
+
+	fmt.Fprintf(buf, "language: %s\n", i18n.Language())
+	fmt.Fprintf(buf, "input backend: %s\n", o.settings.InputBackend)
+	fmt.Fprintf(buf, "safe mode: %t\n", o.safeMode)
+	fmt.Fprintf(buf, "offsets redacted: %t\n", redactOffsets)
+	fmt.Fprintf(buf, "created: %s\n", time.Now().Format(time.RFC3339))
+
+	return buf.Bytes()
+}
+
+func (o *app) supportErrors() []byte {
+	buf := bytes.NewBuffer(nil)
+
+	lastErr, lastErrTime := o.lastError()
+	if lastErr != nil {
+		fmt.Fprintf(buf, "last error (%s):\n%s\n\n", lastErrTime.Format(time.RFC3339), lastErr)
+	} else {
+		fmt.Fprint(buf, "last error: none\n\n")
+	}
+
+	fmt.Fprintln(buf, "error log:")
+	for _, message := range o.errorLog.recent() {
+		fmt.Fprintln(buf, message)
+	}
+
+	return buf.Bytes()
+}
+
+// readFileTail reads up to the last maxSize bytes of a file.
+func readFileTail(filePath string, maxSize int64) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() > maxSize {
+		_, err = f.Seek(-maxSize, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return io.ReadAll(f)
+}
+
+// newPathSanitizer returns a function that replaces homeDir
+// with %USERPROFILE%, ignoring case like Windows does.
+func newPathSanitizer(homeDir string) func([]byte) []byte {
+	if homeDir == "" {
+		return func(b []byte) []byte {
+			return b
+		}
+	}
+
+	homeDirRe := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(homeDir))
+
+	return func(b []byte) []byte {
+		return homeDirRe.ReplaceAllLiteral(b, []byte("%USERPROFILE%"))
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/cloudsync"
+	"github.com/SeungKang/blaj/internal/credui"
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/getlantern/systray"
+)
+
+const (
+	// statesDirName is the directory that saved state
+	// files are synced from (see blaj run -state).
+	statesDirName = "states"
+
+	// syncStateFileName is the name of the file in the cache
+	// directory that records the files as of the last sync.
+	syncStateFileName = "sync.json"
+
+	remoteConfigsDir = "configs"
+	remoteStatesDir  = "states"
+)
+
+// errConfigsSynced is sent on the app's reload channel to reload
+// the program configs after configs are pulled from remote storage.
+var errConfigsSynced = errors.New("configs pulled from remote storage")
+
+// addSyncMenu adds the "Sync now" menu item
+// if the syncURL setting is set.
+func (o *app) addSyncMenu() {
+	if o.settings.SyncURL == "" {
+		return
+	}
+
+	o.syncMenu = systray.AddMenuItem(i18n.T(i18n.SyncNowMenu), i18n.T(i18n.SyncNowMenuTooltip))
+}
+
+// syncFiles syncs the configs and saved states with the remote
+// storage at the syncURL setting when blaj starts, every
+// syncInterval, and when "Sync now" is clicked, until ctx is done.
+func (o *app) syncFiles(ctx context.Context) {
+	if o.syncMenu == nil {
+		return
+	}
+
+	configDir, err := configDirPath()
+	if err != nil {
+		log.Printf("failed to start syncing - %s", err)
+		return
+	}
+
+	// The states directory is created so that
+	// users know where to save state files.
+	err = os.MkdirAll(filepath.Join(configDir, statesDirName), 0o700)
+	if err != nil {
+		log.Printf("failed to create states directory - %s", err)
+	}
+
+	// The password is only prompted for once the
+	// server asks for one, and then remembered.
+	var password string
+
+	for {
+		password, err = o.syncOnce(ctx, configDir, password)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("failed to sync files - %s", err)
+			o.errorLog.addEntry("failed to sync files - " + err.Error())
+		}
+
+		var interval <-chan time.Time
+		if o.settings.SyncInterval > 0 {
+			interval = time.After(o.settings.SyncInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.syncMenu.ClickedCh:
+		case <-interval:
+		}
+	}
+}
+
+// syncOnce syncs the files with the remote storage, prompting for
+// a new password if the server rejects password. It returns the
+// password that was accepted.
+func (o *app) syncOnce(ctx context.Context, configDir string, password string) (string, error) {
+	syncer, err := o.newSyncer(configDir, password)
+	if err != nil {
+		return password, err
+	}
+
+	result, err := syncer.Sync(ctx)
+	if errors.Is(err, cloudsync.ErrUnauthorized) {
+		password, err = credui.PromptPassword(appName,
+			"Enter the password for "+o.settings.SyncURL,
+			o.settings.SyncUsername)
+		if err != nil {
+			return "", fmt.Errorf("failed to get sync password - %w", err)
+		}
+
+		syncer, err = o.newSyncer(configDir, password)
+		if err != nil {
+			return password, err
+		}
+
+		result, err = syncer.Sync(ctx)
+	}
+
+	if errors.Is(err, cloudsync.ErrUnauthorized) {
+		return "", err
+	}
+
+	if result != nil {
+		o.handleSyncResult(result)
+	}
+
+	return password, err
+}
+
+func (o *app) newSyncer(configDir string, password string) (*cloudsync.Syncer, error) {
+	provider, err := cloudsync.NewWebDAV(o.settings.SyncURL, o.settings.SyncUsername, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudsync.Syncer{
+		Provider:  provider,
+		StatePath: filepath.Join(configDir, "cache", syncStateFileName),
+		Dirs: []cloudsync.Dir{
+			{
+				Local:  configDir,
+				Remote: remoteConfigsDir,
+				Match:  isConfigFileName,
+				Write: func(filePath string, data []byte) error {
+					return writeConfigFile(configDir, filePath, data)
+				},
+			},
+			{
+				Local:  filepath.Join(configDir, statesDirName),
+				Remote: remoteStatesDir,
+			},
+		},
+	}, nil
+}
+
+// handleSyncResult logs the files that a sync changed, and reloads
+// the configs if any were pulled.
+func (o *app) handleSyncResult(result *cloudsync.Result) {
+	if len(result.Pushed) > 0 {
+		log.Printf("pushed %s", strings.Join(result.Pushed, ", "))
+	}
+
+	if len(result.Pulled) > 0 {
+		log.Printf("pulled %s", strings.Join(result.Pulled, ", "))
+	}
+
+	for _, name := range result.Conflicts {
+		message := name + " changed on this PC and in remote storage - the remote copy was saved with a " +
+			".remote suffix (delete it to keep this PC's version)"
+		log.Printf("sync conflict: %s", message)
+		o.errorLog.addEntry(message)
+	}
+
+	for _, name := range result.Pulled {
+		if strings.HasPrefix(name, remoteConfigsDir+"/") {
+			select {
+			case o.reload <- errConfigsSynced:
+			default:
+			}
+
+			return
+		}
+	}
+}
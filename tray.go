@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/i18n"
+	"github.com/SeungKang/blaj/internal/icon"
+	"github.com/getlantern/systray"
+)
+
+// trayIconSizes are the image sizes generated for the systray icon.
+// Windows picks the closest size for the current display scaling,
+// so this covers 100% through 200% scaling of both small and large
+// icon metrics.
+var trayIconSizes = []int{16, 20, 24, 32, 40, 48, 64}
+
+type trayIconKey struct {
+	base *byte
+	n    int
+}
+
+func (o *app) setChecking() {
+	o.setBaseIcon(systrayBlueIco)
+}
+
+func (o *app) setRunning() {
+	o.setBaseIcon(systrayGreenIco)
+}
+
+func (o *app) setError(err error) {
+	o.trayMu.Lock()
+	o.lastErr = err
+	o.lastErrTime = time.Now()
+	o.trayMu.Unlock()
+
+	o.setBaseIcon(systrayRedIco)
+}
+
+// lastError returns the most recent error passed to setError
+// and when it occurred, or nil if there has not been one.
+func (o *app) lastError() (error, time.Time) {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	return o.lastErr, o.lastErrTime
+}
+
+func (o *app) setBaseIcon(ico []byte) {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	o.baseIcon = ico
+	o.refreshTrayLocked()
+}
+
+// addAttached adjusts the number of attached programs shown
+// in the systray icon's badge and tooltip by delta.
+func (o *app) addAttached(delta int) {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	o.numAttached += delta
+	if o.numAttached < 0 {
+		o.numAttached = 0
+	}
+
+	o.refreshTrayLocked()
+}
+
+// attachedCount returns the number of attached programs.
+func (o *app) attachedCount() int {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	return o.numAttached
+}
+
+// addErrors adjusts the number of programs in an error state
+// shown in the systray tooltip by delta.
+func (o *app) addErrors(delta int) {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	o.numErrors += delta
+	if o.numErrors < 0 {
+		o.numErrors = 0
+	}
+
+	o.refreshTrayLocked()
+}
+
+func (o *app) setAppFailed(failed bool) {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	o.appFailed = failed
+	o.refreshTrayLocked()
+}
+
+func (o *app) resetStatus() {
+	o.trayMu.Lock()
+	defer o.trayMu.Unlock()
+
+	o.numAttached = 0
+	o.numErrors = 0
+	o.appFailed = false
+	o.refreshTrayLocked()
+}
+
+func (o *app) refreshTrayLocked() {
+	systray.SetTooltip(o.statusTextLocked())
+
+	key := trayIconKey{base: &o.baseIcon[0], n: o.numAttached}
+	ico, hasIt := o.trayIcons[key]
+	if !hasIt {
+		var err error
+		ico, err = trayIcon(o.baseIcon, o.numAttached)
+		if err != nil {
+			log.Printf("failed to create tray icon - %s", err)
+			systray.SetIcon(o.baseIcon)
+			return
+		}
+
+		if o.trayIcons == nil {
+			o.trayIcons = make(map[trayIconKey][]byte)
+		}
+
+		o.trayIcons[key] = ico
+	}
+
+	systray.SetIcon(ico)
+}
+
+// statusTextLocked returns the systray tooltip text
+// (e.g. "blaj v1.0.0 - 2 attached, 1 error").
+func (o *app) statusTextLocked() string {
+	status := []string{i18n.Sprintf(i18n.TooltipAttached, o.numAttached)}
+
+	numErrors := o.numErrors
+	if o.appFailed {
+		numErrors++
+	}
+
+	switch numErrors {
+	case 0:
+	case 1:
+		status = append(status, i18n.Sprintf(i18n.TooltipError, numErrors))
+	default:
+		status = append(status, i18n.Sprintf(i18n.TooltipErrors, numErrors))
+	}
+
+	title := appName
+	if version != "" {
+		title += " " + version
+	}
+
+	return title + " - " + strings.Join(status, ", ")
+}
+
+// trayIcon regenerates ico at each of the trayIconSizes. If n is
+// greater than zero, n is drawn in a badge on each image.
+func trayIcon(ico []byte, n int) ([]byte, error) {
+	img, err := icon.Decode(ico)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode icon - %w", err)
+	}
+
+	scaled := make([]image.Image, len(trayIconSizes))
+	for i, size := range trayIconSizes {
+		if n > 0 {
+			scaled[i] = icon.WithBadge(img, size, n)
+		} else {
+			scaled[i] = icon.Resize(img, size)
+		}
+	}
+
+	result, err := icon.Encode(scaled...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode icon - %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SeungKang/blaj/internal/i18n"
+)
+
+// sessionTooltipInterval is how often the session durations
+// shown in the programs' tooltips are updated.
+const sessionTooltipInterval = time.Minute
+
+// sessionStarted records when the program was attached to
+// and shows the session's duration in its tooltip.
+func (o *programUI) sessionStarted() {
+	attachedAt, err := o.routine.AttachedAt()
+	if err != nil {
+		attachedAt = time.Now()
+	}
+
+	o.mu.Lock()
+	o.attachedAt = attachedAt
+	o.mu.Unlock()
+
+	o.refreshSessionTooltip()
+}
+
+// sessionEnded adds the duration of the session to the total
+// time spent attached to the program, and logs both of them.
+func (o *programUI) sessionEnded() {
+	o.mu.Lock()
+	attachedAt := o.attachedAt
+	o.attachedAt = time.Time{}
+	o.mu.Unlock()
+
+	if attachedAt.IsZero() {
+		return
+	}
+
+	o.runningMenu.SetTooltip("")
+
+	exeName := o.program.General.ExeName
+	session := time.Since(attachedAt)
+
+	total, err := o.app.attachedTimes.Add(exeName, session)
+	if err != nil {
+		log.Printf("failed to save the total time attached to %s - %s", exeName, err)
+	}
+
+	log.Printf("attached to %s for %s (%s in total)",
+		exeName, formatUptime(session), formatUptime(total))
+}
+
+// refreshSessionTooltip shows how long the program has been
+// attached to in its menu item's tooltip, along with the total
+// time across sessions. It does nothing if the program is
+// not attached.
+func (o *programUI) refreshSessionTooltip() {
+	o.mu.Lock()
+	attachedAt := o.attachedAt
+	o.mu.Unlock()
+
+	if attachedAt.IsZero() {
+		return
+	}
+
+	session := time.Since(attachedAt)
+	total := o.app.attachedTimes.Get(o.program.General.ExeName) + session
+
+	o.runningMenu.SetTooltip(i18n.Sprintf(i18n.SessionTooltip,
+		formatUptime(session), formatUptime(total)))
+}
+
+// refreshSessionTooltips updates the session durations in the
+// tooltips of the attached programs until ctx is done.
+func (o *app) refreshSessionTooltips(ctx context.Context) {
+	ticker := time.NewTicker(sessionTooltipInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		o.programsMu.Lock()
+		programs := o.programs
+		o.programsMu.Unlock()
+
+		for _, program := range programs {
+			program.refreshSessionTooltip()
+		}
+	}
+}
+
+// formatUptime formats d in hours and minutes (e.g. "1h05m").
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}